@@ -7,6 +7,6 @@ import (
 
 func main() {
     if err := cmd.Execute(); err != nil {
-	os.Exit(3) // CLI/config error
+	os.Exit(cmd.ExitCode(err))
     }
 }
@@ -0,0 +1,162 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	tarMagicOffset = 257
+	tarBlockSize   = 512
+)
+
+// Unarchive returns the restorable file inside a tar artifact, and its name in
+// the archive. member selects the file by path or glob pattern, matched against
+// the full path or, for patterns without a slash, the base name. Without member,
+// a tar holding a single file, not counting checksum and metadata sidecars, is
+// unpacked into tempDir and the file returned. Any other tar, such as a
+// directory-format dump or a data directory, and any stream that is not a tar,
+// is returned as is with an empty name for the restorer to handle. Finding the
+// single file takes a copy of the whole tar in tempDir, which the disk space
+// check counts; with member, the tar is streamed. Closing the returned reader
+// closes rc.
+func Unarchive(rc io.ReadCloser, member, tempDir string) (io.ReadCloser, string, error) {
+	buffered := bufio.NewReader(rc)
+	header, _ := buffered.Peek(tarBlockSize)
+	if !isTar(header) {
+		if member != "" {
+			return nil, "", fmt.Errorf("backup.archive_member is %q but the backup is not a tar archive", member)
+		}
+		return &readCloser{Reader: buffered, closer: rc}, "", nil
+	}
+	if member != "" {
+		return extractMember(buffered, rc, member)
+	}
+
+	spooled, err := os.CreateTemp(tempDir, "restorable-archive-*.tar")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp file for the tar archive: %w", err)
+	}
+	closer := &tempFileCloser{file: spooled, src: rc}
+	if _, err := io.Copy(spooled, buffered); err != nil {
+		closer.Close()
+		return nil, "", fmt.Errorf("failed to unpack the tar archive: %w", err)
+	}
+
+	name, err := singleMember(spooled)
+	if err != nil {
+		closer.Close()
+		return nil, "", err
+	}
+	if _, err := spooled.Seek(0, io.SeekStart); err != nil {
+		closer.Close()
+		return nil, "", fmt.Errorf("failed to rewind the tar archive: %w", err)
+	}
+	if name == "" {
+		return &readCloser{Reader: spooled, closer: closer}, "", nil
+	}
+	return extractMember(spooled, closer, name)
+}
+
+// isTar reports whether header is the first block of a ustar, POSIX or GNU tar.
+func isTar(header []byte) bool {
+	return len(header) == tarBlockSize && bytes.HasPrefix(header[tarMagicOffset:], []byte("ustar"))
+}
+
+// singleMember returns the path of the only regular file in the tar, ignoring
+// sidecars, or "" if it holds none or several.
+func singleMember(f *os.File) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind the tar archive: %w", err)
+	}
+	var found string
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return found, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read the tar archive: %w", err)
+		}
+		name := memberName(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || isSidecar(name) {
+			continue
+		}
+		if found != "" {
+			return "", nil
+		}
+		found = name
+	}
+}
+
+// extractMember reads the tar in r up to the first file matching pattern and
+// returns a reader of its contents that closes closer.
+func extractMember(r io.Reader, closer io.Closer, pattern string) (io.ReadCloser, string, error) {
+	var names []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			closer.Close()
+			return nil, "", fmt.Errorf("failed to read the tar archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := memberName(hdr.Name)
+		if matchMember(pattern, name) {
+			return &readCloser{Reader: tr, closer: closer}, name, nil
+		}
+		names = append(names, name)
+	}
+	closer.Close()
+	if len(names) > 10 {
+		names = append(names[:10], "...")
+	}
+	return nil, "", fmt.Errorf("no file in the tar archive matches backup.archive_member %q (files: %s)", pattern, strings.Join(names, ", "))
+}
+
+// memberName normalizes a tar entry name, e.g. ./backup/db.dump to backup/db.dump.
+func memberName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// matchMember reports whether name is selected by pattern.
+func matchMember(pattern, name string) bool {
+	pattern = memberName(pattern)
+	if pattern == name {
+		return true
+	}
+	if ok, _ := path.Match(pattern, name); ok {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return false
+}
+
+// tempFileCloser closes and removes a spooled file, then closes the source it
+// was copied from.
+type tempFileCloser struct {
+	file *os.File
+	src  io.Closer
+}
+
+func (c *tempFileCloser) Close() error {
+	c.file.Close()
+	os.Remove(c.file.Name())
+	return c.src.Close()
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SourceFailure records a source in a chain that could not serve the artifact.
+type SourceFailure struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// ChainSource tries an ordered list of sources, e.g. a primary bucket, a DR-region
+// replica and a local cache directory, and acquires from the first that succeeds.
+type ChainSource struct {
+	Sources []BackupSource
+	served  BackupSource
+	// Failures lists the sources tried before one succeeded, in order.
+	Failures []SourceFailure
+}
+
+// Acquire returns the stream of the first source that can be acquired.
+func (s *ChainSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	s.served = nil
+	s.Failures = nil
+
+	var errs []error
+	for _, source := range s.Sources {
+		stream, err := source.Acquire(ctx)
+		if err == nil {
+			s.served = source
+			return stream, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		fmt.Printf("⚠ Backup source %s failed, trying next: %v\n", source.Identifier(), err)
+		s.Failures = append(s.Failures, SourceFailure{Source: source.Identifier(), Error: err.Error()})
+		errs = append(errs, fmt.Errorf("%s: %w", source.Identifier(), err))
+	}
+	return nil, fmt.Errorf("all backup sources failed: %w", errors.Join(errs...))
+}
+
+// Metadata returns the producer metadata of the source that served the artifact.
+func (s *ChainSource) Metadata(ctx context.Context) (*ProducerMetadata, error) {
+	provider, ok := s.served.(MetadataProvider)
+	if !ok {
+		return nil, nil
+	}
+	return provider.Metadata(ctx)
+}
+
+// AcquireRange resumes the download of the source that served the artifact.
+func (s *ChainSource) AcquireRange(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	ranger, ok := s.served.(RangeAcquirer)
+	if !ok {
+		return nil, errRangeUnsupported
+	}
+	return ranger.AcquireRange(ctx, offset)
+}
+
+// ArtifactName returns the artifact name of the source that served the artifact.
+func (s *ChainSource) ArtifactName(ctx context.Context) (string, error) {
+	reader, ok := s.served.(CompanionReader)
+	if !ok {
+		return "", fmt.Errorf("backup source %s cannot read checksum files", s.Identifier())
+	}
+	return reader.ArtifactName(ctx)
+}
+
+// ReadCompanion reads a file next to the artifact of the source that served it.
+func (s *ChainSource) ReadCompanion(ctx context.Context, name string) ([]byte, error) {
+	reader, ok := s.served.(CompanionReader)
+	if !ok {
+		return nil, fmt.Errorf("backup source %s cannot read checksum files", s.Identifier())
+	}
+	return reader.ReadCompanion(ctx, name)
+}
+
+// RecoveryPoint returns the recovery point of the source that served the artifact.
+func (s *ChainSource) RecoveryPoint() *RecoveryPoint {
+	provider, ok := s.served.(RecoveryPointProvider)
+	if !ok {
+		return nil
+	}
+	return provider.RecoveryPoint()
+}
+
+// ArtifactModTime returns the modification time reported by the source that served
+// the artifact.
+func (s *ChainSource) ArtifactModTime() *time.Time {
+	provider, ok := s.served.(ModTimeProvider)
+	if !ok {
+		return nil
+	}
+	return provider.ArtifactModTime()
+}
+
+// ArtifactSize returns the artifact size reported by the source that served the
+// artifact.
+func (s *ChainSource) ArtifactSize() int64 {
+	provider, ok := s.served.(SizeProvider)
+	if !ok {
+		return 0
+	}
+	return provider.ArtifactSize()
+}
+
+// ArtifactParts returns the parts read from the source that served a split backup.
+func (s *ChainSource) ArtifactParts() []ArtifactPart {
+	provider, ok := s.served.(PartsProvider)
+	if !ok {
+		return nil
+	}
+	return provider.ArtifactParts()
+}
+
+// ArtifactVersion returns the artifact version of the source that served the artifact.
+func (s *ChainSource) ArtifactVersion() *ArtifactVersion {
+	provider, ok := s.served.(VersionProvider)
+	if !ok {
+		return nil
+	}
+	return provider.ArtifactVersion()
+}
+
+// Identifier returns the identifier of the source that served the artifact, or the
+// whole chain before Acquire has succeeded.
+func (s *ChainSource) Identifier() string {
+	if s.served != nil {
+		return s.served.Identifier()
+	}
+	ids := make([]string, len(s.Sources))
+	for i, source := range s.Sources {
+		ids[i] = source.Identifier()
+	}
+	return fmt.Sprintf("chain:[%s]", strings.Join(ids, ", "))
+}
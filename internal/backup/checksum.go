@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"strings"
+)
+
+// Checksum algorithms accepted in sidecars and manifests.
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumMD5    = "md5"
+)
+
+// checksumSidecars are the suffixes of checksum sidecar files, in lookup order.
+var checksumSidecars = []struct {
+	suffix    string
+	algorithm string
+}{
+	{".sha256", ChecksumSHA256},
+	{".sha256sum", ChecksumSHA256},
+	{".md5", ChecksumMD5},
+	{".md5sum", ChecksumMD5},
+}
+
+// isSidecar reports whether name is a metadata or checksum sidecar, which is never
+// selected as the artifact.
+func isSidecar(name string) bool {
+	if strings.HasSuffix(name, MetadataSidecarSuffix) {
+		return true
+	}
+	for _, sidecar := range checksumSidecars {
+		if strings.HasSuffix(name, sidecar.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompanionReader is implemented by sources that can read small files stored next
+// to the artifact, such as checksum sidecars and manifests.
+type CompanionReader interface {
+	// ArtifactName returns the base name of the artifact.
+	ArtifactName(ctx context.Context) (string, error)
+	// ReadCompanion returns the file named name in the artifact's directory, or
+	// nil if it does not exist.
+	ReadCompanion(ctx context.Context, name string) ([]byte, error)
+}
+
+// ArtifactChecksum records the published checksum an artifact was verified against.
+type ArtifactChecksum struct {
+	Algorithm string `json:"algorithm"`
+	// Expected is the hex digest published by the producer.
+	Expected string `json:"expected"`
+	// Source is the sidecar or manifest file the digest was read from.
+	Source string `json:"source"`
+	// Verified is true when the acquired artifact matched Expected.
+	Verified bool `json:"verified"`
+}
+
+// ExpectedChecksum looks for a checksum of the artifact in a sidecar next to it
+// (<name>.sha256, .sha256sum, .md5 or .md5sum) and then in the manifest file, if
+// one is named. It returns nil when neither lists the artifact. A manifest may
+// list a split backup by its parts alone; see VerifyParts.
+func ExpectedChecksum(ctx context.Context, reader CompanionReader, manifest string) (*ArtifactChecksum, error) {
+	name, err := reader.ArtifactName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := sidecarChecksum(ctx, reader, name)
+	if err != nil || expected != nil || manifest == "" {
+		return expected, err
+	}
+
+	data, err := reader.ReadCompanion(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("checksum manifest %s not found next to %s", manifest, name)
+	}
+	return manifestEntry(data, name, manifest, countParts(data, name) > 0)
+}
+
+// sidecarChecksum reads the checksum of name from its sidecar, or returns nil if
+// it has none.
+func sidecarChecksum(ctx context.Context, reader CompanionReader, name string) (*ArtifactChecksum, error) {
+	for _, sidecar := range checksumSidecars {
+		data, err := reader.ReadCompanion(ctx, name+sidecar.suffix)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 || !validDigest(fields[0], sidecar.algorithm) {
+			return nil, fmt.Errorf("checksum sidecar %s does not start with a %s digest", name+sidecar.suffix, sidecar.algorithm)
+		}
+		return &ArtifactChecksum{Algorithm: sidecar.algorithm, Expected: strings.ToLower(fields[0]), Source: name + sidecar.suffix}, nil
+	}
+	return nil, nil
+}
+
+// manifestEntry finds name in a manifest in sha256sum or md5sum format, one
+// "<digest>  <file>" line per file, with the algorithm inferred from the digest
+// length. A missing entry is an error unless optional is set.
+func manifestEntry(data []byte, name, manifest string, optional bool) (*ArtifactChecksum, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		digest, file, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || strings.HasPrefix(digest, "#") {
+			continue
+		}
+		// Binary mode entries are marked with "*", and entries may carry a directory
+		file = strings.TrimPrefix(strings.TrimSpace(file), "*")
+		if file != name && path.Base(file) != name {
+			continue
+		}
+		for _, algorithm := range []string{ChecksumSHA256, ChecksumMD5} {
+			if validDigest(digest, algorithm) {
+				return &ArtifactChecksum{Algorithm: algorithm, Expected: strings.ToLower(digest), Source: manifest}, nil
+			}
+		}
+		return nil, fmt.Errorf("checksum manifest %s has an invalid digest for %s", manifest, name)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksum manifest %s: %w", manifest, err)
+	}
+	if optional {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("checksum manifest %s has no entry for %s", manifest, name)
+}
+
+// validDigest reports whether s is a hex digest of the algorithm's length.
+func validDigest(s, algorithm string) bool {
+	size := sha256.Size
+	if algorithm == ChecksumMD5 {
+		size = md5.Size
+	}
+	decoded, err := hex.DecodeString(s)
+	return err == nil && len(decoded) == size
+}
+
+// VerifyChecksum compares the artifact with the expected checksum and sets
+// Verified. SHA-256 uses the digest computed while spooling; MD5 rereads the file.
+func (a *SpooledArtifact) VerifyChecksum(expected *ArtifactChecksum) error {
+	actual := a.Digest
+	if expected.Algorithm == ChecksumMD5 {
+		var err error
+		if actual, err = a.hashFile(md5.New()); err != nil {
+			return err
+		}
+	}
+	if actual != expected.Expected {
+		return fmt.Errorf("backup artifact %s mismatch: expected %s from %s, got %s", expected.Algorithm, expected.Expected, expected.Source, actual)
+	}
+	expected.Verified = true
+	return nil
+}
+
+// hashFile returns the hex digest of the spooled file, leaving it positioned at
+// the start.
+func (a *SpooledArtifact) hashFile(h hash.Hash) (string, error) {
+	if _, err := a.File.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind backup artifact: %w", err)
+	}
+	if _, err := io.Copy(h, a.File); err != nil {
+		return "", fmt.Errorf("failed to hash backup artifact: %w", err)
+	}
+	if _, err := a.File.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind backup artifact: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
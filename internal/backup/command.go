@@ -5,18 +5,104 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"restorable.io/restorable-cli/internal/config"
 )
 
 const defaultCommandTimeout = 10 * time.Minute
 
-// CommandSource implements BackupSource by executing a shell command.
+// CommandAllowlistEnv names the environment variable holding the colon-separated
+// programs that the command and WAL-G sources may run. When it is set, shell
+// commands are refused, only argv commands and wal-g binaries whose program is
+// listed run, and walg.env is restricted, so the programs a config from a
+// less-trusted repository names cannot be arbitrary ones.
+const CommandAllowlistEnv = "RESTORABLE_COMMAND_ALLOWLIST"
+
+// scrubbedEnv lists the variables kept when the environment is scrubbed.
+var scrubbedEnv = []string{"PATH", "HOME", "RESTORABLE_RUN_ID"}
+
+// CommandSource implements BackupSource by executing a shell command, or a program
+// with explicit arguments when Argv is set.
 type CommandSource struct {
-	Exec    string
+	Exec string
+	// Argv runs Argv[0] directly with the remaining arguments, without a shell.
+	Argv []string
+	// Dir is the working directory of the command; empty means the current one.
+	Dir string
+	// Env, when not nil, replaces the inherited environment.
+	Env     []string
 	Timeout time.Duration
 }
 
+// NewCommandSource creates a command source, enforcing the allowlist from
+// CommandAllowlistEnv when it is set.
+func NewCommandSource(cfg *config.Command) (*CommandSource, error) {
+	if cfg.Exec != "" && len(cfg.Argv) > 0 {
+		return nil, fmt.Errorf("backup command sets both exec and argv; use one")
+	}
+	if cfg.Exec == "" && len(cfg.Argv) == 0 {
+		return nil, fmt.Errorf("backup source is 'command' but neither exec nor argv is configured")
+	}
+
+	s := &CommandSource{Exec: cfg.Exec, Argv: cfg.Argv, Dir: cfg.Dir}
+
+	if allowlist, ok := os.LookupEnv(CommandAllowlistEnv); ok {
+		if s.Exec != "" {
+			return nil, fmt.Errorf("shell commands are disabled by %s; configure command.argv instead", CommandAllowlistEnv)
+		}
+		program, err := allowedProgram(s.Argv[0], filepath.SplitList(allowlist))
+		if err != nil {
+			return nil, err
+		}
+		s.Argv = append([]string{program}, s.Argv[1:]...)
+	}
+
+	if cfg.ScrubEnv {
+		for _, name := range append(scrubbedEnv, cfg.PassEnv...) {
+			if value, ok := os.LookupEnv(name); ok {
+				s.Env = append(s.Env, name+"="+value)
+			}
+		}
+		// A nil Env would inherit everything
+		if s.Env == nil {
+			s.Env = []string{}
+		}
+	}
+	return s, nil
+}
+
+// allowedProgram resolves program on PATH and returns its path if it matches an
+// allowlist entry. Entries are resolved the same way, so either names or paths work.
+func allowedProgram(program string, allowlist []string) (string, error) {
+	resolved, err := exec.LookPath(program)
+	if err != nil {
+		return "", fmt.Errorf("backup command %s not found: %w", program, err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve backup command %s: %w", program, err)
+	}
+
+	for _, entry := range allowlist {
+		if entry == "" {
+			continue
+		}
+		allowed, err := exec.LookPath(entry)
+		if err != nil {
+			continue
+		}
+		if allowed, err = filepath.Abs(allowed); err == nil && allowed == resolved {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("backup command %s is not in %s", resolved, CommandAllowlistEnv)
+}
+
 // commandReadCloser wraps a bytes.Reader to implement io.ReadCloser.
 type commandReadCloser struct {
 	*bytes.Reader
@@ -36,7 +122,14 @@ func (s *CommandSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", s.Exec)
+	var cmd *exec.Cmd
+	if len(s.Argv) > 0 {
+		cmd = exec.CommandContext(ctx, s.Argv[0], s.Argv[1:]...)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", s.Exec)
+	}
+	cmd.Dir = s.Dir
+	cmd.Env = s.Env
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -45,7 +138,7 @@ func (s *CommandSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
 
 	if err := cmd.Run(); err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("command timed out after %v: %s", timeout, s.Exec)
+			return nil, fmt.Errorf("command timed out after %v: %s", timeout, s.command())
 		}
 		return nil, fmt.Errorf("command failed: %w\nstderr: %s", err, stderr.String())
 	}
@@ -55,5 +148,13 @@ func (s *CommandSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
 
 // Identifier returns the command for traceability.
 func (s *CommandSource) Identifier() string {
-	return fmt.Sprintf("command:%s", s.Exec)
+	return fmt.Sprintf("command:%s", s.command())
+}
+
+// command returns the command line that runs.
+func (s *CommandSource) command() string {
+	if len(s.Argv) > 0 {
+		return strings.Join(s.Argv, " ")
+	}
+	return s.Exec
 }
@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// compressionFormats are recognized by their magic bytes. Formats without a
+// decoder in the standard library are decompressed by their command-line tool.
+var compressionFormats = []struct {
+	name  string
+	magic []byte
+	tool  string
+}{
+	{name: "gzip", magic: []byte{0x1f, 0x8b}},
+	{name: "bzip2", magic: []byte("BZh")},
+	{name: "xz", magic: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, tool: "xz"},
+	{name: "lz4", magic: []byte{0x04, 0x22, 0x4d, 0x18}, tool: "lz4"},
+	{name: "lz4", magic: []byte{0x02, 0x21, 0x4c, 0x18}, tool: "lz4"}, // Legacy frame, lz4 -l
+}
+
+// Decompress returns rc decompressed when it starts with the magic bytes of gzip,
+// bzip2, xz or lz4, and the name of the format, or "" when it is not compressed.
+// Concatenated gzip members, as written by pigz or by appending, are read as one
+// stream. xz and lz4 need the xz and lz4 tools on the PATH. Closing the returned
+// reader closes rc.
+func Decompress(rc io.ReadCloser) (io.ReadCloser, string, error) {
+	buffered := bufio.NewReader(rc)
+	header, _ := buffered.Peek(6)
+
+	for _, format := range compressionFormats {
+		if !bytes.HasPrefix(header, format.magic) {
+			continue
+		}
+		if format.name == "bzip2" && (len(header) < 4 || header[3] < '1' || header[3] > '9') {
+			continue
+		}
+
+		switch {
+		case format.tool != "":
+			return decompressCommand(buffered, rc, format.name, format.tool)
+		case format.name == "bzip2":
+			return &readCloser{Reader: bzip2.NewReader(buffered), closer: rc}, format.name, nil
+		default:
+			gz, err := gzip.NewReader(buffered)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read gzip header: %w", err)
+			}
+			return &readCloser{Reader: gz, closer: rc}, format.name, nil
+		}
+	}
+	return &readCloser{Reader: buffered, closer: rc}, "", nil
+}
+
+// decompressCommand streams r through tool -dc.
+func decompressCommand(r io.Reader, rc io.Closer, format, tool string) (io.ReadCloser, string, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, "", fmt.Errorf("backup artifact is %s-compressed; install %s to decompress it: %w", format, tool, err)
+	}
+	cmd := exec.Command(path, "-dc")
+	cmd.Stdin = r
+	c := &commandReader{cmd: cmd, tool: tool, src: rc}
+	cmd.Stderr = &c.stderr
+	if c.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, "", fmt.Errorf("failed to start %s: %w", tool, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("failed to start %s: %w", tool, err)
+	}
+	return c, format, nil
+}
+
+// readCloser reads from Reader and closes closer.
+type readCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *readCloser) Close() error {
+	return r.closer.Close()
+}
+
+// commandReader reads the output of a decompression tool, failing at the end of
+// the output if the tool failed, e.g. on a truncated artifact.
+type commandReader struct {
+	cmd    *exec.Cmd
+	tool   string
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	src    io.Closer
+	done   bool
+}
+
+func (c *commandReader) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, io.EOF) && !c.done {
+		c.done = true
+		if werr := c.cmd.Wait(); werr != nil {
+			return n, fmt.Errorf("%s failed: %w: %s", c.tool, werr, strings.TrimSpace(c.stderr.String()))
+		}
+	}
+	return n, err
+}
+
+func (c *commandReader) Close() error {
+	if !c.done {
+		c.done = true
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	}
+	return c.src.Close()
+}
@@ -0,0 +1,242 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	defaultHTTPRetries    = 3
+	httpRetryInitialDelay = 2 * time.Second
+)
+
+// ArtifactVersion identifies the version of a downloaded artifact as reported by
+// the server that served it.
+type ArtifactVersion struct {
+	ETag         string     `json:"etag,omitempty"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+// VersionProvider is implemented by sources that capture the version of the artifact
+// they download. It must be called after Acquire.
+type VersionProvider interface {
+	// ArtifactVersion returns the version of the acquired artifact, or nil if the
+	// server reported none.
+	ArtifactVersion() *ArtifactVersion
+}
+
+// HTTPSource implements BackupSource by downloading a backup from a URL.
+type HTTPSource struct {
+	url     string
+	headers http.Header
+	retries int
+	client  *http.Client
+	version *ArtifactVersion
+	// size is the Content-Length of the last download, or 0 if unknown
+	size int64
+}
+
+// NewHTTPSource creates an HTTP source, resolving credentials from the environment.
+func NewHTTPSource(cfg *config.HTTP) (*HTTPSource, error) {
+	rawURL := cfg.URL
+	if cfg.URLEnv != "" {
+		rawURL = os.Getenv(cfg.URLEnv)
+		if rawURL == "" {
+			return nil, fmt.Errorf("backup URL environment variable %s is not set", cfg.URLEnv)
+		}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("backup.http requires an http or https URL")
+	}
+
+	headers := make(http.Header)
+	for k, v := range cfg.Headers {
+		headers.Set(k, os.ExpandEnv(v))
+	}
+	if cfg.BearerTokenEnv != "" {
+		token := os.Getenv(cfg.BearerTokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("bearer token environment variable %s is not set", cfg.BearerTokenEnv)
+		}
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	if cfg.BasicUser != "" {
+		password := os.Getenv(cfg.BasicPasswordEnv)
+		if password == "" {
+			return nil, fmt.Errorf("basic auth password environment variable %q is not set", cfg.BasicPasswordEnv)
+		}
+		req := http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(cfg.BasicUser, password)
+		headers.Set("Authorization", req.Header.Get("Authorization"))
+	}
+
+	retries := cfg.Retries
+	if retries == 0 {
+		retries = defaultHTTPRetries
+	}
+	return &HTTPSource{
+		url:     u.String(),
+		headers: headers,
+		retries: retries,
+		// No overall timeout: large backups stream for as long as they take
+		client: &http.Client{},
+	}, nil
+}
+
+// Acquire starts the download and returns the response body as a stream. Failed
+// requests are retried with backoff; a stream interrupted by a network error is
+// resumed with a range request, as long as the server reports the same ETag.
+func (s *HTTPSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	s.version = nil
+	s.size = 0
+
+	resp, err := s.get(ctx, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	s.version = responseVersion(resp)
+	s.size = max(resp.ContentLength, 0)
+
+	// Only a strong ETag pins the artifact across range requests
+	etag := resp.Header.Get("ETag")
+	if resp.Header.Get("Accept-Ranges") != "bytes" || strings.HasPrefix(etag, "W/") {
+		etag = ""
+	}
+	return &httpStream{source: s, ctx: ctx, body: resp.Body, etag: etag}, nil
+}
+
+// get requests the artifact from offset, retrying failed requests. A non-empty etag
+// makes the server send the full artifact instead of a range if it has changed.
+func (s *HTTPSource) get(ctx context.Context, offset int64, etag string) (*http.Response, error) {
+	delay := httpRetryInitialDelay
+	var lastErr error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf("⚠ Download of %s failed, retrying in %s: %v\n", s.Identifier(), delay, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+		req.Header = s.headers.Clone()
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", etag)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch {
+		case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+			return resp, nil
+		case offset > 0 && resp.StatusCode == http.StatusOK:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s changed during download", s.Identifier())
+		case offset == 0 && resp.StatusCode == http.StatusOK:
+			return resp, nil
+		}
+		resp.Body.Close()
+		lastErr = fmt.Errorf("server returned %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+	}
+	return nil, fmt.Errorf("failed to download %s: %w", s.Identifier(), lastErr)
+}
+
+// responseVersion returns the ETag and Last-Modified headers of resp.
+func responseVersion(resp *http.Response) *ArtifactVersion {
+	v := &ArtifactVersion{ETag: resp.Header.Get("ETag")}
+	if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		lm = lm.UTC()
+		v.LastModified = &lm
+	}
+	if v.ETag == "" && v.LastModified == nil {
+		return nil
+	}
+	return v
+}
+
+// ArtifactModTime returns the Last-Modified time the server reported.
+func (s *HTTPSource) ArtifactModTime() *time.Time {
+	if s.version == nil {
+		return nil
+	}
+	return s.version.LastModified
+}
+
+// ArtifactSize returns the Content-Length the server reported.
+func (s *HTTPSource) ArtifactSize() int64 {
+	return s.size
+}
+
+// ArtifactVersion returns the ETag and Last-Modified time of the downloaded artifact.
+func (s *HTTPSource) ArtifactVersion() *ArtifactVersion {
+	return s.version
+}
+
+// Identifier returns the URL without credentials or query for traceability, since
+// signed URLs carry secrets in the query string.
+func (s *HTTPSource) Identifier() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return "http:(invalid URL)"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// httpStream reads a download, resuming it after network errors.
+type httpStream struct {
+	source *HTTPSource
+	ctx    context.Context
+	body   io.ReadCloser
+	// etag is empty when the download cannot be resumed
+	etag    string
+	offset  int64
+	resumes int
+}
+
+func (r *httpStream) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == nil || errors.Is(err, io.EOF) || r.etag == "" || r.resumes >= r.source.retries || r.ctx.Err() != nil {
+		return n, err
+	}
+
+	// Resume after a network error
+	r.resumes++
+	fmt.Printf("⚠ Download of %s interrupted after %d bytes, resuming: %v\n", r.source.Identifier(), r.offset, err)
+	r.body.Close()
+	resp, rerr := r.source.get(r.ctx, r.offset, r.etag)
+	if rerr != nil {
+		return n, fmt.Errorf("failed to resume download: %w (after: %v)", rerr, err)
+	}
+	r.body = resp.Body
+	return n, nil
+}
+
+func (r *httpStream) Close() error {
+	return r.body.Close()
+}
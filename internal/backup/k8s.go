@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// K8sSource implements BackupSource by streaming a backup out of a container in a
+// Kubernetes pod with kubectl exec, for backups that live only on volumes inside
+// the cluster. kubectl handles authentication, so kubeconfig files, exec plugins
+// and in-cluster service accounts all work.
+type K8sSource struct {
+	// kubectlArgs select the kubeconfig, context and namespace
+	kubectlArgs []string
+	namespace   string
+	pod         string
+	selector    string
+	container   string
+	// path is a file path or a glob; a glob selects the most recently modified match
+	path    string
+	command []string
+	// resolvedPod and resolvedPath store what was used after resolution
+	resolvedPod  string
+	resolvedPath string
+}
+
+// NewK8sSource creates a Kubernetes source. The kubectl binary must be installed.
+func NewK8sSource(cfg *config.K8s) (*K8sSource, error) {
+	if (cfg.Pod == "") == (cfg.Selector == "") {
+		return nil, fmt.Errorf("backup.k8s requires exactly one of pod and selector")
+	}
+	if (cfg.Path == "") == (len(cfg.Command) == 0) {
+		return nil, fmt.Errorf("backup.k8s requires exactly one of path and command")
+	}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return nil, fmt.Errorf("backup.k8s requires kubectl to be installed: %w", err)
+	}
+
+	var args []string
+	if cfg.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", cfg.Kubeconfig)
+	}
+	if cfg.Context != "" {
+		args = append(args, "--context", cfg.Context)
+	}
+	if cfg.Namespace != "" {
+		args = append(args, "--namespace", cfg.Namespace)
+	}
+
+	return &K8sSource{
+		kubectlArgs: args,
+		namespace:   cfg.Namespace,
+		pod:         cfg.Pod,
+		selector:    cfg.Selector,
+		container:   cfg.Container,
+		path:        cfg.Path,
+		command:     cfg.Command,
+	}, nil
+}
+
+// Acquire resolves the pod and file, then streams the file, or the output of the
+// command, from the container. A failure of kubectl or the command surfaces as a
+// read error rather than a silently truncated backup.
+func (s *K8sSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	pod, err := s.resolvePod(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	command := s.command
+	if s.path != "" {
+		path, err := s.resolvePath(ctx, pod)
+		if err != nil {
+			return nil, err
+		}
+		command = []string{"cat", "--", path}
+	}
+
+	fmt.Printf("Streaming %s from Kubernetes...\n", s.Identifier())
+	cmd := exec.CommandContext(ctx, "kubectl", s.execArgs(pod, command...)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream from pod %s: %w", pod, err)
+	}
+	stream := &k8sStream{ReadCloser: stdout, cmd: cmd}
+	cmd.Stderr = &stream.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run kubectl: %w", err)
+	}
+	return stream, nil
+}
+
+// k8sStream is the stdout of kubectl exec. At the end of the output it waits for
+// kubectl, turning a failed exec into an error instead of EOF.
+type k8sStream struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr bytes.Buffer
+	done   bool
+}
+
+func (s *k8sStream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if err == io.EOF && !s.done {
+		s.done = true
+		if waitErr := s.cmd.Wait(); waitErr != nil {
+			return n, fmt.Errorf("kubectl exec failed: %w\nstderr: %s", waitErr, strings.TrimSpace(s.stderr.String()))
+		}
+	}
+	return n, err
+}
+
+func (s *k8sStream) Close() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	// Stop kubectl if the stream was not read to the end
+	s.cmd.Process.Kill()
+	s.cmd.Wait()
+	return nil
+}
+
+// resolvePod returns the configured pod, or the first running pod matching the
+// selector. The pod is resolved once so later calls agree.
+func (s *K8sSource) resolvePod(ctx context.Context) (string, error) {
+	if s.resolvedPod != "" {
+		return s.resolvedPod, nil
+	}
+	if s.pod != "" {
+		s.resolvedPod = s.pod
+		return s.resolvedPod, nil
+	}
+
+	args := append(append([]string{}, s.kubectlArgs...),
+		"get", "pods",
+		"--selector", s.selector,
+		"--field-selector", "status.phase=Running",
+		"--output", "jsonpath={.items[*].metadata.name}",
+	)
+	output, err := s.kubectl(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods matching %s: %w", s.selector, err)
+	}
+	pods := strings.Fields(output)
+	if len(pods) == 0 {
+		return "", fmt.Errorf("no running pods match %s", s.selector)
+	}
+	s.resolvedPod = pods[0]
+	return s.resolvedPod, nil
+}
+
+// resolvePath returns the file in the container, expanding a glob to its most
+// recently modified regular file with the container's shell.
+func (s *K8sSource) resolvePath(ctx context.Context, pod string) (string, error) {
+	if s.resolvedPath != "" {
+		return s.resolvedPath, nil
+	}
+	if !strings.ContainsAny(s.path, "*?[") {
+		s.resolvedPath = s.path
+		return s.resolvedPath, nil
+	}
+
+	// ls -p marks directories with a trailing slash so they can be skipped
+	output, err := s.kubectl(ctx, s.execArgs(pod, "sh", "-c", "ls -1tdp -- "+quoteGlob(s.path))...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s in pod %s: %w", s.path, pod, err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" && !strings.HasSuffix(line, "/") {
+			s.resolvedPath = line
+			return s.resolvedPath, nil
+		}
+	}
+	return "", fmt.Errorf("no regular files match %s in pod %s", s.path, pod)
+}
+
+// execArgs returns the kubectl arguments that run command in the container.
+func (s *K8sSource) execArgs(pod string, command ...string) []string {
+	args := append(append([]string{}, s.kubectlArgs...), "exec", pod)
+	if s.container != "" {
+		args = append(args, "--container", s.container)
+	}
+	return append(append(args, "--"), command...)
+}
+
+// kubectl runs kubectl and returns its output.
+func (s *K8sSource) kubectl(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w\nstderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Identifier returns the pod, container and file or command for traceability.
+func (s *K8sSource) Identifier() string {
+	pod := s.resolvedPod
+	if pod == "" {
+		pod = s.pod
+	}
+	if pod == "" {
+		pod = "selector=" + s.selector
+	}
+	if s.namespace != "" {
+		pod = s.namespace + "/" + pod
+	}
+	if s.container != "" {
+		pod += "/" + s.container
+	}
+
+	if len(s.command) > 0 {
+		return fmt.Sprintf("k8s:%s:exec %s", pod, strings.Join(s.command, " "))
+	}
+	path := s.resolvedPath
+	if path == "" {
+		path = s.path
+	}
+	return fmt.Sprintf("k8s:%s:%s", pod, path)
+}
@@ -5,23 +5,209 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // LocalSource implements BackupSource for local file paths.
 type LocalSource struct {
+	// Path is a file, a directory or a glob; a directory or glob selects the most
+	// recently modified file
 	Path string
+	// resolvedPath stores the actual file used after directory or glob resolution
+	resolvedPath string
+	// parts lists the files of a backup split into numbered parts, in order
+	parts []string
+	// split reads the parts for the last Acquire
+	split *partsReader
 }
 
 // Acquire opens the local file and returns it as a ReadCloser.
 func (s *LocalSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
-	file, err := os.Open(s.Path)
+	path, err := s.resolvePath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open local backup file at %s: %w", s.Path, err)
+		return nil, err
+	}
+	if len(s.parts) > 0 {
+		s.split = newPartsReader(ctx, s.parts, func(ctx context.Context, name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		})
+		return s.split, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local backup file at %s: %w", path, err)
 	}
 	return file, nil
 }
 
+// resolvePath returns the configured file, or the most recently modified regular
+// file in the configured directory or matching the configured glob. Metadata and
+// checksum sidecars are never selected. When the file is a numbered part, the
+// other parts are collected from its directory. The path is resolved once so
+// later calls agree.
+func (s *LocalSource) resolvePath() (string, error) {
+	if s.resolvedPath != "" {
+		return s.resolvedPath, nil
+	}
+
+	var candidates []string
+	switch info, err := os.Stat(s.Path); {
+	case err == nil && info.IsDir():
+		entries, err := os.ReadDir(s.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read local backup directory %s: %w", s.Path, err)
+		}
+		for _, e := range entries {
+			candidates = append(candidates, filepath.Join(s.Path, e.Name()))
+		}
+	case err == nil || !strings.ContainsAny(s.Path, `*?[`):
+		// A plain file, or a missing one that Acquire reports
+		return s.resolveParts(s.Path)
+	default:
+		matches, err := filepath.Glob(s.Path)
+		if err != nil {
+			return "", fmt.Errorf("invalid local backup glob %q: %w", s.Path, err)
+		}
+		candidates = matches
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, candidate := range candidates {
+		if isSidecar(candidate) {
+			continue
+		}
+		info, err := os.Stat(candidate)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// Ties go to the later name, so equal timestamps resolve the same way every run
+		if newest == "" || info.ModTime().After(newestTime) || info.ModTime().Equal(newestTime) && candidate > newest {
+			newest, newestTime = candidate, info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no backup files match %s", s.Path)
+	}
+	return s.resolveParts(newest)
+}
+
+// resolveParts records path as the resolved file and, when it is a numbered
+// part, collects the parts of its backup.
+func (s *LocalSource) resolveParts(path string) (string, error) {
+	if _, _, ok := splitPart(path); ok {
+		entries, err := os.ReadDir(filepath.Dir(path))
+		if err != nil {
+			return "", fmt.Errorf("failed to list the parts of %s: %w", path, err)
+		}
+		var names []string
+		for _, e := range entries {
+			if e.Type().IsRegular() {
+				names = append(names, filepath.Join(filepath.Dir(path), e.Name()))
+			}
+		}
+		if s.parts, err = collectParts(path, names); err != nil {
+			return "", err
+		}
+	}
+	s.resolvedPath = path
+	return s.resolvedPath, nil
+}
+
+// files returns the backup file, or its parts.
+func (s *LocalSource) files() []string {
+	if len(s.parts) > 0 {
+		return s.parts
+	}
+	return []string{s.resolvedPath}
+}
+
+// ArtifactParts returns the parts read by the last Acquire of a split backup.
+func (s *LocalSource) ArtifactParts() []ArtifactPart {
+	return readParts(s.split)
+}
+
+// Metadata returns producer metadata from the artifact's sidecar file, if present.
+func (s *LocalSource) Metadata(ctx context.Context) (*ProducerMetadata, error) {
+	path, err := s.resolvePath()
+	if err != nil {
+		return nil, err
+	}
+	return readLocalSidecar(splitBase(path))
+}
+
+// ArtifactModTime returns the modification time of the backup file, or of its
+// newest part.
+func (s *LocalSource) ArtifactModTime() *time.Time {
+	if _, err := s.resolvePath(); err != nil {
+		return nil
+	}
+	var newest *time.Time
+	for _, path := range s.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil
+		}
+		if t := info.ModTime(); newest == nil || t.After(*newest) {
+			newest = &t
+		}
+	}
+	return newest
+}
+
+// ArtifactSize returns the size of the backup file, or the total of its parts.
+func (s *LocalSource) ArtifactSize() int64 {
+	if _, err := s.resolvePath(); err != nil {
+		return 0
+	}
+	var size int64
+	for _, path := range s.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0
+		}
+		size += info.Size()
+	}
+	return size
+}
+
+// ArtifactName returns the base name of the backup file, without the part
+// number of a split backup.
+func (s *LocalSource) ArtifactName(ctx context.Context) (string, error) {
+	path, err := s.resolvePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(splitBase(path)), nil
+}
+
+// ReadCompanion reads the file name in the backup file's directory.
+func (s *LocalSource) ReadCompanion(ctx context.Context, name string) ([]byte, error) {
+	path, err := s.resolvePath()
+	if err != nil {
+		return nil, err
+	}
+	companion := filepath.Join(filepath.Dir(path), name)
+	data, err := os.ReadFile(companion)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", companion, err)
+	}
+	return data, nil
+}
+
 // Identifier returns the local file path for traceability.
 func (s *LocalSource) Identifier() string {
-	return fmt.Sprintf("local:%s", s.Path)
+	path := s.resolvedPath
+	if path == "" {
+		path = s.Path
+	}
+	if len(s.parts) > 0 {
+		return fmt.Sprintf("local:%s (%d parts)", splitBase(path), len(s.parts))
+	}
+	return fmt.Sprintf("local:%s", path)
 }
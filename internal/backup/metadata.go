@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MetadataSidecarSuffix is appended to an artifact's path or key to locate its sidecar file.
+const MetadataSidecarSuffix = ".restorable.json"
+
+// metadataKeyPrefix namespaces producer metadata in object metadata and tags.
+const metadataKeyPrefix = "restorable-"
+
+// ProducerMetadata is the annotation a backup job attaches to an artifact, either as a
+// JSON sidecar file or as S3 object metadata with the "restorable-" prefix.
+type ProducerMetadata struct {
+	ProducerHost    string `json:"producer_host,omitempty"`
+	DumpCommand     string `json:"dump_command,omitempty"`
+	SourceDBVersion string `json:"source_db_version,omitempty"`
+	LSN             string `json:"lsn,omitempty"`
+	CreatedAt       string `json:"created_at,omitempty"`
+}
+
+// MetadataProvider is implemented by sources that can return producer metadata for the
+// acquired artifact. It must be called after Acquire.
+type MetadataProvider interface {
+	// Metadata returns the producer metadata, or nil if the producer attached none.
+	Metadata(ctx context.Context) (*ProducerMetadata, error)
+}
+
+// SourceMajorVersion returns the major version from SourceDBVersion, e.g. 15 for "15.4".
+func (m *ProducerMetadata) SourceMajorVersion() (int, error) {
+	major, _, _ := strings.Cut(strings.TrimSpace(m.SourceDBVersion), ".")
+	v, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid source database version %q", m.SourceDBVersion)
+	}
+	return v, nil
+}
+
+// isEmpty reports whether no field was provided.
+func (m *ProducerMetadata) isEmpty() bool {
+	return *m == ProducerMetadata{}
+}
+
+// parseMetadataSidecar decodes a JSON sidecar file.
+func parseMetadataSidecar(data []byte) (*ProducerMetadata, error) {
+	var m ProducerMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse producer metadata: %w", err)
+	}
+	if m.isEmpty() {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+// metadataFromMap extracts producer metadata from key/value pairs such as S3 object
+// metadata ("restorable-producer-host", "restorable-lsn", ...). Keys are case-insensitive.
+func metadataFromMap(values map[string]string) *ProducerMetadata {
+	get := func(name string) string {
+		for k, v := range values {
+			if strings.EqualFold(k, metadataKeyPrefix+name) {
+				return v
+			}
+		}
+		return ""
+	}
+
+	m := &ProducerMetadata{
+		ProducerHost:    get("producer-host"),
+		DumpCommand:     get("dump-command"),
+		SourceDBVersion: get("source-db-version"),
+		LSN:             get("lsn"),
+		CreatedAt:       get("created-at"),
+	}
+	if m.isEmpty() {
+		return nil
+	}
+	return m
+}
+
+// readLocalSidecar loads the sidecar next to a local artifact, if present.
+func readLocalSidecar(path string) (*ProducerMetadata, error) {
+	data, err := os.ReadFile(path + MetadataSidecarSuffix)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read producer metadata sidecar: %w", err)
+	}
+	return parseMetadataSidecar(data)
+}
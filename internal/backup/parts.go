@@ -0,0 +1,247 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// partPattern matches the numbered parts of a split backup, e.g. dump.part001.
+var partPattern = regexp.MustCompile(`^(.+)\.part(\d+)$`)
+
+// ArtifactPart is one numbered part of a backup uploaded in pieces.
+type ArtifactPart struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	md5    string
+	// Checksum is the published checksum the part matched, if any.
+	Checksum *ArtifactChecksum `json:"checksum,omitempty"`
+}
+
+// PartsProvider is implemented by sources that reassemble backups uploaded as
+// numbered parts. It must be called after the stream of Acquire has been read.
+type PartsProvider interface {
+	// ArtifactParts returns the parts read, in order, or nil if the artifact is
+	// a single file.
+	ArtifactParts() []ArtifactPart
+}
+
+// splitPart parses name, a file name or path, as a numbered part.
+func splitPart(name string) (base string, number int, ok bool) {
+	m := partPattern.FindStringSubmatch(path.Base(name))
+	if m == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], number, true
+}
+
+// splitBase returns the name of the reassembled artifact that name is a part of,
+// or name itself when it is not a part.
+func splitBase(name string) string {
+	if base, _, ok := splitPart(name); ok {
+		return strings.TrimSuffix(name, path.Base(name)) + base
+	}
+	return name
+}
+
+// collectParts returns the parts of the split backup that chosen belongs to,
+// among names in its directory, ordered by number. It returns nil when chosen is
+// not a part, and fails when the numbering does not run without gaps from 0 or 1.
+func collectParts(chosen string, names []string) ([]string, error) {
+	base, _, ok := splitPart(chosen)
+	if !ok {
+		return nil, nil
+	}
+	byNumber := make(map[int]string)
+	for _, name := range names {
+		b, number, ok := splitPart(name)
+		if !ok || b != base {
+			continue
+		}
+		if other, dup := byNumber[number]; dup {
+			return nil, fmt.Errorf("backup %s has two parts numbered %d (%s, %s)", base, number, path.Base(other), path.Base(name))
+		}
+		byNumber[number] = name
+	}
+
+	numbers := make([]int, 0, len(byNumber))
+	for number := range byNumber {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	if len(numbers) == 0 || numbers[0] > 1 {
+		return nil, fmt.Errorf("backup %s is missing its first part", base)
+	}
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		if i > 0 && number != numbers[i-1]+1 {
+			return nil, fmt.Errorf("backup %s is missing part %d of %d", base, numbers[i-1]+1, numbers[len(numbers)-1])
+		}
+		parts[i] = byNumber[number]
+	}
+	return parts, nil
+}
+
+// partsReader concatenates the parts of a split backup, opening each in turn and
+// recording its size and digests.
+type partsReader struct {
+	ctx     context.Context
+	names   []string
+	open    func(ctx context.Context, name string) (io.ReadCloser, error)
+	current io.ReadCloser
+	sha256  hash.Hash
+	md5     hash.Hash
+	size    int64
+	// parts holds the parts read to the end
+	parts []ArtifactPart
+}
+
+func newPartsReader(ctx context.Context, names []string, open func(ctx context.Context, name string) (io.ReadCloser, error)) *partsReader {
+	return &partsReader{ctx: ctx, names: names, open: open}
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == len(r.names) {
+				return 0, io.EOF
+			}
+			name := r.names[len(r.parts)]
+			body, err := r.open(r.ctx, name)
+			if err != nil {
+				return 0, fmt.Errorf("failed to open backup part %s: %w", path.Base(name), err)
+			}
+			r.current, r.sha256, r.md5, r.size = body, sha256.New(), md5.New(), 0
+		}
+
+		n, err := r.current.Read(p)
+		r.sha256.Write(p[:n])
+		r.md5.Write(p[:n])
+		r.size += int64(n)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			r.parts = append(r.parts, ArtifactPart{
+				Name:   path.Base(r.names[len(r.parts)]),
+				Size:   r.size,
+				SHA256: hex.EncodeToString(r.sha256.Sum(nil)),
+				md5:    hex.EncodeToString(r.md5.Sum(nil)),
+			})
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
+// readParts returns the parts r has read, or nil if r is nil.
+func readParts(r *partsReader) []ArtifactPart {
+	if r == nil {
+		return nil
+	}
+	return r.parts
+}
+
+// VerifyParts compares each part with a checksum sidecar next to it, or with its
+// entry in the manifest, if one is named. When the manifest lists parts of the
+// backup, their number must match the parts read. It returns how many parts
+// matched a published checksum, setting their Checksum.
+func VerifyParts(ctx context.Context, reader CompanionReader, parts []ArtifactPart, manifest string) (int, error) {
+	if len(parts) == 0 {
+		return 0, nil
+	}
+	var manifestData []byte
+	if manifest != "" {
+		var err error
+		if manifestData, err = reader.ReadCompanion(ctx, manifest); err != nil {
+			return 0, err
+		}
+	}
+
+	if manifestData != nil {
+		base, _, _ := splitPart(parts[0].Name)
+		if listed := countParts(manifestData, base); listed > 0 && listed != len(parts) {
+			return 0, fmt.Errorf("checksum manifest %s lists %d parts of %s, but %d were found", manifest, listed, base, len(parts))
+		}
+	}
+
+	verified := 0
+	for i := range parts {
+		part := &parts[i]
+		expected, err := sidecarChecksum(ctx, reader, part.Name)
+		if err != nil {
+			return 0, err
+		}
+		if expected == nil && manifestData != nil {
+			if expected, err = manifestEntry(manifestData, part.Name, manifest, true); err != nil {
+				return 0, err
+			}
+		}
+		if expected == nil {
+			continue
+		}
+		actual := part.SHA256
+		if expected.Algorithm == ChecksumMD5 {
+			actual = part.md5
+		}
+		if actual != expected.Expected {
+			return 0, fmt.Errorf("backup part %s %s mismatch: expected %s from %s, got %s", part.Name, expected.Algorithm, expected.Expected, expected.Source, actual)
+		}
+		expected.Verified = true
+		part.Checksum = expected
+		verified++
+	}
+	return verified, nil
+}
+
+// countParts returns how many parts of the split backup base a manifest lists.
+func countParts(data []byte, base string) int {
+	listed := 0
+	for _, file := range manifestFiles(data) {
+		if b, _, ok := splitPart(file); ok && b == base {
+			listed++
+		}
+	}
+	return listed
+}
+
+// manifestFiles returns the base names of the files listed in a manifest.
+func manifestFiles(data []byte) []string {
+	var files []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		digest, file, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || strings.HasPrefix(digest, "#") {
+			continue
+		}
+		files = append(files, path.Base(strings.TrimPrefix(strings.TrimSpace(file), "*")))
+	}
+	return files
+}
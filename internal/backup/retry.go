@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	defaultRetryInitialBackoff = 5 * time.Second
+	defaultRetryMaxBackoff     = 5 * time.Minute
+)
+
+// errRangeUnsupported is returned by AcquireRange when the source that served the
+// artifact cannot resume it.
+var errRangeUnsupported = errors.New("source cannot resume downloads")
+
+// RangeAcquirer is implemented by sources that can resume an interrupted download.
+// It must be called after Acquire.
+type RangeAcquirer interface {
+	// AcquireRange returns the artifact of the last Acquire from offset, failing if
+	// the artifact has changed since.
+	AcquireRange(ctx context.Context, offset int64) (io.ReadCloser, error)
+}
+
+// RetryPolicy controls how often and how patiently acquisition is retried.
+type RetryPolicy struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewRetryPolicy returns the policy configured in cfg, which may be nil for a
+// single attempt.
+func NewRetryPolicy(cfg *config.Retry) RetryPolicy {
+	p := RetryPolicy{Attempts: 1, InitialBackoff: defaultRetryInitialBackoff, MaxBackoff: defaultRetryMaxBackoff}
+	if cfg == nil {
+		return p
+	}
+	if cfg.Attempts > 1 {
+		p.Attempts = cfg.Attempts
+	}
+	if cfg.InitialBackoffSeconds > 0 {
+		p.InitialBackoff = time.Duration(cfg.InitialBackoffSeconds) * time.Second
+	}
+	if cfg.MaxBackoffSeconds > 0 {
+		p.MaxBackoff = time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	}
+	return p
+}
+
+// backoff returns the wait before retry n, starting at 1.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// AcquireWithRetry acquires the artifact from source, retrying failures with
+// exponential backoff. When the source implements RangeAcquirer, a stream
+// interrupted by an error is resumed from the last byte read instead of failing.
+func AcquireWithRetry(ctx context.Context, source BackupSource, policy RetryPolicy) (io.ReadCloser, error) {
+	var stream io.ReadCloser
+	var err error
+	for attempt := 1; ; attempt++ {
+		stream, err = source.Acquire(ctx)
+		if err == nil || attempt >= policy.Attempts || ctx.Err() != nil {
+			break
+		}
+		delay := policy.backoff(attempt)
+		fmt.Printf("⚠ Acquiring %s failed (attempt %d of %d), retrying in %s: %v\n", source.Identifier(), attempt, policy.Attempts, delay, err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ranger, ok := source.(RangeAcquirer)
+	if !ok || policy.Attempts <= 1 {
+		return stream, nil
+	}
+	return &resumingStream{ctx: ctx, source: source, ranger: ranger, policy: policy, body: stream}, nil
+}
+
+// resumingStream reads an artifact, reopening it at the current offset after errors.
+type resumingStream struct {
+	ctx     context.Context
+	source  BackupSource
+	ranger  RangeAcquirer
+	policy  RetryPolicy
+	body    io.ReadCloser
+	offset  int64
+	resumes int
+}
+
+func (r *resumingStream) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	for err != nil && !errors.Is(err, io.EOF) && r.resumes < r.policy.Attempts-1 && r.ctx.Err() == nil {
+		r.resumes++
+		delay := r.policy.backoff(r.resumes)
+		fmt.Printf("⚠ Download of %s interrupted after %d bytes, resuming in %s: %v\n", r.source.Identifier(), r.offset, delay, err)
+		r.body.Close()
+		if serr := sleep(r.ctx, delay); serr != nil {
+			return n, serr
+		}
+
+		body, rerr := r.ranger.AcquireRange(r.ctx, r.offset)
+		if errors.Is(rerr, errRangeUnsupported) {
+			// Keep the closed body so Close stays safe; the original error stands
+			return n, err
+		}
+		if rerr != nil {
+			err = fmt.Errorf("failed to resume download: %w (after: %v)", rerr, err)
+			// Nothing is open; a later resume replaces this body
+			r.body = io.NopCloser(errReader{err})
+			continue
+		}
+		r.body = body
+		return n, nil
+	}
+	return n, err
+}
+
+func (r *resumingStream) Close() error {
+	return r.body.Close()
+}
+
+// errReader fails every read with err.
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,232 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// Host key checking modes of the rsync source.
+const (
+	HostKeyCheckingStrict    = "strict"
+	HostKeyCheckingAcceptNew = "accept-new"
+)
+
+// RsyncSource implements BackupSource by pulling a file from a remote host with
+// rsync over SSH, for backup hosts reachable only over SSH.
+type RsyncSource struct {
+	host string
+	port int
+	user string
+	// path is a file path or a glob; a glob selects the most recently modified match
+	path string
+	// sshArgs are the ssh options used for both listing and transfer
+	sshArgs []string
+	// workDir holds the downloaded file until it has been read
+	workDir string
+	// resolvedPath stores the actual file used after glob resolution
+	resolvedPath string
+}
+
+// NewRsyncSource creates an rsync source. The rsync and ssh binaries must be installed.
+func NewRsyncSource(cfg *config.Rsync) (*RsyncSource, error) {
+	if cfg.Host == "" || cfg.Path == "" {
+		return nil, fmt.Errorf("backup.rsync requires host and path")
+	}
+	for _, binary := range []string{"rsync", "ssh"} {
+		if _, err := exec.LookPath(binary); err != nil {
+			return nil, fmt.Errorf("backup.rsync requires %s to be installed: %w", binary, err)
+		}
+	}
+
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	if !validSSHName(user) {
+		return nil, fmt.Errorf("invalid backup.rsync user %q", user)
+	}
+	if !validSSHName(cfg.Host) {
+		return nil, fmt.Errorf("invalid backup.rsync host %q", cfg.Host)
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+
+	// Never prompt: an unattended run must fail rather than hang on a question
+	sshArgs := []string{"-o", "BatchMode=yes", "-p", strconv.Itoa(port)}
+	switch cfg.HostKeyChecking {
+	case "", HostKeyCheckingStrict:
+		sshArgs = append(sshArgs, "-o", "StrictHostKeyChecking=yes")
+	case HostKeyCheckingAcceptNew:
+		sshArgs = append(sshArgs, "-o", "StrictHostKeyChecking=accept-new")
+	default:
+		return nil, fmt.Errorf("unsupported rsync host_key_checking %q (use %s or %s)",
+			cfg.HostKeyChecking, HostKeyCheckingStrict, HostKeyCheckingAcceptNew)
+	}
+	if cfg.KnownHostsPath != "" {
+		sshArgs = append(sshArgs, "-o", "UserKnownHostsFile="+cfg.KnownHostsPath)
+	}
+	if cfg.KeyPath != "" {
+		if _, err := os.Stat(cfg.KeyPath); err != nil {
+			return nil, fmt.Errorf("failed to read SSH key: %w", err)
+		}
+		sshArgs = append(sshArgs, "-i", cfg.KeyPath, "-o", "IdentitiesOnly=yes")
+	}
+
+	return &RsyncSource{
+		host:    cfg.Host,
+		port:    port,
+		user:    user,
+		path:    cfg.Path,
+		sshArgs: sshArgs,
+		workDir: cfg.WorkDir,
+	}, nil
+}
+
+// Acquire resolves the remote file, pulls it with rsync into a temporary directory
+// and streams it. Closing the stream removes the downloaded file.
+func (s *RsyncSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	remotePath, err := s.resolvePath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(s.workDir, "restorable-rsync-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rsync working directory: %w", err)
+	}
+
+	fmt.Printf("Downloading %s with rsync...\n", s.Identifier())
+	local := filepath.Join(dir, path.Base(remotePath))
+	// --protect-args sends the path without remote shell interpretation
+	cmd := exec.CommandContext(ctx, "rsync",
+		"--protect-args",
+		"--times",
+		"-e", s.sshCommand(),
+		"--",
+		s.user+"@"+s.host+":"+remotePath,
+		local,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("rsync of %s failed: %w\nstderr: %s", s.Identifier(), err, strings.TrimSpace(stderr.String()))
+	}
+
+	f, err := os.Open(local)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to open downloaded backup: %w", err)
+	}
+	return &rsyncFile{File: f, dir: dir}, nil
+}
+
+// rsyncFile is a downloaded file whose directory is removed on Close.
+type rsyncFile struct {
+	*os.File
+	dir string
+}
+
+func (f *rsyncFile) Close() error {
+	err := f.File.Close()
+	os.RemoveAll(f.dir)
+	return err
+}
+
+// resolvePath returns the remote file, expanding a glob on the remote host to its
+// most recently modified regular file. The path is resolved once so later calls agree.
+func (s *RsyncSource) resolvePath(ctx context.Context) (string, error) {
+	if s.resolvedPath != "" {
+		return s.resolvedPath, nil
+	}
+
+	// ls -p marks directories with a trailing slash so they can be skipped
+	listing := "ls -1tdp -- " + quoteGlob(s.path)
+	args := append(append([]string{}, s.sshArgs...), "--", s.user+"@"+s.host, listing)
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to list %s on %s: %w\nstderr: %s", s.path, s.host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line != "" && !strings.HasSuffix(line, "/") {
+			s.resolvedPath = line
+			return s.resolvedPath, nil
+		}
+	}
+	return "", fmt.Errorf("no regular files match %s on %s", s.path, s.host)
+}
+
+// validSSHName reports whether name can be part of the bare user@host argument of
+// ssh and rsync without being read as an option or split into several words.
+func validSSHName(name string) bool {
+	return !strings.HasPrefix(name, "-") && !strings.ContainsFunc(name, unicode.IsSpace)
+}
+
+// sshCommand returns the ssh command line rsync runs as its remote shell.
+func (s *RsyncSource) sshCommand() string {
+	parts := []string{"ssh"}
+	for _, arg := range s.sshArgs {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Identifier returns the host and remote path for traceability.
+func (s *RsyncSource) Identifier() string {
+	p := s.resolvedPath
+	if p == "" {
+		p = s.path
+	}
+	host := s.host
+	if s.port != defaultSSHPort {
+		host = net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	}
+	return fmt.Sprintf("rsync:%s@%s:%s", s.user, host, p)
+}
+
+// quoteGlob quotes pattern for a POSIX shell, leaving the wildcards *, ? and
+// brackets unquoted so the remote shell expands them.
+func quoteGlob(pattern string) string {
+	var b strings.Builder
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			b.WriteString(shellQuote(literal.String()))
+			literal.Reset()
+		}
+	}
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', ']':
+			flush()
+			b.WriteRune(r)
+		default:
+			literal.WriteRune(r)
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
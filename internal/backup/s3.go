@@ -2,29 +2,206 @@ package backup
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"sort"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"restorable.io/restorable-cli/internal/config"
 )
 
+// Orders for picking among the objects selected by an S3 source.
+const (
+	s3OrderModified  = "modified"
+	s3OrderName      = "name"
+	s3OrderTimestamp = "timestamp"
+)
+
 // S3Source implements BackupSource for S3-compatible storage.
 type S3Source struct {
 	client   *s3.Client
 	bucket   string
 	prefix   string
 	endpoint string
+	// key is an exact object key that bypasses selection
+	key string
+	// match and regex select objects by key; order picks among them
+	match string
+	regex *regexp.Regexp
+	order string
+	// archiveRestore restores archived objects before download; nil fails on them
+	archiveRestore *config.ArchiveRestore
+	// sse is sent with object requests; nil for unencrypted or SSE-S3 objects
+	sse *ServerSideEncryption
 	// resolvedKey stores the actual key used after prefix resolution
 	resolvedKey string
+	// etag pins Acquire to the object version that was fingerprinted
+	etag string
+	// downloadETag pins AcquireRange to the object version Acquire returned
+	downloadETag string
+	// objectMetadata holds the user metadata returned with the object
+	objectMetadata map[string]string
+	// lastModified is the object's LastModified time
+	lastModified *time.Time
+	// size is the object's ContentLength
+	size int64
+	// parts lists the objects of a backup split into numbered parts, in order
+	parts []types.Object
+	// split reads the parts for the last Acquire
+	split *partsReader
 }
 
 // NewS3Source creates a new S3Source from configuration.
 func NewS3Source(cfg *config.S3) (*S3Source, error) {
+	s := &S3Source{
+		bucket:         cfg.Bucket,
+		prefix:         cfg.Prefix,
+		endpoint:       cfg.Endpoint,
+		key:            cfg.Key,
+		match:          cfg.Match,
+		order:          cfg.Order,
+		archiveRestore: cfg.ArchiveRestore,
+	}
+	if s.match != "" && cfg.Regex != "" {
+		return nil, fmt.Errorf("backup.s3 accepts only one of match and regex")
+	}
+	if s.match != "" {
+		if _, err := path.Match(s.match, ""); err != nil {
+			return nil, fmt.Errorf("invalid backup.s3.match %q: %w", s.match, err)
+		}
+	}
+	if cfg.Regex != "" {
+		regex, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup.s3.regex %q: %w", cfg.Regex, err)
+		}
+		s.regex = regex
+	}
+	switch s.order {
+	case "":
+		s.order = s3OrderModified
+	case s3OrderModified, s3OrderName, s3OrderTimestamp:
+	default:
+		return nil, fmt.Errorf("invalid backup.s3.order %q (use modified, name or timestamp)", s.order)
+	}
+
+	sse, err := NewServerSideEncryption(cfg.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+	s.sse = sse
+
+	client, err := NewS3Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	return s, nil
+}
+
+// ServerSideEncryption holds the server-side encryption parameters sent with S3
+// object requests. Its methods do nothing on a nil value.
+type ServerSideEncryption struct {
+	// customerKey and customerKeyMD5 are base64-encoded, as the SSE-C headers expect
+	customerKey    string
+	customerKeyMD5 string
+	kmsKeyID       string
+}
+
+// NewServerSideEncryption reads the SSE-C key from its environment variable. It
+// returns nil when cfg is nil.
+func NewServerSideEncryption(cfg *config.ServerSideEncryption) (*ServerSideEncryption, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.CustomerKeyEnv != "" && cfg.KMSKeyID != "" {
+		return nil, fmt.Errorf("s3.server_side_encryption accepts only one of customer_key_env and kms_key_id")
+	}
+	sse := &ServerSideEncryption{kmsKeyID: cfg.KMSKeyID}
+	if cfg.CustomerKeyEnv != "" {
+		encoded := strings.TrimSpace(os.Getenv(cfg.CustomerKeyEnv))
+		if encoded == "" {
+			return nil, fmt.Errorf("SSE-C key environment variable %s is not set", cfg.CustomerKeyEnv)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("SSE-C key in %s must be a base64-encoded 256-bit key, e.g. from openssl rand -base64 32", cfg.CustomerKeyEnv)
+		}
+		sum := md5.Sum(key)
+		sse.customerKey = base64.StdEncoding.EncodeToString(key)
+		sse.customerKeyMD5 = base64.StdEncoding.EncodeToString(sum[:])
+	}
+	return sse, nil
+}
+
+// ApplyGet adds the SSE-C key to a download.
+func (e *ServerSideEncryption) ApplyGet(in *s3.GetObjectInput) {
+	if e == nil || e.customerKey == "" {
+		return
+	}
+	in.SSECustomerAlgorithm = aws.String("AES256")
+	in.SSECustomerKey = aws.String(e.customerKey)
+	in.SSECustomerKeyMD5 = aws.String(e.customerKeyMD5)
+}
+
+// ApplyHead adds the SSE-C key to a metadata request.
+func (e *ServerSideEncryption) ApplyHead(in *s3.HeadObjectInput) {
+	if e == nil || e.customerKey == "" {
+		return
+	}
+	in.SSECustomerAlgorithm = aws.String("AES256")
+	in.SSECustomerKey = aws.String(e.customerKey)
+	in.SSECustomerKeyMD5 = aws.String(e.customerKeyMD5)
+}
+
+// ApplyPut encrypts an upload with the SSE-C key or the KMS key.
+func (e *ServerSideEncryption) ApplyPut(in *s3.PutObjectInput) {
+	switch {
+	case e == nil:
+	case e.customerKey != "":
+		in.SSECustomerAlgorithm = aws.String("AES256")
+		in.SSECustomerKey = aws.String(e.customerKey)
+		in.SSECustomerKeyMD5 = aws.String(e.customerKeyMD5)
+	case e.kmsKeyID != "":
+		in.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		in.SSEKMSKeyId = aws.String(e.kmsKeyID)
+	}
+}
+
+// Check fails when kms_key_id is set and the object was not encrypted with that
+// key. S3 decrypts SSE-KMS objects transparently, so a backup written with the
+// wrong key would otherwise go unnoticed until the key is disabled.
+func (e *ServerSideEncryption) Check(uri string, algorithm types.ServerSideEncryption, keyID *string) error {
+	if e == nil || e.kmsKeyID == "" {
+		return nil
+	}
+	if algorithm != types.ServerSideEncryptionAwsKms && algorithm != types.ServerSideEncryptionAwsKmsDsse {
+		if algorithm == "" {
+			algorithm = "none"
+		}
+		return fmt.Errorf("object %s is not encrypted with SSE-KMS (server-side encryption: %s), expected KMS key %s", uri, algorithm, e.kmsKeyID)
+	}
+	actual := aws.ToString(keyID)
+	// S3 returns the key ARN; the configuration may name the bare key ID
+	if actual != e.kmsKeyID && !strings.HasSuffix(actual, ":key/"+e.kmsKeyID) {
+		return fmt.Errorf("object %s is encrypted with KMS key %s, expected %s", uri, actual, e.kmsKeyID)
+	}
+	return nil
+}
+
+// NewS3Client creates an S3 client for the configured endpoint and credentials.
+func NewS3Client(cfg *config.S3) (*s3.Client, error) {
 	accessKey := os.Getenv(cfg.AccessKeyEnv)
 	if accessKey == "" {
 		return nil, fmt.Errorf("S3 access key environment variable %s is not set", cfg.AccessKeyEnv)
@@ -51,73 +228,495 @@ func NewS3Source(cfg *config.S3) (*S3Source, error) {
 		})
 	}
 
-	client := s3.New(s3.Options{}, opts...)
-
-	return &S3Source{
-		client:   client,
-		bucket:   cfg.Bucket,
-		prefix:   cfg.Prefix,
-		endpoint: cfg.Endpoint,
-	}, nil
+	return s3.New(s3.Options{}, opts...), nil
 }
 
 // Acquire retrieves the backup from S3.
-// If a prefix is configured, it lists objects and fetches the most recent one.
+// If a prefix ending in /, a match or a regex is configured, it lists objects and
+// fetches the latest by the configured order.
+// After Fingerprint, it fetches the fingerprinted object and fails if it has changed.
 func (s *S3Source) Acquire(ctx context.Context) (io.ReadCloser, error) {
-	key := s.prefix
+	key, err := s.resolveKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.parts) > 0 {
+		return s.acquireParts(ctx), nil
+	}
 
-	// If prefix ends with /, list and find the most recent object
-	if len(s.prefix) > 0 && s.prefix[len(s.prefix)-1] == '/' {
-		var err error
-		key, err = s.findLatestObject(ctx)
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if s.etag != "" {
+		input.IfMatch = aws.String(s.etag)
+	}
+	s.sse.ApplyGet(input)
+	result, err := s.client.GetObject(ctx, input)
+	var archived *types.InvalidObjectState
+	if errors.As(err, &archived) {
+		if s.archiveRestore == nil {
+			return nil, fmt.Errorf("object s3://%s/%s is in the %s storage class; set backup.s3.archive_restore to restore it before verification",
+				s.bucket, key, archiveClass(archived))
+		}
+		if err := s.restoreArchived(ctx, key, archived); err != nil {
+			return nil, err
+		}
+		result, err = s.client.GetObject(ctx, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object s3://%s/%s: %w", s.bucket, key, err)
+	}
+	if err := s.sse.Check(fmt.Sprintf("s3://%s/%s", s.bucket, key), result.ServerSideEncryption, result.SSEKMSKeyId); err != nil {
+		result.Body.Close()
+		return nil, err
+	}
+	s.objectMetadata = result.Metadata
+	s.lastModified = result.LastModified
+	s.size = aws.ToInt64(result.ContentLength)
+	s.downloadETag = aws.ToString(result.ETag)
+
+	return result.Body, nil
+}
+
+// acquireParts streams the parts of a split backup, pinning each to the version
+// listed when the key was resolved.
+func (s *S3Source) acquireParts(ctx context.Context) io.ReadCloser {
+	etags := make(map[string]string, len(s.parts))
+	names := make([]string, len(s.parts))
+	s.size, s.lastModified, s.objectMetadata = 0, nil, nil
+	for i, obj := range s.parts {
+		names[i] = aws.ToString(obj.Key)
+		etags[names[i]] = aws.ToString(obj.ETag)
+		s.size += aws.ToInt64(obj.Size)
+		if s.lastModified == nil || obj.LastModified.After(*s.lastModified) {
+			s.lastModified = obj.LastModified
+		}
+	}
+	s.split = newPartsReader(ctx, names, func(ctx context.Context, key string) (io.ReadCloser, error) {
+		input := &s3.GetObjectInput{
+			Bucket:  aws.String(s.bucket),
+			Key:     aws.String(key),
+			IfMatch: aws.String(etags[key]),
+		}
+		s.sse.ApplyGet(input)
+		result, err := s.client.GetObject(ctx, input)
 		if err != nil {
+			return nil, fmt.Errorf("failed to get object s3://%s/%s: %w", s.bucket, key, err)
+		}
+		if err := s.sse.Check(fmt.Sprintf("s3://%s/%s", s.bucket, key), result.ServerSideEncryption, result.SSEKMSKeyId); err != nil {
+			result.Body.Close()
 			return nil, err
 		}
+		return result.Body, nil
+	})
+	return s.split
+}
+
+// ArtifactParts returns the parts read by the last Acquire of a split backup.
+func (s *S3Source) ArtifactParts() []ArtifactPart {
+	return readParts(s.split)
+}
+
+// AcquireRange resumes the object returned by Acquire from offset. S3 fails the
+// request if the object has been replaced since. Split backups are not resumed.
+func (s *S3Source) AcquireRange(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	if len(s.parts) > 0 {
+		return nil, errRangeUnsupported
+	}
+	input := &s3.GetObjectInput{
+		Bucket:  aws.String(s.bucket),
+		Key:     aws.String(s.resolvedKey),
+		Range:   aws.String(fmt.Sprintf("bytes=%d-", offset)),
+		IfMatch: aws.String(s.downloadETag),
+	}
+	s.sse.ApplyGet(input)
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object s3://%s/%s from byte %d: %w", s.bucket, s.resolvedKey, offset, err)
 	}
+	return result.Body, nil
+}
 
-	s.resolvedKey = key
+// restoreArchived requests a temporary copy of an archived object and waits until
+// it can be downloaded, up to the configured maximum wait.
+func (s *S3Source) restoreArchived(ctx context.Context, key string, archived *types.InvalidObjectState) error {
+	opts := s.archiveRestore
+	tier := types.Tier(opts.Tier)
+	if tier == "" {
+		tier = types.TierStandard
+	}
+	days := int32(opts.Days)
+	if days <= 0 {
+		days = 1
+	}
+	maxWait := time.Duration(opts.MaxWaitMinutes) * time.Minute
+	if maxWait <= 0 {
+		maxWait = 12 * time.Hour
+	}
+	interval := time.Duration(opts.PollIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	request := &types.RestoreRequest{GlacierJobParameters: &types.GlacierJobParameters{Tier: tier}}
+	// Intelligent-Tiering moves restored objects back to a frequent tier, so no expiry applies
+	if archived.AccessTier == "" {
+		request.Days = aws.Int32(days)
+	}
 
-	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+	fmt.Printf("Object s3://%s/%s is in %s, requesting a %s restore...\n", s.bucket, key, archiveClass(archived), tier)
+	_, err := s.client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket:         aws.String(s.bucket),
+		Key:            aws.String(key),
+		RestoreRequest: request,
+	})
+	var apiErr smithy.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress") {
+		return fmt.Errorf("failed to request restore of s3://%s/%s: %w", s.bucket, key, err)
+	}
+
+	deadline := time.Now().Add(maxWait)
+	for {
+		input := &s3.HeadObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		}
+		s.sse.ApplyHead(input)
+		head, err := s.client.HeadObject(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to check restore status of s3://%s/%s: %w", s.bucket, key, err)
+		}
+		// The x-amz-restore header reads ongoing-request="false" once the copy is ready
+		if strings.Contains(aws.ToString(head.Restore), `ongoing-request="false"`) {
+			fmt.Println("✓ Archived object restored.")
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return fmt.Errorf("restore of s3://%s/%s did not complete within %s", s.bucket, key, maxWait)
+		}
+		fmt.Printf("Waiting for restore of s3://%s/%s (checking again in %s)...\n", s.bucket, key, interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// archiveClass names the archive tier an object is in.
+func archiveClass(e *types.InvalidObjectState) string {
+	if e.AccessTier != "" {
+		return string(e.AccessTier)
+	}
+	if e.StorageClass != "" {
+		return string(e.StorageClass)
+	}
+	return "archive"
+}
+
+// ArtifactModTime returns the object's LastModified time.
+func (s *S3Source) ArtifactModTime() *time.Time {
+	return s.lastModified
+}
+
+// ArtifactSize returns the size of the object Acquire returned.
+func (s *S3Source) ArtifactSize() int64 {
+	return s.size
+}
+
+// Fingerprint identifies the object by bucket, key and ETag.
+func (s *S3Source) Fingerprint(ctx context.Context) (string, error) {
+	key, err := s.resolveKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(s.parts) > 0 {
+		etags := make([]string, len(s.parts))
+		for i, obj := range s.parts {
+			etags[i] = aws.ToString(obj.ETag)
+			if s.lastModified == nil || obj.LastModified.After(*s.lastModified) {
+				s.lastModified = obj.LastModified
+			}
+		}
+		return fmt.Sprintf("s3:%s/%s/%s@%s", s.endpoint, s.bucket, splitBase(key), strings.Join(etags, ",")), nil
+	}
+
+	input := &s3.HeadObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
+	}
+	s.sse.ApplyHead(input)
+	head, err := s.client.HeadObject(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to head object s3://%s/%s: %w", s.bucket, key, err)
+	}
+	if err := s.sse.Check(fmt.Sprintf("s3://%s/%s", s.bucket, key), head.ServerSideEncryption, head.SSEKMSKeyId); err != nil {
+		return "", err
+	}
+	s.etag = aws.ToString(head.ETag)
+	s.objectMetadata = head.Metadata
+	s.lastModified = head.LastModified
+
+	return fmt.Sprintf("s3:%s/%s/%s@%s", s.endpoint, s.bucket, key, s.etag), nil
+}
+
+// resolveKey returns the configured key, or selects an object when a match or regex
+// is set or the prefix ends with /. When the key is a numbered part, the other
+// parts are listed next to it. The key is resolved once so later calls agree.
+func (s *S3Source) resolveKey(ctx context.Context) (string, error) {
+	if s.resolvedKey != "" {
+		return s.resolvedKey, nil
+	}
+
+	key := s.prefix
+	switch {
+	case s.key != "":
+		key = s.key
+	case s.match != "" || s.regex != nil || strings.HasSuffix(s.prefix, "/"):
+		var err error
+		key, err = s.selectObject(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+	if _, _, ok := splitPart(key); ok {
+		if err := s.listParts(ctx, key); err != nil {
+			return "", err
+		}
+	}
+	s.resolvedKey = key
+	return key, nil
+}
+
+// listParts collects the parts of the split backup that key belongs to.
+func (s *S3Source) listParts(ctx context.Context, key string) error {
+	listPrefix := splitBase(key) + ".part"
+	objects := make(map[string]types.Object)
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(listPrefix),
 	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list the parts of s3://%s/%s: %w", s.bucket, key, err)
+		}
+		for _, obj := range page.Contents {
+			objects[aws.ToString(obj.Key)] = obj
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	parts, err := collectParts(key, keys)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object s3://%s/%s: %w", s.bucket, key, err)
+		return err
+	}
+	s.parts = make([]types.Object, len(parts))
+	for i, k := range parts {
+		s.parts[i] = objects[k]
 	}
+	return nil
+}
 
-	return result.Body, nil
+// Metadata returns producer metadata from the object's user metadata, falling back to
+// a sidecar object next to the artifact.
+func (s *S3Source) Metadata(ctx context.Context) (*ProducerMetadata, error) {
+	if m := metadataFromMap(s.objectMetadata); m != nil {
+		return m, nil
+	}
+
+	// Sidecars are expected to be encrypted like the artifact
+	sidecarKey := splitBase(s.resolvedKey) + MetadataSidecarSuffix
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(sidecarKey),
+	}
+	s.sse.ApplyGet(input)
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get metadata sidecar s3://%s/%s: %w", s.bucket, sidecarKey, err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata sidecar s3://%s/%s: %w", s.bucket, sidecarKey, err)
+	}
+	return parseMetadataSidecar(data)
 }
 
-// findLatestObject lists objects under the prefix and returns the key of the most recently modified one.
-func (s *S3Source) findLatestObject(ctx context.Context) (string, error) {
-	input := &s3.ListObjectsV2Input{
+// ArtifactName returns the base name of the object.
+func (s *S3Source) ArtifactName(ctx context.Context) (string, error) {
+	key, err := s.resolveKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	return path.Base(splitBase(key)), nil
+}
+
+// ReadCompanion reads the object name under the same prefix as the artifact.
+func (s *S3Source) ReadCompanion(ctx context.Context, name string) ([]byte, error) {
+	key, err := s.resolveKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	companionKey := name
+	if dir := path.Dir(key); dir != "." {
+		companionKey = dir + "/" + name
+	}
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
-		Prefix: aws.String(s.prefix),
+		Key:    aws.String(companionKey),
 	}
+	s.sse.ApplyGet(input)
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.bucket, companionKey, err)
+	}
+	defer result.Body.Close()
 
-	result, err := s.client.ListObjectsV2(ctx, input)
+	data, err := io.ReadAll(result.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to list objects in s3://%s/%s: %w", s.bucket, s.prefix, err)
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", s.bucket, companionKey, err)
 	}
+	return data, nil
+}
 
-	if len(result.Contents) == 0 {
-		return "", fmt.Errorf("no objects found in s3://%s/%s", s.bucket, s.prefix)
+// selectObject lists objects under the prefix, keeps those matching the glob or
+// regex, and returns the latest by the configured order. Metadata and checksum
+// sidecars and folder markers are never selected.
+func (s *S3Source) selectObject(ctx context.Context) (string, error) {
+	listPrefix := s.prefix
+	if listPrefix == "" && s.match != "" {
+		listPrefix = globLiteralPrefix(s.match)
 	}
 
-	// Sort by LastModified descending
-	sort.Slice(result.Contents, func(i, j int) bool {
-		return result.Contents[i].LastModified.After(*result.Contents[j].LastModified)
+	var best types.Object
+	var bestTime time.Time
+	var listed, matched int
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(listPrefix),
 	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to list objects in s3://%s/%s: %w", s.bucket, listPrefix, err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") || isSidecar(key) {
+				continue
+			}
+			listed++
+			if !s.matches(key) {
+				continue
+			}
+
+			var t time.Time
+			switch s.order {
+			case s3OrderModified:
+				t = aws.ToTime(obj.LastModified)
+			case s3OrderTimestamp:
+				var ok bool
+				if t, ok = keyTimestamp(key); !ok {
+					continue
+				}
+			}
+			matched++
+			if matched == 1 || t.After(bestTime) || t.Equal(bestTime) && key > aws.ToString(best.Key) {
+				best, bestTime = obj, t
+			}
+		}
+	}
+
+	switch {
+	case listed == 0:
+		return "", fmt.Errorf("no objects found in s3://%s/%s", s.bucket, listPrefix)
+	case matched == 0 && s.order == s3OrderTimestamp:
+		return "", fmt.Errorf("no objects with a timestamp in their name match %s in s3://%s/%s", s.selection(), s.bucket, listPrefix)
+	case matched == 0:
+		return "", fmt.Errorf("no objects match %s in s3://%s/%s", s.selection(), s.bucket, listPrefix)
+	}
+	return aws.ToString(best.Key), nil
+}
+
+// matches reports whether key is selected by the glob or regex, if any.
+func (s *S3Source) matches(key string) bool {
+	if s.match != "" {
+		ok, _ := path.Match(s.match, key)
+		return ok
+	}
+	if s.regex != nil {
+		return s.regex.MatchString(key)
+	}
+	return true
+}
 
-	return *result.Contents[0].Key, nil
+// selection describes the glob or regex for messages.
+func (s *S3Source) selection() string {
+	switch {
+	case s.match != "":
+		return s.match
+	case s.regex != nil:
+		return "/" + s.regex.String() + "/"
+	}
+	return "*"
+}
+
+// globLiteralPrefix returns the part of a glob before its first metacharacter, which
+// narrows the listing when no prefix is configured.
+func globLiteralPrefix(glob string) string {
+	if i := strings.IndexAny(glob, `*?[\`); i >= 0 {
+		return glob[:i]
+	}
+	return glob
+}
+
+// keyTimestampPattern finds a date, optionally followed by a time, in a file name:
+// 20240115, 2024-01-15, 20240115T103000Z, 2024-01-15_10-30-00, 2024-01-15T10:30:00.
+var keyTimestampPattern = regexp.MustCompile(`(\d{4})-?(\d{2})-?(\d{2})(?:[T_ -]?(\d{2})[:-]?(\d{2})[:-]?(\d{2}))?`)
+
+// keyTimestamp returns the first valid date and time in the file name of key, read as UTC.
+func keyTimestamp(key string) (time.Time, bool) {
+	for _, m := range keyTimestampPattern.FindAllStringSubmatch(path.Base(key), -1) {
+		value := m[1] + m[2] + m[3]
+		layout := "20060102"
+		if m[4] != "" {
+			value += m[4] + m[5] + m[6]
+			layout += "150405"
+		}
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // Identifier returns the S3 URI for traceability.
 func (s *S3Source) Identifier() string {
 	key := s.resolvedKey
-	if key == "" {
+	switch {
+	case key != "":
+	case s.key != "":
+		key = s.key
+	case s.match != "":
+		key = s.match
+	default:
 		key = s.prefix
 	}
+	if len(s.parts) > 0 {
+		key = fmt.Sprintf("%s (%d parts)", splitBase(key), len(s.parts))
+	}
 	if s.endpoint != "" {
 		return fmt.Sprintf("s3://%s/%s (endpoint: %s)", s.bucket, key, s.endpoint)
 	}
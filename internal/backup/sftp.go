@@ -0,0 +1,464 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	defaultSSHPort    = 22
+	sshConnectTimeout = 30 * time.Second
+)
+
+// SFTPSource implements BackupSource by streaming a file from an SFTP server.
+type SFTPSource struct {
+	host string
+	port int
+	user string
+	// path is a file path or a glob; a glob selects the most recently modified match
+	path         string
+	clientConfig *ssh.ClientConfig
+	// resolvedPath stores the actual file used after glob resolution
+	resolvedPath string
+	// downloaded is the file Acquire opened, which AcquireRange checks is unchanged.
+	// Fingerprint sets it too, for the modification time of cached artifacts.
+	downloaded os.FileInfo
+	// parts lists the files of a backup split into numbered parts, in order, and
+	// partInfos their sizes and modification times
+	parts     []string
+	partInfos []os.FileInfo
+	// split reads the parts for the last Acquire
+	split *partsReader
+}
+
+// NewSFTPSource creates an SFTP source, loading the key and known hosts up front.
+func NewSFTPSource(cfg *config.SFTP) (*SFTPSource, error) {
+	if cfg.Host == "" || cfg.Path == "" {
+		return nil, fmt.Errorf("backup.sftp requires host and path")
+	}
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+
+	auth, err := sshAuth(cfg.KeyPath, cfg.PasswordEnv)
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := sshHostKeyCallback(cfg.KnownHostsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SFTPSource{
+		host: cfg.Host,
+		port: port,
+		user: user,
+		path: cfg.Path,
+		clientConfig: &ssh.ClientConfig{
+			User:            user,
+			Auth:            auth,
+			HostKeyCallback: hostKeys,
+			Timeout:         sshConnectTimeout,
+		},
+	}, nil
+}
+
+// sshAuth returns public key authentication with the key at keyPath, and password
+// authentication when passwordEnv is set.
+func sshAuth(keyPath, passwordEnv string) ([]ssh.AuthMethod, error) {
+	var auth []ssh.AuthMethod
+	if keyPath != "" {
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH key %s: %w", keyPath, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if passwordEnv != "" {
+		password := os.Getenv(passwordEnv)
+		if password == "" {
+			return nil, fmt.Errorf("SSH password environment variable %s is not set", passwordEnv)
+		}
+		auth = append(auth, ssh.Password(password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("no SSH authentication configured; set key_path or password_env")
+	}
+	return auth, nil
+}
+
+// sshHostKeyCallback verifies host keys against a known_hosts file, by default
+// ~/.ssh/known_hosts.
+func sshHostKeyCallback(knownHostsPath string) (ssh.HostKeyCallback, error) {
+	if knownHostsPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// sftpReadCloser closes the remote file, the SFTP session and the SSH connection together.
+type sftpReadCloser struct {
+	*sftp.File
+	client *sftp.Client
+	conn   *ssh.Client
+}
+
+func (r *sftpReadCloser) Close() error {
+	err := r.File.Close()
+	r.client.Close()
+	r.conn.Close()
+	return err
+}
+
+// Acquire connects to the server and streams the backup file.
+func (s *SFTPSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	remotePath, err := s.resolvePath(client)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return nil, err
+	}
+	fmt.Printf("Downloading %s over SFTP...\n", s.Identifier())
+	if len(s.parts) > 0 {
+		s.split = newPartsReader(ctx, s.parts, func(ctx context.Context, name string) (io.ReadCloser, error) {
+			return client.Open(name)
+		})
+		return &readCloser{Reader: s.split, closer: &sftpSession{reader: s.split, client: client, conn: conn}}, nil
+	}
+
+	f, err := client.Open(remotePath)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", s.Identifier(), err)
+	}
+	s.downloaded, err = f.Stat()
+	if err != nil {
+		f.Close()
+		client.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", s.Identifier(), err)
+	}
+	return &sftpReadCloser{File: f, client: client, conn: conn}, nil
+}
+
+// sftpSession closes the parts being read, the SFTP session and the SSH connection
+// together.
+type sftpSession struct {
+	reader io.Closer
+	client *sftp.Client
+	conn   *ssh.Client
+}
+
+func (c *sftpSession) Close() error {
+	err := c.reader.Close()
+	c.client.Close()
+	c.conn.Close()
+	return err
+}
+
+// ArtifactParts returns the parts read by the last Acquire of a split backup.
+func (s *SFTPSource) ArtifactParts() []ArtifactPart {
+	return readParts(s.split)
+}
+
+// AcquireRange reconnects and streams the file returned by Acquire from offset,
+// failing if its size or modification time has changed since. Split backups are
+// not resumed.
+func (s *SFTPSource) AcquireRange(ctx context.Context, offset int64) (io.ReadCloser, error) {
+	if len(s.parts) > 0 {
+		return nil, errRangeUnsupported
+	}
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.openAt(client, offset)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to resume %s from byte %d: %w", s.Identifier(), offset, err)
+	}
+	return &sftpReadCloser{File: f, client: client, conn: conn}, nil
+}
+
+// ArtifactModTime returns the modification time of the remote file, or of its
+// newest part.
+func (s *SFTPSource) ArtifactModTime() *time.Time {
+	if len(s.partInfos) > 0 {
+		var newest *time.Time
+		for _, info := range s.partInfos {
+			if t := info.ModTime(); newest == nil || t.After(*newest) {
+				newest = &t
+			}
+		}
+		return newest
+	}
+	if s.downloaded == nil {
+		return nil
+	}
+	t := s.downloaded.ModTime()
+	return &t
+}
+
+// ArtifactSize returns the size of the remote file, or the total of its parts.
+func (s *SFTPSource) ArtifactSize() int64 {
+	if len(s.partInfos) > 0 {
+		var size int64
+		for _, info := range s.partInfos {
+			size += info.Size()
+		}
+		return size
+	}
+	if s.downloaded == nil {
+		return 0
+	}
+	return s.downloaded.Size()
+}
+
+// openAt opens the downloaded file at offset if it is unchanged.
+func (s *SFTPSource) openAt(client *sftp.Client, offset int64) (*sftp.File, error) {
+	f, err := client.Open(s.resolvedPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && (info.Size() != s.downloaded.Size() || !info.ModTime().Equal(s.downloaded.ModTime())) {
+		err = fmt.Errorf("file changed during download")
+	}
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Fingerprint identifies the remote file by path, size and modification time.
+func (s *SFTPSource) Fingerprint(ctx context.Context) (string, error) {
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	defer client.Close()
+
+	remotePath, err := s.resolvePath(client)
+	if err != nil {
+		return "", err
+	}
+	if len(s.partInfos) > 0 {
+		stamps := make([]string, len(s.partInfos))
+		for i, info := range s.partInfos {
+			stamps[i] = fmt.Sprintf("%d:%d", info.Size(), info.ModTime().Unix())
+		}
+		return fmt.Sprintf("%s@%s", s.Identifier(), strings.Join(stamps, ",")), nil
+	}
+	info, err := client.Stat(remotePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", s.Identifier(), err)
+	}
+	s.downloaded = info
+	return fmt.Sprintf("%s@%d:%d", s.Identifier(), info.Size(), info.ModTime().Unix()), nil
+}
+
+// ArtifactName returns the base name of the remote file, without the part number
+// of a split backup.
+func (s *SFTPSource) ArtifactName(ctx context.Context) (string, error) {
+	if s.resolvedPath != "" {
+		return path.Base(splitBase(s.resolvedPath)), nil
+	}
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	defer client.Close()
+
+	remotePath, err := s.resolvePath(client)
+	if err != nil {
+		return "", err
+	}
+	return path.Base(splitBase(remotePath)), nil
+}
+
+// ReadCompanion reads the file name in the remote file's directory.
+func (s *SFTPSource) ReadCompanion(ctx context.Context, name string) ([]byte, error) {
+	conn, client, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	defer client.Close()
+
+	remotePath, err := s.resolvePath(client)
+	if err != nil {
+		return nil, err
+	}
+	companion := path.Join(path.Dir(remotePath), name)
+	f, err := client.Open(companion)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s on %s: %w", companion, s.host, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s on %s: %w", companion, s.host, err)
+	}
+	return data, nil
+}
+
+// connect opens an SSH connection and an SFTP session on it.
+func (s *SFTPSource) connect(ctx context.Context) (*ssh.Client, *sftp.Client, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := net.Dialer{Timeout: sshConnectTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, addr, s.clientConfig)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, fmt.Errorf("SSH handshake with %s failed: %w", addr, err)
+	}
+	conn := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to start SFTP session on %s: %w", addr, err)
+	}
+	return conn, client, nil
+}
+
+// resolvePath returns the remote file, expanding a glob to its most recently modified
+// match. When the file is a numbered part, the other parts are collected from its
+// directory. The path is resolved once so later calls agree.
+func (s *SFTPSource) resolvePath(client *sftp.Client) (string, error) {
+	if s.resolvedPath != "" {
+		return s.resolvedPath, nil
+	}
+
+	matches, err := client.Glob(s.path)
+	if err != nil {
+		return "", fmt.Errorf("invalid SFTP path pattern %q: %w", s.path, err)
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no files match %s on %s", s.path, s.host)
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var files []candidate
+	for _, m := range matches {
+		info, err := client.Stat(m)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat %s on %s: %w", m, s.host, err)
+		}
+		if info.Mode().IsRegular() && !isSidecar(m) {
+			files = append(files, candidate{path: m, modTime: info.ModTime()})
+		}
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no regular files match %s on %s", s.path, s.host)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	chosen := path.Clean(files[0].path)
+	if _, _, ok := splitPart(chosen); ok {
+		if err := s.listParts(client, chosen); err != nil {
+			return "", err
+		}
+	}
+	s.resolvedPath = chosen
+	return s.resolvedPath, nil
+}
+
+// listParts collects the parts of the split backup that remotePath belongs to.
+func (s *SFTPSource) listParts(client *sftp.Client, remotePath string) error {
+	entries, err := client.ReadDir(path.Dir(remotePath))
+	if err != nil {
+		return fmt.Errorf("failed to list the parts of %s on %s: %w", remotePath, s.host, err)
+	}
+	infos := make(map[string]os.FileInfo)
+	var names []string
+	for _, e := range entries {
+		if e.Mode().IsRegular() {
+			name := path.Join(path.Dir(remotePath), e.Name())
+			infos[name] = e
+			names = append(names, name)
+		}
+	}
+	parts, err := collectParts(remotePath, names)
+	if err != nil {
+		return err
+	}
+	s.parts = parts
+	s.partInfos = make([]os.FileInfo, len(parts))
+	for i, name := range parts {
+		s.partInfos[i] = infos[name]
+	}
+	return nil
+}
+
+// Identifier returns the server and remote path for traceability.
+func (s *SFTPSource) Identifier() string {
+	p := s.resolvedPath
+	if p == "" {
+		p = s.path
+	}
+	if len(s.parts) > 0 {
+		p = fmt.Sprintf("%s (%d parts)", splitBase(p), len(s.parts))
+	}
+	host := s.host
+	if s.port != defaultSSHPort {
+		host = net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	}
+	return fmt.Sprintf("sftp:%s@%s:%s", s.user, host, p)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"restorable.io/restorable-cli/internal/config"
 )
@@ -16,6 +17,27 @@ type BackupSource interface {
 	Identifier() string
 }
 
+// Fingerprinter is implemented by sources that can identify the artifact Acquire
+// would return without downloading it, so a cached copy can be used instead.
+type Fingerprinter interface {
+	// Fingerprint returns a string that changes whenever the artifact does.
+	Fingerprint(ctx context.Context) (string, error)
+}
+
+// ModTimeProvider is implemented by sources that know when the artifact was last
+// written. It must be called after Acquire or Fingerprint.
+type ModTimeProvider interface {
+	// ArtifactModTime returns the artifact's modification time, or nil if unknown.
+	ArtifactModTime() *time.Time
+}
+
+// SizeProvider is implemented by sources that know the artifact's size before it
+// is read. It must be called after Acquire.
+type SizeProvider interface {
+	// ArtifactSize returns the artifact's size in bytes, or 0 if unknown.
+	ArtifactSize() int64
+}
+
 // NewSourceFromConfig creates the appropriate BackupSource based on configuration.
 func NewSourceFromConfig(cfg *config.Backup) (BackupSource, error) {
 	switch cfg.Source {
@@ -32,10 +54,61 @@ func NewSourceFromConfig(cfg *config.Backup) (BackupSource, error) {
 		return NewS3Source(cfg.S3)
 
 	case "command":
-		if cfg.Command == nil || cfg.Command.Exec == "" {
+		if cfg.Command == nil {
 			return nil, fmt.Errorf("backup source is 'command' but exec is not configured")
 		}
-		return &CommandSource{Exec: cfg.Command.Exec}, nil
+		return NewCommandSource(cfg.Command)
+
+	case "walg":
+		if cfg.WALG == nil {
+			return nil, fmt.Errorf("backup source is 'walg' but walg configuration is missing")
+		}
+		return NewWALGSource(cfg.WALG)
+
+	case "sftp":
+		if cfg.SFTP == nil {
+			return nil, fmt.Errorf("backup source is 'sftp' but sftp configuration is missing")
+		}
+		return NewSFTPSource(cfg.SFTP)
+
+	case "http":
+		if cfg.HTTP == nil {
+			return nil, fmt.Errorf("backup source is 'http' but http configuration is missing")
+		}
+		return NewHTTPSource(cfg.HTTP)
+
+	case "rsync":
+		if cfg.Rsync == nil {
+			return nil, fmt.Errorf("backup source is 'rsync' but rsync configuration is missing")
+		}
+		return NewRsyncSource(cfg.Rsync)
+
+	case "k8s":
+		if cfg.K8s == nil {
+			return nil, fmt.Errorf("backup source is 'k8s' but k8s configuration is missing")
+		}
+		return NewK8sSource(cfg.K8s)
+
+	case "chain":
+		if len(cfg.Chain) == 0 {
+			return nil, fmt.Errorf("backup source is 'chain' but no sources are configured")
+		}
+		chain := &ChainSource{}
+		for i := range cfg.Chain {
+			if cfg.Chain[i].Source == "chain" {
+				return nil, fmt.Errorf("backup source chains cannot be nested")
+			}
+			// Retries and checksums apply to whatever source serves the artifact
+			if cfg.Chain[i].Retry != nil || cfg.Chain[i].Checksum != nil {
+				return nil, fmt.Errorf("chain source %d: set retry and checksum on the chain, not on its sources", i+1)
+			}
+			source, err := NewSourceFromConfig(&cfg.Chain[i])
+			if err != nil {
+				return nil, fmt.Errorf("chain source %d: %w", i+1, err)
+			}
+			chain.Sources = append(chain.Sources, source)
+		}
+		return chain, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported backup source type: %s", cfg.Source)
@@ -0,0 +1,60 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SpooledArtifact is a backup artifact written to a local temporary file.
+// Closing it removes the file.
+type SpooledArtifact struct {
+	*os.File
+	// Digest is the hex-encoded SHA-256 of the artifact as acquired.
+	Digest string
+	// Size is the artifact size in bytes.
+	Size int64
+}
+
+// Spool copies the stream into a temporary file in dir (the system default if empty),
+// hashing it on the way, and returns the file positioned at the start.
+func Spool(r io.Reader, dir string) (*SpooledArtifact, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create temp directory %s: %w", dir, err)
+		}
+	}
+
+	file, err := os.CreateTemp(dir, "restorable-artifact-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), r)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to spool backup artifact: %w", err)
+	}
+
+	return &SpooledArtifact{
+		File:   file,
+		Digest: hex.EncodeToString(hash.Sum(nil)),
+		Size:   size,
+	}, nil
+}
+
+// Close closes and removes the spool file.
+func (a *SpooledArtifact) Close() error {
+	err := a.File.Close()
+	if rmErr := os.Remove(a.File.Name()); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
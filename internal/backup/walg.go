@@ -0,0 +1,339 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// WALDirName is the directory inside the data directory holding the WAL fetched for
+// replay. Its presence tells the restorer to run archive recovery.
+const WALDirName = "restorable_wal"
+
+// walSegmentsPerID is the number of 16 MB segments per log ID.
+const walSegmentsPerID = 0x100
+
+var startWALPattern = regexp.MustCompile(`START WAL LOCATION: .* \(file ([0-9A-F]{24})\)`)
+
+// walgEnvPrefixes are the walg.env names allowed when CommandAllowlistEnv is set:
+// WAL-G's own settings and the credentials of the storages it reads.
+var walgEnvPrefixes = []string{"WALG_", "WALE_", "AWS_", "GOOGLE_", "AZURE_", "OS_"}
+
+// RecoveryPoint describes a point-in-time recovery from a base backup and WAL, or
+// from a MySQL or MariaDB snapshot and binlogs.
+type RecoveryPoint struct {
+	BackupName string `json:"backup_name"`
+	// StartSegment is the WAL segment the base backup started in, or the binlog file
+	// and position the snapshot ended at.
+	StartSegment string `json:"start_segment"`
+	// WALSegments is the number of segments, or binlog files, fetched for replay.
+	WALSegments int `json:"wal_segments"`
+	// Binlogs is set when MySQL or MariaDB binlogs were replayed instead of WAL.
+	Binlogs bool `json:"binlogs,omitempty"`
+	// TargetTime is the configured recovery target; nil replays all archived WAL.
+	TargetTime *time.Time `json:"target_time,omitempty"`
+	// AchievedTime is the commit time of the last transaction replayed from WAL.
+	AchievedTime *time.Time `json:"achieved_time,omitempty"`
+}
+
+// LogUnit names the replayed logs for messages, e.g. "WAL segment(s)".
+func (rp *RecoveryPoint) LogUnit() string {
+	if rp.Binlogs {
+		return "binlog file(s)"
+	}
+	return "WAL segment(s)"
+}
+
+// RecoveryPointProvider is implemented by sources that acquire a base backup plus WAL.
+// It must be called after Acquire.
+type RecoveryPointProvider interface {
+	// RecoveryPoint returns the recovery that the acquired artifact performs, or nil.
+	RecoveryPoint() *RecoveryPoint
+}
+
+// WALGSource fetches a base backup and the WAL archived after it from a WAL-G
+// repository, and packs them as a data directory archive set up for archive
+// recovery up to TargetTime.
+type WALGSource struct {
+	// Binary is the wal-g executable.
+	Binary     string
+	BackupName string
+	TargetTime *time.Time
+	// Env is added to the environment of wal-g, e.g. WALG_S3_PREFIX.
+	Env map[string]string
+	// WorkDir holds the fetched data directory until it has been read.
+	WorkDir  string
+	recovery *RecoveryPoint
+	// literalEnv passes Env values without expanding variables
+	literalEnv bool
+}
+
+// NewWALGSource creates a WAL-G source, applying defaults. When CommandAllowlistEnv
+// is set, the binary must be allowlisted, env may only set WAL-G and storage
+// variables, and its values are not expanded, so a config cannot run another
+// program, preload a library into wal-g or copy host secrets into its settings.
+func NewWALGSource(cfg *config.WALG) (*WALGSource, error) {
+	s := &WALGSource{
+		Binary:     cfg.Binary,
+		BackupName: cfg.Backup,
+		Env:        cfg.Env,
+		WorkDir:    cfg.WorkDir,
+	}
+	if s.Binary == "" {
+		s.Binary = "wal-g"
+	}
+	if s.BackupName == "" {
+		s.BackupName = "LATEST"
+	}
+	if allowlist, ok := os.LookupEnv(CommandAllowlistEnv); ok {
+		binary, err := allowedProgram(s.Binary, filepath.SplitList(allowlist))
+		if err != nil {
+			return nil, err
+		}
+		s.Binary = binary
+		for name := range s.Env {
+			if !hasAnyPrefix(name, walgEnvPrefixes) {
+				return nil, fmt.Errorf("walg.env sets %s, but only %s variables are allowed when %s is set",
+					name, strings.Join(walgEnvPrefixes, "*, ")+"*", CommandAllowlistEnv)
+			}
+		}
+		s.literalEnv = true
+	}
+	if cfg.TargetTime != "" {
+		target, err := time.Parse(time.RFC3339, cfg.TargetTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid walg.target_time %q (use RFC 3339, e.g. 2024-01-15T10:30:00Z): %w", cfg.TargetTime, err)
+		}
+		s.TargetTime = &target
+	}
+	return s, nil
+}
+
+// Acquire fetches the base backup and WAL, then streams a tar of the data directory.
+// Closing the stream removes the fetched files.
+func (s *WALGSource) Acquire(ctx context.Context) (io.ReadCloser, error) {
+	s.recovery = nil
+
+	dir, err := os.MkdirTemp(s.WorkDir, "restorable-walg-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WAL-G working directory: %w", err)
+	}
+	stream, err := s.fetch(ctx, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *WALGSource) fetch(ctx context.Context, dir string) (io.ReadCloser, error) {
+	dataDir := filepath.Join(dir, "pgdata")
+	fmt.Printf("Fetching base backup %s with wal-g...\n", s.BackupName)
+	if err := s.run(ctx, "backup-fetch", dataDir, s.BackupName); err != nil {
+		return nil, err
+	}
+
+	label, err := os.ReadFile(filepath.Join(dataDir, "backup_label"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup_label of base backup: %w", err)
+	}
+	match := startWALPattern.FindSubmatch(label)
+	if match == nil {
+		return nil, fmt.Errorf("backup_label has no START WAL LOCATION")
+	}
+	start := string(match[1])
+
+	walDir := filepath.Join(dataDir, WALDirName)
+	if err := os.MkdirAll(walDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+
+	// Fetch segments in order until the archive has no more on this timeline
+	segments := 0
+	for segment := start; ; segment = nextWALSegment(segment) {
+		if err := s.run(ctx, "wal-fetch", segment, filepath.Join(walDir, segment)); err != nil {
+			if segments == 0 {
+				return nil, fmt.Errorf("failed to fetch first WAL segment %s of the base backup: %w", segment, err)
+			}
+			break
+		}
+		segments++
+	}
+	fmt.Printf("✓ Fetched base backup and %d WAL segment(s) from %s.\n", segments, start)
+
+	if err := s.writeRecoveryConfig(dataDir); err != nil {
+		return nil, err
+	}
+
+	s.recovery = &RecoveryPoint{
+		BackupName:   s.BackupName,
+		StartSegment: start,
+		WALSegments:  segments,
+		TargetTime:   s.TargetTime,
+	}
+	return tarDirectory(dir, "pgdata"), nil
+}
+
+// writeRecoveryConfig sets up archive recovery from the fetched WAL.
+func (s *WALGSource) writeRecoveryConfig(dataDir string) error {
+	var conf bytes.Buffer
+	conf.WriteString("\n# Added by restorable for point-in-time recovery\n")
+	fmt.Fprintf(&conf, "restore_command = 'cp %s/%%f \"%%p\"'\n", WALDirName)
+	if s.TargetTime != nil {
+		fmt.Fprintf(&conf, "recovery_target_time = '%s'\n", s.TargetTime.UTC().Format("2006-01-02 15:04:05.999999+00"))
+	}
+	conf.WriteString("recovery_target_action = 'promote'\n")
+
+	f, err := os.OpenFile(filepath.Join(dataDir, "postgresql.auto.conf"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write recovery settings: %w", err)
+	}
+	if _, err := f.Write(conf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write recovery settings: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write recovery settings: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dataDir, "recovery.signal"), nil, 0600); err != nil {
+		return fmt.Errorf("failed to write recovery.signal: %w", err)
+	}
+	return nil
+}
+
+// run executes wal-g with the configured environment.
+func (s *WALGSource) run(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, s.Binary, args...)
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		if !s.literalEnv {
+			v = os.ExpandEnv(v)
+		}
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("wal-g %s failed: %w\nstderr: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// RecoveryPoint returns the recovery set up by the last Acquire.
+func (s *WALGSource) RecoveryPoint() *RecoveryPoint {
+	return s.recovery
+}
+
+// Identifier returns the backup name and recovery target for traceability.
+func (s *WALGSource) Identifier() string {
+	if s.TargetTime != nil {
+		return fmt.Sprintf("walg:%s@%s", s.BackupName, s.TargetTime.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("walg:%s", s.BackupName)
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWALSegment returns the name of the segment after name on the same timeline,
+// assuming the default 16 MB segment size.
+func nextWALSegment(name string) string {
+	timeline := name[:8]
+	logID, _ := strconv.ParseUint(name[8:16], 16, 32)
+	seg, _ := strconv.ParseUint(name[16:24], 16, 32)
+	seg++
+	if seg == walSegmentsPerID {
+		logID++
+		seg = 0
+	}
+	return fmt.Sprintf("%s%08X%08X", timeline, logID, seg)
+}
+
+// tarDirectory streams a tar of dir/root with paths relative to dir. Closing the
+// returned stream stops the writer and removes dir.
+func tarDirectory(dir, root string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bw := bufio.NewWriter(pw)
+		tw := tar.NewWriter(bw)
+		err := filepath.Walk(filepath.Join(dir, root), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			var link string
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(path); err != nil {
+					return err
+				}
+			}
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			if info.IsDir() {
+				hdr.Name += "/"
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	return &dirArchive{PipeReader: pr, dir: dir, done: done}
+}
+
+// dirArchive is a tar stream of a temporary directory.
+type dirArchive struct {
+	*io.PipeReader
+	dir  string
+	done chan struct{}
+}
+
+func (a *dirArchive) Close() error {
+	a.PipeReader.Close()
+	<-a.done
+	return os.RemoveAll(a.dir)
+}
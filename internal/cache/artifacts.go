@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultArtifactCacheSizeMB bounds the artifact cache when no size is configured.
+const DefaultArtifactCacheSizeMB = 10240
+
+// ArtifactCache keeps downloaded backup artifacts on disk, keyed by digest, so
+// repeated verifications of the same object skip the download. A source
+// fingerprint (e.g. bucket, key and ETag) maps to the digest of its artifact.
+// The least recently used artifacts are evicted once the cache exceeds its size.
+type ArtifactCache struct {
+	basePath string
+	maxBytes int64
+}
+
+// ArtifactCacheDir returns the default cache directory: artifact-cache in tempDir,
+// next to the spooled artifacts, or ~/.restorable/cache/artifacts without one.
+func ArtifactCacheDir(tempDir string) (string, error) {
+	if tempDir != "" {
+		return filepath.Join(tempDir, "artifact-cache"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".restorable", "cache", "artifacts"), nil
+}
+
+// NewArtifactCache creates a cache in dir, or ~/.restorable/cache/artifacts if empty.
+// maxSizeMB defaults to DefaultArtifactCacheSizeMB.
+func NewArtifactCache(dir string, maxSizeMB int) (*ArtifactCache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = ArtifactCacheDir(""); err != nil {
+			return nil, err
+		}
+	}
+	if maxSizeMB <= 0 {
+		maxSizeMB = DefaultArtifactCacheSizeMB
+	}
+	for _, sub := range []string{"blobs", "index"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create artifact cache directory: %w", err)
+		}
+	}
+	return &ArtifactCache{basePath: dir, maxBytes: int64(maxSizeMB) << 20}, nil
+}
+
+// Lookup opens the cached artifact for fingerprint and returns it with its digest.
+// Returns nil, "", nil if the artifact is not cached.
+func (c *ArtifactCache) Lookup(fingerprint string) (*os.File, string, error) {
+	data, err := os.ReadFile(c.indexPath(fingerprint))
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read artifact cache index: %w", err)
+	}
+
+	digest := strings.TrimSpace(string(data))
+	blob := c.blobPath(digest)
+	file, err := os.Open(blob)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open cached artifact: %w", err)
+	}
+
+	// The modification time orders eviction
+	now := time.Now()
+	os.Chtimes(blob, now, now)
+	return file, digest, nil
+}
+
+// Store copies the artifact at path into the cache under digest, records it for
+// fingerprint and evicts old artifacts beyond the size limit. Artifacts larger
+// than the limit are not cached.
+func (c *ArtifactCache) Store(fingerprint, digest, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat artifact: %w", err)
+	}
+	if info.Size() > c.maxBytes {
+		return nil
+	}
+
+	blob := c.blobPath(digest)
+	if _, err := os.Stat(blob); os.IsNotExist(err) {
+		if err := copyFile(path, blob); err != nil {
+			return fmt.Errorf("failed to cache artifact: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.indexPath(fingerprint), []byte(digest+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write artifact cache index: %w", err)
+	}
+	return c.evict(digest)
+}
+
+// Invalidate forgets the artifact for fingerprint and removes its blob, e.g. after
+// it failed to match its digest.
+func (c *ArtifactCache) Invalidate(fingerprint, digest string) error {
+	if err := os.Remove(c.indexPath(fingerprint)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(c.blobPath(digest)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// CachedArtifact is an artifact held in the cache.
+type CachedArtifact struct {
+	Digest string
+	Size   int64
+	// LastUsed is when the artifact was last stored or served.
+	LastUsed time.Time
+}
+
+// List returns the cached artifacts, least recently used first.
+func (c *ArtifactCache) List() ([]CachedArtifact, error) {
+	entries, err := os.ReadDir(filepath.Join(c.basePath, "blobs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list artifact cache: %w", err)
+	}
+
+	var artifacts []CachedArtifact
+	for _, e := range entries {
+		info, err := e.Info()
+		// Skip copies in progress
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		artifacts = append(artifacts, CachedArtifact{Digest: e.Name(), Size: info.Size(), LastUsed: info.ModTime()})
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].LastUsed.Before(artifacts[j].LastUsed) })
+	return artifacts, nil
+}
+
+// Dir returns the cache directory.
+func (c *ArtifactCache) Dir() string {
+	return c.basePath
+}
+
+// MaxBytes returns the size limit of the cache.
+func (c *ArtifactCache) MaxBytes() int64 {
+	return c.maxBytes
+}
+
+// Prune removes artifacts last used before cutoff, then the least recently used
+// beyond the size limit, and finally index entries whose artifact is gone. A zero
+// cutoff prunes by size only. With dryRun set, it only returns what it would remove.
+func (c *ArtifactCache) Prune(cutoff time.Time, dryRun bool) ([]CachedArtifact, error) {
+	artifacts, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, a := range artifacts {
+		total += a.Size
+	}
+
+	var removed []CachedArtifact
+	for _, a := range artifacts {
+		if !a.LastUsed.Before(cutoff) && total <= c.maxBytes {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(c.blobPath(a.Digest)); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("failed to remove cached artifact: %w", err)
+			}
+		}
+		removed = append(removed, a)
+		total -= a.Size
+	}
+	if dryRun {
+		return removed, nil
+	}
+
+	indexDir := filepath.Join(c.basePath, "index")
+	entries, err := os.ReadDir(indexDir)
+	if err != nil {
+		return removed, fmt.Errorf("failed to list artifact cache index: %w", err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(indexDir, e.Name()))
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(c.blobPath(strings.TrimSpace(string(data)))); os.IsNotExist(err) {
+			os.Remove(filepath.Join(indexDir, e.Name()))
+		}
+	}
+	return removed, nil
+}
+
+// evict removes the least recently used blobs until the cache fits its limit,
+// never removing keep. Index entries pointing at removed blobs miss on lookup.
+func (c *ArtifactCache) evict(keep string) error {
+	blobs, err := c.List()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, b := range blobs {
+		total += b.Size
+	}
+
+	for _, b := range blobs {
+		if total <= c.maxBytes {
+			break
+		}
+		if b.Digest == keep {
+			continue
+		}
+		if err := os.Remove(c.blobPath(b.Digest)); err != nil {
+			return fmt.Errorf("failed to evict cached artifact: %w", err)
+		}
+		total -= b.Size
+	}
+	return nil
+}
+
+func (c *ArtifactCache) blobPath(digest string) string {
+	return filepath.Join(c.basePath, "blobs", digest)
+}
+
+func (c *ArtifactCache) indexPath(fingerprint string) string {
+	sum := sha256.Sum256([]byte(fingerprint))
+	return filepath.Join(c.basePath, "index", hex.EncodeToString(sum[:]))
+}
+
+// copyFile copies src to dst through a temporary file so readers never see a
+// partial artifact.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".partial-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
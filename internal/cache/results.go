@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResultEntry points at the reports produced for an artifact and configuration.
+type ResultEntry struct {
+	Reports   []CachedReport `json:"reports"`
+	Success   bool           `json:"success"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// CachedReport identifies a single report of a cached run.
+type CachedReport struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// ResultCache maps (artifact digest, config hash) to prior verification reports,
+// so retried runs on the same artifact can return the signed report instantly.
+type ResultCache struct {
+	basePath string
+}
+
+// NewResultCache creates a cache under ~/.restorable/cache/results.
+func NewResultCache() (*ResultCache, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not get user home directory: %w", err)
+	}
+	basePath := filepath.Join(homeDir, ".restorable", "cache", "results")
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create result cache directory: %w", err)
+	}
+	return &ResultCache{basePath: basePath}, nil
+}
+
+// Key derives the cache key from the artifact digest and the configuration hash.
+func Key(artifactDigest, configHash string) string {
+	sum := sha256.Sum256([]byte(artifactDigest + ":" + configHash))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashConfig returns a stable hash of any JSON-serializable configuration value.
+func HashConfig(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config for hashing: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Lookup returns the cached entry for key.
+// Returns nil, nil if there is no entry or any of its reports no longer exists.
+func (c *ResultCache) Lookup(key string) (*ResultEntry, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result cache entry: %w", err)
+	}
+
+	var entry ResultEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse result cache entry: %w", err)
+	}
+
+	if len(entry.Reports) == 0 {
+		return nil, nil
+	}
+	for _, r := range entry.Reports {
+		if _, err := os.Stat(r.Path); err != nil {
+			return nil, nil
+		}
+	}
+	return &entry, nil
+}
+
+// Store records the report produced for key.
+func (c *ResultCache) Store(key string, entry *ResultEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result cache entry: %w", err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write result cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *ResultCache) path(key string) string {
+	return filepath.Join(c.basePath, key+".json")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/cache"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and prune the backup artifact cache",
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the size of the artifact cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		artifactCache, err := openArtifactCache(cfg)
+		if err != nil {
+			return err
+		}
+		artifacts, err := artifactCache.List()
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, a := range artifacts {
+			total += a.Size
+		}
+		if cfg.CLI.ArtifactCache == nil {
+			fmt.Println("⚠ The artifact cache is disabled; add a cli.artifact_cache section to config.yaml to enable it.")
+		}
+		fmt.Printf("Directory: %s\n", artifactCache.Dir())
+		fmt.Printf("Artifacts: %d\n", len(artifacts))
+		fmt.Printf("Size: %s of %s\n", formatBytes(total), formatBytes(artifactCache.MaxBytes()))
+		if len(artifacts) > 0 {
+			fmt.Printf("Last Used: %s\n", artifacts[len(artifacts)-1].LastUsed.UTC().Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached artifacts",
+	Long: `Removes cached backup artifacts beyond cli.artifact_cache.max_size_mb, least
+recently used first. With --days, artifacts not used within that many days are
+removed as well; --all empties the cache.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		all, _ := cmd.Flags().GetBool("all")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		artifactCache, err := openArtifactCache(cfg)
+		if err != nil {
+			return err
+		}
+
+		var cutoff time.Time
+		switch {
+		case all:
+			cutoff = time.Now().Add(time.Second)
+		case days > 0:
+			cutoff = time.Now().AddDate(0, 0, -days)
+		}
+		removed, err := artifactCache.Prune(cutoff, dryRun)
+		if err != nil {
+			return err
+		}
+
+		var freed int64
+		for _, a := range removed {
+			freed += a.Size
+		}
+		verb := "Pruned"
+		if dryRun {
+			verb = "Would prune"
+			for _, a := range removed {
+				fmt.Printf("  %s  %12s  last used %s\n", a.Digest[:12], formatBytes(a.Size), a.LastUsed.UTC().Format(time.RFC3339))
+			}
+		}
+		fmt.Printf("✓ %s %d cached artifact(s), %s.\n", verb, len(removed), formatBytes(freed))
+		return nil
+	},
+}
+
+// openArtifactCache opens the configured artifact cache, with the defaults when
+// it is not configured.
+func openArtifactCache(cfg *config.Config) (*cache.ArtifactCache, error) {
+	var settings config.ArtifactCache
+	if cfg.CLI.ArtifactCache != nil {
+		settings = *cfg.CLI.ArtifactCache
+	}
+	dir := settings.Dir
+	if dir == "" {
+		var err error
+		if dir, err = cache.ArtifactCacheDir(cfg.CLI.TempDir); err != nil {
+			return nil, err
+		}
+	}
+	return cache.NewArtifactCache(dir, settings.MaxSizeMB)
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatusCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().Int("days", 0, "Also remove artifacts not used within this many days")
+	cachePruneCmd.Flags().Bool("all", false, "Remove every cached artifact")
+	cachePruneCmd.Flags().Bool("dry-run", false, "List the artifacts that would be removed without removing them")
+}
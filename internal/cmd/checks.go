@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/verify"
+)
+
+var checksCmd = &cobra.Command{
+	Use:   "checks",
+	Short: "Discover available verification checks",
+	Long:  `List available verification checks and describe how to enable and configure them.`,
+}
+
+var checksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available verification checks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defs := verify.Definitions()
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(defs, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("%-20s  %-8s  %-40s  %s\n", "ID", "LEVEL", "ENABLED BY", "DESCRIPTION")
+		for _, d := range defs {
+			enabledBy := d.EnabledBy
+			if d.AlwaysOn() {
+				enabledBy = "(always)"
+			}
+			fmt.Printf("%-20s  %-8s  %-40s  %s\n", d.ID, d.DefaultLevel, enabledBy, d.Description)
+		}
+		return nil
+	},
+}
+
+var checksDescribeCmd = &cobra.Command{
+	Use:   "describe <id>",
+	Short: "Describe a verification check and its configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, ok := verify.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown check %q (see 'restorable checks list')", args[0])
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			data, err := json.MarshalIndent(d, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("Check: %s\n", d.ID)
+		fmt.Printf("Description: %s\n", d.Description)
+		fmt.Printf("Default Level: %s\n", d.DefaultLevel)
+		if d.AlwaysOn() {
+			fmt.Println("Enabled: always")
+		} else {
+			fmt.Printf("Enabled By: %s\n", d.EnabledBy)
+		}
+		if len(d.Requires) > 0 {
+			fmt.Printf("Requires: %s\n", strings.Join(d.Requires, ", "))
+		}
+		if len(d.Databases) > 0 {
+			fmt.Printf("Databases: %s\n", strings.Join(d.Databases, ", "))
+		} else {
+			fmt.Println("Databases: all")
+		}
+
+		if len(d.Options) > 0 {
+			fmt.Println()
+			fmt.Println("Options:")
+			for _, o := range d.Options {
+				def := ""
+				if o.Default != "" {
+					def = fmt.Sprintf(" (default %s)", o.Default)
+				}
+				fmt.Printf("  %s [%s]%s\n", o.Key, o.Type, def)
+				fmt.Printf("      %s\n", o.Description)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checksCmd)
+	checksCmd.AddCommand(checksListCmd)
+	checksCmd.AddCommand(checksDescribeCmd)
+
+	checksListCmd.Flags().Bool("json", false, "Output checks as JSON")
+	checksDescribeCmd.Flags().Bool("json", false, "Output the check as JSON")
+}
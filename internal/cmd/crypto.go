@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/crypto"
+)
+
+// cryptoTestPayloadSize spans more than one 64 KiB age chunk.
+const cryptoTestPayloadSize = 100 * 1024
+
+var cryptoCmd = &cobra.Command{
+	Use:   "crypto",
+	Short: "Check backup encryption settings",
+}
+
+var cryptoTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Round-trip a sample payload through the configured encryption keys",
+	Long: `Encrypts a random payload to each public key in encryption.recipients and
+decrypts it with the identities in encryption.private_key_path and
+encryption.private_key_paths, printing the key that decrypted it. A recipient that
+fails means backups encrypted to it cannot be verified, or restored, with the
+configured keys, so run this after rotating keys and before the next backup
+depends on them.
+
+Without encryption.recipients, the public keys of the identities themselves are
+tested, which only checks that the key file is readable.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Encryption == nil {
+			return fmt.Errorf("encryption is not configured; add an encryption section to config.yaml")
+		}
+
+		decryptor, err := openDecryptor(cmd.Context(), cfg.Encryption)
+		if err != nil {
+			return err
+		}
+		keys := keyNames(cfg.Encryption)
+		fmt.Printf("✓ Loaded %d identity(ies) from %s\n", decryptor.Identities(), keys)
+
+		var recipients []crypto.Recipient
+		if len(cfg.Encryption.Recipients) > 0 {
+			recipients, err = crypto.ParseRecipients(cfg.Encryption.Recipients)
+		} else {
+			fmt.Println("⚠ encryption.recipients is not configured; testing the key file's own public keys.")
+			recipients, err = decryptor.Recipients()
+		}
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		for _, r := range recipients {
+			if err := decryptor.RoundTrip(r, cryptoTestPayloadSize); err != nil {
+				fmt.Printf("  ✗ %s: %v\n", r.PublicKey, err)
+				failed++
+				continue
+			}
+			fmt.Printf("  ✓ %s (%s)\n", r.PublicKey, decryptor.MatchedKey())
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d recipient(s) cannot be decrypted with %s", failed, len(recipients), keys)
+		}
+		fmt.Printf("\n✓ Encryption round trip passed for %d recipient(s).\n", len(recipients))
+		return nil
+	},
+}
+
+// openDecryptor loads the age identities of the configured private keys, in
+// order, unwrapping each with Cloud KMS for the gcp_kms method so keys are never
+// stored in plaintext.
+func openDecryptor(ctx context.Context, enc *config.Encryption) (*crypto.AgeDecryptor, error) {
+	var kms *crypto.GCPKMS
+	switch enc.Method {
+	case "", config.EncryptionAge:
+	case config.EncryptionGCPKMS:
+		if enc.GCPKMS == nil || enc.GCPKMS.KeyName == "" {
+			return nil, fmt.Errorf("encryption.gcp_kms.key_name is required for the %s method", config.EncryptionGCPKMS)
+		}
+		var err error
+		if kms, err = crypto.NewGCPKMS(enc.GCPKMS.KeyName); err != nil {
+			return nil, err
+		}
+	case config.EncryptionOpenSSL:
+		return nil, fmt.Errorf("the %s encryption method uses a passphrase, not an age key", config.EncryptionOpenSSL)
+	default:
+		return nil, fmt.Errorf("unsupported encryption method %q (use %s, %s or %s)", enc.Method, config.EncryptionAge, config.EncryptionGCPKMS, config.EncryptionOpenSSL)
+	}
+
+	paths := enc.KeyPaths()
+	if len(paths) == 0 && enc.PrivateKeyEnv == "" {
+		return nil, fmt.Errorf("encryption.private_key_path, private_key_paths or private_key_env is required")
+	}
+	var decryptor *crypto.AgeDecryptor
+	if enc.PrivateKeyEnv != "" {
+		if kms != nil {
+			return nil, fmt.Errorf("encryption.private_key_env is not supported with the %s method", config.EncryptionGCPKMS)
+		}
+		var err error
+		if decryptor, err = crypto.NewAgeDecryptorFromEnv(enc.PrivateKeyEnv); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range paths {
+		var next *crypto.AgeDecryptor
+		var err error
+		if kms == nil {
+			next, err = crypto.NewAgeDecryptor(path)
+		} else {
+			next, err = unwrapAgeKey(ctx, kms, path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if decryptor == nil {
+			decryptor = next
+		} else {
+			decryptor.Add(next)
+		}
+	}
+	return decryptor, nil
+}
+
+// sniffEncryption compares the artifact's leading bytes with the configured
+// encryption and reports whether to decrypt it. An unencrypted artifact is restored
+// as is, with a warning, when encryption is configured; an encrypted one fails the
+// run when it cannot be decrypted with the configured method, rather than feeding
+// ciphertext to the restore tools.
+func sniffEncryption(enc *config.Encryption, artifact io.ReaderAt) (bool, error) {
+	header := make([]byte, crypto.DetectionHeaderSize)
+	n, err := artifact.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read backup artifact: %w", err)
+	}
+	detected := crypto.DetectEncryption(header[:n])
+	if detected == crypto.FormatGPG {
+		return false, fmt.Errorf("backup artifact is gpg-encrypted, which is not supported (use age or openssl enc)")
+	}
+
+	if enc == nil {
+		if detected != "" {
+			return false, fmt.Errorf("backup artifact is %s-encrypted, but no encryption section is configured", detected)
+		}
+		return false, nil
+	}
+	if detected == "" {
+		fmt.Println("⚠ Encryption is configured, but the backup artifact is not encrypted; restoring it as is.")
+		return false, nil
+	}
+	expected := crypto.FormatAge
+	if enc.Method == config.EncryptionOpenSSL {
+		expected = crypto.FormatOpenSSL
+	}
+	if detected != expected {
+		method := enc.Method
+		if method == "" {
+			method = config.EncryptionAge
+		}
+		return false, fmt.Errorf("backup artifact is %s-encrypted, but encryption.method is %s", detected, method)
+	}
+	return true, nil
+}
+
+// keyNames lists the configured age keys for messages.
+func keyNames(enc *config.Encryption) string {
+	names := enc.KeyPaths()
+	if enc.PrivateKeyEnv != "" {
+		names = append([]string{"env:" + enc.PrivateKeyEnv}, names...)
+	}
+	return strings.Join(names, ", ")
+}
+
+// unwrapAgeKey decrypts the age key file at path with Cloud KMS.
+func unwrapAgeKey(ctx context.Context, kms *crypto.GCPKMS, path string) (*crypto.AgeDecryptor, error) {
+	wrapped, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapped age private key from %s: %w", path, err)
+	}
+	keyData, err := kms.Decrypt(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap age private key %s: %w", path, err)
+	}
+	return crypto.ParseAgeDecryptor(keyData, path)
+}
+
+// openStreamDecryptor returns the decryptor of backup artifacts for the configured
+// encryption method.
+func openStreamDecryptor(ctx context.Context, enc *config.Encryption) (crypto.StreamDecryptor, error) {
+	if enc.Method != config.EncryptionOpenSSL {
+		return openDecryptor(ctx, enc)
+	}
+	if enc.OpenSSL == nil || enc.OpenSSL.PassphraseEnv == "" {
+		return nil, fmt.Errorf("encryption.openssl.passphrase_env is required for the %s method", config.EncryptionOpenSSL)
+	}
+	return crypto.NewOpenSSLDecryptorFromEnv(enc.OpenSSL.PassphraseEnv, enc.OpenSSL.Iterations, enc.OpenSSL.Digest)
+}
+
+func init() {
+	rootCmd.AddCommand(cryptoCmd)
+	cryptoCmd.AddCommand(cryptoTestCmd)
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const defaultExpansionFactor = 3
+
+// diskRequirement is space a verification run needs on one filesystem.
+type diskRequirement struct {
+	dir      string
+	bytes    int64
+	purposes []string
+}
+
+// checkDiskSpace fails when the temp directories or the Docker data root lack the
+// space to download and restore an artifact of size bytes. spooled is true when the
+// artifact is already on disk, so only the restore needs room.
+func checkDiskSpace(ctx context.Context, cfg *config.Config, size int64, spooled bool) error {
+	settings := config.DiskSpace{}
+	if cfg.CLI.DiskSpace != nil {
+		settings = *cfg.CLI.DiskSpace
+	}
+	if settings.Disabled || size <= 0 {
+		return nil
+	}
+	factor := settings.ExpansionFactor
+	if factor <= 0 {
+		factor = defaultExpansionFactor
+	}
+	expanded := int64(float64(size) * factor)
+
+	tempDir := cfg.CLI.TempDir
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+	var requirements []*diskRequirement
+	add := func(dir string, bytes int64, purpose string) error {
+		// Requirements on the same filesystem add up
+		dev, err := deviceOf(dir)
+		if err != nil {
+			return err
+		}
+		for _, r := range requirements {
+			if d, _ := deviceOf(r.dir); d == dev {
+				r.bytes += bytes
+				r.purposes = append(r.purposes, purpose)
+				return nil
+			}
+		}
+		requirements = append(requirements, &diskRequirement{dir: dir, bytes: bytes, purposes: []string{purpose}})
+		return nil
+	}
+
+	if !spooled {
+		if err := add(tempDir, size, "download"); err != nil {
+			return err
+		}
+	}
+	// Tars are copied into the temp directory to find their single dump, unless
+	// backup.archive_member names it. Whether the artifact is a tar is only known
+	// once it is decrypted and decompressed, so the copy is always counted.
+	if cfg.Backup.ArchiveMember == "" {
+		if err := add(tempDir, size, "tar unpacking"); err != nil {
+			return err
+		}
+	}
+	if cfg.Database.Type == "sqlite" {
+		// SQLite databases are restored on the host, in the temp directory
+		if err := add(tempDir, expanded, "restored database"); err != nil {
+			return err
+		}
+	} else {
+		// Restorers stage the artifact in the system temp directory for the container
+		if err := add(os.TempDir(), size, "restore staging"); err != nil {
+			return err
+		}
+		dataRoot, err := dockerDataRoot(ctx)
+		if err != nil {
+			fmt.Printf("⚠ Skipping the Docker disk space check: %v\n", err)
+		} else if err := add(dataRoot, expanded, "restored database"); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range requirements {
+		free, err := freeSpace(r.dir)
+		if err != nil {
+			return err
+		}
+		if uint64(r.bytes) > free {
+			return fmt.Errorf("not enough disk space in %s for a %s backup: %s needed for %s, %s free (free up space, move cli.temp_dir or tune cli.disk_space.expansion_factor)",
+				r.dir, formatBytes(size), formatBytes(r.bytes), strings.Join(r.purposes, " and "), formatBytes(int64(free)))
+		}
+	}
+	fmt.Printf("✓ Enough disk space to restore a %s backup.\n", formatBytes(size))
+	return nil
+}
+
+// dockerDataRoot returns the local directory Docker keeps containers and volumes
+// in. Daemons on another host or in a VM have no local data root.
+func dockerDataRoot(ctx context.Context) (string, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" && !strings.HasPrefix(host, "unix://") {
+		return "", fmt.Errorf("the Docker daemon at %s is remote", host)
+	}
+	out, err := exec.CommandContext(ctx, "docker", "info", "--format", "{{.DockerRootDir}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the Docker data root: %w", err)
+	}
+	root := strings.TrimSpace(string(out))
+	if root == "" {
+		return "", fmt.Errorf("docker info reported no data root")
+	}
+	if _, err := os.Stat(root); err != nil {
+		return "", fmt.Errorf("the Docker data root %s is not on this host", root)
+	}
+	return root, nil
+}
+
+// existingDir returns dir, or its nearest existing parent when it has not been
+// created yet.
+func existingDir(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// deviceOf returns the device of the filesystem holding dir.
+func deviceOf(dir string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(existingDir(dir), &st); err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", dir, err)
+	}
+	return uint64(st.Dev), nil
+}
+
+// freeSpace returns the bytes available to unprivileged users in the filesystem
+// holding dir.
+func freeSpace(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(existingDir(dir), &st); err != nil {
+		return 0, fmt.Errorf("failed to read free space of %s: %w", dir, err)
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
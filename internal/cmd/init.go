@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/detect"
 	"restorable.io/restorable-cli/internal/signing"
 )
 
@@ -21,7 +22,11 @@ var initCmd = &cobra.Command{
 
 This command creates a '.restorable' directory containing a default 'config.yaml'
 and a new Ed25519 keypair for signing verification reports. It will prompt
-for basic project information to get you started.`,
+for basic project information to get you started.
+
+With --detect, docker-compose files, a .env file and DATABASE_URL in the
+current directory are scanned first, and what is found becomes the default
+for the database type, version, image and credential environment variables.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Bootstrapping a new Restorable project...")
 
@@ -42,6 +47,25 @@ for basic project information to get you started.`,
 			return fmt.Errorf("a config file already exists at %s", configPath)
 		}
 
+		detected := &detect.Result{}
+		if detectFlag, _ := cmd.Flags().GetBool("detect"); detectFlag {
+			wd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("could not get current directory: %w", err)
+			}
+			detected, err = detect.Dir(wd)
+			if err != nil {
+				return fmt.Errorf("failed to detect database settings: %w", err)
+			}
+			if detected.Found() {
+				for _, source := range detected.Sources {
+					fmt.Printf("✓ Detected database settings in %s\n", source)
+				}
+			} else {
+				fmt.Println("⚠ No docker-compose file, .env or DATABASE_URL with database settings found")
+			}
+		}
+
 		reader := bufio.NewReader(os.Stdin)
 
 		// Interactive prompts
@@ -49,14 +73,27 @@ for basic project information to get you started.`,
 		if err != nil {
 			return err
 		}
-		dbType, err := promptWithDefault(reader, "Database type", "postgres")
+		dbType, err := promptWithDefault(reader, "Database type", valueOr(detected.Type, "postgres"))
 		if err != nil {
 			return err
 		}
-		dbVersion, err := promptIntWithDefault(reader, "Database major version", 15)
+		restoreCfg := defaultRestore(dbType)
+		if dbType == detected.Type && detected.MajorVersion > 0 {
+			restoreCfg.majorVersion = detected.MajorVersion
+		}
+		dbVersion, err := promptIntWithDefault(reader, "Database major version", restoreCfg.majorVersion)
 		if err != nil {
 			return err
 		}
+		restoreCfg.restore.DockerImage = fmt.Sprintf("%s:%d", restoreCfg.image, dbVersion)
+		if dbType == detected.Type {
+			if detected.MajorVersion == dbVersion && detected.Image != "" {
+				restoreCfg.restore.DockerImage = detected.Image
+			}
+			restoreCfg.restore.User = valueOr(detected.User, restoreCfg.restore.User)
+			restoreCfg.restore.PasswordEnv = valueOr(detected.PasswordEnv, restoreCfg.restore.PasswordEnv)
+			restoreCfg.restore.DBName = valueOr(detected.DBName, restoreCfg.restore.DBName)
+		}
 
 		// Backup source configuration
 		backupSource, err := promptWithDefault(reader, "Backup source type (local/s3/command)", "local")
@@ -142,13 +179,7 @@ for basic project information to get you started.`,
 			Database: config.Database{
 				Type:         dbType,
 				MajorVersion: dbVersion,
-				Restore: config.Restore{
-					DockerImage: fmt.Sprintf("%s:%d", dbType, dbVersion),
-					User:        "postgres",
-					PasswordEnv: "RESTORABLE_DB_PASSWORD",
-					DBName:      "restorable_verify",
-					Port:        5432,
-				},
+				Restore:      restoreCfg.restore,
 			},
 			Verification: config.Verification{
 				Schema: config.SchemaVerification{Enabled: true},
@@ -194,6 +225,52 @@ for basic project information to get you started.`,
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().Bool("detect", false, "Prefill database settings from docker-compose, .env and DATABASE_URL in the current directory")
+}
+
+// restoreDefaults holds the per-database defaults offered by init.
+type restoreDefaults struct {
+	image        string
+	majorVersion int
+	restore      config.Restore
+}
+
+// defaultRestore returns the restore settings init proposes for a database type.
+func defaultRestore(dbType string) restoreDefaults {
+	d := restoreDefaults{
+		image:        dbType,
+		majorVersion: 15,
+		restore: config.Restore{
+			User:        "postgres",
+			PasswordEnv: "RESTORABLE_DB_PASSWORD",
+			DBName:      "restorable_verify",
+			Port:        5432,
+		},
+	}
+	switch dbType {
+	case "mariadb":
+		d.majorVersion = 11
+		d.restore.User = "root"
+		d.restore.Port = 3306
+	case "mysql":
+		d.majorVersion = 8
+		d.restore.User = "root"
+		d.restore.Port = 3306
+	case "mongodb":
+		d.image = "mongo"
+		d.majorVersion = 7
+		d.restore.User = "root"
+		d.restore.Port = 27017
+	}
+	return d
+}
+
+// valueOr returns value, or fallback if value is empty.
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
 }
 
 // promptString asks the user for input without a default value.
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/report"
+)
+
+var (
+	keysImportOutput string
+	keysImportForce  bool
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage report signing keys",
+}
+
+var keysImportCmd = &cobra.Command{
+	Use:   "import <private-key-file>",
+	Short: "Convert an Ed25519 signing key from PEM or OpenSSH format",
+	Long: `Converts an Ed25519 private key issued elsewhere, such as by a PKI pipeline,
+into the raw key files written by init. PEM-encoded PKCS#8 keys (openssl genpkey
+-algorithm ed25519) and unencrypted OpenSSH keys (ssh-keygen -t ed25519) are
+accepted.
+
+The private key is written to --output, ~/.restorable/keys/signing.key by
+default, and its public key next to it with a .pub extension, where report
+verify looks for it. signing.private_key_path can also point at a PEM or
+OpenSSH key directly; importing only changes the file format.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		privateKey, err := report.LoadPrivateKey(args[0])
+		if err != nil {
+			return err
+		}
+		publicKey := privateKey.Public().(ed25519.PublicKey)
+
+		privKeyPath := keysImportOutput
+		if privKeyPath == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("could not get user home directory: %w", err)
+			}
+			privKeyPath = filepath.Join(homeDir, ".restorable", "keys", "signing.key")
+		}
+		pubKeyPath := strings.TrimSuffix(privKeyPath, ".key") + ".pub"
+
+		if !keysImportForce {
+			for _, path := range []string{privKeyPath, pubKeyPath} {
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists; use --force to replace it", path)
+				}
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(privKeyPath), 0755); err != nil {
+			return fmt.Errorf("failed to create key directory: %w", err)
+		}
+		if err := os.WriteFile(privKeyPath, privateKey, 0600); err != nil {
+			return fmt.Errorf("failed to write private key: %w", err)
+		}
+		if err := os.WriteFile(pubKeyPath, publicKey, 0644); err != nil {
+			return fmt.Errorf("failed to write public key: %w", err)
+		}
+
+		fmt.Printf("✓ Wrote signing keys to %s and %s\n", privKeyPath, pubKeyPath)
+		fmt.Printf("  Public key (base64): %s\n", base64.StdEncoding.EncodeToString(publicKey))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysImportCmd)
+	keysImportCmd.Flags().StringVarP(&keysImportOutput, "output", "o", "", "Path of the converted private key (default ~/.restorable/keys/signing.key)")
+	keysImportCmd.Flags().BoolVar(&keysImportForce, "force", false, "Replace existing key files")
+}
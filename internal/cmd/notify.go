@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/backup"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/notify"
+	"restorable.io/restorable-cli/internal/report"
+	"restorable.io/restorable-cli/internal/verify"
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notification targets",
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification to every configured target",
+	Long: `Send a synthetic report summary to every configured notification target,
+regardless of its 'on' setting, so channel and webhook misconfigurations are
+caught before a real failure needs to page someone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Notifications == nil || len(cfg.Notifications.Targets) == 0 {
+			return fmt.Errorf("no notification targets configured; add a notifications section to config.yaml")
+		}
+
+		targets, err := notify.NewTargets(cfg.Notifications)
+		if err != nil {
+			return err
+		}
+
+		loc, err := cfg.CLI.Location()
+		if err != nil {
+			return err
+		}
+		failure, _ := cmd.Flags().GetBool("failure")
+		msg, err := notify.FromReport(testReport(cfg, failure), loc)
+		if err != nil {
+			return err
+		}
+		msg.Title = "[TEST] " + msg.Title
+		msg.Test = true
+
+		only, _ := cmd.Flags().GetString("target")
+		var sent, failed int
+		for _, t := range targets {
+			if only != "" && t.Name != only {
+				continue
+			}
+			if err := t.Send(ctx, msg); err != nil {
+				fmt.Printf("✗ %s: %v\n", t.Name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s: test notification sent\n", t.Name)
+			sent++
+		}
+
+		if sent+failed == 0 {
+			return fmt.Errorf("no notification target named %q", only)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d notification target(s) failed", failed, sent+failed)
+		}
+		return nil
+	},
+}
+
+// testReport builds a synthetic report for the configured project.
+func testReport(cfg *config.Config, failure bool) *report.Report {
+	check := verify.CheckResult{
+		Name:    "tables_exist",
+		Level:   verify.LevelCritical,
+		Passed:  !failure,
+		Message: "Test notification from restorable notify test",
+	}
+
+	source := cfg.Backup.Source
+	if s, err := backup.NewSourceFromConfig(&cfg.Backup); err == nil {
+		source = s.Identifier()
+	}
+
+	return report.NewReportBuilder().
+		WithID("test").
+		WithProject(cfg.Project.ID, cfg.Project.Name).
+		WithMachineID(cfg.CLI.MachineID).
+		WithBackupSource(source).
+		WithDatabase(cfg.Database.Type, cfg.Database.MajorVersion).
+		WithChecks([]verify.CheckResult{check}).
+		Build()
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+
+	notifyTestCmd.Flags().Bool("failure", false, "Send a failed verification instead of a successful one")
+	notifyTestCmd.Flags().String("target", "", "Only notify the target with this name")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/queue"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Inspect the restore run queue",
+}
+
+var queueStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show verification runs holding or waiting for a restore slot",
+	Long: `Lists the verify runs on this host that share the configured run queue:
+those restoring now, then those waiting in the order they will start (highest
+project priority first, then oldest).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.CLI.RunQueue == nil {
+			return fmt.Errorf("run queue is not configured; add a cli.run_queue section to config.yaml")
+		}
+
+		q, err := queue.Open(cfg.CLI.RunQueue.Dir, cfg.CLI.RunQueue.MaxConcurrent)
+		if err != nil {
+			return err
+		}
+		entries, err := q.Status()
+		if err != nil {
+			return err
+		}
+
+		var running int
+		for _, e := range entries {
+			if e.Running() {
+				running++
+			}
+		}
+		fmt.Printf("%d of %d restore slots in use, %d waiting.\n", running, q.MaxConcurrent(), len(entries)-running)
+		if len(entries) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		fmt.Printf("%-8s  %-36s  %-20s  %8s  %8s  %s\n", "State", "Run", "Project", "Priority", "PID", "For")
+		fmt.Println(strings.Repeat("-", 100))
+		now := time.Now()
+		for _, e := range entries {
+			state, since := "waiting", e.EnqueuedAt
+			if e.Running() {
+				state, since = "running", *e.StartedAt
+			}
+			fmt.Printf("%-8s  %-36s  %-20s  %8d  %8d  %s\n",
+				state,
+				e.RunID,
+				e.ProjectID,
+				e.Priority,
+				e.PID,
+				now.Sub(since).Round(time.Second),
+			)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queueCmd)
+	queueCmd.AddCommand(queueStatusCmd)
+}
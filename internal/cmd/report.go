@@ -1,15 +1,27 @@
 package cmd
 
 import (
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/remote"
 	"restorable.io/restorable-cli/internal/report"
+	"restorable.io/restorable-cli/internal/schema"
+	"restorable.io/restorable-cli/internal/upload"
 )
 
 var reportCmd = &cobra.Command{
@@ -27,33 +39,22 @@ var reportListCmd = &cobra.Command{
 			return err
 		}
 
-		reports, err := report.ListReports(cfg.CLI.ReportDir)
+		output, _ := cmd.Flags().GetString("output")
+		names, _ := cmd.Flags().GetString("columns")
+		columns, err := selectListColumns(output, names)
 		if err != nil {
-			return fmt.Errorf("failed to list reports: %w", err)
+			return err
 		}
-
-		if len(reports) == 0 {
-			fmt.Println("No reports found.")
-			return nil
+		loc, err := displayLocation(cmd, cfg)
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("%-36s  %-20s  %-20s  %s\n", "ID", "Timestamp", "Project", "Status")
-		fmt.Println(strings.Repeat("-", 100))
-
-		for _, r := range reports {
-			status := "✓ Success"
-			if !r.Success {
-				status = "✗ Failed"
-			}
-			fmt.Printf("%-36s  %-20s  %-20s  %s\n",
-				r.ID,
-				r.Timestamp.Format("2006-01-02 15:04:05"),
-				r.ProjectID,
-				status,
-			)
+		reports, err := report.ListReports(cfg.CLI.ReportDir)
+		if err != nil {
+			return fmt.Errorf("failed to list reports: %w", err)
 		}
-
-		return nil
+		return printReportList(reports, output, columns, loc)
 	},
 }
 
@@ -73,6 +74,10 @@ var reportShowCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		loc, err := displayLocation(cmd, cfg)
+		if err != nil {
+			return err
+		}
 
 		showJSON, _ := cmd.Flags().GetBool("json")
 		if showJSON {
@@ -84,17 +89,95 @@ var reportShowCmd = &cobra.Command{
 			return nil
 		}
 
+		sortBy, _ := cmd.Flags().GetString("sort")
+		filter, _ := cmd.Flags().GetString("filter")
+		if showTables, _ := cmd.Flags().GetBool("tables"); showTables {
+			return printTableMetrics(rpt, sortBy, filter)
+		}
+		if showSchema, _ := cmd.Flags().GetBool("schema"); showSchema {
+			return printSchemaDetail(rpt, sortBy, filter)
+		}
+
 		// Display human-readable report
 		fmt.Printf("Report: %s\n", rpt.ID)
 		fmt.Printf("Path: %s\n", path)
-		fmt.Printf("Timestamp: %s\n", rpt.Timestamp.Format("2006-01-02 15:04:05 UTC"))
+		fmt.Printf("Timestamp: %s\n", report.FormatTime(rpt.Timestamp, loc))
 		fmt.Printf("Project: %s (%s)\n", rpt.ProjectName, rpt.ProjectID)
 		fmt.Printf("Machine: %s\n", rpt.MachineID)
 		fmt.Printf("Backup Source: %s\n", rpt.BackupSource)
+		if v := rpt.ArtifactVersion; v != nil {
+			if v.ETag != "" {
+				fmt.Printf("ETag: %s\n", v.ETag)
+			}
+			if v.LastModified != nil {
+				fmt.Printf("Last Modified: %s\n", report.FormatTime(*v.LastModified, loc))
+			}
+		}
+		if rpt.ArtifactTime != nil {
+			fmt.Printf("Backup Written: %s\n", report.FormatTime(*rpt.ArtifactTime, loc))
+		}
+		if c := rpt.ArtifactChecksum; c != nil {
+			fmt.Printf("Checksum: %s:%s (from %s)\n", c.Algorithm, c.Expected, c.Source)
+		}
+		for _, p := range rpt.ArtifactParts {
+			if c := p.Checksum; c != nil {
+				fmt.Printf("Part: %s (%s, %s:%s from %s)\n", p.Name, formatBytes(p.Size), c.Algorithm, c.Expected, c.Source)
+			} else {
+				fmt.Printf("Part: %s (%s)\n", p.Name, formatBytes(p.Size))
+			}
+		}
+		if rpt.DecryptionKey != "" {
+			fmt.Printf("Decryption Key: %s\n", rpt.DecryptionKey)
+		}
+		if rpt.Mode != "" {
+			fmt.Printf("Mode: %s\n", rpt.Mode)
+		}
+		if rpt.Profile != "" {
+			fmt.Printf("Profile: %s\n", rpt.Profile)
+		}
+		if len(rpt.Tables) > 0 {
+			fmt.Printf("Canary Tables: %s\n", strings.Join(rpt.Tables, ", "))
+		}
 		fmt.Println()
 
+		// Producer metadata
+		if p := rpt.Producer; p != nil {
+			fmt.Println("Producer:")
+			fmt.Printf("  Host: %s\n", valueOrNone(p.ProducerHost))
+			fmt.Printf("  Dump Command: %s\n", valueOrNone(p.DumpCommand))
+			fmt.Printf("  Source DB Version: %s\n", valueOrNone(p.SourceDBVersion))
+			fmt.Printf("  LSN: %s\n", valueOrNone(p.LSN))
+			if p.CreatedAt != "" {
+				fmt.Printf("  Created At: %s\n", p.CreatedAt)
+			}
+			fmt.Println()
+		}
+
+		// Point-in-time recovery
+		if rp := rpt.RecoveryPoint; rp != nil {
+			fmt.Println("Recovery Point:")
+			if rp.Binlogs {
+				fmt.Printf("  Binlogs: %d from %s\n", rp.WALSegments, rp.StartSegment)
+			} else {
+				fmt.Printf("  Base Backup: %s\n", rp.BackupName)
+				fmt.Printf("  WAL Segments: %d from %s\n", rp.WALSegments, rp.StartSegment)
+			}
+			if rp.TargetTime != nil {
+				fmt.Printf("  Target: %s\n", report.FormatTime(*rp.TargetTime, loc))
+			}
+			if rp.AchievedTime != nil {
+				fmt.Printf("  Achieved: %s\n", report.FormatTime(*rp.AchievedTime, loc))
+			} else {
+				fmt.Println("  Achieved: (none)")
+			}
+			fmt.Println()
+		}
+
 		// Database info
 		fmt.Printf("Database: %s %d\n", rpt.Database.Type, rpt.Database.MajorVersion)
+		if rpt.Database.Name != "" {
+			fmt.Printf("Database Name: %s\n", rpt.Database.Name)
+		}
 		if rpt.Database.SizeBytes > 0 {
 			fmt.Printf("Database Size: %s\n", formatBytes(rpt.Database.SizeBytes))
 		}
@@ -108,6 +191,9 @@ var reportShowCmd = &cobra.Command{
 			fmt.Println("  Status: ✗ Failed")
 		}
 		fmt.Printf("  Checks: %d/%d passed\n", rpt.Summary.PassedChecks, rpt.Summary.TotalChecks)
+		if rpt.Summary.SkippedChecks > 0 {
+			fmt.Printf("  Skipped: %d\n", rpt.Summary.SkippedChecks)
+		}
 		if rpt.Summary.CriticalFailures > 0 {
 			fmt.Printf("  Critical Failures: %d\n", rpt.Summary.CriticalFailures)
 		}
@@ -122,11 +208,10 @@ var reportShowCmd = &cobra.Command{
 		// Checks
 		fmt.Println("Checks:")
 		for _, c := range rpt.Checks {
-			status := "✓"
-			if !c.Passed {
-				status = "✗"
+			fmt.Printf("  %s [%s] %s: %s\n", c.StatusSymbol(), c.Level, c.Name, c.Message)
+			for _, d := range c.TableDiffs {
+				fmt.Printf("      %s\n", d)
 			}
-			fmt.Printf("  %s [%s] %s: %s\n", status, c.Level, c.Name, c.Message)
 		}
 		fmt.Println()
 
@@ -142,25 +227,53 @@ var reportShowCmd = &cobra.Command{
 }
 
 var reportVerifyCmd = &cobra.Command{
-	Use:   "verify <id>",
+	Use:   "verify <id|file>",
 	Short: "Verify a report's signature",
-	Args:  cobra.ExactArgs(1),
+	Long: `Verifies the Ed25519 signature of a report.
+
+The report is looked up by ID in the report directory, or read from a file path.
+By default the public key is derived from the configured signing key path. Use
+--pubkey to verify against a public key file or an https URL instead; together
+with a report file, this needs no configuration, so third parties holding only
+the public key can check reports.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		reportID := args[0]
+		reportRef := args[0]
+		pubKeyRef, _ := cmd.Flags().GetString("pubkey")
 
-		cfg, err := config.Load()
-		if err != nil {
+		var cfg *config.Config
+		loadConfig := func() error {
+			if cfg != nil {
+				return nil
+			}
+			var err error
+			cfg, err = config.Load()
 			return err
 		}
 
-		rpt, _, err := findReport(cfg.CLI.ReportDir, reportID)
-		if err != nil {
-			return err
+		var rpt *report.Report
+		if info, err := os.Stat(reportRef); err == nil && !info.IsDir() {
+			rpt, err = report.LoadReport(reportRef)
+			if err != nil {
+				return err
+			}
+		} else {
+			if err := loadConfig(); err != nil {
+				return err
+			}
+			rpt, _, err = findReport(cfg.CLI.ReportDir, reportRef)
+			if err != nil {
+				return err
+			}
 		}
 
-		// Load public key
-		pubKeyPath := strings.TrimSuffix(cfg.Signing.PrivateKeyPath, ".key") + ".pub"
-		pubKey, err := report.LoadPublicKey(pubKeyPath)
+		if pubKeyRef == "" {
+			if err := loadConfig(); err != nil {
+				return err
+			}
+			pubKeyRef = strings.TrimSuffix(cfg.Signing.PrivateKeyPath, ".key") + ".pub"
+		}
+		pubKey, err := loadVerificationKey(context.Background(), pubKeyRef)
 		if err != nil {
 			return fmt.Errorf("failed to load public key: %w", err)
 		}
@@ -181,6 +294,209 @@ var reportVerifyCmd = &cobra.Command{
 	},
 }
 
+// loadVerificationKey reads a public key from a file path or an https URL.
+func loadVerificationKey(ctx context.Context, ref string) (ed25519.PublicKey, error) {
+	if strings.HasPrefix(ref, "http://") {
+		return nil, fmt.Errorf("refusing to fetch a public key over plain http: %s", ref)
+	}
+	if !strings.HasPrefix(ref, "https://") {
+		return report.LoadPublicKey(ref)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", ref, resp.Status)
+	}
+
+	// A public key is tiny; anything larger is not one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	return report.ParsePublicKey(data)
+}
+
+var reportOpenCmd = &cobra.Command{
+	Use:   "open <id>",
+	Short: "Open a report as HTML in the default browser",
+	Long: `Render a report as a self-contained HTML page in the temp directory and
+open it in the default browser. With --no-browser, or when no browser can be
+launched, the path of the HTML file is printed instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		rpt, _, err := findReport(cfg.CLI.ReportDir, args[0])
+		if err != nil {
+			return err
+		}
+
+		loc, err := displayLocation(cmd, cfg)
+		if err != nil {
+			return err
+		}
+		html, err := report.RenderHTML(rpt, loc)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.CreateTemp(cfg.CLI.TempDir, "restorable-report-"+rpt.ID+"-*.html")
+		if err != nil {
+			return fmt.Errorf("failed to create HTML file: %w", err)
+		}
+		if _, err := file.Write(html); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write HTML file: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to write HTML file: %w", err)
+		}
+
+		if noBrowser, _ := cmd.Flags().GetBool("no-browser"); noBrowser {
+			fmt.Println(file.Name())
+			return nil
+		}
+		if err := openBrowser(file.Name()); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Could not open browser: %v\n", err)
+			fmt.Println(file.Name())
+			return nil
+		}
+		fmt.Printf("✓ Opened %s\n", file.Name())
+		return nil
+	},
+}
+
+// displayLocation returns the timezone report times are shown in: --timezone,
+// then cli.timezone, then UTC.
+func displayLocation(cmd *cobra.Command, cfg *config.Config) (*time.Location, error) {
+	cli := cfg.CLI
+	if tz, _ := cmd.Flags().GetString("timezone"); tz != "" {
+		cli.Timezone = tz
+	}
+	return cli.Location()
+}
+
+// openBrowser opens path with the platform's default handler.
+func openBrowser(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
+
+var reportSummaryCmd = &cobra.Command{
+	Use:   "summary <id>",
+	Short: "Print a plain-language summary of a report",
+	Long: `Prints a one-paragraph, plain-language summary of a verification report,
+suitable for pasting into status reports.
+
+The exec audience omits hostnames, paths and table names. The ops audience
+adds the backup source, database and failing check names.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		rpt, _, err := findReport(cfg.CLI.ReportDir, args[0])
+		if err != nil {
+			return err
+		}
+
+		audience, _ := cmd.Flags().GetString("audience")
+		lang, _ := cmd.Flags().GetString("lang")
+		loc, err := displayLocation(cmd, cfg)
+		if err != nil {
+			return err
+		}
+		text, err := report.RenderSummary(rpt, audience, lang, loc)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(text)
+		return nil
+	},
+}
+
+var reportPushCmd = &cobra.Command{
+	Use:   "push [id]",
+	Short: "Submit reports to restorable.io",
+	Long: `Submits a signed report to the configured upload endpoint.
+
+With --pending, submits the reports queued by earlier runs while the endpoint
+was unreachable. Queued reports are removed once they are accepted.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		pending, _ := cmd.Flags().GetBool("pending")
+		if pending == (len(args) == 1) {
+			return fmt.Errorf("specify either a report ID or --pending")
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Upload == nil {
+			return fmt.Errorf("upload is not configured; add an upload section to config.yaml")
+		}
+
+		client, err := upload.NewClient(cfg.Upload)
+		if err != nil {
+			return err
+		}
+
+		if pending {
+			queue, err := upload.NewQueue()
+			if err != nil {
+				return err
+			}
+			sent, err := queue.Flush(ctx, client)
+			if sent > 0 {
+				fmt.Printf("✓ Submitted %d queued report(s).\n", sent)
+			}
+			if err != nil {
+				return err
+			}
+			if sent == 0 {
+				fmt.Println("No pending reports.")
+			}
+			return nil
+		}
+
+		rpt, path, err := findReport(cfg.CLI.ReportDir, args[0])
+		if err != nil {
+			return err
+		}
+		if err := client.SubmitFile(ctx, path); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Report %s submitted.\n", rpt.ID)
+		return nil
+	},
+}
+
 func findReport(dir string, id string) (*report.Report, string, error) {
 	reports, err := report.ListReports(dir)
 	if err != nil {
@@ -222,6 +538,124 @@ func findReport(dir string, id string) (*report.Report, string, error) {
 	return rpt, matches[0].Path, err
 }
 
+// tableRow joins schema and metrics data for a single table.
+type tableRow struct {
+	name    string
+	rows    int64
+	size    int64
+	columns []schema.Column
+}
+
+// reportTables collects the tables of a report matching filter, sorted by sortBy.
+// The filter is a glob (e.g. "public.order*") or a plain substring of "schema.table".
+func reportTables(rpt *report.Report, sortBy, filter string) ([]tableRow, error) {
+	byName := make(map[string]*tableRow)
+	var names []string
+	add := func(name string) *tableRow {
+		if row, ok := byName[name]; ok {
+			return row
+		}
+		row := &tableRow{name: name}
+		byName[name] = row
+		names = append(names, name)
+		return row
+	}
+
+	if rpt.Schema != nil {
+		for _, t := range rpt.Schema.Tables {
+			add(fmt.Sprintf("%s.%s", t.Schema, t.Name)).columns = t.Columns
+		}
+	}
+	if rpt.Metrics != nil {
+		for _, tm := range rpt.Metrics.TableMetrics {
+			row := add(fmt.Sprintf("%s.%s", tm.Schema, tm.Name))
+			row.rows = tm.RowCount
+			row.size = tm.SizeBytes
+		}
+	}
+
+	var rows []tableRow
+	for _, name := range names {
+		if filter != "" {
+			matched, _ := path.Match(filter, name)
+			if !matched && !strings.Contains(name, filter) {
+				continue
+			}
+		}
+		rows = append(rows, *byName[name])
+	}
+
+	var less func(a, b tableRow) bool
+	switch sortBy {
+	case "", "name":
+		less = func(a, b tableRow) bool { return a.name < b.name }
+	case "rows":
+		less = func(a, b tableRow) bool { return a.rows > b.rows }
+	case "size":
+		less = func(a, b tableRow) bool { return a.size > b.size }
+	case "columns":
+		less = func(a, b tableRow) bool { return len(a.columns) > len(b.columns) }
+	default:
+		return nil, fmt.Errorf("unsupported sort key %q (use name, rows, size or columns)", sortBy)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+
+	return rows, nil
+}
+
+func printTableMetrics(rpt *report.Report, sortBy, filter string) error {
+	rows, err := reportTables(rpt, sortBy, filter)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("No tables found.")
+		return nil
+	}
+
+	fmt.Printf("%-48s  %14s  %12s  %7s\n", "Table", "Rows", "Size", "Columns")
+	fmt.Println(strings.Repeat("-", 87))
+
+	var totalRows, totalSize int64
+	for _, r := range rows {
+		fmt.Printf("%-48s  %14d  %12s  %7d\n", r.name, r.rows, formatBytes(r.size), len(r.columns))
+		totalRows += r.rows
+		totalSize += r.size
+	}
+
+	fmt.Println(strings.Repeat("-", 87))
+	fmt.Printf("%-48s  %14d  %12s\n", fmt.Sprintf("%d tables", len(rows)), totalRows, formatBytes(totalSize))
+	return nil
+}
+
+func printSchemaDetail(rpt *report.Report, sortBy, filter string) error {
+	if rpt.Schema == nil {
+		return fmt.Errorf("report %s contains no schema", rpt.ID)
+	}
+
+	rows, err := reportTables(rpt, sortBy, filter)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		fmt.Println("No tables found.")
+		return nil
+	}
+
+	for _, r := range rows {
+		fmt.Printf("%s (%d columns)\n", r.name, len(r.columns))
+		for _, c := range r.columns {
+			nullable := "NOT NULL"
+			if c.Nullable {
+				nullable = "NULL"
+			}
+			fmt.Printf("  %-32s  %-28s  %s\n", c.Name, c.DataType, nullable)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
 func formatBytes(bytes int64) string {
 	const (
 		KB = 1024
@@ -241,11 +675,159 @@ func formatBytes(bytes int64) string {
 	}
 }
 
+var reportPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete reports past cli.report_retention",
+	Long: `Deletes reports older than cli.report_retention.days from the report directory.
+
+With require_archived, each report is first confirmed in the sync bucket and
+accepted by the upload endpoint, uploading it where it is missing; a report that
+cannot be archived is kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if days, _ := cmd.Flags().GetInt("days"); days > 0 {
+			retention := config.ReportRetention{}
+			if cfg.CLI.ReportRetention != nil {
+				retention = *cfg.CLI.ReportRetention
+			}
+			retention.Days = days
+			cfg.CLI.ReportRetention = &retention
+		}
+		if cfg.CLI.ReportRetention == nil || cfg.CLI.ReportRetention.Days <= 0 {
+			return fmt.Errorf("report retention is not configured; set cli.report_retention.days or pass --days")
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return pruneReports(context.Background(), cfg, dryRun)
+	},
+}
+
+// pruneReports deletes reports past the retention period. Reports that cannot be
+// confirmed archived are kept and reported, and do not stop the remaining ones.
+func pruneReports(ctx context.Context, cfg *config.Config, dryRun bool) error {
+	retention := cfg.CLI.ReportRetention
+	cutoff := time.Now().AddDate(0, 0, -retention.Days)
+	expired, err := report.ExpiredReports(cfg.CLI.ReportDir, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to list reports: %w", err)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	var archive func(path string) error
+	if retention.RequireArchived {
+		archive, err = reportArchiver(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	var pruned, kept int
+	for _, r := range expired {
+		if archive != nil && !dryRun {
+			if err := archive(r.Path); err != nil {
+				fmt.Printf("⚠ Keeping report %s: %v\n", r.ID, err)
+				kept++
+				continue
+			}
+		}
+		if !dryRun {
+			if err := os.Remove(r.Path); err != nil {
+				fmt.Printf("⚠ Failed to delete report %s: %v\n", r.ID, err)
+				kept++
+				continue
+			}
+		}
+		pruned++
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	fmt.Printf("✓ %s %d report(s) older than %d days.\n", verb, pruned, retention.Days)
+	if kept > 0 {
+		return fmt.Errorf("%d expired report(s) kept because they could not be archived or deleted", kept)
+	}
+	return nil
+}
+
+// reportArchiver returns a function that makes sure a report is held by every
+// configured archive, the sync bucket and the upload endpoint, before it is deleted.
+func reportArchiver(ctx context.Context, cfg *config.Config) (func(path string) error, error) {
+	var syncer *remote.Syncer
+	if cfg.Sync != nil && cfg.Sync.S3 != nil {
+		var err error
+		syncer, err = remote.NewSyncer(cfg.Sync.S3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sync client: %w", err)
+		}
+	}
+	var client *upload.Client
+	if cfg.Upload != nil {
+		var err error
+		client, err = upload.NewClient(cfg.Upload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload client: %w", err)
+		}
+	}
+	if syncer == nil && client == nil {
+		return nil, fmt.Errorf("cli.report_retention.require_archived needs sync.s3 or upload to be configured")
+	}
+
+	return func(path string) error {
+		if syncer != nil {
+			pushed, err := syncer.Mirror(ctx, path, "reports")
+			if err != nil {
+				return err
+			}
+			if pushed {
+				fmt.Printf("  ↑ reports/%s\n", filepath.Base(path))
+			}
+		}
+		if client != nil {
+			// The endpoint accepts reports it already holds, so resubmitting confirms it
+			if err := client.SubmitFile(ctx, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
 func init() {
 	rootCmd.AddCommand(reportCmd)
 	reportCmd.AddCommand(reportListCmd)
 	reportCmd.AddCommand(reportShowCmd)
 	reportCmd.AddCommand(reportVerifyCmd)
+	reportCmd.AddCommand(reportSummaryCmd)
+	reportCmd.AddCommand(reportOpenCmd)
+	reportCmd.AddCommand(reportPushCmd)
+	reportCmd.AddCommand(reportPruneCmd)
+
+	reportCmd.PersistentFlags().String("timezone", "", "Show times in this IANA timezone, e.g. Europe/Berlin (overrides cli.timezone)")
+
+	reportListCmd.Flags().StringP("output", "o", listOutputTable, "Output format: table, wide, json or csv")
+	reportListCmd.Flags().String("columns", "", "Comma-separated columns to show: id, timestamp, project, status, mode, database, duration, db_size, digest, critical, warnings, path")
 
 	reportShowCmd.Flags().Bool("json", false, "Output report as JSON")
+	reportShowCmd.Flags().Bool("tables", false, "Show per-table row counts and sizes")
+	reportShowCmd.Flags().Bool("schema", false, "Show per-table column definitions")
+	reportShowCmd.Flags().String("sort", "name", "Sort tables by: name, rows, size or columns")
+	reportShowCmd.Flags().String("filter", "", "Only show tables matching a glob or substring of schema.table")
+
+	reportSummaryCmd.Flags().String("audience", report.AudienceExec, "Summary audience: exec or ops")
+	reportSummaryCmd.Flags().String("lang", "en", "Summary language: en or de")
+
+	reportOpenCmd.Flags().Bool("no-browser", false, "Print the path of the HTML file instead of opening it")
+
+	reportVerifyCmd.Flags().String("pubkey", "", "Public key file or https URL to verify against")
+
+	reportPushCmd.Flags().Bool("pending", false, "Submit reports queued while the endpoint was unreachable")
+
+	reportPruneCmd.Flags().Int("days", 0, "Override cli.report_retention.days")
+	reportPruneCmd.Flags().Bool("dry-run", false, "Show how many reports would be deleted without deleting them")
 }
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"restorable.io/restorable-cli/internal/report"
+)
+
+// Output formats of report list.
+const (
+	listOutputTable = "table"
+	listOutputWide  = "wide"
+	listOutputJSON  = "json"
+	listOutputCSV   = "csv"
+)
+
+// listColumn is a column of report list. display formats the value for people in
+// table output; value is the raw value written to JSON and CSV.
+type listColumn struct {
+	name    string
+	title   string
+	width   int
+	display func(r *report.ReportSummary, loc *time.Location) string
+	value   func(r *report.ReportSummary) any
+}
+
+var listColumns = []listColumn{
+	{"id", "ID", 36,
+		func(r *report.ReportSummary, _ *time.Location) string { return r.ID },
+		func(r *report.ReportSummary) any { return r.ID }},
+	{"timestamp", "Timestamp", 24,
+		func(r *report.ReportSummary, loc *time.Location) string { return report.FormatTime(r.Timestamp, loc) },
+		func(r *report.ReportSummary) any { return r.Timestamp.UTC() }},
+	{"project", "Project", 20,
+		func(r *report.ReportSummary, _ *time.Location) string { return r.ProjectID },
+		func(r *report.ReportSummary) any { return r.ProjectID }},
+	{"status", "Status", 10,
+		func(r *report.ReportSummary, _ *time.Location) string {
+			if r.Success {
+				return "✓ Success"
+			}
+			return "✗ Failed"
+		},
+		func(r *report.ReportSummary) any { return reportStatus(r) }},
+	{"mode", "Mode", 11,
+		func(r *report.ReportSummary, _ *time.Location) string { return valueOrNone(r.Mode) },
+		func(r *report.ReportSummary) any { return r.Mode }},
+	{"database", "Database", 16,
+		func(r *report.ReportSummary, _ *time.Location) string {
+			return fmt.Sprintf("%s %d", r.DatabaseType, r.DatabaseVersion)
+		},
+		func(r *report.ReportSummary) any { return fmt.Sprintf("%s %d", r.DatabaseType, r.DatabaseVersion) }},
+	{"duration", "Restore", 9,
+		func(r *report.ReportSummary, _ *time.Location) string {
+			return r.RestoreDuration.Round(time.Second).String()
+		},
+		func(r *report.ReportSummary) any { return r.RestoreDuration.Seconds() }},
+	{"db_size", "DB Size", 10,
+		func(r *report.ReportSummary, _ *time.Location) string { return formatBytes(r.DBSizeBytes) },
+		func(r *report.ReportSummary) any { return r.DBSizeBytes }},
+	{"digest", "Digest", 12,
+		func(r *report.ReportSummary, _ *time.Location) string { return shortDigest(r.ArtifactDigest) },
+		func(r *report.ReportSummary) any { return r.ArtifactDigest }},
+	{"critical", "Critical", 8,
+		func(r *report.ReportSummary, _ *time.Location) string { return fmt.Sprint(r.CriticalFailures) },
+		func(r *report.ReportSummary) any { return r.CriticalFailures }},
+	{"warnings", "Warnings", 8,
+		func(r *report.ReportSummary, _ *time.Location) string { return fmt.Sprint(r.WarningFailures) },
+		func(r *report.ReportSummary) any { return r.WarningFailures }},
+	{"path", "Path", 0,
+		func(r *report.ReportSummary, _ *time.Location) string { return r.Path },
+		func(r *report.ReportSummary) any { return r.Path }},
+}
+
+// Default columns by output format. JSON and CSV are for scripts, so they get the
+// wide set plus the report path.
+var (
+	defaultListColumns = []string{"id", "timestamp", "project", "status"}
+	wideListColumns    = []string{"id", "timestamp", "project", "status", "mode", "database", "duration", "db_size", "digest"}
+	scriptListColumns  = []string{"id", "timestamp", "project", "status", "mode", "database", "duration", "db_size", "digest", "critical", "warnings", "path"}
+)
+
+// reportStatus returns the status of a report for scripts.
+func reportStatus(r *report.ReportSummary) string {
+	if r.Success {
+		return "success"
+	}
+	return "failed"
+}
+
+// shortDigest abbreviates a sha256 digest for display.
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return valueOrNone(digest)
+}
+
+// selectListColumns resolves the comma-separated column names, or the default
+// columns of the output format when names is empty.
+func selectListColumns(output, names string) ([]listColumn, error) {
+	var selected []string
+	switch {
+	case names != "":
+		selected = strings.Split(names, ",")
+	case output == listOutputWide:
+		selected = wideListColumns
+	case output == listOutputJSON || output == listOutputCSV:
+		selected = scriptListColumns
+	default:
+		selected = defaultListColumns
+	}
+
+	columns := make([]listColumn, 0, len(selected))
+	for _, name := range selected {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range listColumns {
+			if c.name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var available []string
+			for _, c := range listColumns {
+				available = append(available, c.name)
+			}
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// printReportList writes reports in the output format.
+func printReportList(reports []*report.ReportSummary, output string, columns []listColumn, loc *time.Location) error {
+	switch output {
+	case listOutputJSON:
+		rows := make([]map[string]any, 0, len(reports))
+		for _, r := range reports {
+			row := make(map[string]any, len(columns))
+			for _, c := range columns {
+				row[c.name] = c.value(r)
+			}
+			rows = append(rows, row)
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case listOutputCSV:
+		w := csv.NewWriter(os.Stdout)
+		header := make([]string, len(columns))
+		for i, c := range columns {
+			header[i] = c.name
+		}
+		w.Write(header)
+		for _, r := range reports {
+			record := make([]string, len(columns))
+			for i, c := range columns {
+				switch v := c.value(r).(type) {
+				case time.Time:
+					record[i] = v.Format(time.RFC3339)
+				default:
+					record[i] = fmt.Sprint(v)
+				}
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+
+	case listOutputTable, listOutputWide:
+		if len(reports) == 0 {
+			fmt.Println("No reports found.")
+			return nil
+		}
+		titles := make([]string, len(columns))
+		for i, c := range columns {
+			titles[i] = c.title
+		}
+		header := formatListRow(columns, titles)
+		fmt.Println(header)
+		fmt.Println(strings.Repeat("-", max(100, len(header))))
+		for _, r := range reports {
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = c.display(r, loc)
+			}
+			fmt.Println(formatListRow(columns, cells))
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported output %q (use %s, %s, %s or %s)", output, listOutputTable, listOutputWide, listOutputJSON, listOutputCSV)
+	}
+}
+
+// formatListRow pads each cell to its column width, leaving the last one unpadded.
+func formatListRow(columns []listColumn, cells []string) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		if i < len(cells)-1 {
+			fmt.Fprintf(&b, "%-*s", columns[i].width, cell)
+		} else {
+			b.WriteString(cell)
+		}
+	}
+	return b.String()
+}
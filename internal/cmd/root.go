@@ -1,9 +1,17 @@
 package cmd
 
 import (
+    "errors"
+
     "github.com/spf13/cobra"
 )
 
+// Exit codes returned by ExitCode.
+const (
+    exitCritical = 2 // Verification found critical failures
+    exitCLI      = 3 // CLI/config error
+)
+
 var rootCmd = &cobra.Command{
     Use:   "restorable",
     Short: "Restore verification for database backups",
@@ -15,3 +23,23 @@ func Execute() error {
     return rootCmd.Execute()
 }
 
+// exitError attaches a process exit code to an error.
+type exitError struct {
+    code int
+    err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+    if err == nil {
+        return 0
+    }
+    var exitErr *exitError
+    if errors.As(err, &exitErr) {
+        return exitErr.code
+    }
+    return exitCLI
+}
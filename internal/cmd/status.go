@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/report"
+)
+
+// Statuses of a project that has no usable latest report.
+const (
+	statusStale = "stale" // The latest report is older than --max-age
+	statusNever = "never" // No report was found
+)
+
+// configFileNames are the project configuration files found by status --all, in
+// addition to the config.yaml of a .restorable directory.
+var configFileNames = map[string]bool{
+	"restorable.yaml": true,
+	"restorable.yml":  true,
+}
+
+// skippedDirs are never searched for configuration files.
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+var (
+	statusAll    bool
+	statusDir    string
+	statusOutput string
+	statusMaxAge time.Duration
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the latest verification result of each project",
+	Long: `Reads the latest report of each project and prints whether its backups were
+last verified successfully.
+
+With --all, every project configuration under --dir (default: the current
+directory) is discovered, for a portfolio of services kept in one repository:
+restorable.yaml, restorable.yml and .restorable/config.yaml files. Relative
+report directories are resolved against the directory of their configuration.
+
+A project is stale when its latest report is older than --max-age. The command
+exits with code 2 when any project failed, is stale or was never verified, so it
+can gate a monitoring job.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var paths []string
+		if statusAll {
+			found, err := discoverConfigs(statusDir)
+			if err != nil {
+				return err
+			}
+			if len(found) == 0 {
+				return fmt.Errorf("no project configurations found under %s", statusDir)
+			}
+			paths = found
+		} else {
+			path, err := config.DefaultPath()
+			if err != nil {
+				return err
+			}
+			paths = []string{path}
+		}
+
+		now := time.Now().UTC()
+		var statuses []projectStatus
+		for _, path := range paths {
+			statuses = append(statuses, configStatuses(path, now, statusMaxAge)...)
+		}
+
+		loc := time.UTC
+		if !statusAll {
+			if cfg, err := config.Load(); err == nil {
+				if l, err := cfg.CLI.Location(); err == nil {
+					loc = l
+				}
+			}
+		}
+		if err := printStatuses(statuses, statusOutput, now, loc); err != nil {
+			return err
+		}
+
+		var unhealthy int
+		for _, s := range statuses {
+			if s.Status != statusPassed && s.Status != statusWarning {
+				unhealthy++
+			}
+		}
+		if unhealthy > 0 {
+			cmd.SilenceUsage = true
+			return &exitError{code: exitCritical, err: fmt.Errorf("%d of %d project(s) are not healthy", unhealthy, len(statuses))}
+		}
+		return nil
+	},
+}
+
+// projectStatus is the latest verification result of one project.
+type projectStatus struct {
+	ProjectID string     `json:"project_id"`
+	Name      string     `json:"name"`
+	Config    string     `json:"config"`
+	Status    string     `json:"status"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	ReportID  string     `json:"report_id,omitempty"`
+	Critical  int        `json:"critical"`
+	Warnings  int        `json:"warnings"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// discoverConfigs returns the project configuration files under root, sorted.
+func discoverConfigs(root string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than ending the search
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if configFileNames[d.Name()] || d.Name() == "config.yaml" && filepath.Base(filepath.Dir(path)) == ".restorable" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// configStatuses returns the status of each project verified with the
+// configuration at path: one per logical database when they are configured.
+func configStatuses(path string, now time.Time, maxAge time.Duration) []projectStatus {
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return []projectStatus{{Config: path, Status: statusError, Error: err.Error()}}
+	}
+	if cfg.Project.ID == "" {
+		return []projectStatus{{Config: path, Status: statusError, Error: "project.id is not set"}}
+	}
+
+	reportDir := cfg.CLI.ReportDir
+	if reportDir != "" && !filepath.IsAbs(reportDir) {
+		reportDir = filepath.Join(filepath.Dir(path), reportDir)
+	}
+	reports, err := report.ListReports(reportDir)
+	if err != nil {
+		return []projectStatus{{ProjectID: cfg.Project.ID, Name: cfg.Project.Name, Config: path, Status: statusError, Error: err.Error()}}
+	}
+
+	// ListReports is newest first, so the first report of a project is its latest
+	latest := make(map[string]*report.ReportSummary)
+	var order []string
+	for _, r := range reports {
+		if _, ok := latest[r.ProjectID]; !ok {
+			latest[r.ProjectID] = r
+			order = append(order, r.ProjectID)
+		}
+	}
+
+	targets := verificationTargets(cfg, nil)
+	// Cluster-wide dumps without logical_databases report each database as <id>-<name>
+	if len(cfg.Database.LogicalDatabases) == 0 && latest[cfg.Project.ID] == nil {
+		var restored []string
+		for _, id := range order {
+			if name, ok := strings.CutPrefix(id, cfg.Project.ID+"-"); ok {
+				restored = append(restored, name)
+			}
+		}
+		if len(restored) > 0 {
+			sort.Strings(restored)
+			targets = verificationTargets(cfg, restored)
+		}
+	}
+
+	statuses := make([]projectStatus, 0, len(targets))
+	for _, t := range targets {
+		s := projectStatus{ProjectID: t.projectID, Name: t.projectName, Config: path, Status: statusNever}
+		if r := latest[t.projectID]; r != nil {
+			lastRun := r.Timestamp.UTC()
+			s.LastRun = &lastRun
+			s.ReportID = r.ID
+			s.Critical = r.CriticalFailures
+			s.Warnings = r.WarningFailures
+			switch {
+			case !r.Success:
+				s.Status = statusFailed
+			case maxAge > 0 && now.Sub(lastRun) > maxAge:
+				s.Status = statusStale
+			case r.WarningFailures > 0:
+				s.Status = statusWarning
+			default:
+				s.Status = statusPassed
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// statusLabel formats a status for people.
+func statusLabel(status string) string {
+	switch status {
+	case statusPassed:
+		return "✓ Passed"
+	case statusWarning:
+		return "⚠ Warning"
+	case statusStale:
+		return "⚠ Stale"
+	case statusFailed:
+		return "✗ Failed"
+	case statusNever:
+		return "✗ Never"
+	default:
+		return "✗ Error"
+	}
+}
+
+// printStatuses writes the statuses as a table, JSON or CSV.
+func printStatuses(statuses []projectStatus, output string, now time.Time, loc *time.Location) error {
+	switch output {
+	case listOutputJSON:
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case listOutputCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"project_id", "name", "config", "status", "last_run", "report_id", "critical", "warnings", "error"})
+		for _, s := range statuses {
+			lastRun := ""
+			if s.LastRun != nil {
+				lastRun = s.LastRun.Format(time.RFC3339)
+			}
+			w.Write([]string{s.ProjectID, s.Name, s.Config, s.Status, lastRun, s.ReportID, fmt.Sprint(s.Critical), fmt.Sprint(s.Warnings), s.Error})
+		}
+		w.Flush()
+		return w.Error()
+
+	case listOutputTable:
+		fmt.Printf("%-24s  %-10s  %-24s  %10s  %8s  %8s  %s\n", "Project", "Status", "Last Run", "Age", "Critical", "Warnings", "Config")
+		fmt.Println(strings.Repeat("-", 120))
+		counts := make(map[string]int)
+		for _, s := range statuses {
+			counts[s.Status]++
+			lastRun, age := "(none)", "-"
+			if s.LastRun != nil {
+				lastRun = report.FormatTime(*s.LastRun, loc)
+				age = now.Sub(*s.LastRun).Round(time.Minute).String()
+			}
+			project := s.ProjectID
+			if project == "" {
+				project = "-"
+			}
+			fmt.Printf("%-24s  %-10s  %-24s  %10s  %8d  %8d  %s\n", project, statusLabel(s.Status), lastRun, age, s.Critical, s.Warnings, s.Config)
+			if s.Error != "" {
+				fmt.Printf("  %s\n", s.Error)
+			}
+		}
+
+		fmt.Printf("\n%d project(s):", len(statuses))
+		var parts []string
+		for _, status := range []string{statusPassed, statusWarning, statusStale, statusFailed, statusNever, statusError} {
+			if counts[status] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+			}
+		}
+		fmt.Printf(" %s\n", strings.Join(parts, ", "))
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported output %q (use %s, %s or %s)", output, listOutputTable, listOutputJSON, listOutputCSV)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "Discover every project configuration under --dir")
+	statusCmd.Flags().StringVar(&statusDir, "dir", ".", "Directory searched by --all")
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", listOutputTable, "Output format: table, json or csv")
+	statusCmd.Flags().DurationVar(&statusMaxAge, "max-age", 0, "Mark projects whose latest report is older than this as stale, e.g. 48h")
+}
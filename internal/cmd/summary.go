@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Run statuses recorded in the summary file.
+const (
+	statusPassed  = "passed"  // Every check passed
+	statusWarning = "warning" // Only warning-level checks failed
+	statusFailed  = "failed"  // Critical checks failed
+	statusError   = "error"   // The run ended before every target had a report
+)
+
+// runSummary is the small machine-readable result written by verify --summary-file,
+// so wrapper scripts don't need to parse stdout.
+type runSummary struct {
+	Status      string    `json:"status"`
+	ExitCode    int       `json:"exit_code"`
+	RunID       string    `json:"run_id"`
+	ReportPaths []string  `json:"report_paths"`
+	Cached      bool      `json:"cached,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	// DurationSeconds is the wall time of the whole run.
+	DurationSeconds float64 `json:"duration_seconds"`
+	// StageSeconds is the time spent in each stage the run reached.
+	StageSeconds map[string]float64 `json:"stage_seconds"`
+}
+
+// newRunSummary summarizes a run that ended with runErr.
+func newRunSummary(f *runFailure, reportPaths []string, warnings int, cached bool, runErr error) *runSummary {
+	finished := time.Now().UTC()
+	s := &runSummary{
+		RunID:           f.runID,
+		ReportPaths:     reportPaths,
+		Cached:          cached,
+		StartedAt:       f.started,
+		FinishedAt:      finished,
+		DurationSeconds: finished.Sub(f.started).Seconds(),
+		StageSeconds:    make(map[string]float64),
+		ExitCode:        ExitCode(runErr),
+	}
+	if f.reportPath != "" {
+		s.ReportPaths = append(s.ReportPaths, f.reportPath)
+	}
+	if s.ReportPaths == nil {
+		s.ReportPaths = []string{}
+	}
+	for stage, d := range f.stageDurations(finished) {
+		s.StageSeconds[stage] = d.Seconds()
+	}
+
+	switch {
+	case runErr == nil && warnings > 0:
+		s.Status = statusWarning
+	case runErr == nil:
+		s.Status = statusPassed
+	case s.ExitCode == exitCritical:
+		s.Status = statusFailed
+	default:
+		s.Status = statusError
+	}
+	if runErr != nil {
+		s.Error = runErr.Error()
+	}
+	return s
+}
+
+// write writes the summary as JSON to path.
+func (s *runSummary) write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	return nil
+}
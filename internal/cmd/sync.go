@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/remote"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync reports and baselines with object storage",
+	Long: `Pushes local reports and baselines to the configured bucket and pulls remote
+ones that are missing or newer locally.
+
+Files present on both sides are resolved by modification time, so a replacement
+verification host can be rebuilt from object storage alone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Sync == nil || cfg.Sync.S3 == nil {
+			return fmt.Errorf("sync is not configured; add a sync.s3 section to config.yaml")
+		}
+
+		syncer, err := remote.NewSyncer(cfg.Sync.S3)
+		if err != nil {
+			return fmt.Errorf("failed to create sync client: %w", err)
+		}
+
+		baselineStore, err := schema.NewBaselineStore()
+		if err != nil {
+			return fmt.Errorf("failed to create baseline store: %w", err)
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		dirs := []struct {
+			name string
+			path string
+		}{
+			{"reports", cfg.CLI.ReportDir},
+			{"schemas", baselineStore.Dir()},
+		}
+
+		var pushed, pulled int
+		for _, d := range dirs {
+			actions, err := syncer.SyncDir(ctx, d.path, d.name, dryRun)
+			for _, a := range actions {
+				arrow := "↑"
+				if a.Direction == "pull" {
+					arrow = "↓"
+					pulled++
+				} else {
+					pushed++
+				}
+				fmt.Printf("  %s %s\n", arrow, a.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to sync %s: %w", d.name, err)
+			}
+		}
+
+		verb := "Synced"
+		if dryRun {
+			verb = "Would sync"
+		}
+		fmt.Printf("✓ %s: %d pushed, %d pulled.\n", verb, pushed, pulled)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().Bool("dry-run", false, "Show what would be transferred without changing anything")
+}
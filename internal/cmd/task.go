@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// taskOutputFD is the file descriptor task mode writes its result to by default,
+// keeping it apart from the human-readable output on stdout.
+const taskOutputFD = 3
+
+// taskExitFailed is the single failure exit code of task mode. Orchestrators treat
+// any non-zero exit as a failed task, so critical failures and errors share it and
+// the status in the task output tells them apart.
+const taskExitFailed = 1
+
+// externalRunIDEnvs are checked in order for a run ID assigned by an orchestrator.
+var externalRunIDEnvs = []string{runIDEnv, "AIRFLOW_CTX_DAG_RUN_ID"}
+
+// externalRunID returns the run ID provided by the environment, if any.
+func externalRunID() string {
+	for _, env := range externalRunIDEnvs {
+		if id := os.Getenv(env); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// taskError maps the result of a run to the exit code of task mode.
+func taskError(s *runSummary, runErr error) error {
+	switch s.Status {
+	case statusPassed, statusWarning:
+		return nil
+	default:
+		return &exitError{code: taskExitFailed, err: runErr}
+	}
+}
+
+// writeTaskOutput writes the summary as a single JSON line to path, or to file
+// descriptor 3 when path is empty, for orchestrators to pick up as the task result
+// (e.g. an Airflow XCom).
+func writeTaskOutput(s *runSummary, path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task output: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path != "" {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write task output: %w", err)
+		}
+		return nil
+	}
+
+	f := os.NewFile(taskOutputFD, "task-output")
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write task output to fd %d (use --task-output to write a file): %w", taskOutputFD, err)
+	}
+	return nil
+}
@@ -2,21 +2,52 @@ package cmd
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"restorable.io/restorable-cli/internal/backup"
+	"restorable.io/restorable-cli/internal/cache"
 	"restorable.io/restorable-cli/internal/config"
 	"restorable.io/restorable-cli/internal/crypto"
+	"restorable.io/restorable-cli/internal/notify"
+	"restorable.io/restorable-cli/internal/queue"
 	"restorable.io/restorable-cli/internal/report"
 	"restorable.io/restorable-cli/internal/restore"
 	"restorable.io/restorable-cli/internal/schema"
+	"restorable.io/restorable-cli/internal/upload"
 	"restorable.io/restorable-cli/internal/verify"
 )
 
-var verbose bool
+// runIDEnv exposes the run ID to child processes such as backup commands.
+const runIDEnv = "RESTORABLE_RUN_ID"
+
+// Verbosity levels of verify, one per -v.
+const (
+	verbosityOutput  = 1 // restore tool output
+	verbosityTimings = 2 // durations of extraction steps and checks
+	verbositySQL     = 3 // every SQL statement with its duration
+)
+
+var (
+	verbosity     int
+	verifyMode    string
+	forceVerify   bool
+	verifyProfile string
+	progressMode  string
+	summaryFile   string
+	taskMode      bool
+	taskOutput    string
+	verifyTables  []string
+	objectKey     string
+	executionFlag string
+)
 
 var verifyCmd = &cobra.Command{
 	Use:   "verify",
@@ -30,54 +61,303 @@ This command performs the following steps:
 4. Extracts schema and metrics from the restored database.
 5. Performs integrity checks against the restored database.
 6. Generates and signs a verification report.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		ctx := context.Background()
-		fmt.Println("Running verification...")
+
+		mode, err := restore.ParseMode(verifyMode)
+		if err != nil {
+			return err
+		}
+		progress, err := progressReporter(progressMode)
+		if err != nil {
+			return err
+		}
+		// The run ID identifies this run in container names, labels, reports and
+		// uploads. Exported so backup commands can log or tag with it too. Task mode
+		// uses the run ID of the orchestrator when it provides one.
+		runID := uuid.New().String()
+		if taskMode {
+			if id := externalRunID(); id != "" {
+				runID = id
+			}
+			cmd.SilenceUsage = true
+		}
+		os.Setenv(runIDEnv, runID)
+
+		failure := &runFailure{runID: runID, mode: mode, profile: verifyProfile, started: time.Now().UTC()}
+		failure.enter("configure")
+		var reportPaths []string
+		var warnings int
+		var cached bool
+		if summaryFile != "" || taskMode {
+			defer func() {
+				summary := newRunSummary(failure, reportPaths, warnings, cached, err)
+				if taskMode {
+					err = taskError(summary, err)
+					summary.ExitCode = ExitCode(err)
+					if werr := writeTaskOutput(summary, taskOutput); werr != nil {
+						fmt.Printf("⚠ %v\n", werr)
+					}
+				}
+				if summaryFile != "" {
+					if werr := summary.write(summaryFile); werr != nil {
+						fmt.Printf("⚠ %v\n", werr)
+					}
+				}
+			}()
+		}
 
 		// 1. Load configuration
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load configuration: %w", err)
 		}
+		// Checked up front, since notifications depend on it
+		if _, err := cfg.CLI.Location(); err != nil {
+			return err
+		}
 		fmt.Println("✓ Configuration loaded.")
 
+		if verifyProfile != "" {
+			profile, err := cfg.ApplyProfile(verifyProfile)
+			if err != nil {
+				return err
+			}
+			if profile.Mode != "" && !cmd.Flags().Changed("mode") {
+				if mode, err = restore.ParseMode(profile.Mode); err != nil {
+					return fmt.Errorf("profile %s: %w", verifyProfile, err)
+				}
+			}
+			if profile.TimeoutMinutes > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(profile.TimeoutMinutes)*time.Minute)
+				defer cancel()
+			}
+			fmt.Printf("✓ Profile %s applied.\n", verifyProfile)
+		}
+		if err := validateVerification(cfg); err != nil {
+			return err
+		}
+		if len(verifyTables) > 0 {
+			if cfg.Database.Type != "postgres" {
+				return fmt.Errorf("--tables is not supported for database type: %s", cfg.Database.Type)
+			}
+			fmt.Printf("Canary run restoring only: %s\n", strings.Join(verifyTables, ", "))
+		}
+		if objectKey != "" {
+			if cfg.Backup.Source != "s3" || cfg.Backup.S3 == nil {
+				return fmt.Errorf("--object-key requires backup.source: s3")
+			}
+			cfg.Backup.S3.Key = objectKey
+		}
+		fmt.Printf("Running verification (mode: %s, run: %s)...\n", mode, runID)
+
+		// Runs that end before producing a report still get one, so a verification
+		// that never ran is as visible as one that failed
+		failure.cfg = cfg
+		failure.mode = mode
+		failure.backupSource = cfg.Backup.Source
+		defer func() {
+			if err != nil && !failure.reported {
+				failure.report(context.Background(), err)
+			}
+		}()
+
+		backend, err := executionBackend(cfg)
+		if err != nil {
+			return err
+		}
+		if backend == backendVM {
+			reportPaths, warnings, err = verifyOnVM(ctx, cfg, failure)
+			return err
+		}
+		failure.enter("acquire")
+
 		// 2. Acquire backup artifact using BackupSource interface
 		source, err := backup.NewSourceFromConfig(&cfg.Backup)
 		if err != nil {
 			return fmt.Errorf("failed to create backup source: %w", err)
 		}
+		failure.backupSource = source.Identifier()
 
 		fmt.Printf("Acquiring backup from source: %s\n", source.Identifier())
-		backupStream, err := source.Acquire(ctx)
+		artifact, err := acquireArtifact(ctx, cfg, source)
+		if err != nil {
+			return err
+		}
+		defer artifact.Close()
+		fmt.Printf("✓ Backup artifact acquired (%s, sha256:%s).\n", formatBytes(artifact.Size), artifact.Digest[:12])
+		var artifactParts []backup.ArtifactPart
+		if provider, ok := source.(backup.PartsProvider); ok {
+			artifactParts = provider.ArtifactParts()
+			if len(artifactParts) > 0 {
+				fmt.Printf("✓ Backup reassembled from %d parts.\n", len(artifactParts))
+			}
+		}
+
+		var artifactChecksum *backup.ArtifactChecksum
+		if cfg.Backup.Checksum != nil {
+			artifactChecksum, err = verifyArtifactChecksum(ctx, cfg.Backup.Checksum, source, artifact, artifactParts)
+			if err != nil {
+				return err
+			}
+		}
+
+		var sourceFailures []backup.SourceFailure
+		if chain, ok := source.(*backup.ChainSource); ok {
+			sourceFailures = chain.Failures
+			fmt.Printf("✓ Backup served by %s.\n", chain.Identifier())
+		}
+
+		var artifactVersion *backup.ArtifactVersion
+		if provider, ok := source.(backup.VersionProvider); ok {
+			artifactVersion = provider.ArtifactVersion()
+		}
+
+		var producer *backup.ProducerMetadata
+		if provider, ok := source.(backup.MetadataProvider); ok {
+			producer, err = provider.Metadata(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read producer metadata: %w", err)
+			}
+			if producer != nil {
+				fmt.Printf("✓ Producer metadata found (host: %s).\n", valueOrNone(producer.ProducerHost))
+			}
+		}
+
+		var artifactTime *time.Time
+		if provider, ok := source.(backup.ModTimeProvider); ok {
+			artifactTime = provider.ArtifactModTime()
+		}
+		if artifactTime == nil && producer != nil && producer.CreatedAt != "" {
+			if t, err := time.Parse(time.RFC3339, producer.CreatedAt); err == nil {
+				artifactTime = &t
+			}
+		}
+
+		// Return the prior report if this artifact was already verified with this configuration
+		resultCache, err := cache.NewResultCache()
 		if err != nil {
-			return fmt.Errorf("failed to acquire backup: %w", err)
+			return fmt.Errorf("failed to create result cache: %w", err)
+		}
+		configHash, err := cache.HashConfig(struct {
+			Config  *config.Config
+			Mode    restore.Mode
+			Profile string
+			Tables  []string
+		}{cfg, mode, verifyProfile, verifyTables})
+		if err != nil {
+			return err
+		}
+		cacheKey := cache.Key(artifact.Digest, configHash)
+		if !forceVerify {
+			entry, err := resultCache.Lookup(cacheKey)
+			if err != nil {
+				return err
+			}
+			if entry != nil {
+				failure.reported = true
+				cached = true
+				for _, r := range entry.Reports {
+					reportPaths = append(reportPaths, r.Path)
+				}
+				return printCachedResult(entry)
+			}
 		}
-		defer backupStream.Close()
-		fmt.Println("✓ Backup artifact acquired.")
 
 		// 3. Decrypt (if configured)
-		var dataStream io.ReadCloser = backupStream
-		if cfg.Encryption != nil {
+		failure.enter("decrypt")
+		var dataStream io.ReadCloser = artifact
+		var decryptionKey string
+		decrypt, err := sniffEncryption(cfg.Encryption, artifact)
+		if err != nil {
+			return err
+		}
+		if decrypt {
 			fmt.Println("Decrypting backup...")
-			decryptor, err := crypto.NewAgeDecryptor(cfg.Encryption.PrivateKeyPath)
+			decryptor, err := openStreamDecryptor(ctx, cfg.Encryption)
 			if err != nil {
 				return fmt.Errorf("failed to create decryptor: %w", err)
 			}
-			decryptedStream, err := decryptor.NewDecryptReadCloser(backupStream)
+			decryptedStream, err := decryptor.NewDecryptReadCloser(artifact)
 			if err != nil {
 				return fmt.Errorf("decryption failed: %w", err)
 			}
 			dataStream = decryptedStream
-			fmt.Println("✓ Backup decrypted.")
-		} else {
+			decryptionKey = decryptor.MatchedKey()
+			fmt.Printf("✓ Backup decrypted with %s.\n", decryptionKey)
+		} else if cfg.Encryption == nil {
 			fmt.Println("✓ Backup is not encrypted, skipping decryption.")
 		}
 
+		// Compressed dumps, as written by pg_dump | gzip, are restored decompressed
+		dataStream, compression, err := backup.Decompress(dataStream)
+		if err != nil {
+			return fmt.Errorf("decompression failed: %w", err)
+		}
+		if compression != "" {
+			fmt.Printf("✓ Decompressing %s backup.\n", compression)
+		}
+
+		// A dump packed in a tar is unpacked; the file may be compressed itself
+		dataStream, member, err := backup.Unarchive(dataStream, cfg.Backup.ArchiveMember, cfg.CLI.TempDir)
+		if err != nil {
+			return fmt.Errorf("failed to unpack backup: %w", err)
+		}
+		if member != "" {
+			fmt.Printf("✓ Unpacked %s from the tar archive.\n", member)
+			if dataStream, compression, err = backup.Decompress(dataStream); err != nil {
+				return fmt.Errorf("decompression failed: %w", err)
+			}
+			if compression != "" {
+				fmt.Printf("✓ Decompressing %s file.\n", compression)
+			}
+		}
+
+		// Wait for a restore slot when runs on this host are limited
+		if cfg.CLI.RunQueue != nil {
+			failure.enter("queue")
+			release, err := waitForSlot(ctx, cfg, runID)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+
 		// 4. Start ephemeral DB container and restore backup
+		failure.enter("restore")
+		dbPassword, generatedCredential, err := restore.ResolvePassword(cfg)
+		if err != nil {
+			return err
+		}
+		if generatedCredential {
+			fmt.Println("✓ Generated ephemeral database credential.")
+		}
+
+		restoreOpts := restore.Options{
+			Verbose:  verbosity >= verbosityOutput,
+			Mode:     mode,
+			RunID:    runID,
+			Password: dbPassword,
+			Progress: progress,
+			Tables:   verifyTables,
+			LogSQL:   verbosity >= verbositySQL,
+		}
 		var restorer restore.Restorer
-		if cfg.Database.Type == "postgres" {
-			restorer = restore.NewPostgresRestorer(cfg, verbose)
-		} else {
+		switch cfg.Database.Type {
+		case "postgres":
+			restorer = restore.NewPostgresRestorer(cfg, restoreOpts)
+		case "mariadb", "mysql":
+			restorer = restore.NewMariaDBRestorer(cfg, restoreOpts)
+		case "mongodb":
+			restorer = restore.NewMongoRestorer(cfg, restoreOpts)
+		case "sqlite":
+			restorer = restore.NewSQLiteRestorer(cfg, restoreOpts)
+		case "cockroachdb":
+			restorer = restore.NewCockroachRestorer(cfg, restoreOpts)
+		case "elasticsearch", "opensearch":
+			restorer = restore.NewSearchRestorer(cfg, restoreOpts)
+		default:
 			return fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
 		}
 
@@ -87,133 +367,1210 @@ This command performs the following steps:
 		}
 		defer restorer.Cleanup(context.Background())
 
-		// 5. Extract schema and metrics
-		fmt.Println("Extracting schema...")
-		extractedSchema, err := restorer.ExtractSchema(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to extract schema: %w", err)
+		var recoveryPoint *backup.RecoveryPoint
+		if provider, ok := source.(backup.RecoveryPointProvider); ok {
+			recoveryPoint = provider.RecoveryPoint()
+		}
+		if provider, ok := restorer.(backup.RecoveryPointProvider); ok && recoveryPoint == nil {
+			recoveryPoint = provider.RecoveryPoint()
+		}
+		if recoveryPoint != nil {
+			reporter, ok := restorer.(restore.RecoveryReporter)
+			if !ok {
+				return fmt.Errorf("point-in-time recovery is not supported for database type: %s", cfg.Database.Type)
+			}
+			recoveryPoint.AchievedTime, err = reporter.LastReplayedTransaction(ctx)
+			if err != nil {
+				return err
+			}
+			if recoveryPoint.AchievedTime != nil {
+				fmt.Printf("✓ Recovered to %s.\n", recoveryPoint.AchievedTime.Format(time.RFC3339))
+			}
 		}
-		fmt.Printf("✓ Schema extracted: %d tables found.\n", len(extractedSchema.Tables))
 
-		fmt.Println("Extracting metrics...")
-		metrics, err := restorer.ExtractMetrics(ctx)
+		failure.enter("verify")
+		privateKey, err := report.LoadPrivateKey(cfg.Signing.PrivateKeyPath)
 		if err != nil {
-			return fmt.Errorf("failed to extract metrics: %w", err)
+			return fmt.Errorf("failed to load signing key: %w", err)
 		}
-		fmt.Println("✓ Metrics extracted.")
 
-		// 6. Load baseline schema (if exists)
-		baselineStore, err := schema.NewBaselineStore()
+		baselineStore, err := openBaselineStore(ctx, cfg)
 		if err != nil {
-			return fmt.Errorf("failed to create baseline store: %w", err)
+			return err
 		}
 
-		baseline, err := baselineStore.Load(cfg.Project.ID)
-		if err != nil {
-			return fmt.Errorf("failed to load baseline schema: %w", err)
+		run := &verifyRun{
+			cfg:                 cfg,
+			runID:               runID,
+			mode:                mode,
+			profile:             verifyProfile,
+			tables:              verifyTables,
+			restorer:            restorer,
+			baselineStore:       baselineStore,
+			privateKey:          privateKey,
+			backupSource:        source.Identifier(),
+			sourceFailures:      sourceFailures,
+			artifactDigest:      artifact.Digest,
+			artifactVersion:     artifactVersion,
+			artifactChecksum:    artifactChecksum,
+			artifactParts:       artifactParts,
+			artifactTime:        artifactTime,
+			decryptionKey:       decryptionKey,
+			producer:            producer,
+			recoveryPoint:       recoveryPoint,
+			generatedCredential: generatedCredential,
 		}
 
-		if baseline == nil {
-			fmt.Println("No baseline schema found. This will be stored as the baseline.")
-		} else {
-			fmt.Printf("✓ Baseline schema loaded (%d tables).\n", len(baseline.Tables))
+		// 5-11. Verify and report on each logical database
+		var restored []string
+		if lister, ok := restorer.(restore.DatabaseLister); ok {
+			restored, err = lister.RestoredDatabases(ctx)
+			if err != nil {
+				return err
+			}
+			if len(restored) > 0 && len(cfg.Database.LogicalDatabases) == 0 {
+				fmt.Printf("✓ Cluster dump restored %d database(s): %s.\n", len(restored), strings.Join(restored, ", "))
+			}
+		}
+		targets := verificationTargets(cfg, restored)
+		entry := &cache.ResultEntry{Success: true, CreatedAt: time.Now().UTC()}
+		var critical int
+		var reports []*report.Report
+		for _, target := range targets {
+			if target.database != "" {
+				fmt.Printf("\n=== Database %s (project %s) ===\n", target.database, target.projectID)
+				switcher, ok := restorer.(restore.DatabaseSwitcher)
+				if !ok {
+					return fmt.Errorf("logical databases are not supported for database type: %s", cfg.Database.Type)
+				}
+				if err := switcher.UseDatabase(ctx, target.database); err != nil {
+					return err
+				}
+			}
+
+			rpt, reportPath, err := run.verifyTarget(ctx, target)
+			if err != nil {
+				return err
+			}
+			entry.Reports = append(entry.Reports, cache.CachedReport{ID: rpt.ID, Path: reportPath})
+			reports = append(reports, rpt)
+			reportPaths = append(reportPaths, reportPath)
+			warnings += rpt.Summary.WarningFailures
+			if !rpt.Summary.Success {
+				entry.Success = false
+			}
+			critical += rpt.Summary.CriticalFailures
 		}
+		failure.reported = true
 
-		// 7. Run verification checks
-		fmt.Println("Running verification checks...")
-		checkers := buildCheckers(cfg)
-		checkResults := verify.RunChecks(ctx, checkers, extractedSchema, baseline, metrics)
+		if err := resultCache.Store(cacheKey, entry); err != nil {
+			fmt.Printf("⚠ Failed to cache verification result: %v\n", err)
+		}
+
+		// 12. Submit reports, queueing them while the endpoint is unreachable
+		if cfg.Upload != nil {
+			submitReports(ctx, cfg.Upload, entry.Reports)
+		}
 
-		for _, r := range checkResults {
-			status := "✓"
-			if !r.Passed {
-				status = "✗"
+		if cfg.Notifications != nil {
+			sendNotifications(ctx, cfg, reports)
+		}
+
+		// Retention problems never fail the verification itself
+		if r := cfg.CLI.ReportRetention; r != nil && r.Days > 0 {
+			if err := pruneReports(ctx, cfg, false); err != nil {
+				fmt.Printf("⚠ Report pruning incomplete: %v\n", err)
 			}
-			fmt.Printf("  %s [%s] %s: %s\n", status, r.Level, r.Name, r.Message)
 		}
 
-		critical, warning, _ := verify.CountFailures(checkResults)
 		if critical > 0 {
-			fmt.Printf("\n✗ Verification failed with %d critical failure(s).\n", critical)
-		} else if warning > 0 {
-			fmt.Printf("\n⚠ Verification passed with %d warning(s).\n", warning)
-		} else {
-			fmt.Println("\n✓ All verification checks passed.")
+			return &exitError{code: exitCritical, err: fmt.Errorf("verification failed with %d critical failure(s)", critical)}
 		}
 
-		// 8. Generate report
-		fmt.Println("\nGenerating report...")
-		reportID := uuid.New().String()
+		return nil
+	},
+}
 
-		rpt := report.NewReportBuilder().
-			WithID(reportID).
-			WithProject(cfg.Project.ID, cfg.Project.Name).
-			WithMachineID(cfg.CLI.MachineID).
-			WithBackupSource(source.Identifier()).
-			WithDatabase(cfg.Database.Type, cfg.Database.MajorVersion).
-			WithSchema(extractedSchema).
-			WithMetrics(metrics).
-			WithChecks(checkResults).
-			Build()
+// acquireArtifact spools the backup artifact locally to fingerprint it before doing
+// any work. With an artifact cache configured, sources that can fingerprint the
+// object are served from the cache when it holds a copy, and downloads are cached.
+func acquireArtifact(ctx context.Context, cfg *config.Config, source backup.BackupSource) (*backup.SpooledArtifact, error) {
+	var artifactCache *cache.ArtifactCache
+	var fingerprint string
+	if fp, ok := source.(backup.Fingerprinter); ok && cfg.CLI.ArtifactCache != nil {
+		var err error
+		artifactCache, err = openArtifactCache(cfg)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err = fp.Fingerprint(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire backup: %w", err)
+		}
 
-		// 9. Sign report
-		privateKey, err := report.LoadPrivateKey(cfg.Signing.PrivateKeyPath)
+		cached, digest, err := artifactCache.Lookup(fingerprint)
 		if err != nil {
-			return fmt.Errorf("failed to load signing key: %w", err)
+			return nil, err
+		}
+		if cached != nil {
+			artifact, err := backup.Spool(cached, cfg.CLI.TempDir)
+			cached.Close()
+			if err != nil {
+				return nil, err
+			}
+			if artifact.Digest == digest {
+				fmt.Println("✓ Backup artifact served from cache.")
+				if err := checkDiskSpace(ctx, cfg, artifact.Size, true); err != nil {
+					artifact.Close()
+					return nil, err
+				}
+				return artifact, nil
+			}
+			artifact.Close()
+			fmt.Println("⚠ Cached backup artifact is corrupt, downloading again.")
+			if err := artifactCache.Invalidate(fingerprint, digest); err != nil {
+				return nil, fmt.Errorf("failed to invalidate cached artifact: %w", err)
+			}
+		}
+	}
+
+	backupStream, err := backup.AcquireWithRetry(ctx, source, backup.NewRetryPolicy(cfg.Backup.Retry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire backup: %w", err)
+	}
+	defer backupStream.Close()
+
+	// Sources that report the size up front are checked before the download, the
+	// rest once the artifact is on disk
+	var size int64
+	if provider, ok := source.(backup.SizeProvider); ok {
+		size = provider.ArtifactSize()
+	}
+	if err := checkDiskSpace(ctx, cfg, size, false); err != nil {
+		return nil, err
+	}
+
+	artifact, err := backup.Spool(backupStream, cfg.CLI.TempDir)
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		if err := checkDiskSpace(ctx, cfg, artifact.Size, true); err != nil {
+			artifact.Close()
+			return nil, err
+		}
+	}
+
+	if artifactCache != nil {
+		if err := artifactCache.Store(fingerprint, artifact.Digest, artifact.Name()); err != nil {
+			fmt.Printf("⚠ Failed to cache backup artifact: %v\n", err)
+		}
+	}
+	return artifact, nil
+}
+
+// verifyArtifactChecksum checks the artifact, and each part of a split backup,
+// against the checksums published next to them, returning nil when none is
+// published for the whole artifact and it is not required.
+func verifyArtifactChecksum(ctx context.Context, cfg *config.Checksum, source backup.BackupSource, artifact *backup.SpooledArtifact, parts []backup.ArtifactPart) (*backup.ArtifactChecksum, error) {
+	reader, ok := source.(backup.CompanionReader)
+	if !ok {
+		return nil, fmt.Errorf("backup.checksum is not supported by backup source %s (use local, s3 or sftp)", source.Identifier())
+	}
+	verifiedParts, err := backup.VerifyParts(ctx, reader, parts, cfg.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if verifiedParts > 0 {
+		fmt.Printf("✓ %d of %d backup parts match their published checksums.\n", verifiedParts, len(parts))
+	}
+	expected, err := backup.ExpectedChecksum(ctx, reader, cfg.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup checksum: %w", err)
+	}
+	if expected == nil {
+		if verifiedParts > 0 && (verifiedParts == len(parts) || !cfg.Required) {
+			return nil, nil
+		}
+		if cfg.Required {
+			return nil, fmt.Errorf("no checksum sidecar found for %s and backup.checksum.required is set", source.Identifier())
+		}
+		fmt.Println("⚠ No checksum published for the backup artifact, skipping checksum verification.")
+		return nil, nil
+	}
+	if err := artifact.VerifyChecksum(expected); err != nil {
+		return nil, err
+	}
+	fmt.Printf("✓ Backup artifact matches its %s checksum from %s.\n", expected.Algorithm, expected.Source)
+	return expected, nil
+}
+
+// verificationTarget is a logical database verified, baselined and reported on its own.
+type verificationTarget struct {
+	// database is the database to inspect; empty means the restore database.
+	database     string
+	projectID    string
+	projectName  string
+	verification config.Verification
+}
+
+// verificationTargets returns the configured logical databases. Without any, each
+// database restored from a cluster-wide dump is verified with the default project
+// settings, and otherwise the restore database under the project settings.
+func verificationTargets(cfg *config.Config, restored []string) []verificationTarget {
+	databases := cfg.Database.LogicalDatabases
+	if len(databases) == 0 {
+		for _, name := range restored {
+			databases = append(databases, config.LogicalDatabase{Name: name})
 		}
+	}
+	if len(databases) == 0 {
+		return []verificationTarget{{
+			projectID:    cfg.Project.ID,
+			projectName:  cfg.Project.Name,
+			verification: cfg.Verification,
+		}}
+	}
 
-		if err := report.Sign(rpt, privateKey); err != nil {
-			return fmt.Errorf("failed to sign report: %w", err)
+	targets := make([]verificationTarget, 0, len(databases))
+	for _, db := range databases {
+		t := verificationTarget{
+			database:     db.Name,
+			projectID:    db.ProjectID,
+			projectName:  db.ProjectName,
+			verification: cfg.Verification,
+		}
+		if t.projectID == "" {
+			t.projectID = cfg.Project.ID + "-" + db.Name
+		}
+		if t.projectName == "" {
+			t.projectName = fmt.Sprintf("%s (%s)", cfg.Project.Name, db.Name)
 		}
-		fmt.Println("✓ Report signed.")
+		if db.Verification != nil {
+			t.verification = *db.Verification
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// logTiming prints how long a step took, from verbosity -vv.
+func logTiming(step string, start time.Time) {
+	if verbosity >= verbosityTimings {
+		fmt.Printf("  %s took %s.\n", step, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// verifyRun holds the state shared by all targets of a single verification run.
+type verifyRun struct {
+	cfg                 *config.Config
+	runID               string
+	mode                restore.Mode
+	profile             string
+	tables              []string
+	restorer            restore.Restorer
+	baselineStore       *schema.BaselineStore
+	privateKey          ed25519.PrivateKey
+	backupSource        string
+	sourceFailures      []backup.SourceFailure
+	artifactDigest      string
+	artifactVersion     *backup.ArtifactVersion
+	artifactChecksum    *backup.ArtifactChecksum
+	artifactParts       []backup.ArtifactPart
+	artifactTime        *time.Time
+	decryptionKey       string
+	producer            *backup.ProducerMetadata
+	recoveryPoint       *backup.RecoveryPoint
+	generatedCredential bool
+}
+
+// verifyTarget extracts, checks, reports on and baselines a single target.
+func (v *verifyRun) verifyTarget(ctx context.Context, target verificationTarget) (*report.Report, string, error) {
+	// 5. Extract schema and metrics
+	fmt.Println("Extracting schema...")
+	start := time.Now()
+	extractedSchema, err := v.restorer.ExtractSchema(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to extract schema: %w", err)
+	}
+	fmt.Printf("✓ Schema extracted: %d tables found.\n", len(extractedSchema.Tables))
+	logTiming("Schema extraction", start)
+
+	if checksumsEnabled(target.verification, v.mode) {
+		checksummer, ok := v.restorer.(restore.TableChecksummer)
+		if !ok {
+			return nil, "", fmt.Errorf("table checksums are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		fmt.Println("Computing table checksums...")
+		start := time.Now()
+		if err := checksummer.ComputeChecksums(ctx, extractedSchema); err != nil {
+			return nil, "", fmt.Errorf("failed to compute table checksums: %w", err)
+		}
+		fmt.Println("✓ Table checksums computed.")
+		logTiming("Table checksums", start)
+	}
+
+	if target.verification.Views.RefreshMaterialized && v.mode != restore.ModeSchemaOnly {
+		refresher, ok := v.restorer.(restore.ViewRefresher)
+		if !ok {
+			return nil, "", fmt.Errorf("refreshing materialized views is not supported for database type: %s", v.cfg.Database.Type)
+		}
+		fmt.Println("Refreshing materialized views...")
+		start := time.Now()
+		if err := refresher.RefreshMaterializedViews(ctx, extractedSchema); err != nil {
+			return nil, "", fmt.Errorf("failed to refresh materialized views: %w", err)
+		}
+		fmt.Println("✓ Materialized views refreshed.")
+		logTiming("Materialized view refresh", start)
+	}
 
-		// 10. Write report
-		reportPath, err := report.WriteJSON(rpt, cfg.CLI.ReportDir)
+	fmt.Println("Extracting metrics...")
+	start = time.Now()
+	metrics, err := v.restorer.ExtractMetrics(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to extract metrics: %w", err)
+	}
+	fmt.Println("✓ Metrics extracted.")
+	logTiming("Metrics extraction", start)
+	if checksumsEnabled(target.verification, v.mode) {
+		recordChecksums(metrics, extractedSchema)
+	}
+
+	if profiles := target.verification.ColumnProfiles; profiles.Enabled && len(profiles.Columns) > 0 && v.mode != restore.ModeSchemaOnly {
+		profiler, ok := v.restorer.(restore.ColumnProfiler)
+		if !ok {
+			return nil, "", fmt.Errorf("column profiles are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		fmt.Println("Profiling columns...")
+		start := time.Now()
+		metrics.ColumnProfiles, err = profiler.ProfileColumns(ctx, profiles.Columns)
 		if err != nil {
-			return fmt.Errorf("failed to write report: %w", err)
+			return nil, "", fmt.Errorf("failed to profile columns: %w", err)
 		}
-		fmt.Printf("✓ Report saved to %s\n", reportPath)
+		fmt.Printf("✓ %d columns profiled.\n", len(metrics.ColumnProfiles))
+		logTiming("Column profiling", start)
+	}
 
-		// 11. Save schema as new baseline if this is the first run
-		if baseline == nil {
-			if err := baselineStore.Save(cfg.Project.ID, extractedSchema); err != nil {
-				return fmt.Errorf("failed to save baseline schema: %w", err)
+	var sampleErrors []error
+	if samples := target.verification.Checksums.Samples; len(samples) > 0 && v.mode != restore.ModeSchemaOnly {
+		checksummer, ok := v.restorer.(restore.SampleChecksummer)
+		if !ok {
+			return nil, "", fmt.Errorf("checksum samples are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		fmt.Println("Hashing row samples...")
+		start := time.Now()
+		// A sample that cannot be hashed fails sample_checksums rather than the run
+		for _, s := range samples {
+			sample, err := checksummer.ChecksumSample(ctx, s.Table, s.Where, s.OrderBy, s.Limit)
+			switch {
+			case err == nil:
+				metrics.SampleChecksums = append(metrics.SampleChecksums, *sample)
+			case restore.MissingTable(extractedSchema, s.Table, false):
+				fmt.Printf("⚠ Skipping the row sample of %s: the table was not restored.\n", s.Table)
+			default:
+				sampleErrors = append(sampleErrors, err)
 			}
-			fmt.Println("✓ Schema saved as baseline for future comparisons.")
 		}
+		fmt.Printf("✓ %d row samples hashed.\n", len(metrics.SampleChecksums))
+		logTiming("Row sample hashing", start)
+	}
 
-		// Final summary
-		fmt.Printf("\nVerification completed. Report ID: %s\n", reportID)
-		if critical > 0 {
-			return fmt.Errorf("verification failed with %d critical failure(s)", critical)
+	var nullErrors []error
+	if columns := target.verification.NullRatios.Columns; len(columns) > 0 && v.mode != restore.ModeSchemaOnly {
+		counter, ok := v.restorer.(restore.NullCounter)
+		if !ok {
+			return nil, "", fmt.Errorf("null ratios are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		// A column that cannot be counted fails null_ratios rather than the run
+		for _, column := range columns {
+			nulls, err := counter.CountNulls(ctx, column)
+			switch {
+			case err == nil:
+				metrics.ColumnNulls = append(metrics.ColumnNulls, *nulls)
+			case restore.MissingTable(extractedSchema, column, true):
+				fmt.Printf("⚠ Skipping NULL count of %s: its table was not restored.\n", column)
+			default:
+				nullErrors = append(nullErrors, err)
+			}
 		}
+	}
 
-		return nil
-	},
+	var freshColumns []verify.ColumnFreshness
+	if columns := target.verification.Freshness.Columns; len(columns) > 0 && v.mode != restore.ModeSchemaOnly {
+		reader, ok := v.restorer.(restore.TimestampReader)
+		if !ok {
+			return nil, "", fmt.Errorf("freshness columns are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		// A column that cannot be read fails data_freshness rather than the run
+		for _, c := range columns {
+			latest, err := reader.LatestTimestamp(ctx, c.Column)
+			if err != nil && restore.MissingTable(extractedSchema, c.Column, true) {
+				fmt.Printf("⚠ Skipping freshness of %s: its table was not restored.\n", c.Column)
+				continue
+			}
+			maxAgeHours := c.MaxAgeHours
+			if maxAgeHours <= 0 {
+				maxAgeHours = target.verification.Freshness.MaxAgeHours
+			}
+			maxAge := verify.DefaultFreshnessMaxAge
+			if maxAgeHours > 0 {
+				maxAge = time.Duration(maxAgeHours) * time.Hour
+			}
+			freshColumns = append(freshColumns, verify.ColumnFreshness{Column: c.Column, Latest: latest, MaxAge: maxAge, Err: err})
+		}
+	}
+
+	var integrity *restore.IntegrityResult
+	if verifier, ok := v.restorer.(restore.IntegrityVerifier); ok {
+		fmt.Println("Running database integrity checks...")
+		start := time.Now()
+		integrity, err = verifier.CheckIntegrity(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to check database integrity: %w", err)
+		}
+		fmt.Println("✓ Integrity checks completed.")
+		logTiming("Integrity checks", start)
+	}
+
+	var corruptIndexes []string
+	amcheck := target.verification.Amcheck
+	if amcheck.Enabled {
+		verifier, ok := v.restorer.(restore.IndexVerifier)
+		if !ok {
+			return nil, "", fmt.Errorf("amcheck is not supported for database type: %s", v.cfg.Database.Type)
+		}
+		fmt.Println("Checking B-tree indexes with amcheck...")
+		start := time.Now()
+		corruptIndexes, err = verifier.VerifyIndexes(ctx, amcheck.HeapAllIndexed)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to check indexes: %w", err)
+		}
+		fmt.Println("✓ Index checks completed.")
+		logTiming("amcheck", start)
+	}
+
+	var rehearsal *restore.RehearsalResult
+	if rc := target.verification.Rehearsal; rc != nil && v.mode != restore.ModeSchemaOnly {
+		appTarget, ok := v.restorer.(restore.AppTarget)
+		if !ok {
+			return nil, "", fmt.Errorf("rehearsals are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		conn, err := appTarget.AppConnection(ctx, target.database)
+		if err != nil {
+			return nil, "", err
+		}
+		fmt.Printf("Running application rehearsal with %s...\n", rc.Image)
+		rehearsal, err = restore.RunRehearsal(ctx, v.cfg, v.runID, rc, conn)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to run application rehearsal: %w", err)
+		}
+		if rehearsal.Passed() {
+			fmt.Println("✓ Application rehearsal passed.")
+		} else {
+			fmt.Println("✗ Application rehearsal failed. Last output:")
+			fmt.Println(rehearsal.Output)
+		}
+	}
+
+	// 6. Load baseline schema (if exists)
+	baseline, err := v.baselineStore.Load(target.projectID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load baseline schema: %w", err)
+	}
+
+	switch {
+	case baseline == nil && len(v.tables) > 0:
+		fmt.Println("No baseline schema found. Canary runs do not store one.")
+	case baseline == nil:
+		fmt.Println("No baseline schema found. This will be stored as the baseline.")
+	case len(v.tables) > 0:
+		// A canary run is only compared on the tables it restored
+		baseline = selectTables(baseline, v.tables)
+		fmt.Printf("✓ Baseline schema loaded (%d of the selected tables).\n", len(baseline.Tables))
+	default:
+		fmt.Printf("✓ Baseline schema loaded (%d tables).\n", len(baseline.Tables))
+	}
+
+	// 7. Run verification checks
+	fmt.Println("Running verification checks...")
+	var history []*schema.Metrics
+	if target.verification.Adaptive.Enabled || target.verification.ColumnProfiles.Enabled || target.verification.TableSizes.Enabled ||
+		target.verification.Checksums.Enabled || len(target.verification.Checksums.Samples) > 0 || len(target.verification.NullRatios.Columns) > 0 {
+		history, err = report.LoadMetricsHistory(v.cfg.CLI.ReportDir, target.projectID, adaptiveHistoryRuns(target.verification.Adaptive))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load run history: %w", err)
+		}
+	}
+	var annotations *verify.Annotations
+	if path := target.verification.Schema.AnnotationsPath; path != "" {
+		annotations, err = verify.LoadAnnotations(path, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	checkers := buildCheckers(target.verification, v.mode, history, annotations)
+	checkers = append(checkers, verify.NewProducerMetadataChecker(v.producer, v.cfg.Database.MajorVersion))
+	checkers = append(checkers, verify.NewIdentifierChecker(v.cfg.Database.Type))
+	if target.verification.Freshness.Enabled {
+		checkers = append(checkers, verify.NewBackupFreshnessChecker(v.artifactTime, target.verification.Freshness.MaxAgeHours))
+	}
+	if len(freshColumns) > 0 {
+		checkers = append(checkers, verify.Requires(verify.NewDataFreshnessChecker(freshColumns), "data_freshness", "tables_exist"))
+	}
+	if v.recoveryPoint != nil {
+		checkers = append(checkers, verify.NewRecoveryPointChecker(v.recoveryPoint))
+	}
+	if rehearsal != nil {
+		checkers = append(checkers, verify.NewRehearsalChecker(
+			rehearsal.Image, rehearsal.ExitCode, rehearsal.TimedOut, rehearsal.Duration, rehearsal.Output))
+	}
+	if integrity != nil {
+		checkers = append(checkers,
+			verify.NewIntegrityChecker(integrity.Problems),
+			verify.NewForeignKeyChecker(integrity.ForeignKeyViolations))
+	}
+	if amcheck.Enabled {
+		checkers = append(checkers, verify.NewAmcheckChecker(corruptIndexes))
+	}
+	if counter, ok := v.restorer.(restore.DuplicateCounter); ok {
+		checkers = append(checkers, verify.Requires(verify.NewDuplicateKeyChecker(counter), "duplicate_keys", "tables_exist"))
+	}
+	// Row sample hashes against the previous run (if configured)
+	if len(target.verification.Checksums.Samples) > 0 && v.mode != restore.ModeSchemaOnly {
+		samples := verify.NewSampleChecksumChecker(history)
+		samples.Errors = sampleErrors
+		checkers = append(checkers, verify.Requires(samples, "sample_checksums", "tables_exist"))
+	}
+	if len(metrics.ColumnNulls) > 0 || len(nullErrors) > 0 {
+		maxIncrease, level, err := nullRatioSettings(target.verification.NullRatios)
+		if err != nil {
+			return nil, "", err
+		}
+		nullRatios := verify.NewNullRatioChecker(history, maxIncrease, level)
+		nullRatios.Errors = nullErrors
+		checkers = append(checkers, verify.Requires(nullRatios, "null_ratios", "tables_exist"))
+	}
+	// Custom queries check data, which schema-only restores do not carry
+	if queries := target.verification.CustomQueries; len(queries) > 0 && v.mode != restore.ModeSchemaOnly {
+		runner, ok := v.restorer.(restore.QueryRunner)
+		if !ok {
+			return nil, "", fmt.Errorf("custom queries are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		specs, err := customQueries(queries)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, q := range specs {
+			checkers = append(checkers, verify.Requires(verify.NewQueryChecker(runner, q), "custom_query:"+q.Name, "tables_exist"))
+		}
+	}
+	if rels := target.verification.Relationships; len(rels) > 0 && v.mode != restore.ModeSchemaOnly {
+		counter, ok := v.restorer.(restore.OrphanCounter)
+		if !ok {
+			return nil, "", fmt.Errorf("relationships are not supported for database type: %s", v.cfg.Database.Type)
+		}
+		checkers = append(checkers, verify.Requires(verify.NewOrphanedRowsChecker(counter, relationships(rels)), "orphaned_rows", "tables_exist"))
+	}
+	checkResults := verify.RunChecks(ctx, checkers, extractedSchema, baseline, metrics)
+
+	for _, r := range checkResults {
+		if verbosity >= verbosityTimings && !r.Skipped {
+			fmt.Printf("  %s [%s] %s: %s (%s)\n", r.StatusSymbol(), r.Level, r.Name, r.Message, r.Duration.Round(time.Microsecond))
+			continue
+		}
+		fmt.Printf("  %s [%s] %s: %s\n", r.StatusSymbol(), r.Level, r.Name, r.Message)
+	}
+
+	critical, warning, _ := verify.CountFailures(checkResults)
+	if critical > 0 {
+		fmt.Printf("\n✗ Verification failed with %d critical failure(s).\n", critical)
+	} else if warning > 0 {
+		fmt.Printf("\n⚠ Verification passed with %d warning(s).\n", warning)
+	} else {
+		fmt.Println("\n✓ All verification checks passed.")
+	}
+
+	// 8. Generate report
+	fmt.Println("\nGenerating report...")
+	reportID := uuid.New().String()
+
+	rpt := report.NewReportBuilder().
+		WithID(reportID).
+		WithRunID(v.runID).
+		WithProject(target.projectID, target.projectName).
+		WithMachineID(v.cfg.CLI.MachineID).
+		WithBackupSource(v.backupSource).
+		WithBackupSourceFailures(v.sourceFailures).
+		WithArtifactDigest(v.artifactDigest).
+		WithArtifactVersion(v.artifactVersion).
+		WithArtifactChecksum(v.artifactChecksum).
+		WithArtifactParts(v.artifactParts).
+		WithArtifactTime(v.artifactTime).
+		WithDecryptionKey(v.decryptionKey).
+		WithMode(string(v.mode)).
+		WithProfile(v.profile).
+		WithTables(v.tables).
+		WithProducer(v.producer).
+		WithRecoveryPoint(v.recoveryPoint).
+		WithDatabase(v.cfg.Database.Type, v.cfg.Database.MajorVersion).
+		WithDatabaseName(target.database).
+		WithGeneratedCredential(v.generatedCredential).
+		WithSchema(extractedSchema).
+		WithMetrics(metrics).
+		WithChecks(checkResults).
+		Build()
+
+	// 9. Sign report
+	if err := report.Sign(rpt, v.privateKey); err != nil {
+		return nil, "", fmt.Errorf("failed to sign report: %w", err)
+	}
+	fmt.Println("✓ Report signed.")
+
+	// 10. Write report
+	reportPath, err := report.WriteJSON(rpt, v.cfg.CLI.ReportDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Printf("✓ Report saved to %s\n", reportPath)
+
+	// 11. Save schema as new baseline if this is the first run.
+	// Data-only restores reflect the pre-initialized schema, not the backup's, and
+	// canary runs only a few of its tables.
+	if baseline == nil && v.mode != restore.ModeDataOnly && len(v.tables) == 0 {
+		if err := v.baselineStore.Save(target.projectID, extractedSchema); err != nil {
+			return nil, "", fmt.Errorf("failed to save baseline schema: %w", err)
+		}
+		fmt.Println("✓ Schema saved as baseline for future comparisons.")
+	}
+
+	fmt.Printf("\nVerification completed. Report ID: %s\n", reportID)
+	return rpt, reportPath, nil
 }
 
-func buildCheckers(cfg *config.Config) []verify.Checker {
+// selectTables returns the part of baseline covering tables, each given as a name
+// or a schema-qualified name. Database-wide objects such as extensions are left
+// out, since a canary restore does not include them, and so are table indexes,
+// constraints and triggers: pg_restore --table restores only the table and its
+// data. Privileges are kept whole: they are only compared for objects that were
+// restored.
+func selectTables(baseline *schema.Schema, tables []string) *schema.Schema {
+	selected := make(map[string]bool)
+	for _, t := range tables {
+		selected[t] = true
+	}
+	isSelected := func(schemaName, name string) bool {
+		return selected[name] || selected[schemaName+"."+name]
+	}
+
+	subset := &schema.Schema{Version: baseline.Version, Timestamp: baseline.Timestamp, Privileges: baseline.Privileges}
+	for _, t := range baseline.Tables {
+		if isSelected(t.Schema, t.Name) {
+			t.Indexes, t.Constraints, t.Triggers = nil, nil, nil
+			subset.Tables = append(subset.Tables, t)
+		}
+	}
+	for _, c := range baseline.SpatialColumns {
+		if isSelected(c.Schema, c.Table) {
+			subset.SpatialColumns = append(subset.SpatialColumns, c)
+		}
+	}
+	return subset
+}
+
+func buildCheckers(v config.Verification, mode restore.Mode, history []*schema.Metrics, annotations *verify.Annotations) []verify.Checker {
 	var checkers []verify.Checker
 
 	// Always run table checks (critical)
-	checkers = append(checkers, verify.NewTablesExistChecker())
-	checkers = append(checkers, verify.NewTableCountChecker())
-	checkers = append(checkers, verify.NewNewTablesChecker())
+	checkers = append(checkers, verify.NewTablesExistChecker(annotations))
+	checkers = append(checkers, verify.NewTableCountChecker(annotations))
+	checkers = append(checkers, verify.NewNewTablesChecker(annotations))
+	checkers = append(checkers, verify.Requires(verify.NewColumnDriftChecker(), "column_drift", "tables_exist"))
+	checkers = append(checkers, verify.NewDistributedTablesChecker())
+	checkers = append(checkers, verify.NewIndexMappingChecker())
+	checkers = append(checkers, verify.NewExtensionChecker(v.Extensions.Required))
+	checkers = append(checkers, verify.NewSpatialColumnsChecker())
+	checkers = append(checkers, verify.NewPrivilegesChecker())
+	checkers = append(checkers, verify.Requires(verify.NewIndexChecker(), "indexes", "tables_exist"))
+	checkers = append(checkers, verify.Requires(verify.NewConstraintsChecker(), "constraints", "tables_exist"))
+	checkers = append(checkers, verify.Requires(verify.NewTriggersChecker(), "triggers", "tables_exist"))
+	checkers = append(checkers, verify.Requires(verify.NewViewsChecker(), "views", "tables_exist"))
+	checkers = append(checkers, verify.NewSequencesChecker())
+	checkers = append(checkers, verify.NewRoutinesChecker())
+
+	percentile, minRuns := adaptiveSettings(v.Adaptive)
+
+	// Row count checks (if enabled; schema-only restores carry no data)
+	if v.RowCounts.Enabled && mode != restore.ModeSchemaOnly {
+		var rowCounts verify.Checker = verify.NewRowCountChecker(v.RowCounts.WarnThresholdPercent)
+		if v.Adaptive.Enabled {
+			rowCounts = verify.NewAdaptiveRowCountChecker(history, percentile, minRuns)
+		}
+		checkers = append(checkers, verify.Requires(rowCounts, "row_counts", "tables_exist"))
+		checkers = append(checkers, verify.Requires(verify.NewNonEmptyTablesChecker(1), "non_empty_tables", "tables_exist"))
+		checkers = append(checkers, verify.Requires(verify.NewTotalRowCountChecker(1), "total_row_count", "tables_exist"))
+	}
+
+	// Table content checksums (if enabled)
+	if checksumsEnabled(v, mode) {
+		checkers = append(checkers, verify.Requires(verify.NewTableChecksumChecker(history), "table_checksums", "tables_exist"))
+	}
 
-	// Row count checks (if enabled)
-	if cfg.Verification.RowCounts.Enabled {
-		checkers = append(checkers, verify.NewRowCountChecker(cfg.Verification.RowCounts.WarnThresholdPercent))
-		checkers = append(checkers, verify.NewNonEmptyTablesChecker(1))
-		checkers = append(checkers, verify.NewTotalRowCountChecker(1))
+	// Column profiles against the previous run (if enabled)
+	if v.ColumnProfiles.Enabled && mode != restore.ModeSchemaOnly {
+		threshold := v.ColumnProfiles.WarnThresholdPercent
+		if threshold <= 0 {
+			threshold = 20
+		}
+		checkers = append(checkers, verify.Requires(verify.NewColumnProfileChecker(history, threshold), "column_profiles", "tables_exist"))
+	}
+
+	// Table sizes against their trend across runs (if enabled)
+	if v.TableSizes.Enabled && mode != restore.ModeSchemaOnly {
+		drop, growth, minRuns := tableSizeSettings(v.TableSizes)
+		checkers = append(checkers, verify.Requires(verify.NewTableSizeChecker(history, drop, growth, minRuns), "table_sizes", "tables_exist"))
 	}
 
 	// Always track restore duration
-	checkers = append(checkers, verify.NewRestoreDurationChecker(0))
+	if v.Adaptive.Enabled {
+		checkers = append(checkers, verify.NewAdaptiveDurationChecker(history, percentile, minRuns))
+	} else {
+		checkers = append(checkers, verify.NewRestoreDurationChecker(0))
+	}
 
 	return checkers
 }
 
+// nullRatioSettings returns the tolerated NULL ratio rise and the level of the
+// null_ratios check, applying defaults.
+func nullRatioSettings(n config.NullRatios) (maxIncrease float64, level verify.Level, err error) {
+	maxIncrease = n.MaxIncreasePercent
+	if maxIncrease <= 0 {
+		maxIncrease = 5
+	}
+	switch verify.Level(n.Severity) {
+	case "":
+		level = verify.LevelWarning
+	case verify.LevelWarning, verify.LevelCritical:
+		level = verify.Level(n.Severity)
+	default:
+		return 0, "", fmt.Errorf("verification.null_ratios has invalid severity %q (use warning or critical)", n.Severity)
+	}
+	return maxIncrease, level, nil
+}
+
+// tableSizeSettings returns the drop and growth thresholds and minimum history,
+// applying defaults.
+func tableSizeSettings(t config.TableSizes) (maxDrop, maxGrowth float64, minRuns int) {
+	maxDrop, maxGrowth, minRuns = t.MaxDropPercent, t.MaxGrowthPercent, t.MinRuns
+	if maxDrop <= 0 {
+		maxDrop = 20
+	}
+	if maxGrowth <= 0 {
+		maxGrowth = 100
+	}
+	if minRuns <= 0 {
+		minRuns = 3
+	}
+	return maxDrop, maxGrowth, minRuns
+}
+
+// adaptiveSettings returns the percentile and minimum history, applying defaults.
+func adaptiveSettings(a config.Adaptive) (percentile float64, minRuns int) {
+	percentile, minRuns = a.Percentile, a.MinRuns
+	if percentile <= 0 || percentile > 100 {
+		percentile = 95
+	}
+	if minRuns <= 0 {
+		minRuns = 5
+	}
+	return percentile, minRuns
+}
+
+// adaptiveHistoryRuns returns how many previous runs feed adaptive thresholds.
+func adaptiveHistoryRuns(a config.Adaptive) int {
+	if a.HistoryRuns <= 0 {
+		return 30
+	}
+	return a.HistoryRuns
+}
+
+// submitReports retries previously queued reports, then submits this run's reports.
+// Reports that cannot be submitted are queued for the next run or `report push --pending`;
+// upload problems never fail the verification itself.
+func submitReports(ctx context.Context, cfg *config.Upload, reports []cache.CachedReport) {
+	queue, err := upload.NewQueue()
+	if err != nil {
+		fmt.Printf("⚠ Failed to open upload queue: %v\n", err)
+		return
+	}
+
+	enqueue := func(reports []cache.CachedReport) {
+		for _, r := range reports {
+			if err := queue.Enqueue(r.Path); err != nil {
+				fmt.Printf("⚠ Failed to queue report %s: %v\n", r.ID, err)
+			}
+		}
+		fmt.Printf("⚠ %d report(s) queued for upload; retry with 'restorable report push --pending'.\n", len(reports))
+	}
+
+	client, err := upload.NewClient(cfg)
+	if err != nil {
+		fmt.Printf("⚠ Report upload failed: %v\n", err)
+		enqueue(reports)
+		return
+	}
+
+	if sent, err := queue.Flush(ctx, client); err != nil {
+		fmt.Printf("⚠ Report upload failed: %v\n", err)
+		enqueue(reports)
+		return
+	} else if sent > 0 {
+		fmt.Printf("✓ Submitted %d previously queued report(s).\n", sent)
+	}
+
+	for i, r := range reports {
+		if err := client.SubmitFile(ctx, r.Path); err != nil {
+			fmt.Printf("⚠ Report upload failed: %v\n", err)
+			enqueue(reports[i:])
+			return
+		}
+		fmt.Printf("✓ Report %s submitted.\n", r.ID)
+	}
+}
+
+// sendNotifications notifies the configured targets of each report. Like uploads,
+// notification problems never fail the verification itself.
+func sendNotifications(ctx context.Context, cfg *config.Config, reports []*report.Report) {
+	targets, err := notify.NewTargets(cfg.Notifications)
+	if err != nil {
+		fmt.Printf("⚠ Notifications not sent: %v\n", err)
+		return
+	}
+	loc, err := cfg.CLI.Location()
+	if err != nil {
+		fmt.Printf("⚠ Notifications not sent: %v\n", err)
+		return
+	}
+
+	for _, rpt := range reports {
+		msg, err := notify.FromReport(rpt, loc)
+		if err != nil {
+			fmt.Printf("⚠ Notifications not sent: %v\n", err)
+			return
+		}
+		for _, t := range targets {
+			if !t.Wants(rpt.Summary.Success) {
+				continue
+			}
+			if err := t.Send(ctx, msg); err != nil {
+				fmt.Printf("⚠ Notification failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("✓ Notified %s of report %s.\n", t.Name, rpt.ID)
+		}
+	}
+}
+
+// progressReporter returns the handler for restore progress events: a line per event
+// on stdout, JSON lines on stderr, or nothing.
+func progressReporter(mode string) (func(restore.ProgressEvent), error) {
+	switch mode {
+	case "text":
+		return func(e restore.ProgressEvent) {
+			elapsed := formatElapsed(e.Elapsed)
+			switch {
+			case e.Heartbeat:
+				fmt.Printf("  [%s] still working: %s %s\n", elapsed, e.Action, e.Object)
+			case e.Section == restore.SectionData && e.TablesTotal > 0:
+				fmt.Printf("  [%s] %s %d/%d: %s\n", elapsed, e.Section, e.TablesDone, e.TablesTotal, e.Object)
+			default:
+				fmt.Printf("  [%s] %s: %s %s\n", elapsed, e.Section, e.Action, e.Object)
+			}
+		}, nil
+	case "json":
+		enc := json.NewEncoder(os.Stderr)
+		return func(e restore.ProgressEvent) {
+			enc.Encode(e)
+		}, nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported progress output: %s (use text, json or none)", mode)
+	}
+}
+
+// formatElapsed formats d as hh:mm:ss.
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// runFailure tracks how far a run got, to report it when it ends before every
+// target has a report.
+type runFailure struct {
+	cfg          *config.Config
+	runID        string
+	mode         restore.Mode
+	profile      string
+	stage        string
+	backupSource string
+	reported     bool
+	// reportPath is where the failure report was written, if it was.
+	reportPath string
+
+	started      time.Time
+	stageStarted time.Time
+	durations    map[string]time.Duration
+}
+
+// enter records the end of the current stage and the start of the next.
+func (f *runFailure) enter(stage string) {
+	now := time.Now()
+	if f.durations == nil {
+		f.durations = make(map[string]time.Duration)
+	}
+	if f.stage != "" {
+		f.durations[f.stage] += now.Sub(f.stageStarted)
+	}
+	f.stage = stage
+	f.stageStarted = now
+}
+
+// stageDurations returns the time spent in each stage, counting the current one until now.
+func (f *runFailure) stageDurations(now time.Time) map[string]time.Duration {
+	durations := make(map[string]time.Duration, len(f.durations)+1)
+	for stage, d := range f.durations {
+		durations[stage] = d
+	}
+	if f.stage != "" {
+		durations[f.stage] += now.Sub(f.stageStarted)
+	}
+	return durations
+}
+
+// report writes, uploads and notifies a report whose only check records the error
+// that ended the run. Problems doing so are printed as warnings.
+func (f *runFailure) report(ctx context.Context, runErr error) {
+	fmt.Printf("\n✗ Verification did not complete (stage: %s).\n", f.stage)
+
+	rpt := report.NewReportBuilder().
+		WithID(uuid.New().String()).
+		WithRunID(f.runID).
+		WithProject(f.cfg.Project.ID, f.cfg.Project.Name).
+		WithMachineID(f.cfg.CLI.MachineID).
+		WithBackupSource(f.backupSource).
+		WithMode(string(f.mode)).
+		WithProfile(f.profile).
+		WithDatabase(f.cfg.Database.Type, f.cfg.Database.MajorVersion).
+		WithChecks([]verify.CheckResult{{
+			Name:    "verification_run",
+			Level:   verify.LevelCritical,
+			Passed:  false,
+			Message: fmt.Sprintf("Verification did not complete (stage: %s): %v", f.stage, runErr),
+		}}).
+		Build()
+
+	if privateKey, err := report.LoadPrivateKey(f.cfg.Signing.PrivateKeyPath); err != nil {
+		fmt.Printf("⚠ Failure report not signed: %v\n", err)
+	} else if err := report.Sign(rpt, privateKey); err != nil {
+		fmt.Printf("⚠ Failure report not signed: %v\n", err)
+	}
+
+	reportPath, err := report.WriteJSON(rpt, f.cfg.CLI.ReportDir)
+	if err != nil {
+		fmt.Printf("⚠ Failed to write failure report: %v\n", err)
+	} else {
+		fmt.Printf("✓ Failure report saved to %s\n", reportPath)
+		f.reportPath = reportPath
+		if f.cfg.Upload != nil {
+			submitReports(ctx, f.cfg.Upload, []cache.CachedReport{{ID: rpt.ID, Path: reportPath}})
+		}
+	}
+
+	if f.cfg.Notifications != nil {
+		sendNotifications(ctx, f.cfg, []*report.Report{rpt})
+	}
+}
+
+// openBaselineStore returns the baseline store, encrypting baselines at rest when configured.
+func openBaselineStore(ctx context.Context, cfg *config.Config) (*schema.BaselineStore, error) {
+	store, err := schema.NewBaselineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create baseline store: %w", err)
+	}
+	if cfg.Encryption == nil || !cfg.Encryption.EncryptBaselines {
+		return store, nil
+	}
+
+	decryptor, err := openDecryptor(ctx, cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline encryption key: %w", err)
+	}
+	cipher, err := crypto.NewAgeCipher(decryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline encryption key %s: %w", keyNames(cfg.Encryption), err)
+	}
+	return store.WithCipher(cipher), nil
+}
+
+// waitForSlot blocks until the run queue lets this run restore, and returns the
+// function that frees its slot.
+func waitForSlot(ctx context.Context, cfg *config.Config, runID string) (func(), error) {
+	q, err := queue.Open(cfg.CLI.RunQueue.Dir, cfg.CLI.RunQueue.MaxConcurrent)
+	if err != nil {
+		return nil, err
+	}
+	entry := queue.Entry{RunID: runID, ProjectID: cfg.Project.ID, Priority: cfg.Project.Priority}
+	waited := time.Now()
+	release, err := q.Acquire(ctx, entry, func(ahead int) {
+		fmt.Printf("Waiting for a restore slot (%d running at most, %d waiting ahead)...\n", q.MaxConcurrent(), ahead)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for a restore slot: %w", err)
+	}
+	if wait := time.Since(waited); wait >= time.Second {
+		fmt.Printf("✓ Restore slot acquired after %s.\n", wait.Round(time.Second))
+	}
+	return release, nil
+}
+
+// printCachedResult reports a cached verification result, failing like the original run did.
+func printCachedResult(entry *cache.ResultEntry) error {
+	fmt.Println("✓ This artifact was already verified with the current configuration (use --force to re-run).")
+	for _, r := range entry.Reports {
+		fmt.Printf("✓ Cached report: %s\n", r.Path)
+		fmt.Printf("\nVerification completed. Report ID: %s\n", r.ID)
+	}
+	if !entry.Success {
+		return &exitError{code: exitCritical, err: fmt.Errorf("cached verification result has critical failures")}
+	}
+	return nil
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// validateVerification checks the custom queries, null ratio settings,
+// relationships and required extensions of every logical database up front, so
+// a typo fails the run before the restore rather than after it.
+func validateVerification(cfg *config.Config) error {
+	check := func(v config.Verification) error {
+		if _, err := customQueries(v.CustomQueries); err != nil {
+			return err
+		}
+		if _, _, err := nullRatioSettings(v.NullRatios); err != nil {
+			return err
+		}
+		// Only PostgreSQL restores record extensions, so the check would always fail
+		if len(v.Extensions.Required) > 0 && cfg.Database.Type != "postgres" {
+			return fmt.Errorf("verification.extensions.required is not supported for database type: %s", cfg.Database.Type)
+		}
+		for i, r := range v.Relationships {
+			if r.Child == "" || r.Parent == "" {
+				return fmt.Errorf("verification.relationships[%d] needs both child and parent", i)
+			}
+		}
+		return nil
+	}
+	if err := check(cfg.Verification); err != nil {
+		return err
+	}
+	for _, db := range cfg.Database.LogicalDatabases {
+		if db.Verification == nil {
+			continue
+		}
+		if err := check(*db.Verification); err != nil {
+			return fmt.Errorf("logical database %s: %w", db.Name, err)
+		}
+	}
+	return nil
+}
+
+// relationships converts verification.relationships to checks.
+func relationships(rels []config.Relationship) []verify.Relationship {
+	out := make([]verify.Relationship, len(rels))
+	for i, r := range rels {
+		out[i] = verify.Relationship{Child: r.Child, Parent: r.Parent}
+	}
+	return out
+}
+
+// customQueries converts verification.custom_queries to checks.
+func customQueries(queries []config.CustomQuery) ([]verify.CustomQuery, error) {
+	specs := make([]verify.CustomQuery, 0, len(queries))
+	seen := make(map[string]bool, len(queries))
+	for i, q := range queries {
+		if q.Name == "" {
+			return nil, fmt.Errorf("verification.custom_queries[%d] has no name", i)
+		}
+		if seen[q.Name] {
+			return nil, fmt.Errorf("verification.custom_queries has two queries named %s", q.Name)
+		}
+		seen[q.Name] = true
+		if strings.TrimSpace(q.SQL) == "" {
+			return nil, fmt.Errorf("custom query %s has no sql", q.Name)
+		}
+		if q.MinRows == nil && q.MaxRows == nil && q.Equals == nil && !q.NonEmpty {
+			return nil, fmt.Errorf("custom query %s needs an expectation: min_rows, max_rows, equals or non_empty", q.Name)
+		}
+		if q.MinRows != nil && q.MaxRows != nil && *q.MinRows > *q.MaxRows {
+			return nil, fmt.Errorf("custom query %s has min_rows %d above max_rows %d", q.Name, *q.MinRows, *q.MaxRows)
+		}
+
+		level := verify.LevelCritical
+		switch verify.Level(q.Severity) {
+		case "", verify.LevelCritical:
+		case verify.LevelWarning, verify.LevelInfo:
+			level = verify.Level(q.Severity)
+		default:
+			return nil, fmt.Errorf("custom query %s has invalid severity %q (use critical, warning or info)", q.Name, q.Severity)
+		}
+		specs = append(specs, verify.CustomQuery{
+			Name:     q.Name,
+			SQL:      q.SQL,
+			MinRows:  q.MinRows,
+			MaxRows:  q.MaxRows,
+			Equals:   q.Equals,
+			NonEmpty: q.NonEmpty,
+			Level:    level,
+		})
+	}
+	return specs, nil
+}
+
+// checksumsEnabled reports whether table content checksums apply to this run.
+func checksumsEnabled(v config.Verification, mode restore.Mode) bool {
+	return v.Checksums.Enabled && mode != restore.ModeSchemaOnly
+}
+
+// recordChecksums copies the table checksums of s into metrics, so the next run
+// can compare against them.
+func recordChecksums(metrics *schema.Metrics, s *schema.Schema) {
+	checksums := make(map[string]string, len(s.Tables))
+	for _, t := range s.Tables {
+		checksums[t.Schema+"."+t.Name] = t.Checksum
+	}
+	for i := range metrics.TableMetrics {
+		tm := &metrics.TableMetrics[i]
+		tm.Checksum = checksums[tm.Schema+"."+tm.Name]
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(verifyCmd)
-	verifyCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	verifyCmd.Flags().CountVarP(&verbosity, "verbose", "v", "Increase verbosity: -v shows restore tool output, -vv adds step and check timings, -vvv logs every SQL statement")
+	verifyCmd.Flags().BoolVar(&forceVerify, "force", false, "Re-run verification even if a cached result exists")
+	verifyCmd.Flags().StringVar(&verifyMode, "mode", string(restore.ModeFull), "Verification mode: full, schema-only or data-only")
+	verifyCmd.Flags().StringVar(&progressMode, "progress", "text", "Restore progress output: text, json (JSON lines on stderr) or none")
+	verifyCmd.Flags().StringVar(&verifyProfile, "profile", "", "Apply a verification profile from the profiles section of config.yaml")
+	verifyCmd.Flags().StringVar(&summaryFile, "summary-file", "", "Write a JSON summary of the run (status, exit code, report paths, durations) to this path")
+	verifyCmd.Flags().BoolVar(&taskMode, "task-mode", false, "Run as an orchestrator task: write the result as JSON to fd 3, use the orchestrator's run ID and exit 0 or 1")
+	verifyCmd.Flags().StringSliceVar(&verifyTables, "tables", nil, "Canary run: restore and check only these tables (Postgres archive dumps), e.g. orders,billing.payments")
+	verifyCmd.Flags().StringVar(&executionFlag, "execution", "", "Where to run the verification: local or vm (default from execution.backend)")
+	verifyCmd.Flags().StringVar(&objectKey, "object-key", "", "Verify this S3 object instead of the one selected by backup.s3, e.g. to re-check an older backup")
+	verifyCmd.Flags().StringVar(&taskOutput, "task-output", "", "Write the task mode result to this file instead of fd 3, e.g. /airflow/xcom/return.json")
 }
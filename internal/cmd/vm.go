@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/report"
+	"restorable.io/restorable-cli/internal/schema"
+	"restorable.io/restorable-cli/internal/vm"
+)
+
+// Execution backends selected by execution.backend or verify --execution.
+const (
+	backendLocal = "local"
+	backendVM    = "vm"
+)
+
+// Paths on the VM, relative to the login user's home directory, where restorable
+// finds its configuration.
+const (
+	vmBinary      = ".restorable/bin/restorable"
+	vmConfig      = ".restorable/config.yaml"
+	vmSigningKey  = ".restorable/keys/signing.key"
+	vmAgeKey      = ".restorable/keys/age.key"
+	vmReportDir   = ".restorable/reports"
+	vmSchemaDir   = ".restorable/schemas"
+	vmEnvFile     = ".restorable/vm.env"
+	vmSummaryFile = ".restorable/vm-summary.json"
+)
+
+// executionBackend returns the backend for this run: the --execution flag, else
+// execution.backend, else local.
+func executionBackend(cfg *config.Config) (string, error) {
+	backend := executionFlag
+	if backend == "" && cfg.Execution != nil {
+		backend = cfg.Execution.Backend
+	}
+	switch backend {
+	case "", backendLocal:
+		return backendLocal, nil
+	case backendVM:
+		if cfg.Execution == nil || cfg.Execution.VM == nil {
+			return "", fmt.Errorf("the vm execution backend requires execution.vm in config.yaml")
+		}
+		return backendVM, nil
+	default:
+		return "", fmt.Errorf("invalid execution backend %q (use local or vm)", backend)
+	}
+}
+
+// verifyOnVM runs the verification on a VM created for this run: it uploads the
+// binary, configuration, keys and baselines, runs verify there, downloads the
+// signed reports and updated baselines, and deletes the VM. It returns the local
+// report paths and the number of warnings, and fails like the remote run did.
+func verifyOnVM(ctx context.Context, cfg *config.Config, failure *runFailure) (reportPaths []string, warnings int, err error) {
+	vmCfg := cfg.Execution.VM
+	if vmCfg.SSHKeyPath == "" {
+		return nil, 0, fmt.Errorf("execution.vm.ssh_key_path is required")
+	}
+	if cfg.Backup.Source == "local" {
+		return nil, 0, fmt.Errorf("backup.source local cannot be verified on a VM; the backup file is only on this host")
+	}
+	binary := vmCfg.Binary
+	if binary == "" {
+		if runtime.GOOS != "linux" {
+			return nil, 0, fmt.Errorf("execution.vm.binary is required when running on %s; point it at a Linux build of restorable", runtime.GOOS)
+		}
+		if binary, err = os.Executable(); err != nil {
+			return nil, 0, fmt.Errorf("failed to locate the restorable binary: %w", err)
+		}
+	}
+	remoteConfig, err := vmConfigFile(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	provider, err := vm.NewProvider(vmCfg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	failure.enter("provision")
+	bootTimeout := time.Duration(vmCfg.BootTimeoutMinutes) * time.Minute
+	if bootTimeout <= 0 {
+		bootTimeout = 10 * time.Minute
+	}
+	// The VM proves its identity with a host key generated for this run
+	hostKey, err := vm.NewHostKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	userData, err := hostKey.UserData(vmCfg.UserData)
+	if err != nil {
+		return nil, 0, err
+	}
+	tag := vmRunTag(failure.runID)
+	name := "restorable-" + tag
+	fmt.Printf("Creating %s VM %s (%s) for run %s...\n", provider.Name(), name, vmCfg.Type, failure.runID)
+	bootCtx, cancel := context.WithTimeout(ctx, bootTimeout)
+	machine, err := provider.Create(bootCtx, name, map[string]string{"restorable-run": tag}, userData)
+	cancel()
+	if machine != nil {
+		defer func() {
+			if err != nil && vmCfg.KeepOnFailure {
+				fmt.Printf("⚠ Keeping VM %s (%s, %s) for debugging; delete it when done.\n", machine.Name, machine.ID, machine.Address)
+				return
+			}
+			if derr := provider.Delete(context.Background(), machine); derr != nil {
+				fmt.Printf("⚠ %v; delete it manually.\n", derr)
+				return
+			}
+			fmt.Printf("✓ VM %s deleted.\n", machine.Name)
+		}()
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	fmt.Printf("✓ VM %s running at %s.\n", machine.Name, machine.Address)
+
+	session, err := vm.Connect(ctx, machine, vm.DefaultUser(vmCfg), vmCfg.SSHKeyPath, hostKey, bootTimeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer session.Close()
+	if err := waitForDocker(ctx, session, bootTimeout); err != nil {
+		return nil, 0, err
+	}
+
+	// A safety net in case this host dies before deleting the VM
+	lifetime := vmCfg.MaxLifetimeMinutes
+	if lifetime <= 0 {
+		lifetime = 360
+	}
+	if _, err := session.Output(fmt.Sprintf(`if [ "$(id -u)" -eq 0 ]; then shutdown -h +%[1]d; else sudo -n shutdown -h +%[1]d; fi`, lifetime)); err != nil {
+		fmt.Printf("⚠ Could not schedule the VM to power off after %d minutes: %v\n", lifetime, err)
+	}
+
+	if err := uploadRunFiles(session, cfg, binary, remoteConfig); err != nil {
+		return nil, 0, err
+	}
+	fmt.Println("✓ Binary, configuration, keys and baselines copied to the VM.")
+
+	failure.enter("remote")
+	fmt.Printf("Running verification on %s...\n", machine.Name)
+	command := fmt.Sprintf("cd && set -a && . ./%s && set +a && ./%s verify %s", vmEnvFile, vmBinary, strings.Join(remoteVerifyArgs(), " "))
+	exitCode, err := session.Run(command, os.Stdout, os.Stderr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	failure.enter("collect")
+	summary, err := session.ReadFile(vmSummaryFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("remote verification exited with code %d without a summary: %w", exitCode, err)
+	}
+	var result runSummary
+	if err := json.Unmarshal(summary, &result); err != nil {
+		return nil, 0, fmt.Errorf("invalid remote run summary: %w", err)
+	}
+	// The remote run wrote its own report, failure report included
+	failure.reported = true
+
+	if err := os.MkdirAll(cfg.CLI.ReportDir, 0755); err != nil {
+		return nil, 0, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	for _, remotePath := range result.ReportPaths {
+		localPath := filepath.Join(cfg.CLI.ReportDir, path.Base(remotePath))
+		if err := session.Download(remotePath, localPath); err != nil {
+			return nil, 0, err
+		}
+		reportPaths = append(reportPaths, localPath)
+		fmt.Printf("✓ Report downloaded to %s\n", localPath)
+	}
+	if err := downloadBaselines(session, cfg.Project.ID); err != nil {
+		fmt.Printf("⚠ Baselines not updated: %v\n", err)
+	}
+
+	if result.Status == statusWarning {
+		warnings = 1
+	}
+	if result.ExitCode != 0 {
+		return reportPaths, warnings, &exitError{code: result.ExitCode, err: errors.New(result.Error)}
+	}
+	return reportPaths, warnings, nil
+}
+
+// vmConfigFile returns the configuration for the remote run: key paths point at
+// the uploaded copies, reports go to the VM's report directory, and settings that
+// only make sense on this host are dropped.
+func vmConfigFile(cfg *config.Config) ([]byte, error) {
+	remote := *cfg
+	remote.Execution = nil
+	remote.CLI.ReportDir = vmReportDir
+	remote.CLI.TempDir = ""
+	remote.CLI.ArtifactCache = nil
+	remote.CLI.RunQueue = nil
+	remote.Signing.PrivateKeyPath = vmSigningKey
+	if cfg.Encryption != nil && len(cfg.Encryption.KeyPaths()) > 0 {
+		encryption := *cfg.Encryption
+		encryption.PrivateKeyPath = ""
+		encryption.PrivateKeyPaths = vmAgeKeys(len(cfg.Encryption.KeyPaths()))
+		remote.Encryption = &encryption
+	}
+	data, err := yaml.Marshal(&remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the VM configuration: %w", err)
+	}
+	return data, nil
+}
+
+// vmAgeKeys returns the VM paths of n age key files, in configured order.
+func vmAgeKeys(n int) []string {
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = vmAgeKey
+		if i > 0 {
+			paths[i] = fmt.Sprintf(".restorable/keys/age-%d.key", i+1)
+		}
+	}
+	return paths
+}
+
+// waitForDocker waits for cloud-init, where present, and for the Docker daemon.
+func waitForDocker(ctx context.Context, session *vm.Session, timeout time.Duration) error {
+	const check = "if command -v cloud-init >/dev/null; then cloud-init status --wait >/dev/null; fi; docker info >/dev/null"
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := session.Output(check)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("docker is not available on the VM; install it in the image or with execution.vm.user_data: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// uploadRunFiles copies what the remote run needs: the binary, configuration,
+// keys, environment, baselines and the metrics history for adaptive thresholds.
+func uploadRunFiles(session *vm.Session, cfg *config.Config, binary string, remoteConfig []byte) error {
+	if err := session.Upload(binary, vmBinary, 0755); err != nil {
+		return err
+	}
+	if err := session.Write(vmConfig, bytes.NewReader(remoteConfig), 0600); err != nil {
+		return err
+	}
+	if err := session.Upload(cfg.Signing.PrivateKeyPath, vmSigningKey, 0600); err != nil {
+		return err
+	}
+	if cfg.Encryption != nil {
+		paths := cfg.Encryption.KeyPaths()
+		for i, remotePath := range vmAgeKeys(len(paths)) {
+			if err := session.Upload(paths[i], remotePath, 0600); err != nil {
+				return err
+			}
+		}
+	}
+
+	var env strings.Builder
+	for _, name := range cfg.Execution.VM.Env {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			fmt.Printf("⚠ %s is not set and is not passed to the VM.\n", name)
+			continue
+		}
+		fmt.Fprintf(&env, "%s=%s\n", name, shellQuote(value))
+	}
+	if err := session.Write(vmEnvFile, strings.NewReader(env.String()), 0600); err != nil {
+		return err
+	}
+
+	store, err := schema.NewBaselineStore()
+	if err != nil {
+		return err
+	}
+	baselines, err := os.ReadDir(store.Dir())
+	if err != nil {
+		return fmt.Errorf("failed to read baselines: %w", err)
+	}
+	for _, entry := range baselines {
+		if entry.Type().IsRegular() && strings.HasPrefix(entry.Name(), cfg.Project.ID) {
+			if err := session.Upload(filepath.Join(store.Dir(), entry.Name()), path.Join(vmSchemaDir, entry.Name()), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	reports, err := report.ListReports(cfg.CLI.ReportDir)
+	if err != nil {
+		return err
+	}
+	history := 0
+	for _, r := range reports {
+		if history == adaptiveHistoryRuns(cfg.Verification.Adaptive) {
+			break
+		}
+		if !strings.HasPrefix(r.ProjectID, cfg.Project.ID) {
+			continue
+		}
+		if err := session.Upload(r.Path, path.Join(vmReportDir, filepath.Base(r.Path)), 0644); err != nil {
+			return err
+		}
+		history++
+	}
+	return nil
+}
+
+// downloadBaselines copies the project's baselines back after the remote run,
+// which saves a new baseline when the schema was accepted.
+func downloadBaselines(session *vm.Session, projectID string) error {
+	store, err := schema.NewBaselineStore()
+	if err != nil {
+		return err
+	}
+	names, err := session.ReadDir(vmSchemaDir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, projectID) {
+			continue
+		}
+		if err := session.Download(path.Join(vmSchemaDir, name), filepath.Join(store.Dir(), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteVerifyArgs returns the verify flags of this run for the remote run,
+// which writes its summary to a known file.
+func remoteVerifyArgs() []string {
+	args := []string{"--summary-file", vmSummaryFile, "--mode", shellQuote(verifyMode), "--progress", shellQuote(progressMode)}
+	if verbosity > 0 {
+		args = append(args, "-"+strings.Repeat("v", verbosity))
+	}
+	if forceVerify {
+		args = append(args, "--force")
+	}
+	if verifyProfile != "" {
+		args = append(args, "--profile", shellQuote(verifyProfile))
+	}
+	if len(verifyTables) > 0 {
+		args = append(args, "--tables", shellQuote(strings.Join(verifyTables, ",")))
+	}
+	if objectKey != "" {
+		args = append(args, "--object-key", shellQuote(objectKey))
+	}
+	return args
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// vmRunTag identifies a run in VM names and labels. Run IDs from task mode may
+// be short or hold characters that providers reject, such as the colons and plus
+// signs of Airflow run IDs, so a hash of the ID is used.
+func vmRunTag(runID string) string {
+	sum := sha256.Sum256([]byte(runID))
+	return hex.EncodeToString(sum[:])[:8]
+}
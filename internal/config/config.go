@@ -4,32 +4,107 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	// Embedded so timezones resolve on hosts and images without tzdata
+	_ "time/tzdata"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config matches the structure of the config.yaml file.
 type Config struct {
-	Version      int           `yaml:"version"`
-	Project      Project       `yaml:"project"`
-	CLI          CLI           `yaml:"cli"`
-	Backup       Backup        `yaml:"backup"`
-	Encryption   *Encryption   `yaml:"encryption,omitempty"`
-	Database     Database      `yaml:"database"`
-	Verification Verification  `yaml:"verification"`
-	Docker       Docker        `yaml:"docker"`
-	Signing      Signing       `yaml:"signing"`
+	Version      int          `yaml:"version"`
+	Project      Project      `yaml:"project"`
+	CLI          CLI          `yaml:"cli"`
+	Backup       Backup       `yaml:"backup"`
+	Encryption   *Encryption  `yaml:"encryption,omitempty"`
+	Database     Database     `yaml:"database"`
+	Verification Verification `yaml:"verification"`
+	Docker       Docker       `yaml:"docker"`
+	Signing      Signing      `yaml:"signing"`
+	Sync         *Sync        `yaml:"sync,omitempty"`
+	Upload       *Upload      `yaml:"upload,omitempty"`
+	// Notifications sends verification results to chat channels and webhooks.
+	Notifications *Notifications `yaml:"notifications,omitempty"`
+	// Execution selects where verify runs. Defaults to the local host.
+	Execution *Execution `yaml:"execution,omitempty"`
+	// Profiles are named variations of a run, selected with verify --profile.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
 }
 
 type Project struct {
 	ID   string `yaml:"id"`
 	Name string `yaml:"name"`
+	// Priority orders this project's runs in the run queue; higher runs first.
+	Priority int `yaml:"priority,omitempty"`
 }
 
 type CLI struct {
-	MachineID string `yaml:"machine_id"`
-	ReportDir string `yaml:"report_dir"`
-	TempDir   string `yaml:"temp_dir"`
+	MachineID     string         `yaml:"machine_id"`
+	ReportDir     string         `yaml:"report_dir"`
+	TempDir       string         `yaml:"temp_dir"`
+	ArtifactCache *ArtifactCache `yaml:"artifact_cache,omitempty"`
+	// ReportRetention deletes old reports from ReportDir after each run.
+	ReportRetention *ReportRetention `yaml:"report_retention,omitempty"`
+	// RunQueue limits how many verifications restore at once on this host.
+	RunQueue *RunQueue `yaml:"run_queue,omitempty"`
+	// DiskSpace tunes the free space check made before a backup is downloaded.
+	DiskSpace *DiskSpace `yaml:"disk_space,omitempty"`
+	// Timezone is the IANA zone timestamps are displayed in, e.g. Europe/Berlin.
+	// Timestamps are always stored in UTC. Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// Location returns the display timezone.
+func (c CLI) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// DiskSpace tunes the check that the verification host has room to download and
+// restore a backup before either starts.
+type DiskSpace struct {
+	// Disabled skips the check.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// ExpansionFactor estimates the restored database size as a multiple of the
+	// artifact size. Defaults to 3.
+	ExpansionFactor float64 `yaml:"expansion_factor,omitempty"`
+}
+
+// RunQueue makes concurrent verify runs wait for a restore slot, so several
+// projects scheduled at once do not exhaust the verification host.
+type RunQueue struct {
+	// Dir defaults to ~/.restorable/queue/runs. Point configs at the same directory
+	// to share one limit between them.
+	Dir string `yaml:"dir,omitempty"`
+	// MaxConcurrent is the number of runs restoring at once. Defaults to 2.
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+}
+
+// ReportRetention bounds how long reports are kept on the verification host.
+type ReportRetention struct {
+	// Days is how long reports are kept; 0 keeps them forever.
+	Days int `yaml:"days"`
+	// RequireArchived only deletes reports held by the sync bucket or the upload
+	// endpoint, copying them there first, so pruning never destroys the only copy.
+	RequireArchived bool `yaml:"require_archived,omitempty"`
+}
+
+// ArtifactCache keeps downloaded artifacts on the verification host so repeated
+// runs against the same object skip the download.
+type ArtifactCache struct {
+	// Dir defaults to artifact-cache in TempDir, or ~/.restorable/cache/artifacts.
+	Dir string `yaml:"dir,omitempty"`
+	// MaxSizeMB bounds the cache; least recently used artifacts are evicted. Defaults to 10240.
+	MaxSizeMB int `yaml:"max_size_mb,omitempty"`
 }
 
 type Local struct {
@@ -37,15 +112,146 @@ type Local struct {
 }
 
 type Command struct {
-	Exec string `yaml:"exec"`
+	// Exec is a shell command run with sh -c.
+	Exec string `yaml:"exec,omitempty"`
+	// Argv runs a program with explicit arguments instead of a shell command.
+	Argv []string `yaml:"argv,omitempty"`
+	// Dir is the working directory of the command.
+	Dir string `yaml:"dir,omitempty"`
+	// ScrubEnv runs the command with only PATH, HOME, RESTORABLE_RUN_ID and PassEnv.
+	ScrubEnv bool     `yaml:"scrub_env,omitempty"`
+	PassEnv  []string `yaml:"pass_env,omitempty"`
 }
 
 type Backup struct {
-	Source        string   `yaml:"source"`
-	Local         *Local   `yaml:"local,omitempty"`
-	S3            *S3      `yaml:"s3,omitempty"`
-	Command       *Command `yaml:"command,omitempty"`
+	Source  string   `yaml:"source"`
+	Local   *Local   `yaml:"local,omitempty"`
+	S3      *S3      `yaml:"s3,omitempty"`
+	Command *Command `yaml:"command,omitempty"`
+	WALG    *WALG    `yaml:"walg,omitempty"`
+	SFTP    *SFTP    `yaml:"sftp,omitempty"`
+	HTTP    *HTTP    `yaml:"http,omitempty"`
+	Rsync   *Rsync   `yaml:"rsync,omitempty"`
+	K8s     *K8s     `yaml:"k8s,omitempty"`
+	// Chain lists the sources tried in order when Source is "chain".
+	Chain         []Backup `yaml:"chain,omitempty"`
 	RetentionDays int      `yaml:"retention_days"`
+	// Retry retries failed acquisitions and resumes interrupted S3 and SFTP downloads.
+	Retry *Retry `yaml:"retry,omitempty"`
+	// Checksum verifies the artifact against a published checksum before restore.
+	Checksum *Checksum `yaml:"checksum,omitempty"`
+	// ArchiveMember selects the file to restore from a tar artifact by path or
+	// glob pattern. A tar holding a single file is unpacked without it.
+	ArchiveMember string `yaml:"archive_member,omitempty"`
+}
+
+// Checksum configures verification of the artifact against checksum sidecars
+// (<artifact>.sha256, .sha256sum, .md5 or .md5sum) or a manifest next to it.
+type Checksum struct {
+	// Manifest names a file next to the artifact in sha256sum or md5sum format,
+	// e.g. SHA256SUMS, consulted when there is no sidecar.
+	Manifest string `yaml:"manifest,omitempty"`
+	// Required fails verification when no checksum is published for the artifact.
+	Required bool `yaml:"required,omitempty"`
+}
+
+// Retry is the retry policy for acquiring a backup.
+type Retry struct {
+	// Attempts is the total number of tries, including the first. Interrupted
+	// downloads are resumed up to Attempts-1 times. Defaults to 1.
+	Attempts int `yaml:"attempts,omitempty"`
+	// InitialBackoffSeconds before the first retry, doubling after each. Defaults to 5.
+	InitialBackoffSeconds int `yaml:"initial_backoff_seconds,omitempty"`
+	// MaxBackoffSeconds caps the backoff. Defaults to 300.
+	MaxBackoffSeconds int `yaml:"max_backoff_seconds,omitempty"`
+}
+
+// SFTP streams a backup file from an SFTP server.
+type SFTP struct {
+	Host string `yaml:"host"`
+	// Port defaults to 22.
+	Port int `yaml:"port,omitempty"`
+	// User defaults to the local user.
+	User string `yaml:"user,omitempty"`
+	// KeyPath is an unencrypted private key for public key authentication.
+	KeyPath string `yaml:"key_path,omitempty"`
+	// PasswordEnv names the environment variable holding the password.
+	PasswordEnv string `yaml:"password_env,omitempty"`
+	// KnownHostsPath verifies the server's host key. Defaults to ~/.ssh/known_hosts.
+	KnownHostsPath string `yaml:"known_hosts_path,omitempty"`
+	// Path is the remote file, or a glob whose most recently modified match is used.
+	Path string `yaml:"path"`
+}
+
+// Rsync pulls a backup file from a remote host with rsync over SSH.
+type Rsync struct {
+	Host string `yaml:"host"`
+	// Port defaults to 22.
+	Port int `yaml:"port,omitempty"`
+	// User defaults to the local user.
+	User string `yaml:"user,omitempty"`
+	// KeyPath is the private key passed to ssh; defaults to ssh's own configuration.
+	KeyPath string `yaml:"key_path,omitempty"`
+	// KnownHostsPath verifies the host key. Defaults to ssh's known hosts files.
+	KnownHostsPath string `yaml:"known_hosts_path,omitempty"`
+	// HostKeyChecking is "strict" (default), or "accept-new" to trust and record
+	// the key of a host seen for the first time.
+	HostKeyChecking string `yaml:"host_key_checking,omitempty"`
+	// Path is the remote file, or a glob whose most recently modified match is used.
+	Path string `yaml:"path"`
+	// WorkDir holds the downloaded file until it has been read. Defaults to the
+	// system temp directory.
+	WorkDir string `yaml:"work_dir,omitempty"`
+}
+
+// K8s streams a backup out of a container in a Kubernetes pod with kubectl exec,
+// either by reading a file or by running a dump command there.
+type K8s struct {
+	// Kubeconfig defaults to kubectl's own resolution: $KUBECONFIG, ~/.kube/config,
+	// or the in-cluster service account when running in a pod.
+	Kubeconfig string `yaml:"kubeconfig,omitempty"`
+	Context    string `yaml:"context,omitempty"`
+	// Namespace defaults to the namespace of the kubeconfig context.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Pod names the pod; Selector picks the first running pod matching a label selector.
+	Pod      string `yaml:"pod,omitempty"`
+	Selector string `yaml:"selector,omitempty"`
+	// Container defaults to the pod's default container.
+	Container string `yaml:"container,omitempty"`
+	// Path is a file in the container, or a glob whose most recently modified match is used.
+	Path string `yaml:"path,omitempty"`
+	// Command runs in the container and its stdout is the backup, e.g. pg_dump -Fc.
+	Command []string `yaml:"command,omitempty"`
+}
+
+// HTTP downloads a backup from an HTTP or HTTPS URL.
+type HTTP struct {
+	URL string `yaml:"url,omitempty"`
+	// URLEnv names an environment variable holding the URL, for signed URLs.
+	URLEnv string `yaml:"url_env,omitempty"`
+	// BearerTokenEnv names the environment variable holding a bearer token.
+	BearerTokenEnv string `yaml:"bearer_token_env,omitempty"`
+	// BasicUser and BasicPasswordEnv configure basic authentication.
+	BasicUser        string `yaml:"basic_user,omitempty"`
+	BasicPasswordEnv string `yaml:"basic_password_env,omitempty"`
+	// Headers are added to every request; values may reference environment variables.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Retries of failed requests and interrupted downloads. Defaults to 3.
+	Retries int `yaml:"retries,omitempty"`
+}
+
+// WALG fetches a base backup plus WAL from a WAL-G repository for point-in-time recovery.
+type WALG struct {
+	// Binary is the wal-g executable. Defaults to "wal-g" on PATH.
+	Binary string `yaml:"binary,omitempty"`
+	// Backup is the base backup name. Defaults to LATEST.
+	Backup string `yaml:"backup,omitempty"`
+	// TargetTime is the RFC 3339 recovery target. Empty replays all archived WAL.
+	TargetTime string `yaml:"target_time,omitempty"`
+	// Env is passed to wal-g, e.g. WALG_S3_PREFIX; values may reference environment variables.
+	Env map[string]string `yaml:"env,omitempty"`
+	// WorkDir holds the fetched backup. Defaults to the system temp directory.
+	WorkDir string `yaml:"work_dir,omitempty"`
 }
 
 type S3 struct {
@@ -55,34 +261,228 @@ type S3 struct {
 	AccessKeyEnv string `yaml:"access_key_env"`
 	SecretKeyEnv string `yaml:"secret_key_env"`
 	Prefix       string `yaml:"prefix"`
+	// Key fetches this exact object, bypassing prefix and match selection.
+	Key string `yaml:"key,omitempty"`
+	// Match selects objects by a glob over the whole key, e.g. "backups/prod-*.dump.age".
+	Match string `yaml:"match,omitempty"`
+	// Regex selects objects by a regular expression over the whole key.
+	Regex string `yaml:"regex,omitempty"`
+	// Order picks among the selected objects: modified (default) takes the most
+	// recently modified, name the last key in lexicographic order and timestamp the
+	// latest date and time in the file name.
+	Order string `yaml:"order,omitempty"`
+	// ArchiveRestore restores objects in Glacier or Deep Archive before downloading.
+	ArchiveRestore *ArchiveRestore `yaml:"archive_restore,omitempty"`
+	// ServerSideEncryption supplies the key of SSE-C objects or pins the KMS key
+	// of SSE-KMS objects.
+	ServerSideEncryption *ServerSideEncryption `yaml:"server_side_encryption,omitempty"`
+}
+
+// ServerSideEncryption configures S3 server-side encryption.
+type ServerSideEncryption struct {
+	// CustomerKeyEnv names the environment variable holding the base64-encoded
+	// 256-bit key of SSE-C objects, which is sent with every object request.
+	CustomerKeyEnv string `yaml:"customer_key_env,omitempty"`
+	// KMSKeyID is the KMS key ID or ARN objects must be encrypted with. Uploads
+	// are encrypted with it.
+	KMSKeyID string `yaml:"kms_key_id,omitempty"`
 }
 
+// ArchiveRestore controls the restore request issued for archived S3 objects.
+type ArchiveRestore struct {
+	// Tier is Standard, Bulk or Expedited. Defaults to Standard.
+	Tier string `yaml:"tier,omitempty"`
+	// Days the restored copy stays available. Defaults to 1.
+	Days int `yaml:"days,omitempty"`
+	// MaxWaitMinutes bounds how long to wait for the restore. Defaults to 720.
+	MaxWaitMinutes int `yaml:"max_wait_minutes,omitempty"`
+	// PollIntervalSeconds between restore status checks. Defaults to 60.
+	PollIntervalSeconds int `yaml:"poll_interval_seconds,omitempty"`
+}
+
+// Encryption methods.
+const (
+	EncryptionAge     = "age"
+	EncryptionGCPKMS  = "gcp_kms"
+	EncryptionOpenSSL = "openssl"
+)
+
 type Encryption struct {
-	Method         string `yaml:"method"`
+	Method string `yaml:"method"`
+	// PrivateKeyPath is the age private key file. With the gcp_kms method, the file
+	// holds the key encrypted with the Cloud KMS key.
 	PrivateKeyPath string `yaml:"private_key_path"`
+	// PrivateKeyPaths are further key files, tried in order after PrivateKeyPath,
+	// so backups encrypted before a key rotation can still be verified.
+	PrivateKeyPaths []string `yaml:"private_key_paths,omitempty"`
+	// PrivateKeyEnv is an environment variable holding an age identity, tried
+	// before the key files, for CI runners that receive the key as a secret.
+	PrivateKeyEnv string `yaml:"private_key_env,omitempty"`
+	// GCPKMS is the Cloud KMS key that unwraps the private key.
+	GCPKMS *GCPKMS `yaml:"gcp_kms,omitempty"`
+	// OpenSSL configures the openssl method, which has no private key.
+	OpenSSL *OpenSSL `yaml:"openssl,omitempty"`
+	// Recipients are the age public keys backups are encrypted to. restorable
+	// crypto test checks that the private key decrypts data encrypted to each.
+	Recipients []string `yaml:"recipients,omitempty"`
+	// EncryptBaselines encrypts stored baseline schemas to the key's recipient.
+	EncryptBaselines bool `yaml:"encrypt_baselines"`
+}
+
+// KeyPaths returns PrivateKeyPath, if set, followed by PrivateKeyPaths.
+func (e *Encryption) KeyPaths() []string {
+	var paths []string
+	if e.PrivateKeyPath != "" {
+		paths = append(paths, e.PrivateKeyPath)
+	}
+	return append(paths, e.PrivateKeyPaths...)
+}
+
+// GCPKMS identifies a Google Cloud KMS key, used with Application Default Credentials.
+type GCPKMS struct {
+	// KeyName is projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>.
+	KeyName string `yaml:"key_name"`
+}
+
+// OpenSSL matches the options backups were encrypted with by
+// openssl enc -aes-256-cbc -pbkdf2.
+type OpenSSL struct {
+	// PassphraseEnv is the environment variable holding the passphrase.
+	PassphraseEnv string `yaml:"passphrase_env"`
+	// Iterations is the -iter count. Defaults to 10000, openssl's default.
+	Iterations int `yaml:"iterations,omitempty"`
+	// Digest is the -md digest: sha256 (default), sha512 or sha1.
+	Digest string `yaml:"digest,omitempty"`
 }
 
 type Database struct {
-	Type         string  `yaml:"type"`
-	MajorVersion int     `yaml:"major_version"`
-	Restore      Restore `yaml:"restore"`
+	Type             string            `yaml:"type"`
+	MajorVersion     int               `yaml:"major_version"`
+	Restore          Restore           `yaml:"restore"`
+	LogicalDatabases []LogicalDatabase `yaml:"logical_databases,omitempty"`
+}
+
+// LogicalDatabase maps a database inside a multi-database artifact to its own
+// verification profile, baseline and report.
+type LogicalDatabase struct {
+	Name         string        `yaml:"name"`
+	ProjectID    string        `yaml:"project_id,omitempty"`
+	ProjectName  string        `yaml:"project_name,omitempty"`
+	Verification *Verification `yaml:"verification,omitempty"`
 }
 
 type Restore struct {
-	DockerImage string `yaml:"docker_image"`
-	User        string `yaml:"user"`
-	PasswordEnv string `yaml:"password_env"`
-	DBName      string `yaml:"db_name"`
-	Port        int    `yaml:"port"`
+	DockerImage string    `yaml:"docker_image"`
+	User        string    `yaml:"user"`
+	PasswordEnv string    `yaml:"password_env"`
+	DBName      string    `yaml:"db_name"`
+	Port        int       `yaml:"port"`
+	DataOnly    *DataOnly `yaml:"data_only,omitempty"`
+	// Roles are created before the restore so ownership and grants referencing them restore.
+	Roles []string `yaml:"roles,omitempty"`
+	// PreserveOwnership restores object owners instead of assigning everything to User.
+	PreserveOwnership bool `yaml:"preserve_ownership,omitempty"`
+	// Jobs is the number of parallel pg_restore jobs. Defaults to 4 for directory-format
+	// dumps and 1 otherwise.
+	Jobs int `yaml:"jobs,omitempty"`
+	// XtraBackupImage prepares MySQL physical backups. Defaults to the Percona image
+	// for the major version.
+	XtraBackupImage string `yaml:"xtrabackup_image,omitempty"`
+	// Binlogs replays MySQL or MariaDB binary logs on top of the restored snapshot.
+	Binlogs *Binlogs `yaml:"binlogs,omitempty"`
+}
+
+// Binlogs locates the binary logs to replay after a MySQL or MariaDB restore.
+type Binlogs struct {
+	// Path is a directory holding the source's binary log files.
+	Path string `yaml:"path"`
+	// TargetTime is the RFC 3339 recovery target. Empty replays all binlogs.
+	TargetTime string `yaml:"target_time,omitempty"`
+}
+
+// DataOnly describes how the target schema is prepared for data-only restores.
+type DataOnly struct {
+	// Image is a database image with the application schema already applied.
+	Image string `yaml:"image,omitempty"`
+	// InitScripts are SQL files or directories of SQL files applied in order.
+	InitScripts []string `yaml:"init_scripts,omitempty"`
 }
 
 type Verification struct {
 	Schema    SchemaVerification `yaml:"schema"`
 	RowCounts RowCounts          `yaml:"row_counts"`
+	Checksums Checksums          `yaml:"checksums"`
+	Adaptive  Adaptive           `yaml:"adaptive"`
+	// NullRatios compares the NULL ratio of critical columns with the previous run.
+	NullRatios NullRatios `yaml:"null_ratios"`
+	// TableSizes compares table row counts and sizes with their trend across runs.
+	TableSizes TableSizes `yaml:"table_sizes"`
+	// ColumnProfiles profiles selected wide columns to catch systemic truncation.
+	ColumnProfiles ColumnProfiles `yaml:"column_profiles"`
+	Extensions     Extensions     `yaml:"extensions"`
+	// Amcheck checks the B-tree indexes of the restored database for corruption.
+	Amcheck Amcheck `yaml:"amcheck"`
+	Views   Views   `yaml:"views"`
+	// Freshness fails verification when the backup is older than a maximum age.
+	Freshness Freshness `yaml:"freshness"`
+	// Rehearsal runs an application smoke test against the restored database.
+	Rehearsal *Rehearsal `yaml:"rehearsal,omitempty"`
+	// CustomQueries check business invariants with SQL run against the restored database.
+	CustomQueries []CustomQuery `yaml:"custom_queries,omitempty"`
+	// Relationships declare parent/child relations that the schema does not
+	// enforce with foreign keys, checked for orphaned child rows.
+	Relationships []Relationship `yaml:"relationships,omitempty"`
+}
+
+// Relationship declares that every non-NULL value of Child is a value of Parent.
+// Both are given as schema.table.column, or table.column in the default schema.
+type Relationship struct {
+	Child  string `yaml:"child"`
+	Parent string `yaml:"parent"`
+}
+
+// CustomQuery is a named SQL query and the result it must produce. At least one
+// of MinRows, MaxRows, Equals and NonEmpty is required.
+type CustomQuery struct {
+	Name string `yaml:"name"`
+	SQL  string `yaml:"sql"`
+	// MinRows and MaxRows bound the number of rows returned.
+	MinRows *int `yaml:"min_rows,omitempty"`
+	MaxRows *int `yaml:"max_rows,omitempty"`
+	// Equals is the expected first column of the first row, compared as text.
+	Equals *string `yaml:"equals,omitempty"`
+	// NonEmpty requires at least one row.
+	NonEmpty bool `yaml:"non_empty,omitempty"`
+	// Severity is critical (default), warning or info.
+	Severity string `yaml:"severity,omitempty"`
 }
 
 type SchemaVerification struct {
 	Enabled bool `yaml:"enabled"`
+	// AnnotationsPath is a file declaring planned table changes, so verifications
+	// during a deploy window don't fail on expected drift.
+	AnnotationsPath string `yaml:"annotations_path,omitempty"`
+}
+
+// Freshness checks the age of the artifact, taken from the source's modification
+// time or the producer metadata's created_at, and of the newest rows of selected
+// timestamp columns.
+type Freshness struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxAgeHours is the oldest acceptable backup. Defaults to 26, a daily
+	// schedule with some slack.
+	MaxAgeHours int `yaml:"max_age_hours,omitempty"`
+	// Columns are timestamp columns whose newest value must be recent. They are
+	// checked whether or not Enabled is set.
+	Columns []FreshnessColumn `yaml:"columns,omitempty"`
+}
+
+// FreshnessColumn is a timestamp column and the oldest acceptable newest value.
+type FreshnessColumn struct {
+	// Column is given as schema.table.column, or table.column in the default schema.
+	Column string `yaml:"column"`
+	// MaxAgeHours defaults to the freshness max_age_hours.
+	MaxAgeHours int `yaml:"max_age_hours,omitempty"`
 }
 
 type RowCounts struct {
@@ -90,28 +490,271 @@ type RowCounts struct {
 	WarnThresholdPercent int  `yaml:"warn_threshold_percent"`
 }
 
+type Checksums struct {
+	Enabled bool `yaml:"enabled"`
+	// Samples hash selected rows of critical tables and compare them with the
+	// previous run. They are hashed whether or not Enabled is set.
+	Samples []ChecksumSample `yaml:"samples,omitempty"`
+}
+
+// ChecksumSample selects the rows of a table to hash. Samples should select rows
+// that no longer change, e.g. the oldest by primary key, so that a different
+// hash means corruption rather than new writes.
+type ChecksumSample struct {
+	// Table is given as schema.table, or table in the default schema.
+	Table string `yaml:"table"`
+	// Where is an SQL predicate restricting the rows.
+	Where string `yaml:"where,omitempty"`
+	// OrderBy is an SQL ORDER BY list. Defaults to the primary key.
+	OrderBy string `yaml:"order_by,omitempty"`
+	// Limit is the number of rows hashed. Defaults to 1000.
+	Limit int `yaml:"limit,omitempty"`
+}
+
+// Adaptive derives row count and duration thresholds from previous runs instead
+// of fixed values.
+type Adaptive struct {
+	Enabled bool `yaml:"enabled"`
+	// Percentile of previous runs beyond which a run is flagged. Defaults to 95.
+	Percentile float64 `yaml:"percentile,omitempty"`
+	// MinRuns is the history needed before thresholds apply. Defaults to 5.
+	MinRuns int `yaml:"min_runs,omitempty"`
+	// HistoryRuns is how many previous runs are considered. Defaults to 30.
+	HistoryRuns int `yaml:"history_runs,omitempty"`
+}
+
+// NullRatios flags critical columns whose share of NULLs rose since the previous
+// run, a sign of truncated or misaligned COPY data in plain dumps.
+type NullRatios struct {
+	// Columns are given as schema.table.column, or table.column in the default schema.
+	Columns []string `yaml:"columns,omitempty"`
+	// MaxIncreasePercent is the tolerated rise in percentage points. Defaults to 5.
+	MaxIncreasePercent float64 `yaml:"max_increase_percent,omitempty"`
+	// Severity is warning (default) or critical.
+	Severity string `yaml:"severity,omitempty"`
+}
+
+// TableSizes flags tables whose row count or size deviates from the trend of
+// previous runs.
+type TableSizes struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxDropPercent is the largest tolerated shortfall from the trend. Defaults to 20.
+	MaxDropPercent float64 `yaml:"max_drop_percent,omitempty"`
+	// MaxGrowthPercent is the largest tolerated excess over the trend. Defaults to 100.
+	MaxGrowthPercent float64 `yaml:"max_growth_percent,omitempty"`
+	// MinRuns is the history needed before the check applies. Defaults to 3.
+	MinRuns int `yaml:"min_runs,omitempty"`
+}
+
+// ColumnProfiles records distinct estimates, null rates and average value sizes of
+// the listed columns and compares them with the previous run.
+type ColumnProfiles struct {
+	Enabled bool `yaml:"enabled"`
+	// Columns are given as schema.table.column, or table.column in the default schema.
+	Columns []string `yaml:"columns"`
+	// WarnThresholdPercent is the change that triggers a warning. Defaults to 20.
+	WarnThresholdPercent int `yaml:"warn_threshold_percent,omitempty"`
+}
+
+// Amcheck runs PostgreSQL's amcheck extension against every B-tree index.
+type Amcheck struct {
+	Enabled bool `yaml:"enabled"`
+	// HeapAllIndexed also verifies that every table row is present in its
+	// indexes, which reads each table in full.
+	HeapAllIndexed bool `yaml:"heapallindexed,omitempty"`
+}
+
+// Views configures the verification of views and materialized views.
+type Views struct {
+	// RefreshMaterialized refreshes every materialized view after the restore,
+	// catching definitions that no longer run against the restored data.
+	RefreshMaterialized bool `yaml:"refresh_materialized,omitempty"`
+}
+
+// Extensions lists database extensions the application depends on.
+type Extensions struct {
+	Required []string `yaml:"required,omitempty"`
+}
+
+// Rehearsal starts an application image next to the restored database and runs its
+// health check or smoke test command.
+type Rehearsal struct {
+	Image string `yaml:"image"`
+	// Command overrides the image's command; the image default runs when empty.
+	Command []string `yaml:"command,omitempty"`
+	// Env is added to the DATABASE_URL and DB_* variables. Values may reference those
+	// variables and the host environment.
+	Env map[string]string `yaml:"env,omitempty"`
+	// TimeoutSeconds bounds the smoke test. Defaults to 300.
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
+}
+
 type Docker struct {
-	Network        string `yaml:"network"`
-	PullPolicy     string `yaml:"pull_policy"`
-	TimeoutMinutes int    `yaml:"timeout_minutes"`
+	Network        string            `yaml:"network"`
+	PullPolicy     string            `yaml:"pull_policy"`
+	TimeoutMinutes int               `yaml:"timeout_minutes"`
+	NamePrefix     string            `yaml:"name_prefix,omitempty"`
+	Labels         map[string]string `yaml:"labels,omitempty"`
+	IsolateNetwork bool              `yaml:"isolate_network"`
+	ProxyImage     string            `yaml:"proxy_image,omitempty"`
+	Security       DockerSecurity    `yaml:"security"`
+}
+
+// DockerSecurity hardens the restore container.
+type DockerSecurity struct {
+	// User runs the container as this user (name or uid:gid) instead of root.
+	User            string `yaml:"user,omitempty"`
+	ReadOnlyRootfs  bool   `yaml:"read_only_rootfs"`
+	NoNewPrivileges bool   `yaml:"no_new_privileges"`
+	// SeccompProfile is a path to a seccomp JSON profile, or "unconfined".
+	SeccompProfile  string   `yaml:"seccomp_profile,omitempty"`
+	ApparmorProfile string   `yaml:"apparmor_profile,omitempty"`
+	CapDrop         []string `yaml:"cap_drop,omitempty"`
+}
+
+// Execution selects the host that restores and verifies backups.
+type Execution struct {
+	// Backend is local (default) or vm.
+	Backend string `yaml:"backend,omitempty"`
+	VM      *VM    `yaml:"vm,omitempty"`
+}
+
+// VM provisions a short-lived cloud VM per verify run and deletes it afterwards.
+type VM struct {
+	// Provider is aws or hetzner.
+	Provider string `yaml:"provider"`
+	// Image is the AMI ID or Hetzner image. It must run Docker, or install it with
+	// UserData. Defaults to docker-ce on Hetzner.
+	Image string `yaml:"image,omitempty"`
+	// Type is the instance or server type, e.g. m6i.2xlarge or ccx33.
+	Type string `yaml:"type"`
+	// Region is the AWS region or Hetzner location, e.g. eu-central-1 or fsn1.
+	Region string `yaml:"region,omitempty"`
+	// KeyName is the EC2 key pair or Hetzner SSH key installed for login.
+	KeyName string `yaml:"key_name"`
+	// SSHKeyPath is the unencrypted private key matching KeyName.
+	SSHKeyPath string `yaml:"ssh_key_path"`
+	// User is the SSH login user. Defaults to ec2-user on AWS and root on Hetzner.
+	User string `yaml:"user,omitempty"`
+	// SubnetID and SecurityGroupIDs place the EC2 instance. The security group must
+	// allow SSH from this host.
+	SubnetID         string   `yaml:"subnet_id,omitempty"`
+	SecurityGroupIDs []string `yaml:"security_group_ids,omitempty"`
+	// DiskGB sizes the EC2 root volume. Hetzner disks are fixed by Type.
+	DiskGB int `yaml:"disk_gb,omitempty"`
+	// TokenEnv names the environment variable holding the Hetzner API token.
+	// Defaults to HCLOUD_TOKEN.
+	TokenEnv string `yaml:"token_env,omitempty"`
+	// UserData is a cloud-init script run at first boot, e.g. to install Docker.
+	UserData string `yaml:"user_data,omitempty"`
+	// Binary is the Linux restorable binary copied to the VM. Defaults to the
+	// running executable on Linux.
+	Binary string `yaml:"binary,omitempty"`
+	// Env names environment variables passed to the remote run, e.g. backup
+	// credentials and the database password.
+	Env []string `yaml:"env,omitempty"`
+	// BootTimeoutMinutes bounds the wait for SSH and Docker. Defaults to 10.
+	BootTimeoutMinutes int `yaml:"boot_timeout_minutes,omitempty"`
+	// MaxLifetimeMinutes powers the VM off if it is still running after this long,
+	// in case this host dies before deleting it. Defaults to 360.
+	MaxLifetimeMinutes int `yaml:"max_lifetime_minutes,omitempty"`
+	// KeepOnFailure leaves the VM running after a failed run for debugging.
+	KeepOnFailure bool `yaml:"keep_on_failure,omitempty"`
+}
+
+// Sync configures the bucket that reports and baselines are mirrored to.
+type Sync struct {
+	S3 *S3 `yaml:"s3"`
+}
+
+// Upload submits signed reports to restorable.io after each run.
+type Upload struct {
+	// Endpoint defaults to the restorable.io reports API.
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// TokenEnv names the environment variable holding the API token.
+	TokenEnv string `yaml:"token_env,omitempty"`
+}
+
+type Notifications struct {
+	Targets []NotificationTarget `yaml:"targets"`
+}
+
+// NotificationTarget is a chat channel or webhook notified of verification results.
+type NotificationTarget struct {
+	Name string `yaml:"name,omitempty"`
+	// Type is "webhook" (JSON message) or "slack" (incoming webhook).
+	Type string `yaml:"type"`
+	URL  string `yaml:"url,omitempty"`
+	// URLEnv names an environment variable holding the URL, for URLs that embed secrets.
+	URLEnv string `yaml:"url_env,omitempty"`
+	// Headers are added to each request; values may reference environment variables.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// On is "failure" (default) or "always".
+	On string `yaml:"on,omitempty"`
 }
 
 type Signing struct {
 	PrivateKeyPath string `yaml:"private_key_path"`
 }
 
+// Profile bundles the settings of one kind of run, e.g. a quick nightly and a deep
+// monthly verification.
+type Profile struct {
+	// Mode is the restore mode; an explicit --mode takes precedence.
+	Mode string `yaml:"mode,omitempty"`
+	// TimeoutMinutes bounds the whole run.
+	TimeoutMinutes int `yaml:"timeout_minutes,omitempty"`
+	// Verification overrides the keys it sets in the verification section.
+	Verification yaml.Node `yaml:"verification,omitempty"`
+}
+
+// ApplyProfile overlays the named profile's verification settings onto the
+// configuration and returns the profile.
+func (c *Config) ApplyProfile(name string) (*Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("profile %q not found: no profiles configured", name)
+		}
+		return nil, fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	if !profile.Verification.IsZero() {
+		if err := profile.Verification.Decode(&c.Verification); err != nil {
+			return nil, fmt.Errorf("invalid verification settings in profile %s: %w", name, err)
+		}
+	}
+	return &profile, nil
+}
+
 // Load finds, reads, and parses the configuration file.
 func Load() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := DefaultPath()
 	if err != nil {
-		return nil, fmt.Errorf("could not get user home directory: %w", err)
+		return nil, err
 	}
-	configPath := filepath.Join(homeDir, ".restorable", "config.yaml")
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("config file not found at %s. Please run 'restorable init'", configPath)
 	}
+	return LoadFile(configPath)
+}
+
+// DefaultPath returns the configuration file used by Load, ~/.restorable/config.yaml.
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".restorable", "config.yaml"), nil
+}
 
+// LoadFile reads and parses the configuration file at configPath.
+func LoadFile(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not read config file at %s: %w", configPath, err)
@@ -1,18 +1,37 @@
 package crypto
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 
 	"filippo.io/age"
+	"filippo.io/age/armor"
+	"filippo.io/age/plugin"
+)
+
+// pluginIdentityPrefix starts the identities of age plugins such as
+// age-plugin-yubikey, which keep the key on the device and are run to unwrap it.
+const pluginIdentityPrefix = "AGE-PLUGIN-"
+
+// pluginUI relays plugin messages and prompts, such as a YubiKey PIN or touch
+// request, through the terminal.
+var pluginUI = plugin.NewTerminalUI(
+	func(format string, v ...any) { fmt.Printf(format+"\n", v...) },
+	func(format string, v ...any) { fmt.Printf("⚠ "+format+"\n", v...) },
 )
 
 // AgeDecryptor handles age-encrypted backup decryption.
 type AgeDecryptor struct {
 	identities []age.Identity
+	// sources names the key file of each identity
+	sources []string
+	// matched is the source of the identity that decrypted the last stream
+	matched string
 }
 
 // NewAgeDecryptor creates a decryptor from a private key file path.
@@ -21,17 +40,36 @@ func NewAgeDecryptor(privateKeyPath string) (*AgeDecryptor, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read age private key from %s: %w", privateKeyPath, err)
 	}
+	return ParseAgeDecryptor(keyData, privateKeyPath)
+}
 
-	identities, err := age.ParseIdentities(bytes.NewReader(keyData))
+// ParseAgeDecryptor creates a decryptor from the contents of an age private key
+// file; source names the key in errors.
+func ParseAgeDecryptor(keyData []byte, source string) (*AgeDecryptor, error) {
+	identities, err := parseIdentities(string(keyData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse age identities: %w", err)
 	}
 
 	if len(identities) == 0 {
-		return nil, fmt.Errorf("no age identities found in %s", privateKeyPath)
+		return nil, fmt.Errorf("no age identities found in %s", source)
 	}
 
-	return &AgeDecryptor{identities: identities}, nil
+	return &AgeDecryptor{identities: identities, sources: repeat(source, len(identities))}, nil
+}
+
+// Add appends the identities of other, which are tried after d's own.
+func (d *AgeDecryptor) Add(other *AgeDecryptor) {
+	d.identities = append(d.identities, other.identities...)
+	d.sources = append(d.sources, other.sources...)
+}
+
+func repeat(s string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = s
+	}
+	return out
 }
 
 // NewAgeDecryptorFromEnv creates a decryptor using a private key from an environment variable.
@@ -41,7 +79,7 @@ func NewAgeDecryptorFromEnv(envVar string) (*AgeDecryptor, error) {
 		return nil, fmt.Errorf("age private key environment variable %s is not set", envVar)
 	}
 
-	identities, err := age.ParseIdentities(strings.NewReader(keyData))
+	identities, err := parseIdentities(keyData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse age identities from env: %w", err)
 	}
@@ -50,19 +88,92 @@ func NewAgeDecryptorFromEnv(envVar string) (*AgeDecryptor, error) {
 		return nil, fmt.Errorf("no age identities found in environment variable %s", envVar)
 	}
 
-	return &AgeDecryptor{identities: identities}, nil
+	return &AgeDecryptor{identities: identities, sources: repeat("env:"+envVar, len(identities))}, nil
+}
+
+// parseIdentities parses an age identities file. Besides native identities, it
+// accepts plugin identities (AGE-PLUGIN-...), whose plugin binary, e.g.
+// age-plugin-yubikey, must be on the PATH when decrypting.
+func parseIdentities(keyData string) ([]age.Identity, error) {
+	var identities []age.Identity
+	for n, line := range strings.Split(keyData, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, pluginIdentityPrefix) {
+			identity, err := plugin.NewIdentity(line, pluginUI)
+			if err != nil {
+				return nil, fmt.Errorf("error at line %d: %w", n+1, err)
+			}
+			identities = append(identities, identity)
+			continue
+		}
+		parsed, err := age.ParseIdentities(strings.NewReader(line))
+		if err != nil {
+			return nil, fmt.Errorf("error at line %d: %w", n+1, err)
+		}
+		identities = append(identities, parsed...)
+	}
+	return identities, nil
 }
 
-// Decrypt wraps the reader with age decryption.
-// The returned reader must be fully consumed and closed.
+// Decrypt wraps the reader with age decryption, trying the identities in order.
+// Native identities are tried before plugin identities, which may prompt for a
+// PIN or touch. ASCII-armored files are accepted. The returned reader must be
+// fully consumed and closed.
 func (d *AgeDecryptor) Decrypt(r io.Reader) (io.Reader, error) {
-	decrypted, err := age.Decrypt(r, d.identities...)
+	d.matched = ""
+	buffered := bufio.NewReader(r)
+	r = buffered
+	if start, _ := buffered.Peek(DetectionHeaderSize); bytes.HasPrefix(bytes.TrimLeft(start, " \t\r\n"), ageArmorHeader) {
+		r = armor.NewReader(buffered)
+	}
+
+	var native, plugins []age.Identity
+	for i, identity := range d.identities {
+		tracked := &trackedIdentity{Identity: identity, source: d.sources[i], matched: &d.matched}
+		if _, ok := identity.(*plugin.Identity); ok {
+			plugins = append(plugins, tracked)
+		} else {
+			native = append(native, tracked)
+		}
+	}
+
+	decrypted, err := age.Decrypt(r, append(native, plugins...)...)
 	if err != nil {
 		return nil, fmt.Errorf("age decryption failed: %w", err)
 	}
 	return decrypted, nil
 }
 
+// MatchedKey returns the key file whose identity decrypted the last stream.
+func (d *AgeDecryptor) MatchedKey() string {
+	return d.matched
+}
+
+// trackedIdentity records the source of the identity that unwraps the file key.
+type trackedIdentity struct {
+	age.Identity
+	source  string
+	matched *string
+}
+
+func (t *trackedIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	fileKey, err := t.Identity.Unwrap(stanzas)
+	if err == nil {
+		*t.matched = t.source
+	}
+	return fileKey, err
+}
+
+// StreamDecryptor is implemented by the decryptors of each encryption method.
+type StreamDecryptor interface {
+	NewDecryptReadCloser(rc io.ReadCloser) (*DecryptReadCloser, error)
+	// MatchedKey names the key that decrypted the last stream.
+	MatchedKey() string
+}
+
 // DecryptReadCloser wraps a ReadCloser with decryption, preserving the Close method.
 type DecryptReadCloser struct {
 	decrypted io.Reader
@@ -88,3 +199,118 @@ func (d *DecryptReadCloser) Read(p []byte) (n int, err error) {
 func (d *DecryptReadCloser) Close() error {
 	return d.original.Close()
 }
+
+// AgeCipher encrypts to the recipients of a key file's identities and decrypts
+// with the identities themselves, for data the CLI both writes and reads.
+type AgeCipher struct {
+	*AgeDecryptor
+	recipients []age.Recipient
+}
+
+// NewAgeCipher creates a cipher from a decryptor's identities. Only X25519
+// identities are supported, since their recipients can be derived locally.
+func NewAgeCipher(decryptor *AgeDecryptor) (*AgeCipher, error) {
+	var recipients []age.Recipient
+	for _, identity := range decryptor.identities {
+		x25519, ok := identity.(*age.X25519Identity)
+		if !ok {
+			return nil, fmt.Errorf("unsupported age identity type %T", identity)
+		}
+		recipients = append(recipients, x25519.Recipient())
+	}
+
+	return &AgeCipher{AgeDecryptor: decryptor, recipients: recipients}, nil
+}
+
+// Encrypt returns a writer encrypting to dst. Close must be called to flush it.
+func (c *AgeCipher) Encrypt(dst io.Writer) (io.WriteCloser, error) {
+	w, err := age.Encrypt(dst, c.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age encryption failed: %w", err)
+	}
+	return w, nil
+}
+
+// Recipient is an age recipient and the public key it was parsed from.
+type Recipient struct {
+	PublicKey string
+	recipient age.Recipient
+}
+
+// ParseRecipients parses age public keys (age1...), as backups are encrypted to.
+// Plugin recipients, such as age1yubikey1..., are encrypted to by their plugin.
+func ParseRecipients(publicKeys []string) ([]Recipient, error) {
+	recipients := make([]Recipient, 0, len(publicKeys))
+	for _, key := range publicKeys {
+		parsed, err := age.ParseRecipients(strings.NewReader(key))
+		if err != nil {
+			pluginRecipient, perr := plugin.NewRecipient(strings.TrimSpace(key), pluginUI)
+			if perr != nil {
+				return nil, fmt.Errorf("failed to parse age recipient %q: %w", key, err)
+			}
+			parsed = []age.Recipient{pluginRecipient}
+		}
+		if len(parsed) != 1 {
+			return nil, fmt.Errorf("expected one age recipient in %q, found %d", key, len(parsed))
+		}
+		recipients = append(recipients, Recipient{PublicKey: key, recipient: parsed[0]})
+	}
+	return recipients, nil
+}
+
+// Recipients returns the public keys of the decryptor's identities.
+func (d *AgeDecryptor) Recipients() ([]Recipient, error) {
+	var recipients []Recipient
+	for _, identity := range d.identities {
+		switch id := identity.(type) {
+		case *age.X25519Identity:
+			recipients = append(recipients, Recipient{PublicKey: id.Recipient().String(), recipient: id.Recipient()})
+		case *age.HybridIdentity:
+			recipients = append(recipients, Recipient{PublicKey: id.Recipient().String(), recipient: id.Recipient()})
+		case *plugin.Identity:
+			recipients = append(recipients, Recipient{PublicKey: "age-plugin-" + id.Name() + " identity", recipient: id.Recipient()})
+		default:
+			return nil, fmt.Errorf("unsupported age identity type %T", identity)
+		}
+	}
+	return recipients, nil
+}
+
+// Identities returns the number of identities the decryptor holds.
+func (d *AgeDecryptor) Identities() int {
+	return len(d.identities)
+}
+
+// RoundTrip encrypts a random payload of size bytes to r and decrypts it with the
+// decryptor's identities, failing unless the payload comes back unchanged.
+func (d *AgeDecryptor) RoundTrip(r Recipient, size int) error {
+	payload := make([]byte, size)
+	if _, err := rand.Read(payload); err != nil {
+		return fmt.Errorf("failed to generate test payload: %w", err)
+	}
+
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, r.recipient)
+	if err != nil {
+		return fmt.Errorf("age encryption failed: %w", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("age encryption failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("age encryption failed: %w", err)
+	}
+
+	decrypted, err := d.Decrypt(&encrypted)
+	if err != nil {
+		return err
+	}
+	roundTripped, err := io.ReadAll(decrypted)
+	if err != nil {
+		return fmt.Errorf("age decryption failed: %w", err)
+	}
+	if !bytes.Equal(roundTripped, payload) {
+		return fmt.Errorf("decrypted payload does not match the original")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package crypto
+
+import (
+	"bytes"
+)
+
+// Encryption formats recognized by DetectEncryption.
+const (
+	FormatAge     = "age"
+	FormatOpenSSL = "openssl"
+	FormatGPG     = "gpg"
+)
+
+// DetectionHeaderSize is how many leading bytes DetectEncryption needs.
+const DetectionHeaderSize = 64
+
+var (
+	ageMagic       = []byte("age-encryption.org/")
+	ageArmorHeader = []byte("-----BEGIN AGE ENCRYPTED FILE-----")
+	pgpArmorHeader = []byte("-----BEGIN PGP MESSAGE-----")
+)
+
+// DetectEncryption returns the encryption format of an artifact from its first
+// bytes, or "" when it does not look encrypted.
+func DetectEncryption(header []byte) string {
+	trimmed := bytes.TrimLeft(header, " \t\r\n")
+	switch {
+	case bytes.HasPrefix(header, ageMagic), bytes.HasPrefix(trimmed, ageArmorHeader):
+		return FormatAge
+	case bytes.HasPrefix(header, []byte(opensslMagic)):
+		return FormatOpenSSL
+	case bytes.HasPrefix(trimmed, pgpArmorHeader), isPGPSessionKeyPacket(header):
+		return FormatGPG
+	}
+	return ""
+}
+
+// isPGPSessionKeyPacket reports whether header starts with the public-key or
+// symmetric-key encrypted session key packet that begins an OpenPGP message.
+func isPGPSessionKeyPacket(header []byte) bool {
+	if len(header) < 2 || header[0]&0x80 == 0 {
+		return false
+	}
+	var tag byte
+	if header[0]&0x40 != 0 {
+		tag = header[0] & 0x3f // New format
+	} else {
+		tag = (header[0] >> 2) & 0x0f // Old format
+	}
+	return tag == 1 || tag == 3
+}
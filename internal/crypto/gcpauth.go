@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	gcpTokenURL      = "https://oauth2.googleapis.com/token"
+	gcpScope         = "https://www.googleapis.com/auth/cloud-platform"
+	gcpMetadataHost  = "metadata.google.internal"
+	gcpMetadataProbe = 3 * time.Second
+)
+
+// gcpCredentials is a Google credentials file, as written by gcloud auth
+// application-default login or downloaded for a service account.
+type gcpCredentials struct {
+	Type string `json:"type"`
+	// Service account keys
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+	// User credentials
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// gcpAccessToken returns an access token from Application Default Credentials:
+// the file named by GOOGLE_APPLICATION_CREDENTIALS, then gcloud's application
+// default credentials, then the metadata server of Google Cloud compute.
+func gcpAccessToken(ctx context.Context, client *http.Client) (string, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = gcloudCredentialsPath()
+		if _, err := os.Stat(path); err != nil {
+			return gcpMetadataToken(ctx, client)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Google credentials from %s: %w", path, err)
+	}
+	var creds gcpCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("failed to parse Google credentials in %s: %w", path, err)
+	}
+	switch creds.Type {
+	case "service_account":
+		return gcpServiceAccountToken(ctx, client, &creds)
+	case "authorized_user":
+		return gcpToken(ctx, client, gcpTokenURL, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		})
+	default:
+		return "", fmt.Errorf("unsupported Google credentials type %q in %s (use a service account key or gcloud auth application-default login)", creds.Type, path)
+	}
+}
+
+// gcloudCredentialsPath returns where gcloud auth application-default login
+// stores credentials.
+func gcloudCredentialsPath() string {
+	dir := os.Getenv("CLOUDSDK_CONFIG")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config", "gcloud")
+	}
+	return filepath.Join(dir, "application_default_credentials.json")
+}
+
+// gcpServiceAccountToken exchanges a JWT signed with the service account key for
+// an access token.
+func gcpServiceAccountToken(ctx context.Context, client *http.Client, creds *gcpCredentials) (string, error) {
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("service account %s has no PEM private key", creds.ClientEmail)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the private key of service account %s: %w", creds.ClientEmail, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account %s does not have an RSA private key", creds.ClientEmail)
+	}
+
+	tokenURL := creds.TokenURI
+	if tokenURL == "" {
+		tokenURL = gcpTokenURL
+	}
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": creds.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": gcpScope,
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the token request of service account %s: %w", creds.ClientEmail, err)
+	}
+
+	return gcpToken(ctx, client, tokenURL, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	})
+}
+
+// gcpToken requests an access token from the OAuth token endpoint.
+func gcpToken(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doTokenRequest(client, req)
+}
+
+// gcpMetadataToken requests the access token of the instance's service account
+// from the metadata server.
+func gcpMetadataToken(ctx context.Context, client *http.Client) (string, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = gcpMetadataHost
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, gcpMetadataProbe)
+	defer cancel()
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata token request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	token, err := doTokenRequest(client, req)
+	if err != nil {
+		return "", fmt.Errorf("no Google Application Default Credentials found (set GOOGLE_APPLICATION_CREDENTIALS or run gcloud auth application-default login): %w", err)
+	}
+	return token, nil
+}
+
+// doTokenRequest sends req and returns the access token of the response.
+func doTokenRequest(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return "", fmt.Errorf("token request failed: %s: %s", body.Error, body.ErrorDescription)
+		}
+		return "", fmt.Errorf("token request failed: %s", resp.Status)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", decodeErr)
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("token response has no access token")
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const gcpKMSEndpoint = "https://cloudkms.googleapis.com/v1/"
+
+// gcpKeyName matches a Cloud KMS key resource name.
+var gcpKeyName = regexp.MustCompile(`^projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+$`)
+
+// GCPKMS decrypts data with a Google Cloud KMS symmetric key, authenticating
+// with Application Default Credentials.
+type GCPKMS struct {
+	keyName string
+	client  *http.Client
+}
+
+// NewGCPKMS creates a Cloud KMS client for the key resource name
+// projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>.
+func NewGCPKMS(keyName string) (*GCPKMS, error) {
+	if !gcpKeyName.MatchString(keyName) {
+		return nil, fmt.Errorf("invalid Cloud KMS key name %q (expected projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>)", keyName)
+	}
+	return &GCPKMS{keyName: keyName, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Decrypt returns the plaintext of ciphertext, as produced by gcloud kms encrypt.
+// Key material never leaves Cloud KMS.
+func (k *GCPKMS) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	token, err := gcpAccessToken(ctx, k.client)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, _ := json.Marshal(map[string]string{"ciphertext": base64.StdEncoding.EncodeToString(ciphertext)})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gcpKMSEndpoint+k.keyName+":decrypt", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud KMS request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Cloud KMS decrypt with %s failed: %w", k.keyName, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Plaintext string `json:"plaintext"`
+		Error     struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		if body.Error.Message != "" {
+			return nil, fmt.Errorf("Cloud KMS decrypt with %s failed: %s: %s", k.keyName, body.Error.Status, body.Error.Message)
+		}
+		return nil, fmt.Errorf("Cloud KMS decrypt with %s failed: %s", k.keyName, resp.Status)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to parse Cloud KMS response: %w", decodeErr)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(body.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Cloud KMS plaintext: %w", err)
+	}
+	return plaintext, nil
+}
+
+// KeyName returns the Cloud KMS key resource name.
+func (k *GCPKMS) KeyName() string {
+	return k.keyName
+}
@@ -0,0 +1,165 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+const (
+	// DefaultOpenSSLIterations is the PBKDF2 iteration count of openssl enc -pbkdf2
+	// without -iter.
+	DefaultOpenSSLIterations = 10000
+
+	opensslMagic     = "Salted__"
+	opensslSaltSize  = 8
+	opensslChunkSize = 32 * 1024
+)
+
+// opensslDigests are the -md digests accepted for PBKDF2.
+var opensslDigests = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"sha1":   sha1.New,
+}
+
+// OpenSSLDecryptor decrypts files written by
+// openssl enc -aes-256-cbc -pbkdf2 -salt, the format of many cron backup scripts.
+type OpenSSLDecryptor struct {
+	envVar     string
+	passphrase string
+	iterations int
+	digest     func() hash.Hash
+}
+
+// NewOpenSSLDecryptorFromEnv creates a decryptor with the passphrase in the
+// environment variable envVar. iterations and digest match openssl's -iter and
+// -md options; zero values select openssl's defaults.
+func NewOpenSSLDecryptorFromEnv(envVar string, iterations int, digest string) (*OpenSSLDecryptor, error) {
+	passphrase := os.Getenv(envVar)
+	if passphrase == "" {
+		return nil, fmt.Errorf("openssl passphrase environment variable %s is not set", envVar)
+	}
+	if iterations <= 0 {
+		iterations = DefaultOpenSSLIterations
+	}
+	if digest == "" {
+		digest = "sha256"
+	}
+	newHash, ok := opensslDigests[digest]
+	if !ok {
+		return nil, fmt.Errorf("unsupported openssl digest %q (use sha256, sha512 or sha1)", digest)
+	}
+	return &OpenSSLDecryptor{envVar: envVar, passphrase: passphrase, iterations: iterations, digest: newHash}, nil
+}
+
+// Decrypt reads the salted header from r and returns a reader of the plaintext.
+func (d *OpenSSLDecryptor) Decrypt(r io.Reader) (io.Reader, error) {
+	header := make([]byte, len(opensslMagic)+opensslSaltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("openssl decryption failed: failed to read header: %w", err)
+	}
+	if !bytes.Equal(header[:len(opensslMagic)], []byte(opensslMagic)) {
+		return nil, errors.New("openssl decryption failed: missing Salted__ header (encrypt with -salt, without -a)")
+	}
+	salt := header[len(opensslMagic):]
+
+	// openssl derives the key and IV together
+	keyIV, err := pbkdf2.Key(d.digest, d.passphrase, salt, d.iterations, 32+aes.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("openssl decryption failed: %w", err)
+	}
+	block, err := aes.NewCipher(keyIV[:32])
+	if err != nil {
+		return nil, fmt.Errorf("openssl decryption failed: %w", err)
+	}
+	return &cbcReader{src: r, mode: cipher.NewCBCDecrypter(block, keyIV[32:])}, nil
+}
+
+// NewDecryptReadCloser creates a decrypting ReadCloser.
+func (d *OpenSSLDecryptor) NewDecryptReadCloser(rc io.ReadCloser) (*DecryptReadCloser, error) {
+	decrypted, err := d.Decrypt(rc)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptReadCloser{
+		decrypted: decrypted,
+		original:  rc,
+	}, nil
+}
+
+// MatchedKey names the environment variable of the passphrase.
+func (d *OpenSSLDecryptor) MatchedKey() string {
+	return "env:" + d.envVar
+}
+
+// cbcReader decrypts an AES-CBC stream with PKCS#7 padding. The last block is
+// held back until the end of the stream, where its padding is removed.
+type cbcReader struct {
+	src     io.Reader
+	mode    cipher.BlockMode
+	pending []byte // ciphertext not yet decrypted
+	held    []byte // last decrypted block
+	out     []byte // plaintext ready to be read
+	done    bool
+}
+
+func (c *cbcReader) Read(p []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+// fill decrypts the next chunk of the stream into out.
+func (c *cbcReader) fill() error {
+	chunk := make([]byte, opensslChunkSize)
+	n, err := io.ReadAtLeast(c.src, chunk, aes.BlockSize)
+	c.pending = append(c.pending, chunk[:n]...)
+	eof := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !eof {
+		return fmt.Errorf("openssl decryption failed: %w", err)
+	}
+
+	blocks := len(c.pending) / aes.BlockSize * aes.BlockSize
+	if eof && blocks != len(c.pending) {
+		return errors.New("openssl decryption failed: ciphertext is not a whole number of blocks (truncated file?)")
+	}
+	plaintext := make([]byte, len(c.held)+blocks)
+	copy(plaintext, c.held)
+	c.mode.CryptBlocks(plaintext[len(c.held):], c.pending[:blocks])
+	c.pending = c.pending[blocks:]
+
+	if !eof {
+		keep := len(plaintext) - aes.BlockSize
+		c.out, c.held = plaintext[:keep], plaintext[keep:]
+		return nil
+	}
+
+	c.done = true
+	if len(plaintext) == 0 {
+		return errors.New("openssl decryption failed: no ciphertext after the header")
+	}
+	pad := int(plaintext[len(plaintext)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plaintext[len(plaintext)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return errors.New("openssl decryption failed: bad padding (wrong passphrase, digest or iteration count?)")
+	}
+	c.out = plaintext[:len(plaintext)-pad]
+	return nil
+}
@@ -0,0 +1,279 @@
+// Package detect infers database settings from a project's existing environment:
+// docker-compose files, .env files and DATABASE_URL.
+package detect
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result holds the settings detected in a directory. Empty fields were not detected.
+type Result struct {
+	Type         string
+	MajorVersion int
+	// Image is the database image used by the compose service.
+	Image string
+	User  string
+	// PasswordEnv names the variable holding the database password.
+	PasswordEnv string
+	DBName      string
+	// Sources describes where each setting came from.
+	Sources []string
+}
+
+var composeFiles = []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
+
+// databaseImages maps image name fragments to database types.
+var databaseImages = []struct {
+	fragment string
+	dbType   string
+}{
+	{"postgis", "postgres"},
+	{"timescale", "postgres"},
+	{"postgres", "postgres"},
+	{"mariadb", "mariadb"},
+	{"mysql", "mysql"},
+	{"mongo", "mongodb"},
+}
+
+// Per-type environment variables used by the official images.
+var (
+	passwordVars = map[string][]string{
+		"postgres": {"POSTGRES_PASSWORD"},
+		"mariadb":  {"MARIADB_ROOT_PASSWORD", "MYSQL_ROOT_PASSWORD", "MARIADB_PASSWORD", "MYSQL_PASSWORD"},
+		"mysql":    {"MYSQL_ROOT_PASSWORD", "MYSQL_PASSWORD"},
+		"mongodb":  {"MONGO_INITDB_ROOT_PASSWORD"},
+	}
+	userVars = map[string][]string{
+		"postgres": {"POSTGRES_USER"},
+		"mariadb":  {"MARIADB_USER", "MYSQL_USER"},
+		"mysql":    {"MYSQL_USER"},
+		"mongodb":  {"MONGO_INITDB_ROOT_USERNAME"},
+	}
+	dbNameVars = map[string][]string{
+		"postgres": {"POSTGRES_DB"},
+		"mariadb":  {"MARIADB_DATABASE", "MYSQL_DATABASE"},
+		"mysql":    {"MYSQL_DATABASE"},
+		"mongodb":  {"MONGO_INITDB_DATABASE"},
+	}
+)
+
+var leadingNumber = regexp.MustCompile(`^\d+`)
+
+// Dir scans dir for compose files and a .env file, then falls back to the
+// DATABASE_URL of the current environment. Compose services take precedence.
+func Dir(dir string) (*Result, error) {
+	result := &Result{}
+
+	dotenv, err := readDotEnv(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range composeFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := result.fromCompose(name, data); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if databaseURL, ok := dotenv["DATABASE_URL"]; ok {
+		result.fromURL(".env DATABASE_URL", databaseURL)
+	}
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		result.fromURL("DATABASE_URL environment variable", databaseURL)
+	}
+
+	return result, nil
+}
+
+// Found reports whether anything was detected.
+func (r *Result) Found() bool {
+	return len(r.Sources) > 0
+}
+
+type composeFile struct {
+	Services map[string]struct {
+		Image       string    `yaml:"image"`
+		Environment yaml.Node `yaml:"environment"`
+	} `yaml:"services"`
+}
+
+// fromCompose uses the first database service, in service name order.
+func (r *Result) fromCompose(file string, data []byte) error {
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := compose.Services[name]
+		dbType := imageType(service.Image)
+		if dbType == "" {
+			continue
+		}
+
+		r.Type = dbType
+		r.Image = service.Image
+		r.MajorVersion = imageMajorVersion(service.Image)
+		r.Sources = append(r.Sources, fmt.Sprintf("%s service %q (%s)", file, name, service.Image))
+
+		env := composeEnvironment(&service.Environment)
+		if v := firstKey(env, passwordVars[dbType]); v != "" {
+			r.PasswordEnv = v
+		}
+		if v := firstKey(env, userVars[dbType]); v != "" && !strings.HasPrefix(env[v], "$") {
+			r.User = env[v]
+		}
+		if v := firstKey(env, dbNameVars[dbType]); v != "" && !strings.HasPrefix(env[v], "$") {
+			r.DBName = env[v]
+		}
+		return nil
+	}
+	return nil
+}
+
+// fromURL fills in whatever the compose file did not provide from a connection URL.
+func (r *Result) fromURL(source, raw string) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return
+	}
+
+	var dbType string
+	switch strings.ToLower(u.Scheme) {
+	case "postgres", "postgresql":
+		dbType = "postgres"
+	case "mariadb":
+		dbType = "mariadb"
+	case "mysql":
+		// MariaDB applications commonly use the mysql scheme too
+		dbType = "mysql"
+		if r.Type == "mariadb" {
+			dbType = "mariadb"
+		}
+	case "mongodb", "mongodb+srv":
+		dbType = "mongodb"
+	case "sqlite", "sqlite3", "file":
+		dbType = "sqlite"
+	default:
+		return
+	}
+
+	if r.Type != "" && r.Type != dbType {
+		return
+	}
+	r.Type = dbType
+	if r.User == "" && u.User != nil {
+		r.User = u.User.Username()
+	}
+	if r.DBName == "" && dbType != "sqlite" {
+		r.DBName = strings.TrimPrefix(u.Path, "/")
+	}
+	r.Sources = append(r.Sources, source)
+}
+
+// imageType returns the database type of an image, or "" if it is not a database.
+func imageType(image string) string {
+	repo := strings.ToLower(image)
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	for _, db := range databaseImages {
+		if strings.Contains(repo, db.fragment) {
+			return db.dbType
+		}
+	}
+	return ""
+}
+
+// imageMajorVersion parses the major version from tags like "15", "15.4-alpine"
+// or "latest-pg16". Returns 0 if the tag has no leading number.
+func imageMajorVersion(image string) int {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || i < strings.LastIndex(image, "/") {
+		return 0
+	}
+	major, err := strconv.Atoi(leadingNumber.FindString(image[i+1:]))
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+// composeEnvironment reads a service environment in either map or list form.
+func composeEnvironment(node *yaml.Node) map[string]string {
+	env := make(map[string]string)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			env[node.Content[i].Value] = node.Content[i+1].Value
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			key, value, _ := strings.Cut(item.Value, "=")
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// firstKey returns the first of keys present in env.
+func firstKey(env map[string]string, keys []string) string {
+	for _, k := range keys {
+		if _, ok := env[k]; ok {
+			return k
+		}
+	}
+	return ""
+}
+
+// readDotEnv parses KEY=VALUE lines, ignoring comments and blank lines.
+func readDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		env[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return env, scanner.Err()
+}
@@ -0,0 +1,152 @@
+// Package notify sends verification results to chat channels and webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/report"
+)
+
+// When a target is notified.
+const (
+	OnFailure = "failure"
+	OnAlways  = "always"
+)
+
+// Message is a notification about a verification report.
+type Message struct {
+	Title     string `json:"title"`
+	Text      string `json:"text"`
+	Success   bool   `json:"success"`
+	ProjectID string `json:"project_id"`
+	ReportID  string `json:"report_id"`
+	// Test marks synthetic messages sent by `notify test`.
+	Test bool `json:"test,omitempty"`
+}
+
+// FromReport builds the message for a report from its ops summary, dated in loc.
+func FromReport(rpt *report.Report, loc *time.Location) (Message, error) {
+	text, err := report.RenderSummary(rpt, report.AudienceOps, "en", loc)
+	if err != nil {
+		return Message{}, err
+	}
+	status := "verified"
+	if !rpt.Summary.Success {
+		status = "FAILED"
+	}
+	return Message{
+		Title:     fmt.Sprintf("Backup verification %s: %s", status, rpt.ProjectName),
+		Text:      text,
+		Success:   rpt.Summary.Success,
+		ProjectID: rpt.ProjectID,
+		ReportID:  rpt.ID,
+	}, nil
+}
+
+// Target delivers messages to one configured destination.
+type Target struct {
+	Name    string
+	kind    string
+	url     string
+	on      string
+	headers map[string]string
+	http    *http.Client
+}
+
+// NewTargets creates the configured targets. A target whose URL cannot be resolved
+// is an error, so misconfiguration surfaces before a failure needs reporting.
+func NewTargets(cfg *config.Notifications) ([]*Target, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	targets := make([]*Target, 0, len(cfg.Targets))
+	for i, tc := range cfg.Targets {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", tc.Type, i)
+		}
+
+		switch tc.Type {
+		case "webhook", "slack":
+		default:
+			return nil, fmt.Errorf("notification target %s: unsupported type %q (use webhook or slack)", name, tc.Type)
+		}
+
+		url := tc.URL
+		if tc.URLEnv != "" {
+			url = os.Getenv(tc.URLEnv)
+			if url == "" {
+				return nil, fmt.Errorf("notification target %s: URL not found: set %s", name, tc.URLEnv)
+			}
+		}
+		if url == "" {
+			return nil, fmt.Errorf("notification target %s: url or url_env is required", name)
+		}
+
+		on := tc.On
+		switch on {
+		case "":
+			on = OnFailure
+		case OnFailure, OnAlways:
+		default:
+			return nil, fmt.Errorf("notification target %s: unsupported on %q (use failure or always)", name, tc.On)
+		}
+
+		targets = append(targets, &Target{
+			Name:    name,
+			kind:    tc.Type,
+			url:     url,
+			on:      on,
+			headers: tc.Headers,
+			http:    &http.Client{Timeout: 15 * time.Second},
+		})
+	}
+	return targets, nil
+}
+
+// Wants reports whether the target is notified of a result with the given outcome.
+func (t *Target) Wants(success bool) bool {
+	return t.on == OnAlways || !success
+}
+
+// Send posts the message to the target.
+func (t *Target) Send(ctx context.Context, msg Message) error {
+	var payload any = msg
+	if t.kind == "slack" {
+		payload = map[string]string{"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", t.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected by %s: %s: %s", t.Name, resp.Status, bytes.TrimSpace(text))
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxConcurrent bounds concurrent restores when no limit is configured.
+const DefaultMaxConcurrent = 2
+
+const pollInterval = 2 * time.Second
+
+// Entry is a verification run waiting for, or holding, a restore slot.
+type Entry struct {
+	RunID     string `json:"run_id"`
+	ProjectID string `json:"project_id"`
+	// Priority orders waiting runs; higher runs first.
+	Priority   int       `json:"priority"`
+	PID        int       `json:"pid"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// StartedAt is set once the run holds a slot.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+}
+
+// Running reports whether the entry holds a slot.
+func (e *Entry) Running() bool {
+	return e.StartedAt != nil
+}
+
+// Queue limits how many verification runs restore at once on a host. Every run
+// sharing the directory takes part, whichever config or user started it. Waiting
+// runs start in priority order, then in the order they were enqueued.
+type Queue struct {
+	dir           string
+	maxConcurrent int
+}
+
+// Open opens the queue in dir, or ~/.restorable/queue/runs if empty.
+// maxConcurrent defaults to DefaultMaxConcurrent.
+func Open(dir string, maxConcurrent int) (*Queue, error) {
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not get user home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, ".restorable", "queue", "runs")
+	}
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create run queue directory: %w", err)
+	}
+	return &Queue{dir: dir, maxConcurrent: maxConcurrent}, nil
+}
+
+// MaxConcurrent returns the number of runs that may hold a slot at once.
+func (q *Queue) MaxConcurrent() int {
+	return q.maxConcurrent
+}
+
+// Acquire enqueues entry and blocks until it holds a slot. If the run has to wait,
+// onWait is called once with the number of waiting runs ahead of it. The returned
+// function releases the slot. If ctx ends first, the entry is removed.
+func (q *Queue) Acquire(ctx context.Context, entry Entry, onWait func(ahead int)) (func(), error) {
+	entry.PID = os.Getpid()
+	entry.EnqueuedAt = time.Now().UTC()
+	entry.StartedAt = nil
+	release := func() { q.remove(entry.RunID) }
+
+	err := q.locked(func() error { return q.write(&entry) })
+	if err != nil {
+		return nil, err
+	}
+
+	waiting := false
+	for {
+		var ahead int
+		err := q.locked(func() error {
+			entries, err := q.entries()
+			if err != nil {
+				return err
+			}
+			var running int
+			for _, e := range entries {
+				if e.Running() {
+					running++
+				} else if e.RunID != entry.RunID && before(e, entry) {
+					ahead++
+				}
+			}
+			if ahead > 0 || running >= q.maxConcurrent {
+				return nil
+			}
+			started := time.Now().UTC()
+			entry.StartedAt = &started
+			return q.write(&entry)
+		})
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if entry.Running() {
+			return release, nil
+		}
+
+		if !waiting && onWait != nil {
+			onWait(ahead)
+		}
+		waiting = true
+		select {
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Status returns the live entries, running ones first, then waiting ones in the
+// order they will start.
+func (q *Queue) Status() ([]Entry, error) {
+	var entries []Entry
+	err := q.locked(func() error {
+		var err error
+		entries, err = q.entries()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Running() != entries[j].Running() {
+			return entries[i].Running()
+		}
+		if entries[i].Running() {
+			return entries[i].StartedAt.Before(*entries[j].StartedAt)
+		}
+		return before(entries[i], entries[j])
+	})
+	return entries, nil
+}
+
+// before reports whether waiting entry a starts before b.
+func before(a, b Entry) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.EnqueuedAt.Before(b.EnqueuedAt)
+}
+
+// entries reads the queue, dropping entries whose process has exited so a crashed
+// run does not hold its slot forever. The caller must hold the lock.
+func (q *Queue) entries() ([]Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(q.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run queue: %w", err)
+	}
+	var entries []Entry
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read run queue entry: %w", err)
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil || !alive(e.PID) {
+			os.Remove(path)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// write stores entry. The caller must hold the lock.
+func (q *Queue) write(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run queue entry: %w", err)
+	}
+	if err := os.WriteFile(q.path(entry.RunID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run queue entry: %w", err)
+	}
+	return nil
+}
+
+// remove drops the entry for runID, freeing its slot.
+func (q *Queue) remove(runID string) {
+	q.locked(func() error {
+		os.Remove(q.path(runID))
+		return nil
+	})
+}
+
+func (q *Queue) path(runID string) string {
+	// Run IDs from orchestrators may contain path separators
+	return filepath.Join(q.dir, strings.ReplaceAll(runID, string(filepath.Separator), "_")+".json")
+}
+
+// locked runs fn while holding an exclusive lock on the queue directory.
+func (q *Queue) locked(fn func() error) error {
+	f, err := os.OpenFile(filepath.Join(q.dir, ".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open run queue lock: %w", err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock run queue: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return fn()
+}
+
+// alive reports whether a process with pid exists.
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
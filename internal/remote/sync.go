@@ -0,0 +1,238 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"restorable.io/restorable-cli/internal/backup"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// Action describes what Sync did, or would do, for a single file.
+type Action struct {
+	Name      string
+	Direction string // "push" or "pull"
+}
+
+// Syncer mirrors local directories to prefixes in an S3-compatible bucket.
+// Files present on only one side are copied to the other; files present on both
+// are resolved in favour of the newer modification time.
+type Syncer struct {
+	client *s3.Client
+	bucket string
+	prefix string
+	sse    *backup.ServerSideEncryption
+}
+
+// NewSyncer creates a syncer for the configured bucket.
+func NewSyncer(cfg *config.S3) (*Syncer, error) {
+	sse, err := backup.NewServerSideEncryption(cfg.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+	client, err := backup.NewS3Client(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Syncer{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix, sse: sse}, nil
+}
+
+// SyncDir synchronizes the *.json and *.json.age files in localDir with <prefix><name>/ in the bucket.
+// With dryRun set, it only reports the actions it would take.
+func (s *Syncer) SyncDir(ctx context.Context, localDir, name string, dryRun bool) ([]Action, error) {
+	remotePrefix := path.Join(s.prefix, name) + "/"
+
+	local, err := listLocal(localDir)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.listRemote(ctx, remotePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	for file, localTime := range local {
+		remoteTime, ok := remote[file]
+		if !ok || localTime.After(remoteTime) {
+			actions = append(actions, Action{Name: path.Join(name, file), Direction: "push"})
+			if !dryRun {
+				if err := s.push(ctx, filepath.Join(localDir, file), remotePrefix+file); err != nil {
+					return actions, err
+				}
+			}
+		}
+	}
+	for file, remoteTime := range remote {
+		localTime, ok := local[file]
+		if !ok || remoteTime.After(localTime) {
+			actions = append(actions, Action{Name: path.Join(name, file), Direction: "pull"})
+			if !dryRun {
+				if err := s.pull(ctx, remotePrefix+file, filepath.Join(localDir, file), remoteTime); err != nil {
+					return actions, err
+				}
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+// syncable reports whether a file is a report or a plain or encrypted baseline.
+func syncable(name string) bool {
+	return strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.age")
+}
+
+// listLocal returns the modification times of the syncable files in dir.
+func listLocal(dir string) (map[string]time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	files := make(map[string]time.Time)
+	for _, entry := range entries {
+		if entry.IsDir() || !syncable(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		files[entry.Name()] = info.ModTime().UTC().Truncate(time.Second)
+	}
+	return files, nil
+}
+
+// listRemote returns the last-modified times of the syncable objects under prefix.
+func (s *Syncer) listRemote(ctx context.Context, prefix string) (map[string]time.Time, error) {
+	files := make(map[string]time.Time)
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			name := path.Base(*obj.Key)
+			if !syncable(name) || path.Dir(*obj.Key)+"/" != prefix {
+				continue
+			}
+			files[name] = obj.LastModified.UTC().Truncate(time.Second)
+		}
+	}
+	return files, nil
+}
+
+// push uploads a local file and aligns its modification time with the object's, so
+// the next sync sees both sides as equal.
+func (s *Syncer) push(ctx context.Context, localPath, key string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer file.Close()
+
+	put := &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   file,
+	}
+	s.sse.ApplyPut(put)
+	if _, err := s.client.PutObject(ctx, put); err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", s.bucket, key, err)
+	}
+
+	head := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	s.sse.ApplyHead(head)
+	stat, err := s.client.HeadObject(ctx, head)
+	if err != nil {
+		return fmt.Errorf("failed to stat s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return os.Chtimes(localPath, *stat.LastModified, *stat.LastModified)
+}
+
+// pull downloads an object, writing it atomically and stamping the remote time.
+func (s *Syncer) pull(ctx context.Context, key, localPath string, modTime time.Time) error {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	s.sse.ApplyGet(input)
+	result, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to download s3://%s/%s: %w", s.bucket, key, err)
+	}
+	defer result.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(localPath), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), ".sync-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, result.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download s3://%s/%s: %w", s.bucket, key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", localPath, err)
+	}
+	return os.Chtimes(localPath, modTime, modTime)
+}
+
+// Mirror makes sure the bucket holds the local file under <prefix><name>/, uploading
+// it when the object is missing or differs in size. It reports whether it uploaded.
+func (s *Syncer) Mirror(ctx context.Context, localPath, name string) (bool, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", localPath, err)
+	}
+	key := path.Join(s.prefix, name, filepath.Base(localPath))
+
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	s.sse.ApplyHead(input)
+	head, err := s.client.HeadObject(ctx, input)
+	if err == nil && aws.ToInt64(head.ContentLength) == info.Size() {
+		return false, nil
+	}
+	var notFound *types.NotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return false, fmt.Errorf("failed to stat s3://%s/%s: %w", s.bucket, key, err)
+	}
+	if err := s.push(ctx, localPath, key); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+
+	"restorable.io/restorable-cli/internal/verify"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.ProjectName}} – {{.ID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 1000px; color: #1f2328; padding: 0 1rem; }
+h1 { margin-bottom: 0.25rem; }
+h2 { margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.25rem; }
+.status { display: inline-block; padding: 0.25rem 0.75rem; border-radius: 4px; font-weight: 600; color: #fff; }
+.success { background: #1a7f37; }
+.failed { background: #cf222e; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9rem; }
+th, td { text-align: left; padding: 0.35rem 0.6rem; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+th { background: #f6f8fa; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.fail td { background: #ffebe9; }
+tr.warn td { background: #fff8c5; }
+tr.skip td { color: #656d76; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.25rem 1rem; }
+dt { font-weight: 600; }
+dd { margin: 0; word-break: break-all; }
+code { font-size: 0.85rem; }
+</style>
+</head>
+<body>
+<h1>{{.ProjectName}}</h1>
+<p>
+{{if .Summary.Success}}<span class="status success">✓ Success</span>{{else}}<span class="status failed">✗ Failed</span>{{end}}
+{{.Summary.PassedChecks}}/{{.Summary.TotalChecks}} checks passed{{if .Summary.CriticalFailures}}, {{.Summary.CriticalFailures}} critical{{end}}{{if .Summary.WarningFailures}}, {{.Summary.WarningFailures}} warning(s){{end}}{{if .Summary.SkippedChecks}}, {{.Summary.SkippedChecks}} skipped{{end}}
+</p>
+
+<h2>Run</h2>
+<dl>
+<dt>Report</dt><dd><code>{{.ID}}</code></dd>
+{{if .RunID}}<dt>Run</dt><dd><code>{{.RunID}}</code></dd>{{end}}
+<dt>Timestamp</dt><dd>{{time .Timestamp}}</dd>
+<dt>Project</dt><dd>{{.ProjectName}} ({{.ProjectID}})</dd>
+<dt>Machine</dt><dd>{{.MachineID}}</dd>
+<dt>Backup Source</dt><dd>{{.BackupSource}}</dd>
+{{range .BackupSourceFailures}}<dt>Failed Source</dt><dd>{{.Source}}: {{.Error}}</dd>{{end}}
+{{if .ArtifactDigest}}<dt>Artifact Digest</dt><dd><code>{{.ArtifactDigest}}</code></dd>{{end}}
+{{with .ArtifactTime}}<dt>Backup Written</dt><dd>{{time .}}</dd>{{end}}
+{{with .ArtifactChecksum}}<dt>Published Checksum</dt><dd><code>{{.Algorithm}}:{{.Expected}}</code> from {{.Source}}</dd>{{end}}
+{{range .ArtifactParts}}<dt>Part</dt><dd>{{.Name}} ({{.Size}} bytes){{with .Checksum}}, <code>{{.Algorithm}}:{{.Expected}}</code> from {{.Source}}{{end}}</dd>{{end}}
+{{with .DecryptionKey}}<dt>Decryption Key</dt><dd><code>{{.}}</code></dd>{{end}}
+{{with .ArtifactVersion}}{{if .ETag}}<dt>ETag</dt><dd><code>{{.ETag}}</code></dd>{{end}}{{if .LastModified}}<dt>Last Modified</dt><dd>{{time .LastModified}}</dd>{{end}}{{end}}
+{{if .Mode}}<dt>Mode</dt><dd>{{.Mode}}</dd>{{end}}
+{{if .Profile}}<dt>Profile</dt><dd>{{.Profile}}</dd>{{end}}
+{{if .Tables}}<dt>Canary Tables</dt><dd>{{range $i, $t := .Tables}}{{if $i}}, {{end}}{{$t}}{{end}}</dd>{{end}}
+<dt>Database</dt><dd>{{.Database.Type}} {{.Database.MajorVersion}}{{if .Database.SizeBytes}} ({{bytes .Database.SizeBytes}}){{end}}</dd>
+{{if .Database.Name}}<dt>Database Name</dt><dd>{{.Database.Name}}</dd>{{end}}
+{{if .Summary.RestoreDuration}}<dt>Restore Duration</dt><dd>{{.Summary.RestoreDuration}}</dd>{{end}}
+{{with .Producer}}
+<dt>Producer Host</dt><dd>{{or .ProducerHost "(none)"}}</dd>
+{{if .DumpCommand}}<dt>Dump Command</dt><dd><code>{{.DumpCommand}}</code></dd>{{end}}
+{{if .SourceDBVersion}}<dt>Source DB Version</dt><dd>{{.SourceDBVersion}}</dd>{{end}}
+{{if .LSN}}<dt>LSN</dt><dd>{{.LSN}}</dd>{{end}}
+{{end}}
+<dt>Signature</dt><dd>{{if .Signature}}<code>{{.Signature}}</code>{{else}}(not signed){{end}}</dd>
+</dl>
+
+<h2>Checks</h2>
+<table>
+<tr><th></th><th>Check</th><th>Level</th><th>Message</th></tr>
+{{range .Checks}}<tr class="{{checkClass .}}"><td>{{.StatusSymbol}}</td><td>{{.Name}}</td><td>{{.Level}}</td><td>{{.Message}}{{range .TableDiffs}}<br><code>{{.}}</code>{{end}}</td></tr>
+{{end}}</table>
+
+{{with tables .}}
+<h2>Tables</h2>
+<table>
+<tr><th>Table</th><th>Rows</th><th>Size</th><th>Columns</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td class="num">{{.Rows}}</td><td class="num">{{bytes .Size}}</td><td class="num">{{.Columns}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`
+
+// htmlTable is a table row in the HTML report.
+type htmlTable struct {
+	Name    string
+	Rows    int64
+	Size    int64
+	Columns int
+}
+
+// RenderHTML renders the report as a self-contained HTML page, showing times in loc.
+func RenderHTML(rpt *Report, loc *time.Location) ([]byte, error) {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"bytes":      formatSize,
+		"checkClass": checkClass,
+		"tables":     htmlTables,
+		"time":       func(t time.Time) string { return FormatTime(t, loc) },
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, rpt); err != nil {
+		return nil, fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkClass returns the row class highlighting failed and skipped checks.
+func checkClass(c verify.CheckResult) string {
+	switch {
+	case c.Skipped:
+		return "skip"
+	case c.Passed:
+		return ""
+	case c.Level == verify.LevelCritical:
+		return "fail"
+	default:
+		return "warn"
+	}
+}
+
+// htmlTables joins schema and metrics by table name, sorted by name.
+func htmlTables(rpt *Report) []htmlTable {
+	byName := make(map[string]*htmlTable)
+	row := func(schemaName, name string) *htmlTable {
+		key := schemaName + "." + name
+		if t, ok := byName[key]; ok {
+			return t
+		}
+		t := &htmlTable{Name: key}
+		byName[key] = t
+		return t
+	}
+
+	if rpt.Schema != nil {
+		for _, t := range rpt.Schema.Tables {
+			row(t.Schema, t.Name).Columns = len(t.Columns)
+		}
+	}
+	if rpt.Metrics != nil {
+		for _, tm := range rpt.Metrics.TableMetrics {
+			t := row(tm.Schema, tm.Name)
+			t.Rows = tm.RowCount
+			t.Size = tm.SizeBytes
+		}
+	}
+
+	tables := make([]htmlTable, 0, len(byName))
+	for _, t := range byName {
+		tables = append(tables, *t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// formatSize formats a byte count with binary units.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"restorable.io/restorable-cli/internal/backup"
 	"restorable.io/restorable-cli/internal/schema"
 	"restorable.io/restorable-cli/internal/verify"
 )
@@ -14,28 +15,60 @@ import (
 // ReportVersion is the current report format version.
 const ReportVersion = "1"
 
+// displayTimeFormat shows timestamps with their zone, so times in a configured
+// display timezone are never mistaken for UTC.
+const displayTimeFormat = "2006-01-02 15:04:05 MST"
+
+// FormatTime formats t for display in loc.
+func FormatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(displayTimeFormat)
+}
+
 // Report represents a verification report.
 type Report struct {
-	Version      string              `json:"version"`
-	ID           string              `json:"id"`
-	Timestamp    time.Time           `json:"timestamp"`
-	ProjectID    string              `json:"project_id"`
-	ProjectName  string              `json:"project_name"`
-	MachineID    string              `json:"machine_id"`
-	BackupSource string              `json:"backup_source"`
-	Database     DatabaseInfo        `json:"database"`
-	Schema       *schema.Schema      `json:"schema,omitempty"`
-	Metrics      *schema.Metrics     `json:"metrics,omitempty"`
-	Checks       []verify.CheckResult `json:"checks"`
-	Summary      Summary             `json:"summary"`
-	Signature    string              `json:"signature,omitempty"`
+	Version      string    `json:"version"`
+	ID           string    `json:"id"`
+	RunID        string    `json:"run_id,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	ProjectID    string    `json:"project_id"`
+	ProjectName  string    `json:"project_name"`
+	MachineID    string    `json:"machine_id"`
+	BackupSource string    `json:"backup_source"`
+	// BackupSourceFailures lists chain sources that failed before BackupSource served the artifact.
+	BackupSourceFailures []backup.SourceFailure  `json:"backup_source_failures,omitempty"`
+	ArtifactDigest       string                  `json:"artifact_digest,omitempty"`
+	ArtifactVersion      *backup.ArtifactVersion `json:"artifact_version,omitempty"`
+	// ArtifactTime is when the artifact was written, as reported by the source.
+	ArtifactTime *time.Time `json:"artifact_time,omitempty"`
+	// ArtifactChecksum is the published checksum the artifact was verified against.
+	ArtifactChecksum *backup.ArtifactChecksum `json:"artifact_checksum,omitempty"`
+	// ArtifactParts lists the parts a split backup was reassembled from.
+	ArtifactParts []backup.ArtifactPart `json:"artifact_parts,omitempty"`
+	// DecryptionKey is the key file, or passphrase variable, that decrypted the artifact.
+	DecryptionKey string `json:"decryption_key,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	Profile       string `json:"profile,omitempty"`
+	// Tables lists the only tables restored by a canary run; empty for a full run.
+	Tables        []string                 `json:"tables,omitempty"`
+	Producer      *backup.ProducerMetadata `json:"producer,omitempty"`
+	RecoveryPoint *backup.RecoveryPoint    `json:"recovery_point,omitempty"`
+	Database      DatabaseInfo             `json:"database"`
+	Schema        *schema.Schema           `json:"schema,omitempty"`
+	Metrics       *schema.Metrics          `json:"metrics,omitempty"`
+	Checks        []verify.CheckResult     `json:"checks"`
+	Summary       Summary                  `json:"summary"`
+	Signature     string                   `json:"signature,omitempty"`
 }
 
 // DatabaseInfo contains database-related metadata.
 type DatabaseInfo struct {
 	Type         string `json:"type"`
 	MajorVersion int    `json:"major_version"`
-	SizeBytes    int64  `json:"size_bytes,omitempty"`
+	// Name is the logical database the report covers in a multi-database artifact.
+	Name      string `json:"name,omitempty"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	// GeneratedCredential is true when the restore database used a per-run random password.
+	GeneratedCredential bool `json:"generated_credential,omitempty"`
 }
 
 // Summary provides an overview of the verification result.
@@ -44,6 +77,7 @@ type Summary struct {
 	TotalChecks      int    `json:"total_checks"`
 	PassedChecks     int    `json:"passed_checks"`
 	FailedChecks     int    `json:"failed_checks"`
+	SkippedChecks    int    `json:"skipped_checks,omitempty"`
 	CriticalFailures int    `json:"critical_failures"`
 	WarningFailures  int    `json:"warning_failures"`
 	RestoreDuration  string `json:"restore_duration"`
@@ -85,6 +119,85 @@ func (b *ReportBuilder) WithBackupSource(source string) *ReportBuilder {
 	return b
 }
 
+func (b *ReportBuilder) WithBackupSourceFailures(failures []backup.SourceFailure) *ReportBuilder {
+	b.report.BackupSourceFailures = failures
+	return b
+}
+
+func (b *ReportBuilder) WithArtifactDigest(digest string) *ReportBuilder {
+	b.report.ArtifactDigest = digest
+	return b
+}
+
+// WithArtifactTime records when the artifact was written.
+func (b *ReportBuilder) WithArtifactTime(t *time.Time) *ReportBuilder {
+	if t != nil {
+		utc := t.UTC()
+		t = &utc
+	}
+	b.report.ArtifactTime = t
+	return b
+}
+
+// WithArtifactChecksum records the published checksum the artifact matched.
+func (b *ReportBuilder) WithArtifactChecksum(c *backup.ArtifactChecksum) *ReportBuilder {
+	b.report.ArtifactChecksum = c
+	return b
+}
+
+// WithArtifactParts records the parts of a split backup.
+func (b *ReportBuilder) WithArtifactParts(parts []backup.ArtifactPart) *ReportBuilder {
+	b.report.ArtifactParts = parts
+	return b
+}
+
+// WithDecryptionKey records the key that decrypted the artifact.
+func (b *ReportBuilder) WithDecryptionKey(key string) *ReportBuilder {
+	b.report.DecryptionKey = key
+	return b
+}
+
+// WithArtifactVersion records the ETag and Last-Modified time the server reported.
+func (b *ReportBuilder) WithArtifactVersion(v *backup.ArtifactVersion) *ReportBuilder {
+	b.report.ArtifactVersion = v
+	return b
+}
+
+func (b *ReportBuilder) WithProducer(m *backup.ProducerMetadata) *ReportBuilder {
+	b.report.Producer = m
+	return b
+}
+
+// WithRecoveryPoint records the point-in-time recovery performed by the restore.
+func (b *ReportBuilder) WithRecoveryPoint(rp *backup.RecoveryPoint) *ReportBuilder {
+	b.report.RecoveryPoint = rp
+	return b
+}
+
+// WithRunID records the verification run that produced the report. Reports of a
+// multi-database run share the run ID.
+func (b *ReportBuilder) WithRunID(runID string) *ReportBuilder {
+	b.report.RunID = runID
+	return b
+}
+
+func (b *ReportBuilder) WithMode(mode string) *ReportBuilder {
+	b.report.Mode = mode
+	return b
+}
+
+// WithTables records the tables a canary run restored.
+func (b *ReportBuilder) WithTables(tables []string) *ReportBuilder {
+	b.report.Tables = tables
+	return b
+}
+
+// WithProfile records the verification profile the run used.
+func (b *ReportBuilder) WithProfile(profile string) *ReportBuilder {
+	b.report.Profile = profile
+	return b
+}
+
 func (b *ReportBuilder) WithDatabase(dbType string, majorVersion int) *ReportBuilder {
 	b.report.Database = DatabaseInfo{
 		Type:         dbType,
@@ -93,6 +206,17 @@ func (b *ReportBuilder) WithDatabase(dbType string, majorVersion int) *ReportBui
 	return b
 }
 
+// WithDatabaseName records the logical database the report covers.
+func (b *ReportBuilder) WithDatabaseName(name string) *ReportBuilder {
+	b.report.Database.Name = name
+	return b
+}
+
+func (b *ReportBuilder) WithGeneratedCredential(generated bool) *ReportBuilder {
+	b.report.Database.GeneratedCredential = generated
+	return b
+}
+
 func (b *ReportBuilder) WithSchema(s *schema.Schema) *ReportBuilder {
 	b.report.Schema = s
 	return b
@@ -118,10 +242,14 @@ func (b *ReportBuilder) Build() *Report {
 }
 
 func (b *ReportBuilder) computeSummary() {
-	total := len(b.report.Checks)
-	var passed, failed, critical, warning int
+	var total, passed, failed, skipped, critical, warning int
 
 	for _, c := range b.report.Checks {
+		if c.Skipped {
+			skipped++
+			continue
+		}
+		total++
 		if c.Passed {
 			passed++
 		} else {
@@ -140,6 +268,7 @@ func (b *ReportBuilder) computeSummary() {
 		TotalChecks:      total,
 		PassedChecks:     passed,
 		FailedChecks:     failed,
+		SkippedChecks:    skipped,
 		CriticalFailures: critical,
 		WarningFailures:  warning,
 	}
@@ -207,13 +336,24 @@ func ListReports(dir string) ([]*ReportSummary, error) {
 			continue // Skip invalid reports
 		}
 
-		reports = append(reports, &ReportSummary{
-			ID:        report.ID,
-			Timestamp: report.Timestamp,
-			ProjectID: report.ProjectID,
-			Success:   report.Summary.Success,
-			Path:      path,
-		})
+		summary := &ReportSummary{
+			ID:               report.ID,
+			Timestamp:        report.Timestamp,
+			ProjectID:        report.ProjectID,
+			Success:          report.Summary.Success,
+			Path:             path,
+			Mode:             report.Mode,
+			DatabaseType:     report.Database.Type,
+			DatabaseVersion:  report.Database.MajorVersion,
+			DBSizeBytes:      report.Database.SizeBytes,
+			ArtifactDigest:   report.ArtifactDigest,
+			CriticalFailures: report.Summary.CriticalFailures,
+			WarningFailures:  report.Summary.WarningFailures,
+		}
+		if report.Metrics != nil {
+			summary.RestoreDuration = report.Metrics.RestoreDuration
+		}
+		reports = append(reports, summary)
 	}
 
 	// Sort by timestamp, newest first
@@ -228,11 +368,65 @@ func ListReports(dir string) ([]*ReportSummary, error) {
 	return reports, nil
 }
 
+// LoadMetricsHistory returns the metrics of up to limit most recent reports of a
+// project, oldest first. Reports without metrics and canary reports are skipped.
+func LoadMetricsHistory(dir, projectID string, limit int) ([]*schema.Metrics, error) {
+	reports, err := ListReports(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*schema.Metrics
+	for _, r := range reports {
+		if len(history) == limit {
+			break
+		}
+		if r.ProjectID != projectID {
+			continue
+		}
+		rpt, err := LoadReport(r.Path)
+		// Canary runs only cover some tables
+		if err != nil || rpt.Metrics == nil || len(rpt.Tables) > 0 {
+			continue
+		}
+		history = append(history, rpt.Metrics)
+	}
+
+	// ListReports is newest first
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
 // ReportSummary is a lightweight summary for listing reports.
 type ReportSummary struct {
-	ID        string
-	Timestamp time.Time
-	ProjectID string
-	Success   bool
-	Path      string
+	ID               string
+	Timestamp        time.Time
+	ProjectID        string
+	Success          bool
+	Path             string
+	Mode             string
+	DatabaseType     string
+	DatabaseVersion  int
+	RestoreDuration  time.Duration
+	DBSizeBytes      int64
+	ArtifactDigest   string
+	CriticalFailures int
+	WarningFailures  int
+}
+
+// ExpiredReports returns the reports in dir written before cutoff, oldest first.
+func ExpiredReports(dir string, cutoff time.Time) ([]*ReportSummary, error) {
+	reports, err := ListReports(dir)
+	if err != nil {
+		return nil, err
+	}
+	var expired []*ReportSummary
+	for i := len(reports) - 1; i >= 0; i-- {
+		if reports[i].Timestamp.Before(cutoff) {
+			expired = append(expired, reports[i])
+		}
+	}
+	return expired, nil
 }
@@ -2,10 +2,16 @@ package report
 
 import (
 	"crypto/ed25519"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
 )
 
 // Sign signs the report using Ed25519 and stores the signature in the report.
@@ -59,13 +65,50 @@ func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key file: %w", err)
 	}
+	return ParsePrivateKey(data)
+}
+
+// ParsePrivateKey accepts the raw 64-byte key written by init, a PEM-encoded
+// PKCS#8 key ("PRIVATE KEY", as written by openssl genpkey) or an unencrypted
+// OpenSSH key ("OPENSSH PRIVATE KEY", as written by ssh-keygen).
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if len(data) == ed25519.PrivateKeySize {
+		return ed25519.PrivateKey(data), nil
+	}
 
-	// The key file contains the raw 64-byte private key
-	if len(data) != ed25519.PrivateKeySize {
-		return nil, fmt.Errorf("invalid private key size: expected %d bytes, got %d", ed25519.PrivateKeySize, len(data))
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key: expected %d raw bytes, a PEM PKCS#8 key or an OpenSSH key, got %d bytes", ed25519.PrivateKeySize, len(data))
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "OPENSSH PRIVATE KEY":
+		key, err = ssh.ParseRawPrivateKey(data)
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("OpenSSH private key is encrypted; remove the passphrase with ssh-keygen -p first")
+		}
+	case "ENCRYPTED PRIVATE KEY":
+		return nil, fmt.Errorf("PKCS#8 private key is encrypted; decrypt it with openssl pkey first")
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in private key", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
 	}
 
-	return ed25519.PrivateKey(data), nil
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("private key is a %T, not an Ed25519 key", key)
+	}
 }
 
 // LoadPublicKey loads an Ed25519 public key from a file.
@@ -74,11 +117,41 @@ func LoadPublicKey(path string) (ed25519.PublicKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read public key file: %w", err)
 	}
+	return ParsePublicKey(data)
+}
+
+// ParsePublicKey accepts the raw 32-byte key written by init, the same key
+// base64-encoded, as it is usually published on a web page, a PEM-encoded PKIX
+// key ("PUBLIC KEY") or an OpenSSH public key line ("ssh-ed25519 AAAA...").
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if len(data) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(data), nil
+	}
 
-	// The key file contains the raw 32-byte public key
-	if len(data) != ed25519.PublicKeySize {
-		return nil, fmt.Errorf("invalid public key size: expected %d bytes, got %d", ed25519.PublicKeySize, len(data))
+	trimmed := strings.TrimSpace(string(data))
+	decoded, err := base64.StdEncoding.DecodeString(trimmed)
+	if err == nil && len(decoded) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(decoded), nil
 	}
 
-	return ed25519.PublicKey(data), nil
+	var key any
+	if block, _ := pem.Decode(data); block != nil && block.Type == "PUBLIC KEY" {
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+	} else if strings.HasPrefix(trimmed, ssh.KeyAlgoED25519+" ") {
+		sshKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		key = sshKey.(ssh.CryptoPublicKey).CryptoPublicKey()
+	} else {
+		return nil, fmt.Errorf("invalid public key: expected %d raw bytes, their base64 encoding, a PEM key or an OpenSSH key, got %d bytes", ed25519.PublicKeySize, len(data))
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is a %T, not an Ed25519 key", key)
+	}
+	return publicKey, nil
 }
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Summary audiences.
+const (
+	AudienceExec = "exec" // Plain language, no hostnames, paths or table names
+	AudienceOps  = "ops"  // Adds source, database and failure details
+)
+
+// summaryTemplates holds the plain-language summary templates by audience and language.
+var summaryTemplates = map[string]map[string]string{
+	AudienceExec: {
+		"en": `On {{.Date}}, the {{.ProjectName}} backup was restored into an isolated test environment and checked automatically. ` +
+			`{{if .Success}}The restore succeeded and all critical checks passed{{if .Warnings}}, with {{.Warnings}} minor finding(s) to review{{end}}.` +
+			`{{else}}The restore did NOT pass verification: {{.Critical}} critical check(s) failed and the backup should not be relied on until this is resolved.{{end}}` +
+			`{{if .Duration}} A full recovery took {{.Duration}}, which is the recovery time achieved for this system.{{end}}`,
+		"de": `Am {{.Date}} wurde das Backup von {{.ProjectName}} in einer isolierten Testumgebung wiederhergestellt und automatisch geprüft. ` +
+			`{{if .Success}}Die Wiederherstellung war erfolgreich und alle kritischen Prüfungen wurden bestanden{{if .Warnings}}, mit {{.Warnings}} kleineren Auffälligkeit(en) zur Prüfung{{end}}.` +
+			`{{else}}Die Wiederherstellung hat die Prüfung NICHT bestanden: {{.Critical}} kritische Prüfung(en) schlugen fehl, das Backup ist bis zur Klärung nicht verlässlich.{{end}}` +
+			`{{if .Duration}} Eine vollständige Wiederherstellung dauerte {{.Duration}}; dies ist die erreichte Wiederherstellungszeit für dieses System.{{end}}`,
+	},
+	AudienceOps: {
+		"en": `{{.Date}}: {{.ProjectName}} ({{.Database}}) backup from {{.Source}} was restored in {{if .Duration}}{{.Duration}}{{else}}an unknown time{{end}}. ` +
+			`{{.Passed}}/{{.Total}} checks passed across {{.Tables}} tables. ` +
+			`{{if .Success}}Status: verified{{if .Warnings}} with {{.Warnings}} warning(s){{end}}.{{else}}Status: FAILED ({{.Critical}} critical).{{end}}` +
+			`{{if .Failures}} Failing checks: {{.Failures}}.{{end}} Report {{.ID}}.`,
+		"de": `{{.Date}}: Backup von {{.ProjectName}} ({{.Database}}) aus {{.Source}} wurde in {{if .Duration}}{{.Duration}}{{else}}unbekannter Zeit{{end}} wiederhergestellt. ` +
+			`{{.Passed}}/{{.Total}} Prüfungen über {{.Tables}} Tabellen bestanden. ` +
+			`{{if .Success}}Status: verifiziert{{if .Warnings}} mit {{.Warnings}} Warnung(en){{end}}.{{else}}Status: FEHLGESCHLAGEN ({{.Critical}} kritisch).{{end}}` +
+			`{{if .Failures}} Fehlgeschlagene Prüfungen: {{.Failures}}.{{end}} Bericht {{.ID}}.`,
+	},
+}
+
+// summaryData is the data available to summary templates.
+type summaryData struct {
+	ID          string
+	Date        string
+	ProjectName string
+	Database    string
+	Source      string
+	Success     bool
+	Total       int
+	Passed      int
+	Critical    int
+	Warnings    int
+	Tables      int
+	Duration    string
+	Failures    string
+}
+
+// RenderSummary renders a one-paragraph plain-language summary of the report
+// for the given audience and language, dated in loc.
+func RenderSummary(rpt *Report, audience, lang string, loc *time.Location) (string, error) {
+	byLang, ok := summaryTemplates[audience]
+	if !ok {
+		return "", fmt.Errorf("unsupported audience %q (use %s or %s)", audience, AudienceExec, AudienceOps)
+	}
+	text, ok := byLang[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported language %q for audience %s", lang, audience)
+	}
+
+	tmpl, err := template.New(audience + "-" + lang).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse summary template: %w", err)
+	}
+
+	data := summaryData{
+		ID:          rpt.ID,
+		Date:        rpt.Timestamp.In(loc).Format("2006-01-02"),
+		ProjectName: rpt.ProjectName,
+		Database:    fmt.Sprintf("%s %d", rpt.Database.Type, rpt.Database.MajorVersion),
+		Source:      rpt.BackupSource,
+		Success:     rpt.Summary.Success,
+		Total:       rpt.Summary.TotalChecks,
+		Passed:      rpt.Summary.PassedChecks,
+		Critical:    rpt.Summary.CriticalFailures,
+		Warnings:    rpt.Summary.WarningFailures,
+	}
+	if rpt.Schema != nil {
+		data.Tables = len(rpt.Schema.Tables)
+	}
+	if rpt.Metrics != nil && rpt.Metrics.RestoreDuration > 0 {
+		data.Duration = rpt.Metrics.RestoreDuration.Round(time.Second).String()
+	}
+
+	var failures []string
+	for _, c := range rpt.Checks {
+		if !c.Passed {
+			failures = append(failures, c.Name)
+		}
+	}
+	data.Failures = strings.Join(failures, ", ")
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render summary: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,77 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+)
+
+// IndexVerifier is implemented by restorers that can check index structures for
+// corruption.
+type IndexVerifier interface {
+	// VerifyIndexes checks every B-tree index, returning a description of each
+	// corrupted one. heapAllIndexed also checks that every table row is indexed,
+	// which reads the whole table.
+	VerifyIndexes(ctx context.Context, heapAllIndexed bool) ([]string, error)
+}
+
+// VerifyIndexes runs amcheck's bt_index_check on every valid B-tree index. The
+// extension is installed for the check and removed again if the restore did not
+// bring it, so the schema of later runs is unaffected.
+func (r *PostgresRestorer) VerifyIndexes(ctx context.Context, heapAllIndexed bool) ([]string, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	var installed bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'amcheck')`).Scan(&installed); err != nil {
+		return nil, fmt.Errorf("failed to look up the amcheck extension: %w", err)
+	}
+	if !installed {
+		if _, err := r.db.ExecContext(ctx, `CREATE EXTENSION amcheck`); err != nil {
+			return nil, fmt.Errorf("failed to install the amcheck extension (is it in the restore image?): %w", err)
+		}
+		defer r.db.ExecContext(context.WithoutCancel(ctx), `DROP EXTENSION IF EXISTS amcheck`)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE am.amname = 'btree'
+		  AND c.relkind = 'i' -- Partitioned indexes hold no data; their partitions are checked
+		  AND i.indisvalid AND i.indisready
+		  AND c.relpersistence <> 't'
+		ORDER BY n.nspname, c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query B-tree indexes: %w", err)
+	}
+	var indexes []string
+	for rows.Next() {
+		var schemaName, name string
+		if err := rows.Scan(&schemaName, &name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		indexes = append(indexes, quotePostgresName(schemaName, name))
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating index rows: %w", err)
+	}
+
+	// amcheck raises an error for the first inconsistency found in an index
+	var problems []string
+	for _, index := range indexes {
+		if _, err := r.db.ExecContext(ctx, `SELECT bt_index_check($1::regclass, $2)`, index, heapAllIndexed); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			problems = append(problems, fmt.Sprintf("%s: %v", index, err))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,350 @@
+package restore
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"restorable.io/restorable-cli/internal/backup"
+)
+
+const (
+	// basebackupVolumePath is where the data volume is mounted; the data directory
+	// is moved into pgdata under it, so extraction and rename stay on one filesystem.
+	basebackupVolumePath = "/var/lib/postgresql/restorable"
+	basebackupPGData     = basebackupVolumePath + "/pgdata"
+	basebackupHBAFile    = basebackupPGData + "/restorable_hba.conf"
+	// basebackupUser is created after startup, since the backup carries the source's own roles.
+	basebackupUser = "restorable"
+)
+
+// dataDirArchive describes a tar archive holding a Postgres data directory.
+type dataDirArchive struct {
+	// Root is the directory in the archive containing PG_VERSION; "." for the top level.
+	Root         string
+	MajorVersion int
+	Gzipped      bool
+	// ArchiveRecovery is set when the archive carries WAL to replay, as packed by the
+	// walg source, instead of being restored as a standalone copy.
+	ArchiveRecovery bool
+}
+
+// findDataDir reports whether the file is a tar (optionally gzipped) of a data
+// directory, as written by pg_basebackup -Ft or by archiving PGDATA. It returns nil
+// for anything else, including pg_dump tar archives.
+func findDataDir(file string) (*dataDirArchive, error) {
+	tr, gzipped, closeArchive, err := openTarArchive(file)
+	if err != nil || tr == nil {
+		return nil, err
+	}
+	defer closeArchive()
+
+	// PG_VERSION sits at the top of the data directory and in each database
+	// directory below base/; the shallowest one marks the root.
+	archive := &dataDirArchive{Gzipped: gzipped}
+	found := false
+	dirs := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag == tar.TypeDir {
+			dirs[name] = true
+		}
+		if path.Base(name) != "PG_VERSION" || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		root := path.Dir(name)
+		if found && pathDepth(root) >= pathDepth(archive.Root) {
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, 16))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		major, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil {
+			continue
+		}
+		archive.Root = root
+		archive.MajorVersion = major
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	archive.ArchiveRecovery = dirs[path.Join(archive.Root, backup.WALDirName)]
+	return archive, nil
+}
+
+// openTarArchive opens the file as a tar archive, decompressing it if gzipped. It
+// returns a nil reader if the file is not a tar archive.
+func openTarArchive(file string) (tr *tar.Reader, gzipped bool, closeArchive func(), err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, false, nil, fmt.Errorf("failed to open backup file: %w", err)
+	}
+	closers := []io.Closer{f}
+	closeArchive = func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i].Close()
+		}
+	}
+
+	buffered := bufio.NewReader(f)
+	var r io.Reader = buffered
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			closeArchive()
+			return nil, false, nil, nil
+		}
+		closers = append(closers, gz)
+		r = gz
+		gzipped = true
+	}
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(r, header)
+	if n < 262 || !bytes.Equal(header[257:262], []byte("ustar")) {
+		closeArchive()
+		return nil, false, nil, nil
+	}
+	return tar.NewReader(io.MultiReader(bytes.NewReader(header[:n]), r)), gzipped, closeArchive, nil
+}
+
+// pathDepth returns the number of elements in a cleaned relative path; 0 for ".".
+func pathDepth(p string) int {
+	if p == "." {
+		return 0
+	}
+	return strings.Count(p, "/") + 1
+}
+
+// restorePhysical extracts a data directory into a volume in a throwaway container,
+// then starts the server on it. The server replays the WAL included in the backup
+// before accepting connections.
+func (r *PostgresRestorer) restorePhysical(ctx context.Context, backupFile string, archive *dataDirArchive) error {
+	if r.mode != ModeFull {
+		return fmt.Errorf("%s mode is not supported for physical backups", r.mode)
+	}
+	if r.config.Docker.Security.ReadOnlyRootfs {
+		return fmt.Errorf("docker.security.read_only_rootfs is not supported for physical backups")
+	}
+	if major := r.config.Database.MajorVersion; major != 0 && major != archive.MajorVersion {
+		return fmt.Errorf("physical backup is from PostgreSQL %d but database.major_version is %d; the restore image must match the backup's major version",
+			archive.MajorVersion, major)
+	}
+	fmt.Printf("✓ Detected physical backup (PostgreSQL %d data directory).\n", archive.MajorVersion)
+	r.physical = true
+
+	// Archive recovery keeps the restore_command and target written into the backup
+	settings := []string{
+		"-c", "hba_file=" + basebackupHBAFile,
+		"-c", "listen_addresses=*",
+		"-c", "ssl=off",
+		"-c", "archive_mode=off",
+		"-c", "primary_conninfo=",
+	}
+	if archive.ArchiveRecovery {
+		fmt.Println("✓ Backup includes WAL; running point-in-time recovery.")
+	} else {
+		settings = append(settings, "-c", "restore_command=")
+	}
+
+	restoreStart := time.Now()
+	if err := r.prepareDataDir(ctx, backupFile, archive); err != nil {
+		return err
+	}
+
+	labels := containerLabels(r.config, r.runID)
+	opts := []testcontainers.ContainerCustomizer{
+		postgres.WithDatabase(r.config.Database.Restore.DBName),
+		postgres.WithUsername(basebackupUser),
+		postgres.WithPassword(r.password),
+		testcontainers.WithEnv(map[string]string{"PGDATA": basebackupPGData}),
+		testcontainers.WithMounts(testcontainers.VolumeMount(r.dataVolumeName(), basebackupVolumePath)),
+		// Settings from the source that cannot work in the container are overridden
+		testcontainers.WithCmd(append([]string{"postgres"}, settings...)...),
+		// No init restart when PGDATA already exists, so the first ready message is final
+		testcontainers.WithWaitStrategy(wait.ForLog("database system is ready to accept connections").
+			WithStartupTimeout(30 * time.Minute)),
+		testcontainers.WithLabels(labels),
+		testcontainers.WithName(containerName(r.config, r.runID)),
+	}
+
+	securityOpts, err := securityOptions(r.config, r.runID, "/var/run/postgresql")
+	if err != nil {
+		return err
+	}
+	opts = append(opts, securityOpts...)
+
+	if r.config.Docker.IsolateNetwork {
+		isolation, err := newIsolatedNetwork(ctx, labels)
+		if err != nil {
+			return err
+		}
+		r.isolation = isolation
+		opts = append(opts, isolation.containerOption())
+	}
+
+	pgContainer, err := postgres.Run(ctx, r.config.Database.Restore.DockerImage, opts...)
+	if pgContainer != nil {
+		r.container = pgContainer
+	}
+	if err != nil {
+		return fmt.Errorf("could not start postgres container: %w", err)
+	}
+	fmt.Printf("✓ Database container started: %s\n", containerName(r.config, r.runID))
+
+	// Local connections are trusted, so the source's superuser creates the query role
+	role := fmt.Sprintf(`DO $$ BEGIN
+IF EXISTS (SELECT FROM pg_roles WHERE rolname = '%[1]s') THEN
+  ALTER ROLE %[1]s LOGIN SUPERUSER PASSWORD '%[2]s';
+ELSE
+  CREATE ROLE %[1]s LOGIN SUPERUSER PASSWORD '%[2]s';
+END IF;
+END $$;`, basebackupUser, strings.ReplaceAll(r.password, "'", "''"))
+	output, err := runInContainer(ctx, pgContainer, "psql", []string{
+		"psql",
+		"--username", r.config.Database.Restore.User,
+		"--dbname", "postgres",
+		"--no-password",
+		"-v", "ON_ERROR_STOP=1",
+		"--command", role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s role as %s (database.restore.user must be a superuser of the source cluster): %w",
+			basebackupUser, r.config.Database.Restore.User, err)
+	}
+	if r.verbose && len(output) > 0 {
+		fmt.Println(string(output))
+	}
+
+	r.restoreDuration = time.Since(restoreStart)
+	fmt.Println("✓ Database restore completed successfully from physical backup.")
+
+	connStr, err := r.connectionString(ctx, r.password)
+	if err != nil {
+		return err
+	}
+	r.db, err = openDB("postgres", connStr, r.logSQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	r.dsn = connStr
+	return nil
+}
+
+// prepareDataDir extracts the archive into the data volume with networking disabled,
+// and replaces the source's access and recovery settings.
+func (r *PostgresRestorer) prepareDataDir(ctx context.Context, backupFile string, archive *dataDirArchive) (err error) {
+	fmt.Println("Extracting data directory...")
+	prepare, err := testcontainers.Run(ctx, r.config.Database.Restore.DockerImage,
+		testcontainers.WithEntrypoint("sleep", "infinity"),
+		testcontainers.WithLabels(containerLabels(r.config, r.runID)),
+		testcontainers.WithName(containerName(r.config, r.runID)+"-prepare"),
+		testcontainers.WithMounts(testcontainers.VolumeMount(r.dataVolumeName(), basebackupVolumePath)),
+		testcontainers.WithHostConfigModifier(func(hc *container.HostConfig) {
+			hc.NetworkMode = "none"
+		}),
+	)
+	if prepare != nil {
+		defer func() {
+			// Keep the prepared volume for the server; drop it if preparation failed
+			var terminateOpts []testcontainers.TerminateOption
+			if err != nil {
+				terminateOpts = append(terminateOpts, testcontainers.RemoveVolumes(r.dataVolumeName()))
+			}
+			prepare.Terminate(context.Background(), terminateOpts...)
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("could not start prepare container: %w", err)
+	}
+
+	archivePath := path.Join(basebackupVolumePath, "backup.tar")
+	if err := prepare.CopyFileToContainer(ctx, backupFile, archivePath, 0644); err != nil {
+		return fmt.Errorf("failed to copy backup file into container: %w", err)
+	}
+
+	extractDir := path.Join(basebackupVolumePath, "extract")
+	tarFlags := "-xf"
+	if archive.Gzipped {
+		tarFlags = "-xzf"
+	}
+	script := strings.Join([]string{
+		"set -e",
+		fmt.Sprintf("mkdir -p %s", extractDir),
+		fmt.Sprintf("tar %s %s -C %s", tarFlags, archivePath, extractDir),
+		fmt.Sprintf("rm -f %s", archivePath),
+		fmt.Sprintf("mv %s %s", shellQuote(path.Join(extractDir, archive.Root)), basebackupPGData),
+		fmt.Sprintf("rm -rf %s", extractDir),
+		"cd " + basebackupPGData,
+		// Drop the lock file and standby marker of the source
+		"rm -f postmaster.pid postmaster.opts standby.signal",
+		"mkdir -p pg_wal",
+		// Debian-style clusters keep their configuration outside the data directory
+		"[ -f postgresql.conf ] || : > postgresql.conf",
+		"[ -f pg_ident.conf ] || : > pg_ident.conf",
+		fmt.Sprintf("printf 'local all all trust\\nhost all all all md5\\n' > %s", basebackupHBAFile),
+		"chown -R postgres:postgres " + basebackupVolumePath,
+		"chmod 700 " + basebackupPGData,
+	}, "\n")
+	if !archive.ArchiveRecovery {
+		// Start as a primary rather than following the source's recovery settings
+		script += "\nrm -f " + path.Join(basebackupPGData, "recovery.signal")
+	}
+	output, err := runInContainer(ctx, prepare, "extract", []string{"sh", "-c", script})
+	if err != nil {
+		return err
+	}
+	if r.verbose && len(output) > 0 {
+		fmt.Println(string(output))
+	}
+
+	fmt.Println("✓ Data directory prepared.")
+	return nil
+}
+
+// dataVolumeName returns the per-run volume holding an extracted physical backup.
+func (r *PostgresRestorer) dataVolumeName() string {
+	return containerName(r.config, r.runID) + "-data"
+}
+
+// LastReplayedTransaction returns the commit time of the last transaction replayed
+// during recovery, or nil if none was replayed.
+func (r *PostgresRestorer) LastReplayedTransaction(ctx context.Context) (*time.Time, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	var replayed sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT pg_last_xact_replay_timestamp()`).Scan(&replayed); err != nil {
+		return nil, fmt.Errorf("failed to read recovery point: %w", err)
+	}
+	if !replayed.Valid {
+		return nil, nil
+	}
+	t := replayed.Time.UTC()
+	return &t, nil
+}
@@ -0,0 +1,514 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+const (
+	cockroachPort = 26257
+	// cockroachCertsDir holds the certificates the image generates for start-single-node.
+	cockroachCertsDir = "/cockroach/certs"
+	// cockroachExternDir is where nodelocal://1/ URIs resolve on a single node.
+	cockroachExternDir = "/cockroach/cockroach-data/extern"
+	// cockroachBackupURI addresses cockroachExternDir/restorable.
+	cockroachBackupURI = "nodelocal://1/restorable"
+	cockroachUser      = "restorable"
+)
+
+// cockroachLocateBackup prints how to address the backup extracted to the current
+// directory: "LATEST" and the collection path for a backup collection, or the
+// subdirectory and collection path of a single backup.
+const cockroachLocateBackup = `
+latest=$(find . \( -name LATEST -type f \) -o \( -path '*/metadata/latest' -type d \) | head -n 1)
+if [ -n "$latest" ]; then
+  root=$(dirname "$latest")
+  case "$latest" in */metadata/latest) root=$(dirname "$root") ;; esac
+  echo "LATEST $root"
+  exit 0
+fi
+manifest=$(find . -name BACKUP_MANIFEST -type f | head -n 1)
+if [ -z "$manifest" ]; then
+  echo "no BACKUP_MANIFEST or LATEST file found in the backup archive" >&2
+  exit 1
+fi
+dir=$(dirname "$manifest")
+echo "$(basename "$dir") $(dirname "$dir")"
+`
+
+// CockroachRestorer restores CockroachDB BACKUP artifacts, packed as a tar of the
+// backup collection or of a single backup directory, into an ephemeral single-node
+// cluster. Schema and metrics come from crdb_internal and information_schema.
+type CockroachRestorer struct {
+	config          *config.Config
+	verbose         bool
+	logSQL          bool
+	mode            Mode
+	runID           string
+	password        string
+	database        string
+	container       *testcontainers.DockerContainer
+	isolation       *isolatedNetwork
+	db              *sql.DB
+	dsn             string
+	restoreDuration time.Duration
+}
+
+// NewCockroachRestorer creates a new restorer instance.
+func NewCockroachRestorer(cfg *config.Config, opts Options) *CockroachRestorer {
+	return &CockroachRestorer{
+		config:   cfg,
+		verbose:  opts.Verbose,
+		logSQL:   opts.LogSQL,
+		mode:     opts.Mode,
+		runID:    opts.RunID,
+		password: opts.Password,
+		database: cfg.Database.Restore.DBName,
+	}
+}
+
+// Restore starts a single-node cluster, runs RESTORE on the backup and connects to
+// the restored database.
+func (r *CockroachRestorer) Restore(ctx context.Context, backupStream io.Reader) error {
+	if r.mode != ModeFull {
+		return fmt.Errorf("%s mode is not supported for database type: cockroachdb", r.mode)
+	}
+
+	buffered := bufio.NewReader(backupStream)
+	header, _ := buffered.Peek(512)
+	tarFlags := "-xf"
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		tarFlags = "-xzf"
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+	default:
+		return fmt.Errorf("unrecognized CockroachDB backup format; expected a tar of a BACKUP directory")
+	}
+
+	tmpFile, err := os.CreateTemp("", "restorable-backup-*.tar")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, buffered); err != nil {
+		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := r.startServer(ctx); err != nil {
+		return err
+	}
+
+	containerBackupPath := path.Join(backupDir(r.config), "backup.tar")
+	if err := r.container.CopyFileToContainer(ctx, tmpFile.Name(), containerBackupPath, 0644); err != nil {
+		return fmt.Errorf("failed to copy backup file into container: %w", err)
+	}
+
+	restoreStart := time.Now()
+	// Extract one level down, so a single backup at the top of the archive still
+	// sits in a subdirectory of the collection
+	collectionPath := path.Join(cockroachExternDir, "restorable")
+	extract := fmt.Sprintf("mkdir -p %[1]s/backup && tar %[2]s %[3]s -C %[1]s/backup", collectionPath, tarFlags, containerBackupPath)
+	if _, err := runInContainer(ctx, r.container, "extract", []string{"sh", "-c", extract}); err != nil {
+		return err
+	}
+
+	located, err := runInContainer(ctx, r.container, "locate backup",
+		[]string{"sh", "-c", "cd " + collectionPath + " && " + cockroachLocateBackup})
+	if err != nil {
+		return err
+	}
+	subdir, collection, _ := strings.Cut(strings.TrimSpace(string(located)), " ")
+	collectionURI := quoteCockroachString(cockroachBackupURI + path.Join("/", collection))
+	from := "LATEST IN " + collectionURI
+	if subdir != "LATEST" {
+		from = fmt.Sprintf("%s IN %s", quoteCockroachString("/"+subdir), collectionURI)
+	}
+
+	restoreStmt, databases, err := r.restoreStatement(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Restoring backup with RESTORE...")
+	logs, err := r.sql(ctx, restoreStmt)
+	if err != nil {
+		return err
+	}
+	r.restoreDuration = time.Since(restoreStart)
+	if r.verbose && len(logs) > 0 {
+		fmt.Println("--- RESTORE output ---")
+		fmt.Println(string(logs))
+		fmt.Println("-------------------------")
+	}
+	fmt.Println("✓ Database restore completed successfully with RESTORE.")
+
+	// Default to the only restored database when the configured one is not in the backup
+	if len(databases) == 1 && !containsString(databases, r.database) {
+		r.database = databases[0]
+	}
+
+	createUser := fmt.Sprintf("CREATE USER IF NOT EXISTS %s WITH PASSWORD %s; GRANT admin TO %s;",
+		cockroachUser, quoteCockroachString(r.password), cockroachUser)
+	if _, err := r.sql(ctx, createUser); err != nil {
+		return err
+	}
+
+	return r.connect(ctx)
+}
+
+// restoreStatement inspects the backup and returns the RESTORE statement for it,
+// with the databases it restores. Cluster backups are restored whole.
+func (r *CockroachRestorer) restoreStatement(ctx context.Context, from string) (string, []string, error) {
+	out, err := r.sql(ctx, fmt.Sprintf(
+		`SELECT DISTINCT database_name, is_full_cluster FROM [SHOW BACKUP FROM %s] WHERE object_type = 'database' ORDER BY database_name`, from),
+		"--format=csv")
+	if err != nil {
+		return "", nil, err
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse SHOW BACKUP output: %w", err)
+	}
+
+	var databases []string
+	fullCluster := false
+	for _, rec := range records[min(1, len(records)):] {
+		if len(rec) < 2 {
+			continue
+		}
+		if rec[1] == "true" {
+			fullCluster = true
+		}
+		if rec[0] != "system" && rec[0] != "defaultdb" && rec[0] != "postgres" {
+			databases = append(databases, rec[0])
+		}
+	}
+
+	if fullCluster {
+		return "RESTORE FROM " + from, databases, nil
+	}
+	if len(databases) == 0 {
+		return "", nil, fmt.Errorf("backup contains no databases; table-level backups are not supported")
+	}
+	quoted := make([]string, len(databases))
+	for i, db := range databases {
+		quoted[i] = quoteCockroachIdent(db)
+	}
+	return fmt.Sprintf("RESTORE DATABASE %s FROM %s", strings.Join(quoted, ", "), from), databases, nil
+}
+
+// startServer starts a secure single-node cluster with the image's generated certificates.
+func (r *CockroachRestorer) startServer(ctx context.Context) error {
+	labels := containerLabels(r.config, r.runID)
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithCmd("start-single-node"),
+		testcontainers.WithExposedPorts(fmt.Sprintf("%d/tcp", cockroachPort)),
+		testcontainers.WithWaitStrategy(wait.ForExec(
+			[]string{"cockroach", "sql", "--certs-dir=" + cockroachCertsDir, "-e", "SELECT 1"}).
+			WithStartupTimeout(5 * time.Minute)),
+		testcontainers.WithLabels(labels),
+		testcontainers.WithName(containerName(r.config, r.runID)),
+	}
+
+	securityOpts, err := securityOptions(r.config, r.runID, "/tmp")
+	if err != nil {
+		return err
+	}
+	opts = append(opts, securityOpts...)
+
+	if r.config.Docker.IsolateNetwork {
+		isolation, err := newIsolatedNetwork(ctx, labels)
+		if err != nil {
+			return err
+		}
+		r.isolation = isolation
+		opts = append(opts, isolation.containerOption())
+	}
+
+	ctr, err := testcontainers.Run(ctx, r.config.Database.Restore.DockerImage, opts...)
+	if ctr != nil {
+		r.container = ctr
+	}
+	if err != nil {
+		return fmt.Errorf("could not start cockroachdb container: %w", err)
+	}
+
+	fmt.Printf("✓ Database container started: %s\n", containerName(r.config, r.runID))
+	return nil
+}
+
+// sql runs statements as root inside the container. Statements are piped through
+// stdin so the password in CREATE USER never appears in argv.
+func (r *CockroachRestorer) sql(ctx context.Context, statements string, flags ...string) ([]byte, error) {
+	cmd := fmt.Sprintf("printf '%%s' %s | cockroach sql --certs-dir=%s %s",
+		shellQuote(statements), cockroachCertsDir, strings.Join(flags, " "))
+	return runInContainer(ctx, r.container, "cockroach sql", []string{"sh", "-c", cmd})
+}
+
+// connect opens the query connection, through the localhost proxy when isolated.
+func (r *CockroachRestorer) connect(ctx context.Context) error {
+	var host, port string
+	if r.isolation == nil {
+		var err error
+		host, err = r.container.Host(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get container host: %w", err)
+		}
+		mapped, err := r.container.MappedPort(ctx, fmt.Sprintf("%d/tcp", cockroachPort))
+		if err != nil {
+			return fmt.Errorf("failed to get container port: %w", err)
+		}
+		port = mapped.Port()
+	} else {
+		var err error
+		host, port, err = r.isolation.startProxy(ctx, r.config.Docker.ProxyImage,
+			containerName(r.config, r.runID)+"-proxy", containerLabels(r.config, r.runID), cockroachPort)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Database isolated from outbound network, reachable on %s:%s.\n", host, port)
+	}
+
+	// The node certificate is self-signed per container, so it is not verified
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cockroachUser, r.password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     r.database,
+		RawQuery: "sslmode=require",
+	}
+	r.dsn = dsn.String()
+
+	db, err := openDB("postgres", r.dsn, r.logSQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to connect to database %s: %w", r.database, err)
+	}
+	r.db = db
+	return nil
+}
+
+// UseDatabase reconnects to another database in the restored cluster.
+func (r *CockroachRestorer) UseDatabase(ctx context.Context, name string) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM crdb_internal.databases WHERE name = $1)`, name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up database %s: %w", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("database %s not found in restored instance", name)
+	}
+
+	r.db.Close()
+	r.db = nil
+	r.database = name
+	return r.connect(ctx)
+}
+
+// ExtractSchema extracts public tables of the current database from crdb_internal.
+func (r *CockroachRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT schema_name, name
+		FROM crdb_internal.tables
+		WHERE database_name = $1 AND state = 'PUBLIC' AND schema_name NOT IN ('crdb_internal', 'information_schema', 'pg_catalog', 'pg_extension')
+		ORDER BY schema_name, name
+	`, r.database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []schema.Table
+	for rows.Next() {
+		var t schema.Table
+		if err := rows.Scan(&t.Schema, &t.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan table row: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table rows: %w", err)
+	}
+
+	for i := range tables {
+		t := &tables[i]
+		columns, err := r.getTableColumns(ctx, t.Schema, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		t.Columns = columns
+		t.ColumnCount = len(columns)
+	}
+
+	indexes, err := r.getIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachIndexes(tables, indexes)
+
+	constraints, err := r.getConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachConstraints(tables, constraints)
+
+	return &schema.Schema{
+		Version:   "1",
+		Timestamp: time.Now().UTC(),
+		Tables:    tables,
+	}, nil
+}
+
+// getTableColumns lists visible columns, skipping the hidden rowid of tables
+// without a primary key.
+func (r *CockroachRestorer) getTableColumns(ctx context.Context, schemaName, tableName string) ([]schema.Column, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT column_name, data_type, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 AND is_hidden = 'NO'
+		ORDER BY ordinal_position
+	`, schemaName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns for %s.%s: %w", schemaName, tableName, err)
+	}
+	defer rows.Close()
+
+	var columns []schema.Column
+	for rows.Next() {
+		var c schema.Column
+		var nullable string
+		if err := rows.Scan(&c.Name, &c.DataType, &nullable); err != nil {
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+		c.Nullable = nullable == "YES"
+		columns = append(columns, c)
+	}
+
+	return columns, rows.Err()
+}
+
+// ExtractMetrics counts rows exactly, since table statistics are not collected
+// right after a restore. Sizes are not reported.
+func (r *CockroachRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics, error) {
+	s, err := r.ExtractSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &schema.Metrics{
+		Timestamp:       time.Now().UTC(),
+		RestoreDuration: r.restoreDuration,
+	}
+	for _, t := range s.Tables {
+		var count int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s.%s`, quoteCockroachIdent(t.Schema), quoteCockroachIdent(t.Name))
+		if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count rows in %s.%s: %w", t.Schema, t.Name, err)
+		}
+		metrics.TableMetrics = append(metrics.TableMetrics, schema.TableMetrics{
+			Schema:   t.Schema,
+			Name:     t.Name,
+			RowCount: count,
+		})
+	}
+
+	return metrics, nil
+}
+
+// AppConnection returns the connection an application sharing the container's network uses.
+func (r *CockroachRestorer) AppConnection(ctx context.Context, name string) (*AppConnection, error) {
+	if r.container == nil {
+		return nil, fmt.Errorf("database container not started; call Restore first")
+	}
+	if name == "" {
+		name = r.database
+	}
+	return &AppConnection{
+		ContainerID: r.container.GetContainerID(),
+		Scheme:      "postgres",
+		Port:        cockroachPort,
+		User:        cockroachUser,
+		Password:    r.password,
+		Database:    name,
+		Query:       "sslmode=require",
+	}, nil
+}
+
+// RunQuery runs a custom SQL check against the restored database.
+func (r *CockroachRestorer) RunQuery(ctx context.Context, query string) (int, *string, error) {
+	return runQuery(ctx, r.db, query)
+}
+
+// Cleanup terminates the ephemeral database container.
+func (r *CockroachRestorer) Cleanup(ctx context.Context) error {
+	if r.db != nil {
+		r.db.Close()
+		r.db = nil
+	}
+	if r.container != nil {
+		var terminateOpts []testcontainers.TerminateOption
+		if r.config.Docker.Security.ReadOnlyRootfs {
+			terminateOpts = append(terminateOpts, testcontainers.RemoveVolumes(workVolumeName(r.config, r.runID)))
+		}
+		if err := r.container.Terminate(ctx, terminateOpts...); err != nil {
+			return fmt.Errorf("failed to terminate container: %w", err)
+		}
+		r.container = nil
+	}
+	if r.isolation != nil {
+		if err := r.isolation.Remove(ctx); err != nil {
+			return err
+		}
+		r.isolation = nil
+	}
+	return nil
+}
+
+// quoteCockroachIdent quotes an identifier for CockroachDB SQL.
+func quoteCockroachIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteCockroachString renders s as a CockroachDB string literal.
+func quoteCockroachString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
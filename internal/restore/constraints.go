@@ -0,0 +1,204 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// columnSeparator joins column names in aggregated query results, since names
+// may contain commas and spaces.
+const columnSeparator = "\x1f"
+
+// constraintTypes maps pg_constraint.contype to the constraint types recorded.
+var constraintTypes = map[string]string{
+	"p": schema.ConstraintPrimaryKey,
+	"u": schema.ConstraintUnique,
+	"c": schema.ConstraintCheck,
+}
+
+// attachConstraints sets the constraints of each table from constraints keyed by
+// schema.table.
+func attachConstraints(tables []schema.Table, constraints map[string][]schema.Constraint) {
+	for i := range tables {
+		tables[i].Constraints = constraints[tables[i].Schema+"."+tables[i].Name]
+	}
+}
+
+// pgConstraints reads primary key, unique and check constraints from pg_constraint,
+// which Postgres and CockroachDB both provide, skipping the excluded schemas.
+func pgConstraints(ctx context.Context, db *sql.DB, excludedSchemas string) (map[string][]schema.Constraint, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT n.nspname, t.relname, c.conname, c.contype::text, pg_get_constraintdef(c.oid),
+			ARRAY(
+				SELECT a.attname::text
+				FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)
+		FROM pg_constraint c
+		JOIN pg_class t ON t.oid = c.conrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE c.contype IN ('p', 'u', 'c')
+		  AND n.nspname NOT IN (`+excludedSchemas+`)
+		  AND n.nspname NOT LIKE 'pg\_toast%'
+		  AND n.nspname NOT LIKE '\_timescaledb\_%'
+		ORDER BY n.nspname, t.relname, c.conname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := make(map[string][]schema.Constraint)
+	for rows.Next() {
+		var schemaName, table, contype string
+		var c schema.Constraint
+		var columns []string
+		if err := rows.Scan(&schemaName, &table, &c.Name, &contype, &c.Definition, pq.Array(&columns)); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint row: %w", err)
+		}
+		c.Type = constraintTypes[contype]
+		if c.Type != schema.ConstraintCheck {
+			c.Columns = columns
+		}
+		key := schemaName + "." + table
+		constraints[key] = append(constraints[key], c)
+	}
+	return constraints, rows.Err()
+}
+
+// getConstraints records the primary key, unique and check constraints of every table.
+func (r *PostgresRestorer) getConstraints(ctx context.Context) (map[string][]schema.Constraint, error) {
+	return pgConstraints(ctx, r.db, `'information_schema', 'pg_catalog'`)
+}
+
+// getConstraints records the primary key, unique and check constraints of every table.
+func (r *CockroachRestorer) getConstraints(ctx context.Context) (map[string][]schema.Constraint, error) {
+	return pgConstraints(ctx, r.db, `'crdb_internal', 'information_schema', 'pg_catalog', 'pg_extension'`)
+}
+
+// getConstraints records the primary key, unique and check constraints of every
+// table in the current database. Key constraints are described by their columns,
+// e.g. PRIMARY KEY (id), and check constraints by their clause.
+func (r *MariaDBRestorer) getConstraints(ctx context.Context) (map[string][]schema.Constraint, error) {
+	checks, err := r.checkClauses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tc.table_schema, tc.table_name, tc.constraint_name, tc.constraint_type,
+			COALESCE((
+				SELECT GROUP_CONCAT(k.column_name ORDER BY k.ordinal_position SEPARATOR 0x1f)
+				FROM information_schema.key_column_usage k
+				WHERE k.constraint_schema = tc.constraint_schema
+				  AND k.table_name = tc.table_name
+				  AND k.constraint_name = tc.constraint_name
+			), '')
+		FROM information_schema.table_constraints tc
+		WHERE tc.constraint_schema = DATABASE()
+		  AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'CHECK')
+		ORDER BY tc.table_name, tc.constraint_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := make(map[string][]schema.Constraint)
+	for rows.Next() {
+		var schemaName, table, columns string
+		var c schema.Constraint
+		if err := rows.Scan(&schemaName, &table, &c.Name, &c.Type, &columns); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint row: %w", err)
+		}
+		if c.Type == schema.ConstraintCheck {
+			clause, ok := checks[table+"."+c.Name]
+			if !ok {
+				clause = checks[c.Name]
+			}
+			c.Definition = fmt.Sprintf("CHECK (%s)", clause)
+		} else {
+			c.Columns = strings.Split(columns, columnSeparator)
+			c.Definition = fmt.Sprintf("%s (%s)", c.Type, strings.Join(c.Columns, ", "))
+		}
+		key := schemaName + "." + table
+		constraints[key] = append(constraints[key], c)
+	}
+	return constraints, rows.Err()
+}
+
+// checkClauses returns the clause of each check constraint, keyed by table.name on
+// MariaDB and by name on MySQL, whose check constraint names are unique per schema
+// and whose information_schema.check_constraints has no table_name.
+func (r *MariaDBRestorer) checkClauses(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT CONCAT(table_name, '.', constraint_name), check_clause
+		FROM information_schema.check_constraints
+		WHERE constraint_schema = DATABASE()
+	`)
+	if err != nil {
+		rows, err = r.db.QueryContext(ctx, `
+			SELECT constraint_name, check_clause
+			FROM information_schema.check_constraints
+			WHERE constraint_schema = DATABASE()
+		`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	clauses := make(map[string]string)
+	for rows.Next() {
+		var key, clause string
+		if err := rows.Scan(&key, &clause); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint row: %w", err)
+		}
+		clauses[key] = clause
+	}
+	return clauses, rows.Err()
+}
+
+// getConstraints records the primary key and unique constraints of every table,
+// named PRIMARY and after their automatic index. SQLite keeps check constraints
+// only in the CREATE TABLE statement, so they are not recorded.
+func (r *SQLiteRestorer) getConstraints(ctx context.Context) (map[string][]schema.Constraint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.name, 'PRIMARY', 'PRIMARY KEY',
+			(SELECT group_concat(name, char(31)) FROM (SELECT name FROM pragma_table_info(m.name) WHERE pk > 0 ORDER BY pk))
+		FROM sqlite_master m
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		  AND EXISTS (SELECT 1 FROM pragma_table_info(m.name) WHERE pk > 0)
+		UNION ALL
+		SELECT m.name, il.name, 'UNIQUE',
+			(SELECT group_concat(coalesce(ii.name, '(expression)'), char(31)) FROM pragma_index_info(il.name) ii)
+		FROM sqlite_master m
+		JOIN pragma_index_list(m.name) il
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\' AND il.origin = 'u'
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	constraints := make(map[string][]schema.Constraint)
+	for rows.Next() {
+		var table, columns string
+		var c schema.Constraint
+		if err := rows.Scan(&table, &c.Name, &c.Type, &columns); err != nil {
+			return nil, fmt.Errorf("failed to scan constraint row: %w", err)
+		}
+		c.Columns = strings.Split(columns, columnSeparator)
+		c.Definition = fmt.Sprintf("%s (%s)", c.Type, strings.Join(c.Columns, ", "))
+		key := "main." + table
+		constraints[key] = append(constraints[key], c)
+	}
+	return constraints, rows.Err()
+}
@@ -0,0 +1,86 @@
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// Labels applied to every ephemeral container so external monitoring and cleanup
+// can identify them.
+const (
+	LabelManagedBy = "managed-by"
+	LabelProject   = "io.restorable.project"
+	LabelRunID     = "io.restorable.run-id"
+
+	ManagedByValue = "restorable"
+)
+
+const defaultContainerNamePrefix = "restorable"
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// generatedRunID matches the UUIDs generated for runs, whose first 8 characters are
+// distinct enough to name containers.
+var generatedRunID = regexp.MustCompile(`^[0-9a-f]{8}-`)
+
+// containerLabels returns the configured labels plus the labels identifying this run.
+// The identifying labels cannot be overridden by configuration.
+func containerLabels(cfg *config.Config, runID string) map[string]string {
+	labels := make(map[string]string, len(cfg.Docker.Labels)+3)
+	for k, v := range cfg.Docker.Labels {
+		labels[k] = v
+	}
+	labels[LabelManagedBy] = ManagedByValue
+	labels[LabelProject] = cfg.Project.ID
+	labels[LabelRunID] = runID
+	return labels
+}
+
+// containerName returns a deterministic container name: <prefix>-<project>-<run>.
+// Run IDs provided by orchestrators often share a prefix, so they are hashed instead
+// of truncated.
+func containerName(cfg *config.Config, runID string) string {
+	prefix := cfg.Docker.NamePrefix
+	if prefix == "" {
+		prefix = defaultContainerNamePrefix
+	}
+	run := runID
+	if len(run) > 8 {
+		if generatedRunID.MatchString(run) {
+			run = run[:8]
+		} else {
+			sum := sha256.Sum256([]byte(runID))
+			run = hex.EncodeToString(sum[:4])
+		}
+	}
+	name := strings.Join([]string{prefix, cfg.Project.ID, run}, "-")
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), "-._")
+}
+
+// runInContainer runs cmd in ctr and returns its combined output, failing with
+// that output on a non-zero exit code.
+func runInContainer(ctx context.Context, ctr testcontainers.Container, name string, cmd []string) ([]byte, error) {
+	exitCode, output, err := ctr.Exec(ctx, cmd, tcexec.Multiplexed())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %s: %w", name, err)
+	}
+	logs, _ := io.ReadAll(output)
+	if exitCode != 0 {
+		return nil, fmt.Errorf("%s failed (exit %d):\n%s", name, exitCode, string(logs))
+	}
+	return logs, nil
+}
+
+// shellQuote quotes s for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
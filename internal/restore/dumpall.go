@@ -0,0 +1,129 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+// clusterDumpHeader opens every pg_dumpall script.
+const clusterDumpHeader = "PostgreSQL database cluster dump"
+
+// isClusterDump reports whether the file is a plain SQL script written by pg_dumpall.
+func isClusterDump(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	return bytes.Contains(head[:n], []byte(clusterDumpHeader))
+}
+
+// restoreCluster replays a pg_dumpall script with psql. The script creates its roles
+// and databases and connects to each in turn, so it runs from the postgres database.
+func (r *PostgresRestorer) restoreCluster(ctx context.Context, pgContainer *postgres.PostgresContainer, backupPath string) error {
+	fmt.Println("Restoring pg_dumpall cluster dump with psql...")
+	restoreStart := time.Now()
+
+	psqlCmd := []string{
+		"psql",
+		"--username", r.config.Database.Restore.User,
+		"--dbname", "postgres",
+		"--no-password",
+		"--file", backupPath,
+	}
+	exitCode, logs, err := pgContainer.Exec(ctx, psqlCmd)
+	if err != nil {
+		return fmt.Errorf("failed to execute psql: %w", err)
+	}
+	logBytes, _ := io.ReadAll(logs)
+	if exitCode != 0 {
+		return fmt.Errorf("psql failed on cluster dump (exit %d):\n%s", exitCode, string(logBytes))
+	}
+	r.restoreDuration = time.Since(restoreStart)
+
+	if r.verbose && len(logBytes) > 0 {
+		fmt.Println("--- psql output ---")
+		fmt.Println(string(logBytes))
+		fmt.Println("-------------------------")
+	}
+	fmt.Println("✓ Cluster dump restored successfully with psql.")
+	return nil
+}
+
+// RestoredDatabases lists the databases a pg_dumpall script created, or nil for any
+// other dump. The postgres database is listed only when the dump put tables in it.
+func (r *PostgresRestorer) RestoredDatabases(ctx context.Context) ([]string, error) {
+	if !r.cluster {
+		return nil, nil
+	}
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT datname FROM pg_database
+		WHERE NOT datistemplate AND datallowconn AND datname <> $1
+		ORDER BY datname
+	`, r.config.Database.Restore.DBName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list restored databases: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan database row: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating database rows: %w", err)
+	}
+
+	var databases []string
+	for _, name := range names {
+		if name == "postgres" {
+			empty, err := r.isEmptyDatabase(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if empty {
+				continue
+			}
+		}
+		databases = append(databases, name)
+	}
+	return databases, nil
+}
+
+// isEmptyDatabase reports whether the named database holds no user tables.
+func (r *PostgresRestorer) isEmptyDatabase(ctx context.Context, name string) (bool, error) {
+	db, err := r.openDatabase(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var tables int
+	err = db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema NOT IN ('information_schema', 'pg_catalog')
+	`).Scan(&tables)
+	if err != nil {
+		return false, fmt.Errorf("failed to count tables in %s: %w", name, err)
+	}
+	return tables == 0, nil
+}
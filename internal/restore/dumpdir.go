@@ -0,0 +1,61 @@
+package restore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+)
+
+// defaultDumpDirJobs is the pg_restore parallelism for directory-format dumps when
+// database.restore.jobs is not set.
+const defaultDumpDirJobs = 4
+
+// dumpDirArchive describes a tar archive holding a pg_dump directory-format dump.
+type dumpDirArchive struct {
+	// Root is the directory in the archive containing toc.dat; "." for the top level.
+	Root    string
+	Gzipped bool
+}
+
+// findDumpDir reports whether the file is a tar (optionally gzipped) of a pg_dump
+// -Fd output directory. pg_dump -Ft archives also carry a toc.dat but are restored
+// by pg_restore as they are; they are told apart by their restore.sql.
+func findDumpDir(file string) (*dumpDirArchive, error) {
+	tr, gzipped, closeArchive, err := openTarArchive(file)
+	if err != nil || tr == nil {
+		return nil, err
+	}
+	defer closeArchive()
+
+	tocs := make(map[string]bool)
+	scripts := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		switch path.Base(name) {
+		case "toc.dat":
+			tocs[path.Dir(name)] = true
+		case "restore.sql":
+			scripts[path.Dir(name)] = true
+		}
+	}
+
+	var archive *dumpDirArchive
+	for dir := range tocs {
+		if scripts[dir] {
+			continue
+		}
+		if archive != nil {
+			return nil, fmt.Errorf("backup archive holds more than one pg_dump directory (%s, %s)", archive.Root, dir)
+		}
+		archive = &dumpDirArchive{Root: dir, Gzipped: gzipped}
+	}
+	return archive, nil
+}
@@ -0,0 +1,48 @@
+package restore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// pg_dump output formats, as told apart by dumpFormat.
+const (
+	dumpFormatPlain     = "plain"
+	dumpFormatCustom    = "custom"
+	dumpFormatTar       = "tar"
+	dumpFormatDirectory = "directory"
+)
+
+// dumpFormat returns the pg_dump format of the file from its first bytes, so it is
+// restored with pg_restore or psql up front rather than by trying both. dumpDir is
+// the directory-format dump found in the file, if any.
+func dumpFormat(file string, dumpDir *dumpDirArchive) (string, error) {
+	if dumpDir != nil {
+		return dumpFormatDirectory, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read backup file: %w", err)
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PGDMP")):
+		return dumpFormatCustom, nil
+	case n == 512 && bytes.HasPrefix(head[257:], []byte("ustar")):
+		return dumpFormatTar, nil
+	case bytes.IndexByte(head, 0) >= 0:
+		// SQL scripts are text; anything else would fail in psql after a long upload
+		return "", errors.New("backup is neither a pg_dump archive nor an SQL script")
+	}
+	return dumpFormatPlain, nil
+}
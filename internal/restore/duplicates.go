@@ -0,0 +1,65 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// DuplicateCounter is implemented by restorers that can look for duplicate keys.
+type DuplicateCounter interface {
+	// CountDuplicateKeys returns how many distinct values of columns occur in more
+	// than one row of the table. Rows with a NULL key column are ignored.
+	CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error)
+}
+
+// countDuplicateKeys groups the rows of table, already quoted, by the quoted
+// key columns and counts the groups with more than one row.
+func countDuplicateKeys(ctx context.Context, db *sql.DB, table string, columns []string) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database connection not established; call Restore first")
+	}
+	notNull := make([]string, len(columns))
+	for i, c := range columns {
+		notNull[i] = c + " IS NOT NULL"
+	}
+	key := strings.Join(columns, ", ")
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM (SELECT 1 AS d FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1) dup`,
+		table, strings.Join(notNull, " AND "), key)
+
+	var count int64
+	if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// quoteAll quotes each name with quote.
+func quoteAll(names []string, quote func(string) string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quote(n)
+	}
+	return quoted
+}
+
+// CountDuplicateKeys counts the duplicated values of a key.
+func (r *PostgresRestorer) CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error) {
+	return countDuplicateKeys(ctx, r.db, quotePostgresName(schemaName, table), quoteAll(columns, quotePostgresIdent))
+}
+
+// CountDuplicateKeys counts the duplicated values of a key.
+func (r *CockroachRestorer) CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error) {
+	return countDuplicateKeys(ctx, r.db, quoteCockroachIdent(schemaName)+"."+quoteCockroachIdent(table), quoteAll(columns, quoteCockroachIdent))
+}
+
+// CountDuplicateKeys counts the duplicated values of a key.
+func (r *MariaDBRestorer) CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error) {
+	return countDuplicateKeys(ctx, r.db, quoteMariaDBIdent(schemaName)+"."+quoteMariaDBIdent(table), quoteAll(columns, quoteMariaDBIdent))
+}
+
+// CountDuplicateKeys counts the duplicated values of a key.
+func (r *SQLiteRestorer) CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error) {
+	return countDuplicateKeys(ctx, r.db, quoteSQLiteIdent(schemaName)+"."+quoteSQLiteIdent(table), quoteAll(columns, quoteSQLiteIdent))
+}
@@ -0,0 +1,63 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// TimestampReader is implemented by restorers that can read the most recent value
+// of a timestamp column.
+type TimestampReader interface {
+	// LatestTimestamp returns the largest value of a column given as
+	// schema.table.column or table.column, or nil if the table has no rows.
+	LatestTimestamp(ctx context.Context, column string) (*time.Time, error)
+}
+
+// LatestTimestamp returns MAX(column). Timestamps without time zone are taken as UTC.
+func (r *PostgresRestorer) LatestTimestamp(ctx context.Context, column string) (*time.Time, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	ref, err := parseColumnRef(column, "public")
+	if err != nil {
+		return nil, err
+	}
+
+	var latest sql.NullTime
+	query := fmt.Sprintf(`SELECT MAX(%s) FROM %s`, quotePostgresIdent(ref.Column), quotePostgresName(ref.Schema, ref.Table))
+	if err := r.db.QueryRowContext(ctx, query).Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to read the latest %s: %w", column, err)
+	}
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
+// LatestTimestamp returns MAX(column). DATETIME values are read in the session
+// time zone, UTC in the restore container.
+func (r *MariaDBRestorer) LatestTimestamp(ctx context.Context, column string) (*time.Time, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	ref, err := parseColumnRef(column, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var seconds sql.NullFloat64
+	query := fmt.Sprintf("SELECT UNIX_TIMESTAMP(MAX(%s)) FROM %s",
+		quoteMariaDBIdent(ref.Column), quoteMariaDBTable(ref.Schema, ref.Table))
+	if err := r.db.QueryRowContext(ctx, query).Scan(&seconds); err != nil {
+		return nil, fmt.Errorf("failed to read the latest %s: %w", column, err)
+	}
+	if !seconds.Valid {
+		return nil, nil
+	}
+	whole, frac := math.Modf(seconds.Float64)
+	latest := time.Unix(int64(whole), int64(frac*1e9)).UTC()
+	return &latest, nil
+}
@@ -0,0 +1,173 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// attachIndexes sets the indexes of each table from indexes keyed by schema.table.
+func attachIndexes(tables []schema.Table, indexes map[string][]schema.Index) {
+	for i := range tables {
+		tables[i].Indexes = indexes[tables[i].Schema+"."+tables[i].Name]
+	}
+}
+
+// getIndexes records the definition of every index, including invalid ones left
+// behind by a failed build.
+func (r *PostgresRestorer) getIndexes(ctx context.Context) (map[string][]schema.Index, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.nspname, t.relname, i.relname, pg_get_indexdef(i.oid), ix.indisunique, ix.indisvalid
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE 'pg\_toast%'
+		  AND n.nspname NOT LIKE '\_timescaledb\_%'
+		ORDER BY n.nspname, t.relname, i.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]schema.Index)
+	for rows.Next() {
+		var schemaName, table string
+		var idx schema.Index
+		var valid bool
+		if err := rows.Scan(&schemaName, &table, &idx.Name, &idx.Definition, &idx.Unique, &valid); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		idx.Invalid = !valid
+		key := schemaName + "." + table
+		indexes[key] = append(indexes[key], idx)
+	}
+	return indexes, rows.Err()
+}
+
+// getIndexes records the definition of every index in the current database.
+func (r *CockroachRestorer) getIndexes(ctx context.Context) (map[string][]schema.Index, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT schemaname, tablename, indexname, indexdef
+		FROM pg_indexes
+		WHERE schemaname NOT IN ('crdb_internal', 'information_schema', 'pg_catalog', 'pg_extension')
+		ORDER BY schemaname, tablename, indexname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]schema.Index)
+	for rows.Next() {
+		var schemaName, table string
+		var idx schema.Index
+		if err := rows.Scan(&schemaName, &table, &idx.Name, &idx.Definition); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		idx.Unique = strings.HasPrefix(idx.Definition, "CREATE UNIQUE INDEX")
+		key := schemaName + "." + table
+		indexes[key] = append(indexes[key], idx)
+	}
+	return indexes, rows.Err()
+}
+
+// getIndexes records the type and key columns of every index in the current
+// database, e.g. BTREE (customer_id, created_at).
+func (r *MariaDBRestorer) getIndexes(ctx context.Context) (map[string][]schema.Index, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT table_schema, table_name, index_name, index_type, non_unique, column_name, sub_part
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE()
+		ORDER BY table_name, index_name, seq_in_index
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	type indexKey struct{ table, name string }
+	var order []indexKey
+	columns := make(map[indexKey][]string)
+	found := make(map[indexKey]schema.Index)
+	var schemaName string
+	for rows.Next() {
+		var table, name, indexType string
+		var nonUnique int
+		var column sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&schemaName, &table, &name, &indexType, &nonUnique, &column, &subPart); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		key := indexKey{table, name}
+		if _, ok := found[key]; !ok {
+			order = append(order, key)
+			found[key] = schema.Index{Name: name, Definition: indexType, Unique: nonUnique == 0}
+		}
+		// Functional key parts have no column name
+		part := column.String
+		if !column.Valid {
+			part = "(expression)"
+		}
+		if subPart.Valid {
+			part = fmt.Sprintf("%s(%d)", part, subPart.Int64)
+		}
+		columns[key] = append(columns[key], part)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating index rows: %w", err)
+	}
+
+	indexes := make(map[string][]schema.Index)
+	for _, key := range order {
+		idx := found[key]
+		idx.Definition = fmt.Sprintf("%s (%s)", idx.Definition, strings.Join(columns[key], ", "))
+		tableKey := schemaName + "." + key.table
+		indexes[tableKey] = append(indexes[tableKey], idx)
+	}
+	return indexes, nil
+}
+
+// getIndexes records the CREATE INDEX statement of every index, or the key
+// columns of those SQLite creates for UNIQUE and PRIMARY KEY constraints.
+func (r *SQLiteRestorer) getIndexes(ctx context.Context) (map[string][]schema.Index, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.name, il.name, il."unique", il.origin,
+			coalesce((SELECT sql FROM sqlite_master WHERE type = 'index' AND name = il.name), ''),
+			(SELECT group_concat(coalesce(ii.name, '(expression)'), ', ') FROM pragma_index_info(il.name) ii)
+		FROM sqlite_master m
+		JOIN pragma_index_list(m.name) il
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		ORDER BY m.name, il.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[string][]schema.Index)
+	for rows.Next() {
+		var table, origin, statement string
+		var keyColumns sql.NullString
+		var idx schema.Index
+		if err := rows.Scan(&table, &idx.Name, &idx.Unique, &origin, &statement, &keyColumns); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		idx.Definition = statement
+		if statement == "" {
+			constraint := "UNIQUE"
+			if origin == "pk" {
+				constraint = "PRIMARY KEY"
+			}
+			idx.Definition = fmt.Sprintf("%s (%s)", constraint, keyColumns.String)
+		}
+		key := "main." + table
+		indexes[key] = append(indexes[key], idx)
+	}
+	return indexes, rows.Err()
+}
@@ -0,0 +1,102 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/moby/moby/api/types/container"
+	mobynetwork "github.com/moby/moby/api/types/network"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Network isolation runs the database on an internal Docker network with no route to
+// the outside world. A small TCP proxy joins both the internal network and the default
+// bridge and publishes the database port on 127.0.0.1 only, so the CLI can query the
+// database while hostile triggers or extensions in the dump cannot reach out.
+const (
+	isolatedDBAlias   = "restorable-db"
+	defaultProxyImage = "alpine/socat:1.8.0.1"
+)
+
+// isolatedNetwork is the per-run internal network and the proxy exposing the database.
+type isolatedNetwork struct {
+	network *testcontainers.DockerNetwork
+	proxy   testcontainers.Container
+}
+
+// newIsolatedNetwork creates an internal network labeled like the restore container.
+func newIsolatedNetwork(ctx context.Context, labels map[string]string) (*isolatedNetwork, error) {
+	nw, err := network.New(ctx, network.WithInternal(), network.WithLabels(labels))
+	if err != nil {
+		return nil, fmt.Errorf("could not create isolated network: %w", err)
+	}
+	return &isolatedNetwork{network: nw}, nil
+}
+
+// containerOption attaches the database container to the internal network only.
+func (n *isolatedNetwork) containerOption() testcontainers.CustomizeRequestOption {
+	return network.WithNetwork([]string{isolatedDBAlias}, n.network)
+}
+
+// startProxy starts the proxy forwarding 127.0.0.1:<random> to the database port and
+// returns the host and port to connect to.
+func (n *isolatedNetwork) startProxy(ctx context.Context, image, name string, labels map[string]string, dbPort int) (string, string, error) {
+	if image == "" {
+		image = defaultProxyImage
+	}
+	proxyPort := fmt.Sprintf("%d/tcp", dbPort)
+
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			Name:         name,
+			Labels:       labels,
+			ExposedPorts: []string{proxyPort},
+			Networks:     []string{n.network.Name, "bridge"},
+			Cmd: []string{
+				fmt.Sprintf("tcp-listen:%d,fork,reuseaddr", dbPort),
+				fmt.Sprintf("tcp-connect:%s:%d", isolatedDBAlias, dbPort),
+			},
+			HostConfigModifier: func(hc *container.HostConfig) {
+				hc.PortBindings = mobynetwork.PortMap{
+					mobynetwork.MustParsePort(proxyPort): []mobynetwork.PortBinding{
+						{HostIP: netip.MustParseAddr("127.0.0.1")},
+					},
+				}
+			},
+			WaitingFor: wait.ForListeningPort(proxyPort),
+		},
+		Started: true,
+	}
+
+	proxy, err := testcontainers.GenericContainer(ctx, req)
+	if proxy != nil {
+		n.proxy = proxy
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("could not start network proxy container: %w", err)
+	}
+
+	port, err := proxy.MappedPort(ctx, proxyPort)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get proxy port: %w", err)
+	}
+	return "127.0.0.1", port.Port(), nil
+}
+
+// Remove terminates the proxy and removes the network.
+func (n *isolatedNetwork) Remove(ctx context.Context) error {
+	if n.proxy != nil {
+		if err := n.proxy.Terminate(ctx); err != nil {
+			return fmt.Errorf("failed to terminate network proxy: %w", err)
+		}
+		n.proxy = nil
+	}
+	if err := n.network.Remove(ctx); err != nil {
+		return fmt.Errorf("failed to remove isolated network: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+const mongoRootUser = "root"
+
+// mongoArchiveMagic starts every mongodump --archive stream (0x8199e26d, little-endian).
+var mongoArchiveMagic = []byte{0x6d, 0xe2, 0x99, 0x81}
+
+// MongoRestorer restores mongodump archives and dump directories into an ephemeral
+// MongoDB container. Collections are reported as tables of the current database,
+// with document counts as row counts and their indexes; collections have no columns.
+//
+// All queries run through mongosh inside the container, so the database never
+// needs a port published on the host.
+type MongoRestorer struct {
+	config          *config.Config
+	verbose         bool
+	mode            Mode
+	runID           string
+	password        string
+	database        string
+	container       *testcontainers.DockerContainer
+	isolation       *isolatedNetwork
+	restoreDuration time.Duration
+}
+
+// NewMongoRestorer creates a new restorer instance.
+func NewMongoRestorer(cfg *config.Config, opts Options) *MongoRestorer {
+	return &MongoRestorer{
+		config:   cfg,
+		verbose:  opts.Verbose,
+		mode:     opts.Mode,
+		runID:    opts.RunID,
+		password: opts.Password,
+		database: cfg.Database.Restore.DBName,
+	}
+}
+
+// Restore starts MongoDB and runs mongorestore on the backup.
+func (r *MongoRestorer) Restore(ctx context.Context, backupStream io.Reader) error {
+	if r.mode != ModeFull {
+		return fmt.Errorf("%s mode is not supported for database type: mongodb", r.mode)
+	}
+
+	buffered := bufio.NewReader(backupStream)
+	header, _ := buffered.Peek(512)
+
+	// Create a temporary file on the host for the backup stream
+	tmpFile, err := os.CreateTemp("", "restorable-backup-*.dump")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, buffered); err != nil {
+		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	}
+	tmpFile.Close()
+
+	labels := containerLabels(r.config, r.runID)
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithEnv(map[string]string{
+			"MONGO_INITDB_ROOT_USERNAME": mongoRootUser,
+			"MONGO_INITDB_ROOT_PASSWORD": r.password,
+		}),
+		// The entrypoint runs a temporary server to create the root user first
+		testcontainers.WithWaitStrategy(wait.ForLog("Waiting for connections").
+			WithOccurrence(2).
+			WithStartupTimeout(5 * time.Minute)),
+		testcontainers.WithLabels(labels),
+		testcontainers.WithName(containerName(r.config, r.runID)),
+	}
+
+	securityOpts, err := securityOptions(r.config, r.runID, "/tmp")
+	if err != nil {
+		return err
+	}
+	opts = append(opts, securityOpts...)
+
+	if r.config.Docker.IsolateNetwork {
+		// Queries run inside the container, so no proxy is needed
+		isolation, err := newIsolatedNetwork(ctx, labels)
+		if err != nil {
+			return err
+		}
+		r.isolation = isolation
+		opts = append(opts, isolation.containerOption())
+	}
+
+	ctr, err := testcontainers.Run(ctx, r.config.Database.Restore.DockerImage, opts...)
+	if ctr != nil {
+		r.container = ctr
+	}
+	if err != nil {
+		return fmt.Errorf("could not start mongodb container: %w", err)
+	}
+	fmt.Printf("✓ Database container started: %s\n", containerName(r.config, r.runID))
+
+	dir := backupDir(r.config)
+	containerBackupPath := path.Join(dir, "backup.dump")
+	if err := ctr.CopyFileToContainer(ctx, tmpFile.Name(), containerBackupPath, 0644); err != nil {
+		return fmt.Errorf("failed to copy backup file into container: %w", err)
+	}
+
+	// The password is read from the container environment so it never appears in argv
+	restoreCmd := `mongorestore --username ` + mongoRootUser +
+		` --password "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin `
+	switch {
+	case bytes.HasPrefix(header, mongoArchiveMagic):
+		fmt.Println("✓ Detected mongodump archive.")
+		restoreCmd += "--archive=" + containerBackupPath
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+		// A tar of the dump directory, possibly with the dump/ directory itself at the top
+		fmt.Println("✓ Detected mongodump directory archive.")
+		dumpDir := path.Join(dir, "dump")
+		restoreCmd = fmt.Sprintf(`mkdir -p %[1]s && tar -xf %[2]s -C %[1]s && d=%[1]s && if [ -d %[1]s/dump ]; then d=%[1]s/dump; fi && %[3]s--dir="$d"`,
+			dumpDir, containerBackupPath, restoreCmd)
+	default:
+		return fmt.Errorf("unrecognized MongoDB backup format; expected a mongodump --archive file or a tar of a dump directory")
+	}
+
+	restoreStart := time.Now()
+	fmt.Println("Restoring backup with mongorestore...")
+	logs, err := runInContainer(ctx, ctr, "mongorestore", []string{"sh", "-c", restoreCmd})
+	if err != nil {
+		return err
+	}
+	r.restoreDuration = time.Since(restoreStart)
+	if r.verbose && len(logs) > 0 {
+		fmt.Println("--- mongorestore output ---")
+		fmt.Println(string(logs))
+		fmt.Println("-------------------------")
+	}
+	fmt.Println("✓ Database restore completed successfully with mongorestore.")
+
+	return nil
+}
+
+// UseDatabase points extraction at another database in the restored instance.
+func (r *MongoRestorer) UseDatabase(ctx context.Context, name string) error {
+	if r.container == nil {
+		return fmt.Errorf("database container not started; call Restore first")
+	}
+
+	var exists bool
+	script := fmt.Sprintf(`print(EJSON.stringify(db.adminCommand({listDatabases: 1, nameOnly: true}).databases.some(d => d.name === %s)))`, jsonString(name))
+	if err := r.eval(ctx, script, &exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("database %s not found in restored instance", name)
+	}
+
+	r.database = name
+	return nil
+}
+
+// mongoCollection is what the inspection script reports per collection.
+type mongoCollection struct {
+	Name      string `json:"name"`
+	Count     int64  `json:"count"`
+	SizeBytes int64  `json:"size"`
+	Indexes   []struct {
+		Name   string          `json:"name"`
+		Key    json.RawMessage `json:"key"`
+		Unique bool            `json:"unique"`
+	} `json:"indexes"`
+}
+
+type mongoInspection struct {
+	Collections []mongoCollection `json:"collections"`
+	SizeBytes   int64             `json:"size"`
+}
+
+// inspectScript collects collections, exact document counts, sizes and indexes
+// of one database. %s is the database name as a JSON string.
+const inspectScript = `
+const d = db.getSiblingDB(%s);
+const out = {collections: [], size: 0};
+d.getCollectionInfos({type: "collection"})
+  .filter(c => !c.name.startsWith("system."))
+  .forEach(c => {
+    const coll = d.getCollection(c.name);
+    out.collections.push({
+      name: c.name,
+      count: coll.countDocuments({}),
+      size: coll.totalSize(),
+      indexes: coll.getIndexes().map(i => ({name: i.name, key: i.key, unique: !!i.unique})),
+    });
+  });
+const stats = d.stats();
+out.size = (stats.dataSize || 0) + (stats.indexSize || 0);
+print(EJSON.stringify(out, {relaxed: true}));
+`
+
+func (r *MongoRestorer) inspect(ctx context.Context) (*mongoInspection, error) {
+	if r.container == nil {
+		return nil, fmt.Errorf("database container not started; call Restore first")
+	}
+
+	var result mongoInspection
+	if err := r.eval(ctx, fmt.Sprintf(inspectScript, jsonString(r.database)), &result); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Collections, func(i, j int) bool {
+		return result.Collections[i].Name < result.Collections[j].Name
+	})
+	return &result, nil
+}
+
+// eval runs a mongosh script and decodes the JSON it prints last.
+func (r *MongoRestorer) eval(ctx context.Context, script string, v any) error {
+	cmd := fmt.Sprintf(`mongosh --quiet --username %s --password "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin --eval %s`,
+		mongoRootUser, shellQuote(script))
+	logs, err := runInContainer(ctx, r.container, "mongosh", []string{"sh", "-c", cmd})
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+	last := lines[len(lines)-1]
+	if err := json.Unmarshal([]byte(last), v); err != nil {
+		return fmt.Errorf("failed to parse mongosh output: %w\n%s", err, string(logs))
+	}
+	return nil
+}
+
+// ExtractSchema extracts collections and their indexes from the current database.
+func (r *MongoRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, error) {
+	inspection, err := r.inspect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect database: %w", err)
+	}
+
+	var tables []schema.Table
+	for _, c := range inspection.Collections {
+		t := schema.Table{Schema: r.database, Name: c.Name}
+		for _, idx := range c.Indexes {
+			var key bytes.Buffer
+			if err := json.Compact(&key, idx.Key); err != nil {
+				return nil, fmt.Errorf("failed to parse index %s on %s: %w", idx.Name, c.Name, err)
+			}
+			t.Indexes = append(t.Indexes, schema.Index{
+				Name:       idx.Name,
+				Definition: key.String(),
+				Unique:     idx.Unique,
+			})
+		}
+		tables = append(tables, t)
+	}
+
+	return &schema.Schema{
+		Version:   "1",
+		Timestamp: time.Now().UTC(),
+		Tables:    tables,
+	}, nil
+}
+
+// ExtractMetrics extracts document counts and sizes from the current database.
+func (r *MongoRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics, error) {
+	inspection, err := r.inspect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect database: %w", err)
+	}
+
+	metrics := &schema.Metrics{
+		Timestamp:       time.Now().UTC(),
+		RestoreDuration: r.restoreDuration,
+		DBSizeBytes:     inspection.SizeBytes,
+	}
+	for _, c := range inspection.Collections {
+		metrics.TableMetrics = append(metrics.TableMetrics, schema.TableMetrics{
+			Schema:    r.database,
+			Name:      c.Name,
+			RowCount:  c.Count,
+			SizeBytes: c.SizeBytes,
+		})
+	}
+
+	return metrics, nil
+}
+
+// AppConnection returns the connection an application sharing the container's network uses.
+func (r *MongoRestorer) AppConnection(ctx context.Context, name string) (*AppConnection, error) {
+	if r.container == nil {
+		return nil, fmt.Errorf("database container not started; call Restore first")
+	}
+	if name == "" {
+		name = r.database
+	}
+	return &AppConnection{
+		ContainerID: r.container.GetContainerID(),
+		Scheme:      "mongodb",
+		Port:        27017,
+		User:        mongoRootUser,
+		Password:    r.password,
+		Database:    name,
+		Query:       "authSource=admin",
+	}, nil
+}
+
+// Cleanup terminates the ephemeral database container.
+func (r *MongoRestorer) Cleanup(ctx context.Context) error {
+	if r.container != nil {
+		var terminateOpts []testcontainers.TerminateOption
+		if r.config.Docker.Security.ReadOnlyRootfs {
+			terminateOpts = append(terminateOpts, testcontainers.RemoveVolumes(workVolumeName(r.config, r.runID)))
+		}
+		if err := r.container.Terminate(ctx, terminateOpts...); err != nil {
+			return fmt.Errorf("failed to terminate container: %w", err)
+		}
+		r.container = nil
+	}
+	if r.isolation != nil {
+		if err := r.isolation.Remove(ctx); err != nil {
+			return err
+		}
+		r.isolation = nil
+	}
+	return nil
+}
+
+// jsonString renders s as a JavaScript string literal.
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
@@ -0,0 +1,65 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// NullCounter is implemented by restorers that can count the NULLs of a column.
+type NullCounter interface {
+	// CountNulls counts the rows and NULL values of a column given as
+	// schema.table.column or table.column.
+	CountNulls(ctx context.Context, column string) (*schema.ColumnNulls, error)
+}
+
+// countNulls counts the rows of table, already quoted, and the NULLs of column.
+func countNulls(ctx context.Context, db *sql.DB, ref schema.ColumnProfile, table, column string) (*schema.ColumnNulls, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	nulls := &schema.ColumnNulls{Schema: ref.Schema, Table: ref.Table, Column: ref.Column}
+	query := fmt.Sprintf(`SELECT COUNT(*), COUNT(*) - COUNT(%s) FROM %s`, column, table)
+	if err := db.QueryRowContext(ctx, query).Scan(&nulls.Rows, &nulls.Nulls); err != nil {
+		return nil, fmt.Errorf("failed to count NULLs of %s: %w", nulls.QualifiedName(), err)
+	}
+	return nulls, nil
+}
+
+// CountNulls counts the NULLs of a column, in the public schema by default.
+func (r *PostgresRestorer) CountNulls(ctx context.Context, column string) (*schema.ColumnNulls, error) {
+	ref, err := parseColumnRef(column, "public")
+	if err != nil {
+		return nil, err
+	}
+	return countNulls(ctx, r.db, ref, quotePostgresName(ref.Schema, ref.Table), quotePostgresIdent(ref.Column))
+}
+
+// CountNulls counts the NULLs of a column, in the public schema by default.
+func (r *CockroachRestorer) CountNulls(ctx context.Context, column string) (*schema.ColumnNulls, error) {
+	ref, err := parseColumnRef(column, "public")
+	if err != nil {
+		return nil, err
+	}
+	return countNulls(ctx, r.db, ref, quoteCockroachIdent(ref.Schema)+"."+quoteCockroachIdent(ref.Table), quoteCockroachIdent(ref.Column))
+}
+
+// CountNulls counts the NULLs of a column, in the current database by default.
+func (r *MariaDBRestorer) CountNulls(ctx context.Context, column string) (*schema.ColumnNulls, error) {
+	ref, err := parseColumnRef(column, "")
+	if err != nil {
+		return nil, err
+	}
+	return countNulls(ctx, r.db, ref, quoteMariaDBTable(ref.Schema, ref.Table), quoteMariaDBIdent(ref.Column))
+}
+
+// CountNulls counts the NULLs of a column, given as table.column or main.table.column.
+func (r *SQLiteRestorer) CountNulls(ctx context.Context, column string) (*schema.ColumnNulls, error) {
+	ref, err := parseColumnRef(column, "main")
+	if err != nil {
+		return nil, err
+	}
+	return countNulls(ctx, r.db, ref, quoteSQLiteIdent(ref.Schema)+"."+quoteSQLiteIdent(ref.Table), quoteSQLiteIdent(ref.Column))
+}
@@ -0,0 +1,86 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// OrphanCounter is implemented by restorers that can check declared relationships.
+type OrphanCounter interface {
+	// CountOrphans counts the rows whose non-NULL child column value is missing
+	// from the parent column. Columns are given as schema.table.column or
+	// table.column.
+	CountOrphans(ctx context.Context, child, parent string) (int64, error)
+}
+
+// countOrphans counts the rows of childTable, already quoted, whose childColumn
+// has no match in parentColumn of parentTable.
+func countOrphans(ctx context.Context, db *sql.DB, childTable, childColumn, parentTable, parentColumn string) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database connection not established; call Restore first")
+	}
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s c WHERE c.%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s p WHERE p.%s = c.%s)`,
+		childTable, childColumn, parentTable, parentColumn, childColumn)
+
+	var count int64
+	if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// parseRelationship parses the child and parent columns of a relationship.
+func parseRelationship(child, parent, defaultSchema string) (c, p schema.ColumnProfile, err error) {
+	if c, err = parseColumnRef(child, defaultSchema); err != nil {
+		return c, p, err
+	}
+	p, err = parseColumnRef(parent, defaultSchema)
+	return c, p, err
+}
+
+// CountOrphans counts orphaned child rows, in the public schema by default.
+func (r *PostgresRestorer) CountOrphans(ctx context.Context, child, parent string) (int64, error) {
+	c, p, err := parseRelationship(child, parent, "public")
+	if err != nil {
+		return 0, err
+	}
+	return countOrphans(ctx, r.db,
+		quotePostgresName(c.Schema, c.Table), quotePostgresIdent(c.Column),
+		quotePostgresName(p.Schema, p.Table), quotePostgresIdent(p.Column))
+}
+
+// CountOrphans counts orphaned child rows, in the public schema by default.
+func (r *CockroachRestorer) CountOrphans(ctx context.Context, child, parent string) (int64, error) {
+	c, p, err := parseRelationship(child, parent, "public")
+	if err != nil {
+		return 0, err
+	}
+	return countOrphans(ctx, r.db,
+		quoteCockroachIdent(c.Schema)+"."+quoteCockroachIdent(c.Table), quoteCockroachIdent(c.Column),
+		quoteCockroachIdent(p.Schema)+"."+quoteCockroachIdent(p.Table), quoteCockroachIdent(p.Column))
+}
+
+// CountOrphans counts orphaned child rows, in the current database by default.
+func (r *MariaDBRestorer) CountOrphans(ctx context.Context, child, parent string) (int64, error) {
+	c, p, err := parseRelationship(child, parent, "")
+	if err != nil {
+		return 0, err
+	}
+	return countOrphans(ctx, r.db,
+		quoteMariaDBTable(c.Schema, c.Table), quoteMariaDBIdent(c.Column),
+		quoteMariaDBTable(p.Schema, p.Table), quoteMariaDBIdent(p.Column))
+}
+
+// CountOrphans counts orphaned child rows, given as table.column or main.table.column.
+func (r *SQLiteRestorer) CountOrphans(ctx context.Context, child, parent string) (int64, error) {
+	c, p, err := parseRelationship(child, parent, "main")
+	if err != nil {
+		return 0, err
+	}
+	return countOrphans(ctx, r.db,
+		quoteSQLiteIdent(c.Schema)+"."+quoteSQLiteIdent(c.Table), quoteSQLiteIdent(c.Column),
+		quoteSQLiteIdent(p.Schema)+"."+quoteSQLiteIdent(p.Table), quoteSQLiteIdent(p.Column))
+}
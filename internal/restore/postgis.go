@@ -0,0 +1,92 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// spatialSampleRows is the number of non-null values per spatial column checked for validity.
+const spatialSampleRows = 1000
+
+// postgisImageExtensions are created by the postgis/postgis image's init script.
+var postgisImageExtensions = []string{"postgis_tiger_geocoder", "postgis_topology", "fuzzystrmatch", "postgis"}
+
+// isPostGISImage reports whether image is a PostGIS image.
+func isPostGISImage(image string) bool {
+	return strings.Contains(strings.ToLower(image), "postgis")
+}
+
+// dropImageExtensions removes the extensions the PostGIS image installs into the
+// database on first start. The dump recreates the ones it needs; left in place, the
+// image's topology and tiger schemas collide with the restored objects.
+func (r *PostgresRestorer) dropImageExtensions(ctx context.Context, container testcontainers.Container) error {
+	stmt := fmt.Sprintf("DROP EXTENSION IF EXISTS %s CASCADE", strings.Join(postgisImageExtensions, ", "))
+	exitCode, logs, err := container.Exec(ctx, []string{
+		"psql",
+		"--username", r.config.Database.Restore.User,
+		"--dbname", r.config.Database.Restore.DBName,
+		"--no-password",
+		"-v", "ON_ERROR_STOP=1",
+		"--command", stmt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute psql: %w", err)
+	}
+	if exitCode != 0 {
+		output, _ := io.ReadAll(logs)
+		return fmt.Errorf("failed to drop PostGIS image extensions (exit %d): %s", exitCode, string(output))
+	}
+	return nil
+}
+
+// getSpatialColumns lists geometry and geography columns with their type and SRID,
+// and counts invalid values in a sample of each.
+func (r *PostgresRestorer) getSpatialColumns(ctx context.Context) ([]schema.SpatialColumn, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f_table_schema, f_table_name, f_geometry_column, type, srid, coord_dimension
+		FROM geometry_columns
+		UNION ALL
+		SELECT f_table_schema, f_table_name, f_geography_column, 'geography:' || type, srid, coord_dimension
+		FROM geography_columns
+		ORDER BY 1, 2, 3
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spatial columns: %w", err)
+	}
+
+	var columns []schema.SpatialColumn
+	for rows.Next() {
+		var c schema.SpatialColumn
+		if err := rows.Scan(&c.Schema, &c.Table, &c.Column, &c.Type, &c.SRID, &c.Dimensions); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan spatial column row: %w", err)
+		}
+		columns = append(columns, c)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating spatial column rows: %w", err)
+	}
+
+	for i := range columns {
+		c := &columns[i]
+		value := "g"
+		if strings.HasPrefix(c.Type, "geography:") {
+			value = "g::geometry"
+		}
+		column := quotePostgresIdent(c.Column)
+		query := fmt.Sprintf(`SELECT COUNT(*) FILTER (WHERE NOT ST_IsValid(%s)) FROM (SELECT %s AS g FROM %s WHERE %s IS NOT NULL LIMIT %d) sample`,
+			value, column, quotePostgresName(c.Schema, c.Table), column, spatialSampleRows)
+		if err := r.db.QueryRowContext(ctx, query).Scan(&c.InvalidSample); err != nil {
+			return nil, fmt.Errorf("failed to validate %s: %w", c.QualifiedName(), err)
+		}
+	}
+
+	return columns, nil
+}
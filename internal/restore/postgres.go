@@ -5,7 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"io"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -18,103 +25,211 @@ import (
 
 // PostgresRestorer handles the Docker and pg_restore logic for Postgres.
 type PostgresRestorer struct {
-	config          *config.Config
-	verbose         bool
+	config   *config.Config
+	verbose  bool
+	logSQL   bool
+	mode     Mode
+	runID    string
+	password string
+	progress func(ProgressEvent)
+	// tables restricts pg_restore to the selected tables when not empty.
+	tables []string
+	// physical is set when a data directory was restored instead of a pg_dump artifact.
+	physical bool
+	// cluster is set when a pg_dumpall script was restored.
+	cluster         bool
 	container       *postgres.PostgresContainer
+	isolation       *isolatedNetwork
 	db              *sql.DB
+	dsn             string
 	restoreDuration time.Duration
 }
 
 // NewPostgresRestorer creates a new restorer instance.
-func NewPostgresRestorer(cfg *config.Config, verbose bool) *PostgresRestorer {
-	return &PostgresRestorer{config: cfg, verbose: verbose}
+func NewPostgresRestorer(cfg *config.Config, opts Options) *PostgresRestorer {
+	return &PostgresRestorer{
+		config:   cfg,
+		verbose:  opts.Verbose,
+		logSQL:   opts.LogSQL,
+		mode:     opts.Mode,
+		runID:    opts.RunID,
+		password: opts.Password,
+		progress: opts.Progress,
+		tables:   opts.Tables,
+	}
 }
 
 // Restore performs the end-to-end restore process in an ephemeral container.
+// Tar archives of a data directory are restored physically; anything else is
+// treated as a pg_dump artifact, including tars of a directory-format dump.
 func (r *PostgresRestorer) Restore(ctx context.Context, backupStream io.Reader) error {
-	dbPassword, ok := os.LookupEnv(r.config.Database.Restore.PasswordEnv)
-	if !ok {
-		return fmt.Errorf("database password environment variable %s not set", r.config.Database.Restore.PasswordEnv)
+	// Create a temporary file on the host for the backup stream
+	tmpFile, err := os.CreateTemp("", "restorable-backup-*.dump")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	// Write the stream to the temporary file
+	_, err = io.Copy(tmpFile, backupStream)
+	if err != nil {
+		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	}
+	tmpFile.Close()
+
+	dataDir, err := findDataDir(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+	if dataDir != nil {
+		if len(r.tables) > 0 {
+			return fmt.Errorf("table selection is not supported for physical backups")
+		}
+		return r.restorePhysical(ctx, tmpFile.Name(), dataDir)
+	}
+	dumpDir, err := findDumpDir(tmpFile.Name())
+	if err != nil {
+		return err
+	}
+	format, err := dumpFormat(tmpFile.Name(), dumpDir)
+	if err != nil {
+		return err
 	}
+	if format == dumpFormatPlain && isClusterDump(tmpFile.Name()) {
+		if r.mode != ModeFull {
+			return fmt.Errorf("%s restore is not supported for pg_dumpall cluster dumps", r.mode)
+		}
+		if len(r.tables) > 0 {
+			return fmt.Errorf("table selection is not supported for pg_dumpall cluster dumps")
+		}
+		r.cluster = true
+	}
+	if format == dumpFormatPlain {
+		// Plain SQL dumps cannot be filtered by section or table
+		if r.mode != ModeFull {
+			return fmt.Errorf("%s restore requires a pg_dump archive format, but the backup is a plain SQL dump", r.mode)
+		}
+		if len(r.tables) > 0 {
+			return fmt.Errorf("table selection requires a pg_dump archive format, but the backup is a plain SQL dump")
+		}
+	}
+	return r.restoreLogical(ctx, tmpFile.Name(), dumpDir, format)
+}
+
+// restoreLogical restores a pg_dump artifact with pg_restore, or a plain SQL dump
+// with psql. Directory-format dumps are unpacked in the container first.
+func (r *PostgresRestorer) restoreLogical(ctx context.Context, backupFile string, dumpDir *dumpDirArchive, format string) error {
+	dbPassword := r.password
 
 	waitStrategy := wait.ForLog("database system is ready to accept connections").
 		WithOccurrence(2).
 		WithStartupTimeout(5 * time.Minute)
 
-	pgContainer, err := postgres.Run(ctx,
-		r.config.Database.Restore.DockerImage,
+	image := r.config.Database.Restore.DockerImage
+	labels := containerLabels(r.config, r.runID)
+	opts := []testcontainers.ContainerCustomizer{
 		postgres.WithDatabase(r.config.Database.Restore.DBName),
 		postgres.WithUsername(r.config.Database.Restore.User),
 		postgres.WithPassword(dbPassword),
 		testcontainers.WithWaitStrategy(waitStrategy),
-	)
-	if err != nil {
-		return fmt.Errorf("could not start postgres container: %w", err)
+		testcontainers.WithLabels(labels),
+		testcontainers.WithName(containerName(r.config, r.runID)),
 	}
-	r.container = pgContainer
 
-	fmt.Println("✓ Database container started.")
+	if r.mode == ModeDataOnly {
+		dataOnly := r.config.Database.Restore.DataOnly
+		if dataOnly == nil || (dataOnly.Image == "" && len(dataOnly.InitScripts) == 0) {
+			return fmt.Errorf("data-only mode requires database.restore.data_only.image or init_scripts to be configured")
+		}
+		if dataOnly.Image != "" {
+			image = dataOnly.Image
+		}
+		if len(dataOnly.InitScripts) > 0 {
+			scripts, err := resolveInitScripts(dataOnly.InitScripts)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, postgres.WithOrderedInitScripts(scripts...))
+		}
+	}
 
-	// Create a temporary file on the host for the backup stream
-	tmpFile, err := os.CreateTemp("", "restorable-backup-*.dump")
+	securityOpts, err := securityOptions(r.config, r.runID, "/var/run/postgresql")
 	if err != nil {
-		return fmt.Errorf("failed to create temporary backup file: %w", err)
+		return err
 	}
-	defer os.Remove(tmpFile.Name())
+	opts = append(opts, securityOpts...)
 
-	// Write the stream to the temporary file
-	_, err = io.Copy(tmpFile, backupStream)
-	if err != nil {
-		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	if r.config.Docker.IsolateNetwork {
+		isolation, err := newIsolatedNetwork(ctx, labels)
+		if err != nil {
+			return err
+		}
+		r.isolation = isolation
+		opts = append(opts, isolation.containerOption())
 	}
-	tmpFile.Close()
 
-	// Copy the temporary file to the container
-	containerBackupPath := "/tmp/backup.dump"
-	err = pgContainer.CopyFileToContainer(ctx, tmpFile.Name(), containerBackupPath, 0644)
+	pgContainer, err := postgres.Run(ctx, image, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to copy backup file into container: %w", err)
+		return fmt.Errorf("could not start postgres container: %w", err)
 	}
+	r.container = pgContainer
 
-	// Track restore duration
-	restoreStart := time.Now()
+	fmt.Printf("✓ Database container started: %s\n", containerName(r.config, r.runID))
 
-	// --- Attempt 1: pg_restore (for custom format) ---
-	fmt.Println("Attempting restore with pg_restore...")
-	pgRestoreCmd := []string{
-		"pg_restore",
-		"--username", r.config.Database.Restore.User,
-		"--dbname", r.config.Database.Restore.DBName,
-		"--no-password",
-		"--verbose",
-		"--no-owner",
-		containerBackupPath,
+	if r.mode != ModeDataOnly && isPostGISImage(image) {
+		if err := r.dropImageExtensions(ctx, pgContainer); err != nil {
+			return err
+		}
+	}
+	if r.mode != ModeDataOnly {
+		if err := r.createRoles(ctx, pgContainer); err != nil {
+			return err
+		}
 	}
 
-	pgRestoreExitCode, pgRestoreLogs, err := pgContainer.Exec(ctx, pgRestoreCmd)
+	// Copy the temporary file to the container
+	containerBackupPath := path.Join(backupDir(r.config), "backup.dump")
+	err = pgContainer.CopyFileToContainer(ctx, backupFile, containerBackupPath, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to execute pg_restore: %w", err)
+		return fmt.Errorf("failed to copy backup file into container: %w", err)
 	}
 
-	pgRestoreLogBytes, _ := io.ReadAll(pgRestoreLogs)
-
-	if pgRestoreExitCode == 0 {
-		r.restoreDuration = time.Since(restoreStart)
-		if r.verbose && len(pgRestoreLogBytes) > 0 {
-			fmt.Println("--- pg_restore output ---")
-			fmt.Println(string(pgRestoreLogBytes))
-			fmt.Println("-------------------------")
+	archivePath := containerBackupPath
+	var jobs int
+	if format == dumpFormatCustom {
+		jobs = r.config.Database.Restore.Jobs
+	}
+	if dumpDir != nil {
+		extractDir := path.Join(backupDir(r.config), "dump")
+		flags := "-xf"
+		if dumpDir.Gzipped {
+			flags = "-xzf"
+		}
+		extract := fmt.Sprintf("mkdir -p %s && tar %s %s -C %s",
+			shellQuote(extractDir), flags, shellQuote(containerBackupPath), shellQuote(extractDir))
+		if _, err := runInContainer(ctx, pgContainer, "tar", []string{"sh", "-c", extract}); err != nil {
+			return fmt.Errorf("failed to unpack directory-format dump: %w", err)
 		}
-		fmt.Println("✓ Database restore completed successfully with pg_restore.")
-	} else {
-		// --- Attempt 2: psql (for plain text format) ---
-		fmt.Println("pg_restore failed, attempting restore with psql...")
-		if r.verbose {
-			fmt.Println("--- pg_restore failure logs ---")
-			fmt.Println(string(pgRestoreLogBytes))
-			fmt.Println("-----------------------------")
+		archivePath = path.Join(extractDir, dumpDir.Root)
+		jobs = r.config.Database.Restore.Jobs
+		if jobs == 0 {
+			jobs = defaultDumpDirJobs
 		}
+		fmt.Println("✓ Directory-format dump unpacked")
+	}
 
+	if r.cluster {
+		if err := r.restoreCluster(ctx, pgContainer, containerBackupPath); err != nil {
+			return err
+		}
+		return r.connect(ctx, dbPassword)
+	}
+
+	// Track restore duration
+	restoreStart := time.Now()
+
+	if format == dumpFormatPlain {
+		fmt.Println("Restoring plain SQL dump with psql...")
 		psqlCmd := []string{
 			"psql",
 			"--username", r.config.Database.Restore.User,
@@ -131,9 +246,7 @@ func (r *PostgresRestorer) Restore(ctx context.Context, backupStream io.Reader)
 		psqlLogBytes, _ := io.ReadAll(psqlLogs)
 
 		if psqlExitCode != 0 {
-			return fmt.Errorf("all restore methods failed.\n\npg_restore (exit %d):\n%s\n\npsql (exit %d):\n%s",
-				pgRestoreExitCode, string(pgRestoreLogBytes),
-				psqlExitCode, string(psqlLogBytes))
+			return fmt.Errorf("psql failed (exit %d):\n%s", psqlExitCode, string(psqlLogBytes))
 		}
 
 		r.restoreDuration = time.Since(restoreStart)
@@ -144,22 +257,179 @@ func (r *PostgresRestorer) Restore(ctx context.Context, backupStream io.Reader)
 			fmt.Println("-------------------------")
 		}
 		fmt.Println("✓ Database restore completed successfully with psql.")
+		return r.connect(ctx, dbPassword)
+	}
+
+	fmt.Printf("Restoring %s-format dump with pg_restore...\n", format)
+	pgRestoreCmd := []string{
+		"pg_restore",
+		"--username", r.config.Database.Restore.User,
+		"--dbname", r.config.Database.Restore.DBName,
+		"--no-password",
+		"--verbose",
+	}
+	if !r.config.Database.Restore.PreserveOwnership {
+		pgRestoreCmd = append(pgRestoreCmd, "--no-owner")
+	}
+	switch r.mode {
+	case ModeSchemaOnly:
+		pgRestoreCmd = append(pgRestoreCmd, "--schema-only")
+	case ModeDataOnly:
+		pgRestoreCmd = append(pgRestoreCmd, "--data-only", "--disable-triggers")
 	}
+	pgRestoreCmd = append(pgRestoreCmd, tableSelection(r.tables)...)
+	if jobs > 1 {
+		pgRestoreCmd = append(pgRestoreCmd, "--jobs", strconv.Itoa(jobs))
+	}
+	pgRestoreCmd = append(pgRestoreCmd, archivePath)
 
-	// Establish database connection for queries
-	connStr, err := pgContainer.ConnectionString(ctx, "sslmode=disable")
+	pgRestoreExitCode, pgRestoreLogBytes, err := r.runPgRestore(ctx, pgContainer, pgRestoreCmd, archivePath)
 	if err != nil {
-		return fmt.Errorf("failed to get connection string: %w", err)
+		return fmt.Errorf("failed to execute pg_restore: %w", err)
 	}
+	if pgRestoreExitCode != 0 {
+		return fmt.Errorf("pg_restore failed on %s-format dump (exit %d):\n%s",
+			format, pgRestoreExitCode, string(pgRestoreLogBytes))
+	}
+
+	r.restoreDuration = time.Since(restoreStart)
+	if r.verbose && len(pgRestoreLogBytes) > 0 {
+		fmt.Println("--- pg_restore output ---")
+		fmt.Println(string(pgRestoreLogBytes))
+		fmt.Println("-------------------------")
+	}
+	fmt.Println("✓ Database restore completed successfully with pg_restore.")
 
-	r.db, err = sql.Open("postgres", connStr)
+	return r.connect(ctx, dbPassword)
+}
+
+// tableSelection returns the pg_restore flags restoring only tables. pg_restore
+// matches -t against unqualified names, so schema-qualified tables also add -n.
+func tableSelection(tables []string) []string {
+	var args []string
+	schemas := make(map[string]bool)
+	for _, t := range tables {
+		if schemaName, name, ok := strings.Cut(t, "."); ok {
+			if !schemas[schemaName] {
+				schemas[schemaName] = true
+				args = append(args, "--schema", schemaName)
+			}
+			t = name
+		}
+		args = append(args, "--table", t)
+	}
+	return args
+}
+
+// connect establishes the database connection used for queries.
+func (r *PostgresRestorer) connect(ctx context.Context, password string) error {
+	connStr, err := r.connectionString(ctx, password)
+	if err != nil {
+		return err
+	}
+
+	r.db, err = openDB("postgres", connStr, r.logSQL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	r.dsn = connStr
 
 	return nil
 }
 
+// UseDatabase reconnects to another database in the restored instance.
+func (r *PostgresRestorer) UseDatabase(ctx context.Context, name string) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	db, err := r.openDatabase(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	r.db.Close()
+	r.db = db
+	return nil
+}
+
+// openDatabase opens a connection to another database in the restored instance.
+func (r *PostgresRestorer) openDatabase(ctx context.Context, name string) (*sql.DB, error) {
+	dsn, err := url.Parse(r.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	dsn.Path = "/" + name
+
+	db, err := openDB("postgres", dsn.String(), r.logSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database %s: %w", name, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database %s not found in restored instance: %w", name, err)
+	}
+	return db, nil
+}
+
+// connectionString returns the DSN for the restored database. With network isolation
+// the database is only reachable through the localhost proxy.
+func (r *PostgresRestorer) connectionString(ctx context.Context, password string) (string, error) {
+	if r.isolation == nil {
+		connStr, err := r.container.ConnectionString(ctx, "sslmode=disable")
+		if err != nil {
+			return "", fmt.Errorf("failed to get connection string: %w", err)
+		}
+		return connStr, nil
+	}
+
+	host, port, err := r.isolation.startProxy(ctx, r.config.Docker.ProxyImage,
+		containerName(r.config, r.runID)+"-proxy", containerLabels(r.config, r.runID), 5432)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("✓ Database isolated from outbound network, reachable on %s:%s.\n", host, port)
+
+	user := r.config.Database.Restore.User
+	if r.physical {
+		user = basebackupUser
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     r.config.Database.Restore.DBName,
+		RawQuery: "sslmode=disable",
+	}
+	return dsn.String(), nil
+}
+
+// resolveInitScripts expands the configured paths into an ordered list of SQL files.
+// Directories contribute their *.sql files in lexical order.
+func resolveInitScripts(paths []string) ([]string, error) {
+	var scripts []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat init script %s: %w", p, err)
+		}
+		if !info.IsDir() {
+			scripts = append(scripts, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.sql"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list init scripts in %s: %w", p, err)
+		}
+		sort.Strings(matches)
+		scripts = append(scripts, matches...)
+	}
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("no SQL init scripts found in %v", paths)
+	}
+	return scripts, nil
+}
+
 // ExtractSchema extracts the schema from the restored database.
 func (r *PostgresRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, error) {
 	if r.db == nil {
@@ -175,6 +445,7 @@ func (r *PostgresRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, e
 			 WHERE c.table_schema = t.table_schema AND c.table_name = t.table_name) as column_count
 		FROM information_schema.tables t
 		WHERE table_schema NOT IN ('information_schema', 'pg_catalog')
+		  AND table_schema NOT LIKE '\_timescaledb\_%'
 		  AND table_type = 'BASE TABLE'
 		ORDER BY table_schema, table_name
 	`)
@@ -204,13 +475,159 @@ func (r *PostgresRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, e
 		return nil, fmt.Errorf("error iterating table rows: %w", err)
 	}
 
+	indexes, err := r.getIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachIndexes(tables, indexes)
+
+	constraints, err := r.getConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachConstraints(tables, constraints)
+
+	triggers, err := r.getTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachTriggers(tables, triggers)
+
+	extensions, err := r.getExtensions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	distributed, err := r.getDistributedTables(ctx, extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	privileges, err := r.getPrivileges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := r.getViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences, err := r.getSequences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	routines, err := r.getRoutines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var spatial []schema.SpatialColumn
+	for _, ext := range extensions {
+		if ext.Name == "postgis" {
+			spatial, err = r.getSpatialColumns(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &schema.Schema{
-		Version:   "1",
-		Timestamp: time.Now().UTC(),
-		Tables:    tables,
+		Version:           "1",
+		Timestamp:         time.Now().UTC(),
+		Tables:            tables,
+		Extensions:        extensions,
+		DistributedTables: distributed,
+		SpatialColumns:    spatial,
+		Privileges:        privileges,
+		Views:             views,
+		Sequences:         sequences,
+		Routines:          routines,
 	}, nil
 }
 
+func (r *PostgresRestorer) getExtensions(ctx context.Context) ([]schema.Extension, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT extname, extversion
+		FROM pg_extension
+		ORDER BY extname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extensions: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []schema.Extension
+	for rows.Next() {
+		var e schema.Extension
+		if err := rows.Scan(&e.Name, &e.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan extension row: %w", err)
+		}
+		extensions = append(extensions, e)
+	}
+
+	return extensions, rows.Err()
+}
+
+// getDistributedTables collects TimescaleDB hypertables and Citus distributed tables
+// with their chunk and shard counts, when those extensions are installed.
+func (r *PostgresRestorer) getDistributedTables(ctx context.Context, extensions []schema.Extension) ([]schema.DistributedTable, error) {
+	queries := map[string]struct {
+		kind  string
+		query string
+	}{
+		"timescaledb": {
+			kind: schema.KindHypertable,
+			query: `
+				SELECT hypertable_schema, hypertable_name, num_chunks
+				FROM timescaledb_information.hypertables
+				ORDER BY hypertable_schema, hypertable_name
+			`,
+		},
+		"citus": {
+			kind: schema.KindDistributed,
+			query: `
+				SELECT n.nspname, c.relname, COUNT(s.shardid)
+				FROM pg_dist_partition p
+				JOIN pg_class c ON c.oid = p.logicalrelid
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				LEFT JOIN pg_dist_shard s ON s.logicalrelid = p.logicalrelid
+				GROUP BY n.nspname, c.relname
+				ORDER BY n.nspname, c.relname
+			`,
+		},
+	}
+
+	var tables []schema.DistributedTable
+	for _, ext := range extensions {
+		q, ok := queries[ext.Name]
+		if !ok {
+			continue
+		}
+
+		rows, err := r.db.QueryContext(ctx, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s tables: %w", ext.Name, err)
+		}
+		for rows.Next() {
+			t := schema.DistributedTable{Kind: q.kind}
+			if err := rows.Scan(&t.Schema, &t.Name, &t.PartCount); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s table row: %w", ext.Name, err)
+			}
+			tables = append(tables, t)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating %s table rows: %w", ext.Name, err)
+		}
+	}
+
+	return tables, nil
+}
+
 func (r *PostgresRestorer) getTableColumns(ctx context.Context, schemaName, tableName string) ([]schema.Column, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT column_name, data_type, is_nullable
@@ -258,7 +675,7 @@ func (r *PostgresRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics,
 
 	// Get row counts for each table
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT schemaname, relname, n_live_tup
+		SELECT schemaname, relname, n_live_tup, pg_total_relation_size(relid)
 		FROM pg_stat_user_tables
 		ORDER BY schemaname, relname
 	`)
@@ -269,7 +686,7 @@ func (r *PostgresRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics,
 
 	for rows.Next() {
 		var tm schema.TableMetrics
-		if err := rows.Scan(&tm.Schema, &tm.Name, &tm.RowCount); err != nil {
+		if err := rows.Scan(&tm.Schema, &tm.Name, &tm.RowCount, &tm.SizeBytes); err != nil {
 			return nil, fmt.Errorf("failed to scan table metrics row: %w", err)
 		}
 		metrics.TableMetrics = append(metrics.TableMetrics, tm)
@@ -302,6 +719,7 @@ func (r *PostgresRestorer) getAccurateRowCounts(ctx context.Context) ([]schema.T
 		SELECT table_schema, table_name
 		FROM information_schema.tables
 		WHERE table_schema NOT IN ('information_schema', 'pg_catalog')
+		  AND table_schema NOT LIKE '\_timescaledb\_%'
 		  AND table_type = 'BASE TABLE'
 		ORDER BY table_schema, table_name
 	`)
@@ -330,21 +748,206 @@ func (r *PostgresRestorer) getAccurateRowCounts(ctx context.Context) ([]schema.T
 
 	// Count rows in each table
 	for _, t := range tables {
-		var count int64
-		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s"`, t.schema, t.name)
-		if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		var count, size int64
+		table := quotePostgresName(t.schema, t.name)
+		query := fmt.Sprintf(`SELECT COUNT(*), pg_total_relation_size($1::regclass) FROM %s`, table)
+		if err := r.db.QueryRowContext(ctx, query, table).Scan(&count, &size); err != nil {
 			return nil, fmt.Errorf("failed to count rows in %s.%s: %w", t.schema, t.name, err)
 		}
 		metrics = append(metrics, schema.TableMetrics{
-			Schema:   t.schema,
-			Name:     t.name,
-			RowCount: count,
+			Schema:    t.schema,
+			Name:      t.name,
+			RowCount:  count,
+			SizeBytes: size,
 		})
 	}
 
 	return metrics, nil
 }
 
+// ProfileColumns analyzes each column and profiles it. Distinct counts come from the
+// planner statistics; null rates and sizes are computed exactly, since ANALYZE
+// samples rows and reports the compressed width of TOASTed values.
+func (r *PostgresRestorer) ProfileColumns(ctx context.Context, columns []string) ([]schema.ColumnProfile, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	var profiles []schema.ColumnProfile
+	for _, name := range columns {
+		p, err := parseColumnRef(name, "public")
+		if err != nil {
+			return nil, err
+		}
+		table := quotePostgresName(p.Schema, p.Table)
+		column := quotePostgresIdent(p.Column)
+
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf(`ANALYZE %s (%s)`, table, column)); err != nil {
+			return nil, fmt.Errorf("failed to analyze %s: %w", name, err)
+		}
+
+		var rows, nonNull int64
+		var avgSize sql.NullFloat64
+		query := fmt.Sprintf(`SELECT COUNT(*), COUNT(%[1]s), AVG(octet_length(%[1]s::text)) FROM %[2]s`, column, table)
+		if err := r.db.QueryRowContext(ctx, query).Scan(&rows, &nonNull, &avgSize); err != nil {
+			return nil, fmt.Errorf("failed to profile %s: %w", name, err)
+		}
+		p.AvgSizeBytes = avgSize.Float64
+		if rows > 0 {
+			p.NullRate = float64(rows-nonNull) / float64(rows)
+		}
+
+		// Negative n_distinct is a fraction of the row count
+		var distinct sql.NullFloat64
+		err = r.db.QueryRowContext(ctx, `
+			SELECT n_distinct FROM pg_stats
+			WHERE schemaname = $1 AND tablename = $2 AND attname = $3
+		`, p.Schema, p.Table, p.Column).Scan(&distinct)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to read statistics for %s: %w", name, err)
+		}
+		if distinct.Float64 < 0 {
+			p.DistinctEstimate = int64(-distinct.Float64 * float64(nonNull))
+		} else {
+			p.DistinctEstimate = int64(distinct.Float64)
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// parseColumnRef splits schema.table.column, or table.column in defaultSchema.
+// Parts containing dots can be double-quoted, e.g. "my.schema".orders.total.
+func parseColumnRef(name, defaultSchema string) (schema.ColumnProfile, error) {
+	parts, err := splitQualifiedName(name)
+	if err != nil {
+		return schema.ColumnProfile{}, fmt.Errorf("invalid column %q: %w", name, err)
+	}
+	switch len(parts) {
+	case 2:
+		return schema.ColumnProfile{Schema: defaultSchema, Table: parts[0], Column: parts[1]}, nil
+	case 3:
+		return schema.ColumnProfile{Schema: parts[0], Table: parts[1], Column: parts[2]}, nil
+	default:
+		return schema.ColumnProfile{}, fmt.Errorf("invalid column %q; expected schema.table.column or table.column", name)
+	}
+}
+
+// MissingTable reports whether name, a configured schema.table or table, or with
+// column set a schema.table.column or table.column, refers to a table that is not
+// in s. A name without a schema matches a table of that name in any schema.
+// Names that do not parse are not reported missing, so their errors surface.
+func MissingTable(s *schema.Schema, name string, column bool) bool {
+	parts, err := splitQualifiedName(name)
+	if column && len(parts) > 0 {
+		parts = parts[:len(parts)-1]
+	}
+	if err != nil || len(parts) < 1 || len(parts) > 2 {
+		return false
+	}
+	table := parts[len(parts)-1]
+	for _, t := range s.Tables {
+		if t.Name == table && (len(parts) == 1 || t.Schema == parts[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitQualifiedName splits a dotted name into its parts. A double-quoted part may
+// contain dots, and doubled quotes inside it stand for one quote. Unquoted parts
+// are taken as written, since configured names match the catalog exactly.
+func splitQualifiedName(name string) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	quoted := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '"' && quoted && i+1 < len(name) && name[i+1] == '"':
+			part.WriteByte('"')
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == '.' && !quoted:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted identifier")
+	}
+	return append(parts, part.String()), nil
+}
+
+// quotePostgresIdent quotes an identifier with double quotes, doubling embedded
+// quotes, so names with any case or characters are used exactly as they are.
+func quotePostgresIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quotePostgresName returns the quoted schema-qualified name of a relation.
+func quotePostgresName(schemaName, name string) string {
+	return quotePostgresIdent(schemaName) + "." + quotePostgresIdent(name)
+}
+
+// ComputeChecksums hashes the contents of each table in s.
+// Row hashes are sorted before aggregation so the result is independent of physical row order.
+func (r *PostgresRestorer) ComputeChecksums(ctx context.Context, s *schema.Schema) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	for i := range s.Tables {
+		t := &s.Tables[i]
+		var checksum sql.NullString
+		query := fmt.Sprintf(`SELECT md5(string_agg(h, '' ORDER BY h)) FROM (SELECT md5(t::text) AS h FROM %s t) rows`, quotePostgresName(t.Schema, t.Name))
+		if err := r.db.QueryRowContext(ctx, query).Scan(&checksum); err != nil {
+			return fmt.Errorf("failed to checksum %s.%s: %w", t.Schema, t.Name, err)
+		}
+		// Empty tables aggregate to NULL; hash the empty string so they still compare
+		t.Checksum = checksum.String
+		if !checksum.Valid {
+			t.Checksum = "d41d8cd98f00b204e9800998ecf8427e"
+		}
+	}
+
+	return nil
+}
+
+// AppConnection returns the connection an application sharing the container's network uses.
+func (r *PostgresRestorer) AppConnection(ctx context.Context, name string) (*AppConnection, error) {
+	if r.container == nil {
+		return nil, fmt.Errorf("database container not started; call Restore first")
+	}
+	dsn, err := url.Parse(r.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	if name == "" {
+		name = r.config.Database.Restore.DBName
+	}
+	password, _ := dsn.User.Password()
+	return &AppConnection{
+		ContainerID: r.container.GetContainerID(),
+		Scheme:      "postgres",
+		Port:        5432,
+		User:        dsn.User.Username(),
+		Password:    password,
+		Database:    name,
+		Query:       "sslmode=disable",
+	}, nil
+}
+
+// RunQuery runs a custom SQL check against the restored database.
+func (r *PostgresRestorer) RunQuery(ctx context.Context, query string) (int, *string, error) {
+	return runQuery(ctx, r.db, query)
+}
+
 // Cleanup terminates the ephemeral database container.
 func (r *PostgresRestorer) Cleanup(ctx context.Context) error {
 	if r.db != nil {
@@ -352,10 +955,23 @@ func (r *PostgresRestorer) Cleanup(ctx context.Context) error {
 		r.db = nil
 	}
 	if r.container != nil {
-		if err := r.container.Terminate(ctx); err != nil {
+		var volumes []string
+		if r.physical {
+			volumes = append(volumes, r.dataVolumeName())
+		}
+		if r.config.Docker.Security.ReadOnlyRootfs {
+			volumes = append(volumes, workVolumeName(r.config, r.runID))
+		}
+		if err := r.container.Terminate(ctx, testcontainers.RemoveVolumes(volumes...)); err != nil {
 			return fmt.Errorf("failed to terminate container: %w", err)
 		}
 		r.container = nil
 	}
+	if r.isolation != nil {
+		if err := r.isolation.Remove(ctx); err != nil {
+			return err
+		}
+		r.isolation = nil
+	}
 	return nil
 }
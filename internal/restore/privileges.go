@@ -0,0 +1,86 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// createRoles creates the configured roles before the restore, so ownership and
+// grants referencing them restore instead of failing. Roles are global objects
+// and never part of a pg_dump artifact.
+func (r *PostgresRestorer) createRoles(ctx context.Context, container testcontainers.Container) error {
+	roles := r.config.Database.Restore.Roles
+	if len(roles) == 0 {
+		return nil
+	}
+
+	var stmts []string
+	for _, role := range roles {
+		stmts = append(stmts, fmt.Sprintf(
+			"IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '%s') THEN CREATE ROLE %s NOLOGIN; END IF;",
+			strings.ReplaceAll(role, "'", "''"), quotePostgresIdent(role)))
+	}
+	output, err := runInContainer(ctx, container, "psql", []string{
+		"psql",
+		"--username", r.config.Database.Restore.User,
+		"--dbname", r.config.Database.Restore.DBName,
+		"--no-password",
+		"-v", "ON_ERROR_STOP=1",
+		"--command", "DO $$ BEGIN " + strings.Join(stmts, " ") + " END $$;",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create roles: %w", err)
+	}
+	if r.verbose && len(output) > 0 {
+		fmt.Println(string(output))
+	}
+	fmt.Printf("✓ Created %d role(s) for ownership and grants.\n", len(roles))
+	return nil
+}
+
+// getPrivileges records the owner and explicit grants of every table and sequence.
+func (r *PostgresRestorer) getPrivileges(ctx context.Context) ([]schema.ObjectPrivileges, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname,
+			CASE WHEN c.relkind = 'S' THEN 'sequence' ELSE 'table' END,
+			pg_get_userbyid(c.relowner),
+			COALESCE((
+				SELECT string_agg(g, ',' ORDER BY g)
+				FROM (
+					SELECT CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END
+						|| ':' || a.privilege_type AS g
+					FROM aclexplode(c.relacl) a
+				) grants
+			), '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p', 'S')
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE 'pg\_toast%'
+		  AND n.nspname NOT LIKE '\_timescaledb\_%'
+		ORDER BY n.nspname, c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query privileges: %w", err)
+	}
+	defer rows.Close()
+
+	var privileges []schema.ObjectPrivileges
+	for rows.Next() {
+		var p schema.ObjectPrivileges
+		var grants string
+		if err := rows.Scan(&p.Schema, &p.Name, &p.Kind, &p.Owner, &grants); err != nil {
+			return nil, fmt.Errorf("failed to scan privilege row: %w", err)
+		}
+		if grants != "" {
+			p.Grants = strings.Split(grants, ",")
+		}
+		privileges = append(privileges, p)
+	}
+
+	return privileges, rows.Err()
+}
@@ -0,0 +1,207 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// pg_restore runs in the background of the container with its verbose output in a
+// log file, which is polled so progress is reported while the restore runs instead
+// of when it ends.
+const (
+	progressPollInterval = 2 * time.Second
+	// progressHeartbeat is how long a restore may stay on one object before a
+	// heartbeat event shows it is still working.
+	progressHeartbeat = time.Minute
+)
+
+// Restore sections, in the order pg_restore processes them.
+const (
+	SectionPreData  = "pre-data"
+	SectionData     = "data"
+	SectionPostData = "post-data"
+)
+
+var pgRestoreAction = regexp.MustCompile(`^pg_restore: (processing data for table|creating [A-Z ]+) "(.*)"$`)
+
+// ProgressEvent reports what a long-running restore is working on.
+type ProgressEvent struct {
+	Elapsed time.Duration `json:"elapsed_ns"`
+	Section string        `json:"section"`
+	// Action is pg_restore's description, e.g. "processing data for table".
+	Action string `json:"action"`
+	Object string `json:"object"`
+	// TablesDone counts tables whose data is restored or being restored, out of
+	// TablesTotal in the archive.
+	TablesDone  int `json:"tables_done"`
+	TablesTotal int `json:"tables_total"`
+	// Heartbeat marks events repeated because the restore is still on the same object.
+	Heartbeat bool `json:"heartbeat,omitempty"`
+}
+
+// pgRestoreProgress turns pg_restore's verbose output into progress events.
+type pgRestoreProgress struct {
+	report    func(ProgressEvent)
+	start     time.Time
+	last      ProgressEvent
+	lastEvent time.Time
+}
+
+// line parses one line of output, emitting an event when a table's data starts or
+// a new section begins.
+func (p *pgRestoreProgress) line(line string) {
+	match := pgRestoreAction.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	// Older versions quote schema and name separately
+	action, object := match[1], strings.ReplaceAll(match[2], `"."`, ".")
+
+	section := SectionPreData
+	switch {
+	case action == "processing data for table":
+		section = SectionData
+	case isPostDataAction(action):
+		section = SectionPostData
+	}
+
+	event := p.last
+	event.Action = action
+	event.Object = object
+	event.Heartbeat = false
+	if section == SectionData {
+		event.TablesDone++
+	}
+	changed := section != p.last.Section || section == SectionData
+	event.Section = section
+	p.last = event
+	if changed {
+		p.emit(event)
+	}
+}
+
+// tick emits a heartbeat when nothing was reported for a while.
+func (p *pgRestoreProgress) tick() {
+	if p.last.Section == "" || time.Since(p.lastEvent) < progressHeartbeat {
+		return
+	}
+	event := p.last
+	event.Heartbeat = true
+	p.emit(event)
+}
+
+func (p *pgRestoreProgress) emit(event ProgressEvent) {
+	event.Elapsed = time.Since(p.start)
+	p.lastEvent = time.Now()
+	if p.report != nil {
+		p.report(event)
+	}
+}
+
+// isPostDataAction reports whether a "creating X" action belongs to post-data.
+func isPostDataAction(action string) bool {
+	switch strings.TrimPrefix(action, "creating ") {
+	case "INDEX", "INDEX ATTACH", "CONSTRAINT", "FK CONSTRAINT", "TRIGGER", "RULE", "POLICY", "EVENT TRIGGER", "STATISTICS":
+		return true
+	}
+	return false
+}
+
+// countTableData counts the TABLE DATA entries of the archive's table of contents.
+func countTableData(ctx context.Context, ctr testcontainers.Container, archive string) int {
+	output, err := runInContainer(ctx, ctr, "pg_restore --list", []string{"pg_restore", "--list", archive})
+	if err != nil {
+		return 0
+	}
+	var total int
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, ";") && strings.Contains(line, " TABLE DATA ") {
+			total++
+		}
+	}
+	return total
+}
+
+// runPgRestore runs cmd in the background of ctr, reporting progress from its
+// verbose output until it exits, and returns its exit code and output.
+func (r *PostgresRestorer) runPgRestore(ctx context.Context, ctr testcontainers.Container, cmd []string, archive string) (int, []byte, error) {
+	logPath := path.Join(backupDir(r.config), "pg_restore.log")
+	exitPath := path.Join(backupDir(r.config), "pg_restore.exit")
+
+	quoted := make([]string, len(cmd))
+	for i, arg := range cmd {
+		quoted[i] = shellQuote(arg)
+	}
+	// The detached shell is reparented to the container's init process, the postmaster,
+	// which treats children exiting with an error as crashed backends. It always
+	// exits 0 after recording pg_restore's status.
+	script := fmt.Sprintf("%s > %s 2>&1; echo $? > %s", strings.Join(quoted, " "), logPath, exitPath)
+	if _, err := runInContainer(ctx, ctr, "pg_restore", []string{"sh", "-c", "nohup sh -c " + shellQuote(script) + " > /dev/null 2>&1 &"}); err != nil {
+		return 0, nil, err
+	}
+
+	progress := &pgRestoreProgress{report: r.progress, start: time.Now(), lastEvent: time.Now()}
+	if r.mode != ModeSchemaOnly {
+		progress.last.TablesTotal = countTableData(ctx, ctr, archive)
+	}
+
+	var offset int
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(progressPollInterval):
+		}
+
+		// Read the exit status first so the log read after it is complete
+		exitCode, exited := readExitCode(ctx, ctr, exitPath)
+
+		output, err := readContainerFile(ctx, ctr, logPath)
+		if err != nil && exited {
+			return 0, nil, fmt.Errorf("failed to read pg_restore output: %w", err)
+		}
+		if end := bytes.LastIndexByte(output, '\n') + 1; end > offset {
+			for _, line := range strings.Split(string(output[offset:end-1]), "\n") {
+				progress.line(line)
+			}
+			offset = end
+		}
+
+		if exited {
+			return exitCode, output, nil
+		}
+		progress.tick()
+	}
+}
+
+// readExitCode returns the exit status written to exitPath, if it exists yet.
+func readExitCode(ctx context.Context, ctr testcontainers.Container, exitPath string) (int, bool) {
+	data, err := readContainerFile(ctx, ctr, exitPath)
+	if err != nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// readContainerFile reads a file from the container.
+func readContainerFile(ctx context.Context, ctr testcontainers.Container, filePath string) ([]byte, error) {
+	rc, err := ctr.CopyFileFromContainer(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,67 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// QueryRunner is implemented by restorers that can run custom SQL checks against
+// the restored database.
+type QueryRunner interface {
+	// RunQuery returns how many rows query returned and the first column of the
+	// first row as text, nil when there are no rows or the value is NULL.
+	RunQuery(ctx context.Context, query string) (rows int, first *string, err error)
+}
+
+// runQuery runs query on db, reading every row so the count is exact.
+func runQuery(ctx context.Context, db *sql.DB, query string) (int, *string, error) {
+	if db == nil {
+		return 0, nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, nil, err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var count int
+	var first *string
+	for rows.Next() {
+		if count == 0 && len(columns) > 0 {
+			if err := rows.Scan(dest...); err != nil {
+				return 0, nil, err
+			}
+			first = formatQueryValue(values[0])
+		}
+		count++
+	}
+	return count, first, rows.Err()
+}
+
+// formatQueryValue renders a scanned value as text, or nil for NULL.
+func formatQueryValue(v any) *string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s = string(v)
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	default:
+		s = fmt.Sprint(v)
+	}
+	return &s
+}
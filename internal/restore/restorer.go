@@ -2,11 +2,55 @@ package restore
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"os"
+	"time"
 
+	"restorable.io/restorable-cli/internal/config"
 	"restorable.io/restorable-cli/internal/schema"
 )
 
+// Mode selects which sections of a backup are restored.
+type Mode string
+
+const (
+	ModeFull       Mode = "full"        // Schema and data
+	ModeSchemaOnly Mode = "schema-only" // Schema only, for fast sanity checks
+	ModeDataOnly   Mode = "data-only"   // Data only, into a pre-initialized schema
+)
+
+// ParseMode validates a mode string, defaulting to ModeFull when empty.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeFull:
+		return ModeFull, nil
+	case ModeSchemaOnly, ModeDataOnly:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("unsupported verification mode: %s", s)
+	}
+}
+
+// Options control how a restorer runs.
+type Options struct {
+	Verbose bool
+	Mode    Mode
+	// RunID identifies this verification run in container names and labels.
+	RunID string
+	// Password is the superuser password for the ephemeral database.
+	Password string
+	// Progress receives progress events of long-running restores, if supported.
+	Progress func(ProgressEvent)
+	// Tables restricts the restore to these tables, optionally schema-qualified,
+	// for quick canary checks. Only supported for Postgres archive dumps.
+	Tables []string
+	// LogSQL prints every SQL statement the restorer runs, with its duration.
+	LogSQL bool
+}
+
 // Restorer defines the interface for database restore operations.
 type Restorer interface {
 	// Restore performs the database restore from a backup stream.
@@ -18,3 +62,67 @@ type Restorer interface {
 	// Cleanup terminates the ephemeral database container.
 	Cleanup(ctx context.Context) error
 }
+
+// TableChecksummer is implemented by restorers that can hash table contents.
+type TableChecksummer interface {
+	// ComputeChecksums fills in the Checksum of every table in s.
+	ComputeChecksums(ctx context.Context, s *schema.Schema) error
+}
+
+// DatabaseSwitcher is implemented by restorers whose restored instance can hold several
+// logical databases, e.g. from a cluster-wide dump.
+type DatabaseSwitcher interface {
+	// UseDatabase points subsequent extraction at the named database.
+	UseDatabase(ctx context.Context, name string) error
+}
+
+// DatabaseLister is implemented by restorers that can restore cluster-wide dumps.
+type DatabaseLister interface {
+	// RestoredDatabases returns the databases a cluster-wide dump created, or nil
+	// when a single database was restored.
+	RestoredDatabases(ctx context.Context) ([]string, error)
+}
+
+// ColumnProfiler is implemented by restorers that can profile column values.
+type ColumnProfiler interface {
+	// ProfileColumns profiles columns given as schema.table.column or table.column.
+	ProfileColumns(ctx context.Context, columns []string) ([]schema.ColumnProfile, error)
+}
+
+// IntegrityVerifier is implemented by restorers that can run the database's own
+// consistency checks on the restored data.
+type IntegrityVerifier interface {
+	CheckIntegrity(ctx context.Context) (*IntegrityResult, error)
+}
+
+// RecoveryReporter is implemented by restorers that replay WAL, reporting the recovery
+// point actually reached.
+type RecoveryReporter interface {
+	// LastReplayedTransaction returns the commit time of the last replayed transaction.
+	LastReplayedTransaction(ctx context.Context) (*time.Time, error)
+}
+
+// IntegrityResult lists the problems found by the database's consistency checks.
+type IntegrityResult struct {
+	// Problems are storage-level corruption reports.
+	Problems []string
+	// ForeignKeyViolations are rows referencing missing parent rows.
+	ForeignKeyViolations []string
+}
+
+// ResolvePassword returns the password for the ephemeral database. It uses the
+// configured environment variable when set, and otherwise generates a random
+// password for this run, since the container is thrown away afterwards.
+func ResolvePassword(cfg *config.Config) (password string, generated bool, err error) {
+	if env := cfg.Database.Restore.PasswordEnv; env != "" {
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			return value, false, nil
+		}
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, fmt.Errorf("failed to generate database password: %w", err)
+	}
+	return hex.EncodeToString(buf), true, nil
+}
@@ -0,0 +1,74 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// getRoutines records the functions and procedures of every schema with their
+// argument types. Routines belonging to extensions are left to the extensions check.
+func (r *PostgresRestorer) getRoutines(ctx context.Context) ([]schema.Routine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.routine_schema, r.routine_name, COALESCE(r.routine_type, 'FUNCTION'),
+			pg_get_function_identity_arguments(p.oid)
+		FROM information_schema.routines r
+		JOIN pg_proc p ON r.specific_name = p.proname || '_' || p.oid
+		WHERE r.routine_schema NOT IN ('information_schema', 'pg_catalog')
+		  AND r.routine_schema NOT LIKE '\_timescaledb\_%'
+		  AND NOT EXISTS (
+			SELECT 1 FROM pg_depend d
+			WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+		  )
+		ORDER BY 1, 2, 4
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schema.Routine
+	for rows.Next() {
+		var rt schema.Routine
+		if err := rows.Scan(&rt.Schema, &rt.Name, &rt.Type, &rt.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to scan routine row: %w", err)
+		}
+		routines = append(routines, rt)
+	}
+	return routines, rows.Err()
+}
+
+// getRoutines records the stored functions and procedures of the current
+// database with their parameters.
+func (r *MariaDBRestorer) getRoutines(ctx context.Context) ([]schema.Routine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.routine_schema, r.routine_name, r.routine_type,
+			COALESCE((
+				SELECT GROUP_CONCAT(CONCAT_WS(' ', p.parameter_mode, p.parameter_name, p.dtd_identifier)
+					ORDER BY p.ordinal_position SEPARATOR ', ')
+				FROM information_schema.parameters p
+				WHERE p.specific_schema = r.routine_schema
+				  AND p.specific_name = r.specific_name
+				  AND p.routine_type = r.routine_type
+				  AND p.ordinal_position > 0
+			), '')
+		FROM information_schema.routines r
+		WHERE r.routine_schema = DATABASE()
+		ORDER BY r.routine_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schema.Routine
+	for rows.Next() {
+		var rt schema.Routine
+		if err := rows.Scan(&rt.Schema, &rt.Name, &rt.Type, &rt.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to scan routine row: %w", err)
+		}
+		routines = append(routines, rt)
+	}
+	return routines, rows.Err()
+}
@@ -0,0 +1,76 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// DefaultSampleLimit is the number of rows hashed when a sample sets no limit.
+const DefaultSampleLimit = 1000
+
+// SampleChecksummer is implemented by restorers that can hash a sample of a table's rows.
+type SampleChecksummer interface {
+	// ChecksumSample hashes the first limit rows of table, given as schema.table or
+	// table, matching where in orderBy order. An empty where selects every row and
+	// an empty orderBy orders by the primary key.
+	ChecksumSample(ctx context.Context, table, where, orderBy string, limit int) (*schema.SampleChecksum, error)
+}
+
+// ChecksumSample hashes a sample of a table's rows. Row hashes are sorted before
+// aggregation, so only which rows the sample selects depends on orderBy.
+func (r *PostgresRestorer) ChecksumSample(ctx context.Context, table, where, orderBy string, limit int) (*schema.SampleChecksum, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+	parts, err := splitQualifiedName(table)
+	if err != nil || len(parts) > 2 {
+		return nil, fmt.Errorf("invalid table %q; expected schema.table or table", table)
+	}
+	if len(parts) == 1 {
+		parts = append([]string{"public"}, parts...)
+	}
+	name := quotePostgresName(parts[0], parts[1])
+	if limit <= 0 {
+		limit = DefaultSampleLimit
+	}
+
+	if orderBy == "" {
+		var primaryKey sql.NullString
+		if err := r.db.QueryRowContext(ctx, `
+			SELECT string_agg(quote_ident(a.attname), ', ' ORDER BY array_position(i.indkey, a.attnum))
+			FROM pg_index i
+			JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+			WHERE i.indrelid = $1::regclass AND i.indisprimary
+		`, name).Scan(&primaryKey); err != nil {
+			return nil, fmt.Errorf("failed to read the primary key of %s: %w", table, err)
+		}
+		if !primaryKey.Valid {
+			return nil, fmt.Errorf("table %s has no primary key; set order_by for its checksum sample", table)
+		}
+		orderBy = primaryKey.String
+	}
+
+	filter := ""
+	if where != "" {
+		filter = "WHERE " + where
+	}
+	sample := &schema.SampleChecksum{Table: table, Sample: describeSample(where, orderBy, limit)}
+	query := fmt.Sprintf(`SELECT md5(COALESCE(string_agg(h, '' ORDER BY h), '')), COUNT(*) FROM (SELECT md5(t::text) AS h FROM %s t %s ORDER BY %s LIMIT %d) rows`,
+		name, filter, orderBy, limit)
+	if err := r.db.QueryRowContext(ctx, query).Scan(&sample.Checksum, &sample.Rows); err != nil {
+		return nil, fmt.Errorf("failed to checksum the sample of %s: %w", table, err)
+	}
+	return sample, nil
+}
+
+// describeSample renders the rows a sample selects, e.g. WHERE paid ORDER BY id LIMIT 1000.
+func describeSample(where, orderBy string, limit int) string {
+	desc := fmt.Sprintf("ORDER BY %s LIMIT %d", orderBy, limit)
+	if where != "" {
+		desc = fmt.Sprintf("WHERE %s %s", where, desc)
+	}
+	return desc
+}
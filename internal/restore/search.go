@@ -0,0 +1,467 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+const (
+	searchPort = 9200
+	// searchRepoDir is the registered path.repo; /tmp stays writable with a read-only rootfs.
+	searchRepoDir  = "/tmp/restorable-snapshots"
+	searchRepoName = "restorable"
+	// searchSchema is the schema name indices are reported under.
+	searchSchema = "indices"
+)
+
+// searchEnv returns the environment for a single-node cluster without security,
+// so the restore needs no credentials. The node is only reachable from this host.
+func searchEnv(dbType string) map[string]string {
+	env := map[string]string{
+		"discovery.type": "single-node",
+		"path.repo":      searchRepoDir,
+	}
+	if dbType == "opensearch" {
+		env["DISABLE_SECURITY_PLUGIN"] = "true"
+		env["DISABLE_INSTALL_DEMO_CONFIG"] = "true"
+		env["OPENSEARCH_JAVA_OPTS"] = "-Xms512m -Xmx512m"
+	} else {
+		env["xpack.security.enabled"] = "false"
+		env["ES_JAVA_OPTS"] = "-Xms512m -Xmx512m"
+	}
+	return env
+}
+
+// SearchRestorer restores Elasticsearch or OpenSearch snapshots from a file-system
+// snapshot repository, packed as a tar of the repository directory. Indices are
+// reported as tables with document counts as row counts, mapped fields as columns
+// and a hash of the mapping.
+type SearchRestorer struct {
+	config          *config.Config
+	verbose         bool
+	mode            Mode
+	runID           string
+	dbType          string
+	snapshot        string
+	container       *testcontainers.DockerContainer
+	isolation       *isolatedNetwork
+	baseURL         string
+	client          *http.Client
+	restoreDuration time.Duration
+}
+
+// NewSearchRestorer creates a new restorer instance for database type
+// "elasticsearch" or "opensearch".
+func NewSearchRestorer(cfg *config.Config, opts Options) *SearchRestorer {
+	return &SearchRestorer{
+		config:  cfg,
+		verbose: opts.Verbose,
+		mode:    opts.Mode,
+		runID:   opts.RunID,
+		dbType:  cfg.Database.Type,
+		client:  &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// Restore starts a single-node cluster, registers the extracted repository and
+// restores its latest successful snapshot.
+func (r *SearchRestorer) Restore(ctx context.Context, backupStream io.Reader) error {
+	if r.mode != ModeFull {
+		return fmt.Errorf("%s mode is not supported for database type: %s", r.mode, r.dbType)
+	}
+
+	buffered := bufio.NewReader(backupStream)
+	header, _ := buffered.Peek(512)
+	tarFlags := "-xf"
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		tarFlags = "-xzf"
+	case len(header) >= 262 && bytes.Equal(header[257:262], []byte("ustar")):
+	default:
+		return fmt.Errorf("unrecognized %s backup format; expected a tar of a snapshot repository", r.dbType)
+	}
+
+	tmpFile, err := os.CreateTemp("", "restorable-backup-*.tar")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, buffered); err != nil {
+		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := r.startServer(ctx); err != nil {
+		return err
+	}
+
+	containerBackupPath := path.Join(backupDir(r.config), "backup.tar")
+	if err := r.container.CopyFileToContainer(ctx, tmpFile.Name(), containerBackupPath, 0644); err != nil {
+		return fmt.Errorf("failed to copy backup file into container: %w", err)
+	}
+
+	restoreStart := time.Now()
+	// The repository root holds the shallowest index-N file; shards have their own deeper down
+	extract := fmt.Sprintf(`mkdir -p %[1]s && tar %[2]s %[3]s -C %[1]s && `+
+		`root=$(dirname "$(find %[1]s -name 'index-*' -type f | awk -F/ '{print NF, $0}' | sort -n | head -n 1 | cut -d' ' -f2-)") && echo "$root"`,
+		searchRepoDir, tarFlags, containerBackupPath)
+	out, err := runInContainer(ctx, r.container, "extract", []string{"sh", "-c", extract})
+	if err != nil {
+		return err
+	}
+	repoRoot := strings.TrimSpace(string(out))
+	if repoRoot == "" || repoRoot == "." {
+		return fmt.Errorf("no snapshot repository (index-N file) found in the backup archive")
+	}
+
+	if err := r.connect(ctx); err != nil {
+		return err
+	}
+
+	repo := map[string]any{
+		"type":     "fs",
+		"settings": map[string]any{"location": repoRoot, "readonly": true},
+	}
+	if err := r.request(ctx, http.MethodPut, "/_snapshot/"+searchRepoName, repo, nil); err != nil {
+		return fmt.Errorf("failed to register snapshot repository: %w", err)
+	}
+
+	snapshot, err := r.latestSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	r.snapshot = snapshot
+	fmt.Printf("✓ Found snapshot %s.\n", snapshot)
+
+	fmt.Println("Restoring snapshot...")
+	body := map[string]any{
+		"indices":              "*,-.*",
+		"include_global_state": false,
+	}
+	var restored struct {
+		Snapshot struct {
+			Shards struct {
+				Total  int `json:"total"`
+				Failed int `json:"failed"`
+			} `json:"shards"`
+		} `json:"snapshot"`
+	}
+	restorePath := fmt.Sprintf("/_snapshot/%s/%s/_restore?wait_for_completion=true", searchRepoName, url.PathEscape(snapshot))
+	if err := r.request(ctx, http.MethodPost, restorePath, body, &restored); err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", snapshot, err)
+	}
+	if restored.Snapshot.Shards.Failed > 0 {
+		return fmt.Errorf("snapshot restore failed for %d of %d shards", restored.Snapshot.Shards.Failed, restored.Snapshot.Shards.Total)
+	}
+	r.restoreDuration = time.Since(restoreStart)
+	fmt.Printf("✓ Database restore completed successfully (%d shards).\n", restored.Snapshot.Shards.Total)
+
+	return r.request(ctx, http.MethodPost, "/_refresh", nil, nil)
+}
+
+// latestSnapshot returns the most recent successful snapshot in the repository.
+func (r *SearchRestorer) latestSnapshot(ctx context.Context) (string, error) {
+	var listing struct {
+		Snapshots []struct {
+			Snapshot string `json:"snapshot"`
+			State    string `json:"state"`
+			EndTime  int64  `json:"end_time_in_millis"`
+		} `json:"snapshots"`
+	}
+	if err := r.request(ctx, http.MethodGet, "/_snapshot/"+searchRepoName+"/_all", nil, &listing); err != nil {
+		return "", fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	latest, latestEnd := "", int64(-1)
+	for _, s := range listing.Snapshots {
+		if s.State == "SUCCESS" && s.EndTime > latestEnd {
+			latest, latestEnd = s.Snapshot, s.EndTime
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no successful snapshot found in repository (%d snapshots)", len(listing.Snapshots))
+	}
+	return latest, nil
+}
+
+// startServer starts the search container and waits for the cluster to be usable.
+func (r *SearchRestorer) startServer(ctx context.Context) error {
+	labels := containerLabels(r.config, r.runID)
+	health := fmt.Sprintf("http://localhost:%d/_cluster/health?wait_for_status=yellow&timeout=1s", searchPort)
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithEnv(searchEnv(r.dbType)),
+		testcontainers.WithExposedPorts(fmt.Sprintf("%d/tcp", searchPort)),
+		testcontainers.WithWaitStrategy(wait.ForExec([]string{"curl", "-sf", health}).
+			WithStartupTimeout(5 * time.Minute)),
+		testcontainers.WithLabels(labels),
+		testcontainers.WithName(containerName(r.config, r.runID)),
+	}
+
+	securityOpts, err := securityOptions(r.config, r.runID, "/tmp")
+	if err != nil {
+		return err
+	}
+	opts = append(opts, securityOpts...)
+
+	if r.config.Docker.IsolateNetwork {
+		isolation, err := newIsolatedNetwork(ctx, labels)
+		if err != nil {
+			return err
+		}
+		r.isolation = isolation
+		opts = append(opts, isolation.containerOption())
+	}
+
+	ctr, err := testcontainers.Run(ctx, r.config.Database.Restore.DockerImage, opts...)
+	if ctr != nil {
+		r.container = ctr
+	}
+	if err != nil {
+		return fmt.Errorf("could not start %s container: %w", r.dbType, err)
+	}
+
+	fmt.Printf("✓ Database container started: %s\n", containerName(r.config, r.runID))
+	return nil
+}
+
+// connect resolves the HTTP endpoint, through the localhost proxy when isolated.
+func (r *SearchRestorer) connect(ctx context.Context) error {
+	var host, port string
+	if r.isolation == nil {
+		var err error
+		host, err = r.container.Host(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get container host: %w", err)
+		}
+		mapped, err := r.container.MappedPort(ctx, fmt.Sprintf("%d/tcp", searchPort))
+		if err != nil {
+			return fmt.Errorf("failed to get container port: %w", err)
+		}
+		port = mapped.Port()
+	} else {
+		var err error
+		host, port, err = r.isolation.startProxy(ctx, r.config.Docker.ProxyImage,
+			containerName(r.config, r.runID)+"-proxy", containerLabels(r.config, r.runID), searchPort)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Database isolated from outbound network, reachable on %s:%s.\n", host, port)
+	}
+
+	r.baseURL = "http://" + net.JoinHostPort(host, port)
+	return nil
+}
+
+// request sends a JSON request and decodes the JSON response into out, if given.
+func (r *SearchRestorer) request(ctx context.Context, method, path string, body, out any) error {
+	if r.baseURL == "" {
+		return fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response of %s %s: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// searchIndex is one row of _cat/indices.
+type searchIndex struct {
+	Name      string `json:"index"`
+	SizeBytes string `json:"store.size"`
+}
+
+// indices lists the restored indices, excluding hidden and system indices.
+func (r *SearchRestorer) indices(ctx context.Context) ([]searchIndex, error) {
+	var indices []searchIndex
+	if err := r.request(ctx, http.MethodGet, "/_cat/indices?format=json&bytes=b&h=index,store.size&expand_wildcards=open", nil, &indices); err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+
+	visible := indices[:0]
+	for _, idx := range indices {
+		if !strings.HasPrefix(idx.Name, ".") {
+			visible = append(visible, idx)
+		}
+	}
+	sort.Slice(visible, func(i, j int) bool { return visible[i].Name < visible[j].Name })
+	return visible, nil
+}
+
+// ExtractSchema reports each index with its mapped fields and mapping hash.
+func (r *SearchRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, error) {
+	indices, err := r.indices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []schema.Table
+	for _, idx := range indices {
+		var mappings map[string]struct {
+			Mappings json.RawMessage `json:"mappings"`
+		}
+		if err := r.request(ctx, http.MethodGet, "/"+url.PathEscape(idx.Name)+"/_mapping", nil, &mappings); err != nil {
+			return nil, fmt.Errorf("failed to get mapping of %s: %w", idx.Name, err)
+		}
+
+		var mapping map[string]any
+		if err := json.Unmarshal(mappings[idx.Name].Mappings, &mapping); err != nil {
+			return nil, fmt.Errorf("failed to parse mapping of %s: %w", idx.Name, err)
+		}
+		// Marshaling a map sorts its keys, so equal mappings hash equally
+		canonical, err := json.Marshal(mapping)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(canonical)
+
+		t := schema.Table{
+			Schema:      searchSchema,
+			Name:        idx.Name,
+			MappingHash: hex.EncodeToString(sum[:]),
+		}
+		if properties, ok := mapping["properties"].(map[string]any); ok {
+			t.Columns = mappingFields("", properties)
+		}
+		t.ColumnCount = len(t.Columns)
+		tables = append(tables, t)
+	}
+
+	return &schema.Schema{
+		Version:   "1",
+		Timestamp: time.Now().UTC(),
+		Tables:    tables,
+	}, nil
+}
+
+// mappingFields flattens mapping properties into dotted field paths with their types.
+func mappingFields(prefix string, properties map[string]any) []schema.Column {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var columns []schema.Column
+	for _, name := range names {
+		field, _ := properties[name].(map[string]any)
+		fieldType, _ := field["type"].(string)
+		if nested, ok := field["properties"].(map[string]any); ok {
+			if fieldType == "" {
+				fieldType = "object"
+			}
+			columns = append(columns, schema.Column{Name: prefix + name, DataType: fieldType, Nullable: true})
+			columns = append(columns, mappingFields(prefix+name+".", nested)...)
+			continue
+		}
+		columns = append(columns, schema.Column{Name: prefix + name, DataType: fieldType, Nullable: true})
+	}
+	return columns
+}
+
+// ExtractMetrics reports document counts and store sizes per index.
+func (r *SearchRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics, error) {
+	indices, err := r.indices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &schema.Metrics{
+		Timestamp:       time.Now().UTC(),
+		RestoreDuration: r.restoreDuration,
+	}
+	for _, idx := range indices {
+		// _count counts top-level documents, unlike docs.count which includes nested ones
+		var count struct {
+			Count int64 `json:"count"`
+		}
+		if err := r.request(ctx, http.MethodGet, "/"+url.PathEscape(idx.Name)+"/_count", nil, &count); err != nil {
+			return nil, fmt.Errorf("failed to count documents in %s: %w", idx.Name, err)
+		}
+		size, _ := strconv.ParseInt(idx.SizeBytes, 10, 64)
+
+		metrics.DBSizeBytes += size
+		metrics.TableMetrics = append(metrics.TableMetrics, schema.TableMetrics{
+			Schema:    searchSchema,
+			Name:      idx.Name,
+			RowCount:  count.Count,
+			SizeBytes: size,
+		})
+	}
+
+	return metrics, nil
+}
+
+// Cleanup terminates the ephemeral database container.
+func (r *SearchRestorer) Cleanup(ctx context.Context) error {
+	r.baseURL = ""
+	if r.container != nil {
+		var terminateOpts []testcontainers.TerminateOption
+		if r.config.Docker.Security.ReadOnlyRootfs {
+			terminateOpts = append(terminateOpts, testcontainers.RemoveVolumes(workVolumeName(r.config, r.runID)))
+		}
+		if err := r.container.Terminate(ctx, terminateOpts...); err != nil {
+			return fmt.Errorf("failed to terminate container: %w", err)
+		}
+		r.container = nil
+	}
+	if r.isolation != nil {
+		if err := r.isolation.Remove(ctx); err != nil {
+			return err
+		}
+		r.isolation = nil
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	defaultBackupDir = "/tmp"
+	// workDir receives the backup when the root filesystem is read-only.
+	// It is backed by a per-run volume because docker cp cannot write to tmpfs.
+	workDir = "/restorable"
+)
+
+// backupDir returns the directory inside the container the backup is copied to.
+func backupDir(cfg *config.Config) string {
+	if cfg.Docker.Security.ReadOnlyRootfs {
+		return workDir
+	}
+	return defaultBackupDir
+}
+
+// workVolumeName returns the name of the per-run volume used with a read-only rootfs.
+func workVolumeName(cfg *config.Config, runID string) string {
+	return containerName(cfg, runID) + "-work"
+}
+
+// securityOptions translates docker.security into container customizers.
+// socketDir is the database server's socket directory, which needs to stay writable.
+func securityOptions(cfg *config.Config, runID, socketDir string) ([]testcontainers.ContainerCustomizer, error) {
+	sec := cfg.Docker.Security
+
+	var securityOpt []string
+	if sec.NoNewPrivileges {
+		securityOpt = append(securityOpt, "no-new-privileges:true")
+	}
+	if sec.SeccompProfile != "" {
+		profile := sec.SeccompProfile
+		if profile != "unconfined" {
+			// The Docker API expects the profile JSON itself, not a path
+			data, err := os.ReadFile(profile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read seccomp profile: %w", err)
+			}
+			profile = string(data)
+		}
+		securityOpt = append(securityOpt, "seccomp="+profile)
+	}
+	if sec.ApparmorProfile != "" {
+		securityOpt = append(securityOpt, "apparmor="+sec.ApparmorProfile)
+	}
+
+	var opts []testcontainers.ContainerCustomizer
+	if len(securityOpt) > 0 || len(sec.CapDrop) > 0 || sec.ReadOnlyRootfs {
+		opts = append(opts, testcontainers.WithHostConfigModifier(func(hc *container.HostConfig) {
+			hc.SecurityOpt = append(hc.SecurityOpt, securityOpt...)
+			hc.CapDrop = append(hc.CapDrop, sec.CapDrop...)
+			hc.ReadonlyRootfs = sec.ReadOnlyRootfs
+		}))
+	}
+
+	if sec.User != "" {
+		opts = append(opts, testcontainers.WithConfigModifier(func(c *container.Config) {
+			c.User = sec.User
+		}))
+	}
+
+	if sec.ReadOnlyRootfs {
+		if cfg.Database.Restore.DataOnly != nil && len(cfg.Database.Restore.DataOnly.InitScripts) > 0 {
+			return nil, fmt.Errorf("docker.security.read_only_rootfs cannot be combined with data_only.init_scripts; bake the schema into data_only.image instead")
+		}
+		// The server needs writable scratch space; the data directory is already a volume
+		opts = append(opts,
+			testcontainers.WithTmpfs(map[string]string{
+				"/tmp":    "rw,mode=1777",
+				socketDir: "rw,mode=1777",
+			}),
+			testcontainers.WithMounts(testcontainers.VolumeMount(workVolumeName(cfg, runID), workDir)),
+		)
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,68 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// getSequences records the current value of every sequence and, for sequences
+// owned by an integer column (serial and identity columns), the largest value in
+// that column, so a sequence reset below the restored rows can be detected.
+func (r *PostgresRestorer) getSequences(ctx context.Context) ([]schema.Sequence, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.schemaname, s.sequencename, s.last_value, s.increment_by,
+			COALESCE(tn.nspname, ''), COALESCE(t.relname, ''), COALESCE(a.attname, '')
+		FROM pg_sequences s
+		JOIN pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.sequencename
+		LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.objid = c.oid
+			AND d.refclassid = 'pg_class'::regclass AND d.refobjsubid > 0 AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+			AND a.atttypid IN ('int2'::regtype, 'int4'::regtype, 'int8'::regtype)
+		LEFT JOIN pg_class t ON t.oid = a.attrelid
+		LEFT JOIN pg_namespace tn ON tn.oid = t.relnamespace
+		WHERE s.schemaname NOT IN ('information_schema', 'pg_catalog')
+		  AND s.schemaname NOT LIKE '\_timescaledb\_%'
+		ORDER BY s.schemaname, s.sequencename
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var sequences []schema.Sequence
+	for rows.Next() {
+		var s schema.Sequence
+		var lastValue sql.NullInt64
+		if err := rows.Scan(&s.Schema, &s.Name, &lastValue, &s.Increment, &s.OwnerSchema, &s.OwnerTable, &s.OwnerColumn); err != nil {
+			return nil, fmt.Errorf("failed to scan sequence row: %w", err)
+		}
+		if lastValue.Valid {
+			s.LastValue = &lastValue.Int64
+		}
+		sequences = append(sequences, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sequence rows: %w", err)
+	}
+	rows.Close()
+
+	for i := range sequences {
+		s := &sequences[i]
+		if s.OwnerColumn == "" {
+			continue
+		}
+		var max sql.NullInt64
+		query := fmt.Sprintf("SELECT max(%s)::bigint FROM %s", quotePostgresIdent(s.OwnerColumn), quotePostgresName(s.OwnerSchema, s.OwnerTable))
+		if err := r.db.QueryRowContext(ctx, query).Scan(&max); err != nil {
+			return nil, fmt.Errorf("failed to read the largest %s of %s.%s: %w", s.OwnerColumn, s.OwnerSchema, s.OwnerTable, err)
+		}
+		if max.Valid {
+			s.OwnerMax = &max.Int64
+		}
+	}
+	return sequences, nil
+}
@@ -0,0 +1,309 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+	"restorable.io/restorable-cli/internal/config"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// sqliteHeader starts every SQLite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// SQLiteRestorer verifies SQLite backups without Docker. Database files are
+// copied and opened directly; SQL dumps (sqlite3 .dump) are replayed into a new
+// database file.
+type SQLiteRestorer struct {
+	config          *config.Config
+	verbose         bool
+	logSQL          bool
+	mode            Mode
+	path            string
+	db              *sql.DB
+	restoreDuration time.Duration
+}
+
+// NewSQLiteRestorer creates a new restorer instance.
+func NewSQLiteRestorer(cfg *config.Config, opts Options) *SQLiteRestorer {
+	return &SQLiteRestorer{
+		config:  cfg,
+		verbose: opts.Verbose,
+		logSQL:  opts.LogSQL,
+		mode:    opts.Mode,
+	}
+}
+
+// Restore copies the backup into a scratch database file and opens it.
+func (r *SQLiteRestorer) Restore(ctx context.Context, backupStream io.Reader) error {
+	if r.mode != ModeFull {
+		return fmt.Errorf("%s mode is not supported for database type: sqlite", r.mode)
+	}
+
+	restoreStart := time.Now()
+	buffered := bufio.NewReader(backupStream)
+	header, _ := buffered.Peek(len(sqliteHeader))
+	isDatabaseFile := bytes.Equal(header, sqliteHeader)
+
+	tmpDir := r.config.CLI.TempDir
+	if tmpDir != "" {
+		if err := os.MkdirAll(tmpDir, 0700); err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+	}
+	tmpFile, err := os.CreateTemp(tmpDir, "restorable-sqlite-*.db")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary database file: %w", err)
+	}
+	r.path = tmpFile.Name()
+
+	var dump []byte
+	if isDatabaseFile {
+		_, err = io.Copy(tmpFile, buffered)
+	} else {
+		dump, err = io.ReadAll(buffered)
+	}
+	tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write backup to temporary file: %w", err)
+	}
+
+	r.db, err = openDB("sqlite", r.path, r.logSQL)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if isDatabaseFile {
+		// Force SQLite to read the header so a truncated or foreign file fails here
+		if _, err := r.db.ExecContext(ctx, "SELECT count(*) FROM sqlite_master"); err != nil {
+			return fmt.Errorf("backup is not a readable SQLite database: %w", err)
+		}
+		fmt.Println("✓ SQLite database file copied.")
+	} else {
+		fmt.Println("Replaying SQL dump into a new SQLite database...")
+		if _, err := r.db.ExecContext(ctx, string(dump)); err != nil {
+			return fmt.Errorf("failed to replay SQL dump: %w", err)
+		}
+		fmt.Println("✓ SQL dump replayed.")
+	}
+	r.restoreDuration = time.Since(restoreStart)
+
+	return nil
+}
+
+// ExtractSchema extracts the tables of the main database.
+func (r *SQLiteRestorer) ExtractSchema(ctx context.Context) (*schema.Schema, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	names, err := r.tableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []schema.Table
+	for _, name := range names {
+		columns, err := r.getTableColumns(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, schema.Table{
+			Schema:      "main",
+			Name:        name,
+			ColumnCount: len(columns),
+			Columns:     columns,
+		})
+	}
+
+	indexes, err := r.getIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachIndexes(tables, indexes)
+
+	constraints, err := r.getConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachConstraints(tables, constraints)
+
+	triggers, err := r.getTriggers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attachTriggers(tables, triggers)
+
+	views, err := r.getViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.Schema{
+		Version:   "1",
+		Timestamp: time.Now().UTC(),
+		Tables:    tables,
+		Views:     views,
+	}, nil
+}
+
+func (r *SQLiteRestorer) tableNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table row: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (r *SQLiteRestorer) getTableColumns(ctx context.Context, tableName string) ([]schema.Column, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, type, "notnull" FROM pragma_table_info(?) ORDER BY cid`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns for %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var columns []schema.Column
+	for rows.Next() {
+		var c schema.Column
+		var notNull bool
+		if err := rows.Scan(&c.Name, &c.DataType, &notNull); err != nil {
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+		c.Nullable = !notNull
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// ExtractMetrics extracts the file size and exact row counts.
+func (r *SQLiteRestorer) ExtractMetrics(ctx context.Context) (*schema.Metrics, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	metrics := &schema.Metrics{
+		Timestamp:       time.Now().UTC(),
+		RestoreDuration: r.restoreDuration,
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+	metrics.DBSizeBytes = info.Size()
+
+	names, err := r.tableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		tm := schema.TableMetrics{Schema: "main", Name: name}
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "main".%s`, quoteSQLiteIdent(name))
+		if err := r.db.QueryRowContext(ctx, query).Scan(&tm.RowCount); err != nil {
+			return nil, fmt.Errorf("failed to count rows in %s: %w", name, err)
+		}
+		metrics.TableMetrics = append(metrics.TableMetrics, tm)
+	}
+
+	return metrics, nil
+}
+
+// CheckIntegrity runs PRAGMA integrity_check and PRAGMA foreign_key_check.
+func (r *SQLiteRestorer) CheckIntegrity(ctx context.Context) (*IntegrityResult, error) {
+	if r.db == nil {
+		return nil, fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	result := &IntegrityResult{}
+
+	rows, err := r.db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity_check: %w", err)
+	}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan integrity_check row: %w", err)
+		}
+		// A healthy database returns a single "ok" row
+		if line != "ok" {
+			result.Problems = append(result.Problems, line)
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity_check: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run foreign_key_check: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign_key_check row: %w", err)
+		}
+		violation := fmt.Sprintf("%s references missing row in %s", table, parent)
+		if rowid.Valid {
+			violation = fmt.Sprintf("%s rowid %d references missing row in %s", table, rowid.Int64, parent)
+		}
+		result.ForeignKeyViolations = append(result.ForeignKeyViolations, violation)
+	}
+
+	return result, rows.Err()
+}
+
+// RunQuery runs a custom SQL check against the restored database.
+func (r *SQLiteRestorer) RunQuery(ctx context.Context, query string) (int, *string, error) {
+	return runQuery(ctx, r.db, query)
+}
+
+// Cleanup closes and removes the scratch database file.
+func (r *SQLiteRestorer) Cleanup(ctx context.Context) error {
+	if r.db != nil {
+		r.db.Close()
+		r.db = nil
+	}
+	if r.path != "" {
+		for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+			if err := os.Remove(r.path + suffix); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove temporary database: %w", err)
+			}
+		}
+		r.path = ""
+	}
+	return nil
+}
+
+// quoteSQLiteIdent quotes an identifier with double quotes.
+func quoteSQLiteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,232 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// openDB opens a database handle. With logSQL, every statement run through it is
+// printed with its duration and row count, to find what makes a verification slow.
+func openDB(driverName, dsn string, logSQL bool) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil || !logSQL {
+		return db, err
+	}
+	d := db.Driver()
+	db.Close()
+
+	var connector driver.Connector = dsnConnector{driver: d, dsn: dsn}
+	if dc, ok := d.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(&loggedConnector{Connector: connector}), nil
+}
+
+// logStatement prints a statement with its duration. A statement that failed is
+// marked with its error.
+func logStatement(query string, elapsed time.Duration, rows int, err error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch {
+	case err != nil && err != io.EOF:
+		fmt.Printf("  [sql %s] %s (error: %v)\n", elapsed.Round(time.Microsecond), query, err)
+	case rows >= 0:
+		fmt.Printf("  [sql %s] %s (%d rows)\n", elapsed.Round(time.Microsecond), query, rows)
+	default:
+		fmt.Printf("  [sql %s] %s\n", elapsed.Round(time.Microsecond), query)
+	}
+}
+
+// dsnConnector is a connector for drivers that only implement driver.Driver.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// loggedConnector hands out connections that log their statements.
+type loggedConnector struct {
+	driver.Connector
+}
+
+func (c *loggedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedConn{Conn: conn}, nil
+}
+
+// loggedConn logs the statements run on a driver connection. Optional driver
+// interfaces are passed through, returning driver.ErrSkip where the wrapped
+// connection lacks them so database/sql falls back as it would without logging.
+type loggedConn struct {
+	driver.Conn
+}
+
+func (c *loggedConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedStmt{Stmt: stmt, query: query}, nil
+}
+
+func (c *loggedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	pc, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+	stmt, err := pc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &loggedStmt{Stmt: stmt, query: query}, nil
+}
+
+func (c *loggedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		if err != driver.ErrSkip {
+			logStatement(query, time.Since(start), -1, err)
+		}
+		return nil, err
+	}
+	return &loggedRows{Rows: rows, query: query, start: start}, nil
+}
+
+func (c *loggedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := ec.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		logStatement(query, time.Since(start), -1, err)
+	}
+	return result, err
+}
+
+func (c *loggedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *loggedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *loggedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// loggedStmt logs each execution of a prepared statement.
+type loggedStmt struct {
+	driver.Stmt
+	query string
+}
+
+func (s *loggedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValues(args))
+	}
+	logStatement(s.query, time.Since(start), -1, err)
+	return result, err
+}
+
+func (s *loggedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	if err != nil {
+		logStatement(s.query, time.Since(start), -1, err)
+		return nil, err
+	}
+	return &loggedRows{Rows: rows, query: s.query, start: start}, nil
+}
+
+// namedValues converts arguments for drivers without context-aware statements.
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// loggedRows logs its query once the rows are closed, so the duration includes
+// reading every row rather than only the wait for the first.
+type loggedRows struct {
+	driver.Rows
+	query string
+	start time.Time
+	count int
+	err   error
+}
+
+func (r *loggedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	} else {
+		r.err = err
+	}
+	return err
+}
+
+func (r *loggedRows) Close() error {
+	err := r.Rows.Close()
+	logStatement(r.query, time.Since(r.start), r.count, r.err)
+	return err
+}
@@ -0,0 +1,101 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// attachTriggers sets the triggers of each table from triggers keyed by schema.table.
+func attachTriggers(tables []schema.Table, triggers map[string][]schema.Trigger) {
+	for i := range tables {
+		tables[i].Triggers = triggers[tables[i].Schema+"."+tables[i].Name]
+	}
+}
+
+// getTriggers records the user-defined triggers of every table and whether they
+// fire. pg_restore --disable-triggers re-enables triggers at the end of each
+// table's data, so an interrupted restore can leave them disabled.
+func (r *PostgresRestorer) getTriggers(ctx context.Context) (map[string][]schema.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.nspname, c.relname, t.tgname, pg_get_triggerdef(t.oid), t.tgenabled = 'D'
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE '\_timescaledb\_%'
+		ORDER BY n.nspname, c.relname, t.tgname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make(map[string][]schema.Trigger)
+	for rows.Next() {
+		var schemaName, table string
+		var t schema.Trigger
+		if err := rows.Scan(&schemaName, &table, &t.Name, &t.Definition, &t.Disabled); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger row: %w", err)
+		}
+		key := schemaName + "." + table
+		triggers[key] = append(triggers[key], t)
+	}
+	return triggers, rows.Err()
+}
+
+// getTriggers records the triggers of every table in the current database, e.g.
+// BEFORE INSERT.
+func (r *MariaDBRestorer) getTriggers(ctx context.Context) (map[string][]schema.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_object_schema, event_object_table, trigger_name,
+			CONCAT(action_timing, ' ', event_manipulation)
+		FROM information_schema.triggers
+		WHERE event_object_schema = DATABASE()
+		ORDER BY event_object_table, trigger_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make(map[string][]schema.Trigger)
+	for rows.Next() {
+		var schemaName, table string
+		var t schema.Trigger
+		if err := rows.Scan(&schemaName, &table, &t.Name, &t.Definition); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger row: %w", err)
+		}
+		key := schemaName + "." + table
+		triggers[key] = append(triggers[key], t)
+	}
+	return triggers, rows.Err()
+}
+
+// getTriggers records the CREATE TRIGGER statement of every trigger.
+func (r *SQLiteRestorer) getTriggers(ctx context.Context) (map[string][]schema.Trigger, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tbl_name, name, COALESCE(sql, '')
+		FROM sqlite_master
+		WHERE type = 'trigger'
+		ORDER BY tbl_name, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make(map[string][]schema.Trigger)
+	for rows.Next() {
+		var table string
+		var t schema.Trigger
+		if err := rows.Scan(&table, &t.Name, &t.Definition); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger row: %w", err)
+		}
+		key := "main." + table
+		triggers[key] = append(triggers[key], t)
+	}
+	return triggers, rows.Err()
+}
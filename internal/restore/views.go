@@ -0,0 +1,153 @@
+package restore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ViewRefresher is implemented by restorers that can refresh materialized views.
+type ViewRefresher interface {
+	// RefreshMaterializedViews refreshes every materialized view in s, recording
+	// its row count, or the error when the refresh fails.
+	RefreshMaterializedViews(ctx context.Context, s *schema.Schema) error
+}
+
+// probeViews selects no rows from each view, recording the error of those that
+// cannot be queried, e.g. because a table or function they use is missing.
+// Unpopulated materialized views are left alone.
+func probeViews(ctx context.Context, db *sql.DB, views []schema.View, quote func(schemaName, name string) string) {
+	for i := range views {
+		v := &views[i]
+		if v.Materialized && !v.Populated {
+			continue
+		}
+		rows, err := db.QueryContext(ctx, "SELECT * FROM "+quote(v.Schema, v.Name)+" LIMIT 0")
+		if err != nil {
+			v.Error = err.Error()
+			continue
+		}
+		rows.Close()
+	}
+}
+
+// getViews lists views and materialized views and probes them.
+func (r *PostgresRestorer) getViews(ctx context.Context) ([]schema.View, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT schemaname, viewname, false, false FROM pg_views
+		WHERE schemaname NOT IN ('information_schema', 'pg_catalog')
+		  AND schemaname NOT LIKE '\_timescaledb\_%'
+		UNION ALL
+		SELECT schemaname, matviewname, true, ispopulated FROM pg_matviews
+		WHERE schemaname NOT LIKE '\_timescaledb\_%'
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []schema.View
+	for rows.Next() {
+		var v schema.View
+		if err := rows.Scan(&v.Schema, &v.Name, &v.Materialized, &v.Populated); err != nil {
+			return nil, fmt.Errorf("failed to scan view row: %w", err)
+		}
+		views = append(views, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating view rows: %w", err)
+	}
+	rows.Close()
+
+	probeViews(ctx, r.db, views, quotePostgresName)
+	return views, nil
+}
+
+// RefreshMaterializedViews refreshes each materialized view in s and counts its rows.
+func (r *PostgresRestorer) RefreshMaterializedViews(ctx context.Context, s *schema.Schema) error {
+	if r.db == nil {
+		return fmt.Errorf("database connection not established; call Restore first")
+	}
+
+	for i := range s.Views {
+		v := &s.Views[i]
+		if !v.Materialized {
+			continue
+		}
+		name := quotePostgresName(v.Schema, v.Name)
+		if _, err := r.db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW "+name); err != nil {
+			v.Error = fmt.Sprintf("refresh failed: %v", err)
+			continue
+		}
+		var count int64
+		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count rows of %s.%s: %w", v.Schema, v.Name, err)
+		}
+		v.Populated, v.Error, v.RowCount = true, "", &count
+	}
+	return nil
+}
+
+// getViews lists the views of the current database and probes them. MariaDB
+// keeps a view whose tables were dropped, failing only when it is queried.
+func (r *MariaDBRestorer) getViews(ctx context.Context) ([]schema.View, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT table_schema, table_name
+		FROM information_schema.views
+		WHERE table_schema = DATABASE()
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []schema.View
+	for rows.Next() {
+		var v schema.View
+		if err := rows.Scan(&v.Schema, &v.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan view row: %w", err)
+		}
+		views = append(views, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating view rows: %w", err)
+	}
+	rows.Close()
+
+	probeViews(ctx, r.db, views, func(schemaName, name string) string {
+		return quoteMariaDBIdent(schemaName) + "." + quoteMariaDBIdent(name)
+	})
+	return views, nil
+}
+
+// getViews lists the views of the database and probes them. SQLite only resolves
+// the tables a view selects from when it is queried.
+func (r *SQLiteRestorer) getViews(ctx context.Context) ([]schema.View, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'view' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []schema.View
+	for rows.Next() {
+		v := schema.View{Schema: "main"}
+		if err := rows.Scan(&v.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan view row: %w", err)
+		}
+		views = append(views, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating view rows: %w", err)
+	}
+	rows.Close()
+
+	probeViews(ctx, r.db, views, func(schemaName, name string) string {
+		return quoteSQLiteIdent(schemaName) + "." + quoteSQLiteIdent(name)
+	})
+	return views, nil
+}
@@ -1,8 +1,10 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -10,9 +12,119 @@ import (
 
 // Schema represents the database schema structure.
 type Schema struct {
-	Version   string    `json:"version"`
-	Timestamp time.Time `json:"timestamp"`
-	Tables    []Table   `json:"tables"`
+	Version           string             `json:"version"`
+	Timestamp         time.Time          `json:"timestamp"`
+	Tables            []Table            `json:"tables"`
+	Extensions        []Extension        `json:"extensions,omitempty"`
+	DistributedTables []DistributedTable `json:"distributed_tables,omitempty"`
+	SpatialColumns    []SpatialColumn    `json:"spatial_columns,omitempty"`
+	Privileges        []ObjectPrivileges `json:"privileges,omitempty"`
+	Views             []View             `json:"views,omitempty"`
+	Sequences         []Sequence         `json:"sequences,omitempty"`
+	Routines          []Routine          `json:"routines,omitempty"`
+}
+
+// Extension represents an installed database extension.
+type Extension struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Kinds of distributed tables.
+const (
+	KindHypertable  = "hypertable"  // TimescaleDB hypertable, split into chunks
+	KindDistributed = "distributed" // Citus distributed table, split into shards
+)
+
+// DistributedTable represents a table split into chunks or shards by an extension.
+type DistributedTable struct {
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+	Kind      string `json:"kind"`
+	PartCount int    `json:"part_count"`
+}
+
+// SpatialColumn represents a PostGIS geometry or geography column.
+type SpatialColumn struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	// Type is the geometry type, e.g. POINT, prefixed with "geography:" for geography columns.
+	Type       string `json:"type"`
+	SRID       int    `json:"srid"`
+	Dimensions int    `json:"dimensions"`
+	// InvalidSample counts invalid values among a sample of the column's rows.
+	InvalidSample int `json:"invalid_sample,omitempty"`
+}
+
+// QualifiedName returns schema.table.column.
+func (c SpatialColumn) QualifiedName() string {
+	return fmt.Sprintf("%s.%s.%s", c.Schema, c.Table, c.Column)
+}
+
+// ObjectPrivileges records the owner and explicit grants of a table or sequence.
+type ObjectPrivileges struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Owner  string `json:"owner"`
+	// Grants are sorted "grantee:PRIVILEGE" entries, with PUBLIC as grantee for public grants.
+	Grants []string `json:"grants,omitempty"`
+}
+
+// View represents a view or materialized view.
+type View struct {
+	Schema       string `json:"schema"`
+	Name         string `json:"name"`
+	Materialized bool   `json:"materialized,omitempty"`
+	// Populated reports whether a materialized view holds data. Unpopulated ones,
+	// e.g. created WITH NO DATA, cannot be queried until refreshed.
+	Populated bool `json:"populated,omitempty"`
+	// Error is why the view could not be queried or refreshed after restore.
+	Error string `json:"error,omitempty"`
+	// RowCount is the number of rows of a materialized view after a refresh.
+	RowCount *int64 `json:"row_count,omitempty"`
+}
+
+// QualifiedName returns schema.view.
+func (v View) QualifiedName() string {
+	return fmt.Sprintf("%s.%s", v.Schema, v.Name)
+}
+
+// Sequence represents a sequence and the column it generates values for, if any.
+type Sequence struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	// LastValue is the value last returned, or nil if the sequence was never used.
+	LastValue *int64 `json:"last_value,omitempty"`
+	Increment int64  `json:"increment"`
+	// OwnerSchema, OwnerTable and OwnerColumn name the serial or identity column
+	// that owns the sequence.
+	OwnerSchema string `json:"owner_schema,omitempty"`
+	OwnerTable  string `json:"owner_table,omitempty"`
+	OwnerColumn string `json:"owner_column,omitempty"`
+	// OwnerMax is the largest value in the owning column, or nil if it has no rows.
+	OwnerMax *int64 `json:"owner_max,omitempty"`
+}
+
+// QualifiedName returns schema.sequence.
+func (s Sequence) QualifiedName() string {
+	return fmt.Sprintf("%s.%s", s.Schema, s.Name)
+}
+
+// Routine represents a stored function or procedure.
+type Routine struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	// Type is FUNCTION or PROCEDURE.
+	Type string `json:"type"`
+	// Arguments lists the argument types, which tell overloads apart.
+	Arguments string `json:"arguments"`
+}
+
+// Signature returns schema.name(arguments).
+func (r Routine) Signature() string {
+	return fmt.Sprintf("%s.%s(%s)", r.Schema, r.Name, r.Arguments)
 }
 
 // Table represents a database table's metadata.
@@ -21,6 +133,14 @@ type Table struct {
 	Schema      string   `json:"schema"`
 	ColumnCount int      `json:"column_count"`
 	Columns     []Column `json:"columns,omitempty"`
+	Indexes     []Index  `json:"indexes,omitempty"`
+	// Constraints are the table's primary key, unique and check constraints.
+	Constraints []Constraint `json:"constraints,omitempty"`
+	Triggers    []Trigger    `json:"triggers,omitempty"`
+	// Checksum is an order-independent hash of the table contents, if computed.
+	Checksum string `json:"checksum,omitempty"`
+	// MappingHash is a hash of a search index's field mapping.
+	MappingHash string `json:"mapping_hash,omitempty"`
 }
 
 // Column represents a database column's metadata.
@@ -30,12 +150,108 @@ type Column struct {
 	Nullable bool   `json:"nullable"`
 }
 
+// Index represents an index on a table or collection.
+type Index struct {
+	Name string `json:"name"`
+	// Definition is the database's own description of the index, e.g. its key spec.
+	Definition string `json:"definition"`
+	Unique     bool   `json:"unique,omitempty"`
+	// Invalid marks an index whose build failed, e.g. one left behind by a
+	// failed CREATE INDEX CONCURRENTLY, which queries cannot use.
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// Types of table constraints.
+const (
+	ConstraintPrimaryKey = "PRIMARY KEY"
+	ConstraintUnique     = "UNIQUE"
+	ConstraintCheck      = "CHECK"
+)
+
+// Constraint represents a primary key, unique or check constraint.
+type Constraint struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Definition is the constraint as the database describes it, e.g. PRIMARY KEY (id).
+	Definition string `json:"definition"`
+	// Columns are the key columns of a primary key or unique constraint.
+	Columns []string `json:"columns,omitempty"`
+}
+
+// Trigger represents a trigger on a table.
+type Trigger struct {
+	Name string `json:"name"`
+	// Definition is the database's own description of the trigger, e.g. its CREATE TRIGGER statement.
+	Definition string `json:"definition"`
+	// Disabled marks a trigger that exists but does not fire.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
 // Metrics represents database metrics collected after restore.
 type Metrics struct {
-	Timestamp       time.Time       `json:"timestamp"`
-	RestoreDuration time.Duration   `json:"restore_duration_ns"`
-	DBSizeBytes     int64           `json:"db_size_bytes"`
-	TableMetrics    []TableMetrics  `json:"table_metrics"`
+	Timestamp       time.Time      `json:"timestamp"`
+	RestoreDuration time.Duration  `json:"restore_duration_ns"`
+	DBSizeBytes     int64          `json:"db_size_bytes"`
+	TableMetrics    []TableMetrics `json:"table_metrics"`
+	// ColumnProfiles holds the profiles of the configured columns.
+	ColumnProfiles []ColumnProfile `json:"column_profiles,omitempty"`
+	// SampleChecksums holds the hashes of the configured row samples.
+	SampleChecksums []SampleChecksum `json:"sample_checksums,omitempty"`
+	// ColumnNulls holds the NULL counts of the configured critical columns.
+	ColumnNulls []ColumnNulls `json:"column_nulls,omitempty"`
+}
+
+// ColumnNulls counts the NULL values of a column.
+type ColumnNulls struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Rows   int64  `json:"rows"`
+	Nulls  int64  `json:"nulls"`
+}
+
+// QualifiedName returns schema.table.column, or table.column without a schema.
+func (n ColumnNulls) QualifiedName() string {
+	if n.Schema == "" {
+		return fmt.Sprintf("%s.%s", n.Table, n.Column)
+	}
+	return fmt.Sprintf("%s.%s.%s", n.Schema, n.Table, n.Column)
+}
+
+// Ratio returns the fraction of rows where the column is NULL.
+func (n ColumnNulls) Ratio() float64 {
+	if n.Rows == 0 {
+		return 0
+	}
+	return float64(n.Nulls) / float64(n.Rows)
+}
+
+// SampleChecksum is an order-independent hash of a sample of a table's rows.
+type SampleChecksum struct {
+	// Table is the table as configured.
+	Table string `json:"table"`
+	// Sample describes the rows selected, e.g. ORDER BY id LIMIT 1000.
+	Sample   string `json:"sample"`
+	Rows     int64  `json:"rows"`
+	Checksum string `json:"checksum"`
+}
+
+// ColumnProfile summarizes the values of a single column.
+type ColumnProfile struct {
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	// DistinctEstimate is the planner's estimate of distinct non-null values.
+	DistinctEstimate int64 `json:"distinct_estimate"`
+	// NullRate is the fraction of rows where the column is NULL.
+	NullRate float64 `json:"null_rate"`
+	// AvgSizeBytes is the average size of non-null values in their text form.
+	AvgSizeBytes float64 `json:"avg_size_bytes"`
+}
+
+// QualifiedName returns schema.table.column.
+func (p ColumnProfile) QualifiedName() string {
+	return fmt.Sprintf("%s.%s.%s", p.Schema, p.Table, p.Column)
 }
 
 // TableMetrics represents metrics for a single table.
@@ -43,6 +259,10 @@ type TableMetrics struct {
 	Name     string `json:"name"`
 	Schema   string `json:"schema"`
 	RowCount int64  `json:"row_count"`
+	// SizeBytes is the total on-disk size including indexes and TOAST.
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+	// Checksum is the table's content hash, if checksums are enabled.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // TableNames returns a list of fully qualified table names (schema.table).
@@ -54,9 +274,19 @@ func (s *Schema) TableNames() []string {
 	return names
 }
 
+// Cipher encrypts baseline files at rest.
+type Cipher interface {
+	Encrypt(dst io.Writer) (io.WriteCloser, error)
+	Decrypt(src io.Reader) (io.Reader, error)
+}
+
+// encryptedSuffix is appended to the file name of encrypted baselines.
+const encryptedSuffix = ".age"
+
 // BaselineStore handles persisting and loading baseline schemas.
 type BaselineStore struct {
 	basePath string
+	cipher   Cipher
 }
 
 // NewBaselineStore creates a store for baseline schemas.
@@ -72,29 +302,63 @@ func NewBaselineStore() (*BaselineStore, error) {
 	return &BaselineStore{basePath: basePath}, nil
 }
 
+// WithCipher encrypts baselines written from now on and allows reading encrypted ones.
+// Existing plaintext baselines are still read, and replaced on their next save.
+func (s *BaselineStore) WithCipher(c Cipher) *BaselineStore {
+	s.cipher = c
+	return s
+}
+
+// Dir returns the directory holding the baseline files.
+func (s *BaselineStore) Dir() string {
+	return s.basePath
+}
+
+func (s *BaselineStore) path(projectID string) string {
+	return filepath.Join(s.basePath, projectID+".json")
+}
+
 // Save persists a schema as the baseline for a project.
 func (s *BaselineStore) Save(projectID string, schema *Schema) error {
-	path := filepath.Join(s.basePath, projectID+".json")
 	data, err := json.MarshalIndent(schema, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal schema: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0644); err != nil {
+
+	if s.cipher == nil {
+		if err := os.WriteFile(s.path(projectID), data, 0644); err != nil {
+			return fmt.Errorf("failed to write schema file: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w, err := s.cipher.Encrypt(&buf)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt schema: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to encrypt schema: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt schema: %w", err)
+	}
+	if err := os.WriteFile(s.path(projectID)+encryptedSuffix, buf.Bytes(), 0600); err != nil {
 		return fmt.Errorf("failed to write schema file: %w", err)
 	}
+	// Drop the plaintext copy left from before encryption was enabled
+	if err := os.Remove(s.path(projectID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove plaintext schema file: %w", err)
+	}
 	return nil
 }
 
-// Load retrieves the baseline schema for a project.
+// Load retrieves the baseline schema for a project, decrypting it if needed.
 // Returns nil, nil if no baseline exists.
 func (s *BaselineStore) Load(projectID string) (*Schema, error) {
-	path := filepath.Join(s.basePath, projectID+".json")
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	data, err := s.read(projectID)
+	if err != nil || data == nil {
+		return nil, err
 	}
 
 	var schema Schema
@@ -104,9 +368,44 @@ func (s *BaselineStore) Load(projectID string) (*Schema, error) {
 	return &schema, nil
 }
 
+// read returns the baseline JSON, preferring the encrypted file.
+func (s *BaselineStore) read(projectID string) ([]byte, error) {
+	encrypted, err := os.Open(s.path(projectID) + encryptedSuffix)
+	if err == nil {
+		defer encrypted.Close()
+		if s.cipher == nil {
+			return nil, fmt.Errorf("baseline for %s is encrypted; configure encryption.encrypt_baselines to read it", projectID)
+		}
+		r, err := s.cipher.Decrypt(encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt schema file: %w", err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt schema file: %w", err)
+		}
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	data, err := os.ReadFile(s.path(projectID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	return data, nil
+}
+
 // Exists checks if a baseline schema exists for a project.
 func (s *BaselineStore) Exists(projectID string) bool {
-	path := filepath.Join(s.basePath, projectID+".json")
-	_, err := os.Stat(path)
-	return err == nil
+	for _, path := range []string{s.path(projectID) + encryptedSuffix, s.path(projectID)} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,104 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	// DefaultEndpoint receives signed reports on restorable.io.
+	DefaultEndpoint = "https://api.restorable.io/v1/reports"
+	// DefaultTokenEnv holds the API token when upload.token_env is not set.
+	DefaultTokenEnv = "RESTORABLE_API_TOKEN"
+)
+
+// Client submits signed reports to the restorable.io API.
+type Client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+// NewClient creates a client from the upload configuration.
+func NewClient(cfg *config.Upload) (*Client, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = DefaultTokenEnv
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("API token not found: set %s", tokenEnv)
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		token:    token,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Submit uploads the signed report JSON. Reports are sent byte-for-byte as written
+// so the server can verify the signature. A report the server already holds is
+// treated as submitted.
+func (c *Client) Submit(ctx context.Context, data []byte) error {
+	var ids struct {
+		ID    string `json:"id"`
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", IdempotencyKey(ids.RunID, ids.ID))
+	if ids.RunID != "" {
+		req.Header.Set("X-Restorable-Run-ID", ids.RunID)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("upload rejected: %s: %s", resp.Status, bytes.TrimSpace(body))
+}
+
+// IdempotencyKey identifies a delivery of a report, so retries of the same run
+// never double-post. A run can produce several reports, one per logical database.
+func IdempotencyKey(runID, reportID string) string {
+	if runID == "" {
+		return reportID
+	}
+	return runID + ":" + reportID
+}
+
+// SubmitFile uploads the report stored at path.
+func (c *Client) SubmitFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	return c.Submit(ctx, data)
+}
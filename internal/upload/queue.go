@@ -0,0 +1,73 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Queue holds copies of signed reports that could not be submitted, so no
+// evidence is lost while the upload endpoint is unreachable.
+type Queue struct {
+	basePath string
+}
+
+// NewQueue creates a queue under ~/.restorable/queue/reports.
+func NewQueue() (*Queue, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not get user home directory: %w", err)
+	}
+	basePath := filepath.Join(homeDir, ".restorable", "queue", "reports")
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload queue directory: %w", err)
+	}
+	return &Queue{basePath: basePath}, nil
+}
+
+// Enqueue stores a copy of the report at reportPath. The copy is independent of
+// the report directory, so pruning reports does not drop pending uploads.
+func (q *Queue) Enqueue(reportPath string) error {
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report: %w", err)
+	}
+	dest := filepath.Join(q.basePath, filepath.Base(reportPath))
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("failed to queue report: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the paths of queued reports, oldest first.
+func (q *Queue) Pending() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(q.basePath, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload queue: %w", err)
+	}
+	// Report file names start with their timestamp.
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Flush submits every queued report, removing each one once it is accepted.
+// It stops at the first failure, leaving the remaining reports queued, and
+// returns the number of reports submitted.
+func (q *Queue) Flush(ctx context.Context, client *Client) (int, error) {
+	paths, err := q.Pending()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, path := range paths {
+		if err := client.SubmitFile(ctx, path); err != nil {
+			return i, fmt.Errorf("failed to submit %s: %w", filepath.Base(path), err)
+		}
+		if err := os.Remove(path); err != nil {
+			return i + 1, fmt.Errorf("failed to remove %s from queue: %w", filepath.Base(path), err)
+		}
+	}
+	return len(paths), nil
+}
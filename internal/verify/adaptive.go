@@ -0,0 +1,166 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// minAdaptiveDropPercent ignores drops too small to matter, so a project whose
+// tables never shrank does not alert on the first deleted row.
+const minAdaptiveDropPercent = 1.0
+
+// Percentile returns the p-th percentile (0-100) of values by nearest rank.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// AdaptiveRowCountChecker flags tables whose row count dropped, since the previous
+// run, by more than the given percentile of the drops seen between earlier runs.
+type AdaptiveRowCountChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History    []*schema.Metrics
+	Percentile float64
+	// MinRuns is the number of previous runs needed before thresholds apply.
+	MinRuns int
+}
+
+func NewAdaptiveRowCountChecker(history []*schema.Metrics, percentile float64, minRuns int) *AdaptiveRowCountChecker {
+	return &AdaptiveRowCountChecker{History: history, Percentile: percentile, MinRuns: minRuns}
+}
+
+func (c *AdaptiveRowCountChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "row_counts",
+		Level: LevelWarning,
+	}
+
+	if metrics == nil {
+		result.Passed = true
+		result.Message = "No metrics available"
+		return result
+	}
+	if len(c.History) < c.MinRuns || len(c.History) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Collecting history for adaptive thresholds (%d/%d runs)", len(c.History), c.MinRuns)
+		return result
+	}
+
+	// Per-table drops between consecutive earlier runs
+	drops := make(map[string][]float64)
+	for i := 1; i < len(c.History); i++ {
+		previous := rowCounts(c.History[i-1])
+		for name, count := range rowCounts(c.History[i]) {
+			if prev, ok := previous[name]; ok {
+				drops[name] = append(drops[name], dropPercent(prev, count))
+			}
+		}
+	}
+
+	last := rowCounts(c.History[len(c.History)-1])
+	var anomalies []string
+	for _, tm := range metrics.TableMetrics {
+		name := fmt.Sprintf("%s.%s", tm.Schema, tm.Name)
+		prev, ok := last[name]
+		if !ok {
+			continue
+		}
+		drop := dropPercent(prev, tm.RowCount)
+		threshold := math.Max(Percentile(drops[name], c.Percentile), minAdaptiveDropPercent)
+		if drop > threshold {
+			anomalies = append(anomalies, fmt.Sprintf("%s dropped %.1f%% (p%g: %.1f%%)", name, drop, c.Percentile, threshold))
+		}
+	}
+
+	if len(anomalies) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d tables dropped beyond their usual range: %s", len(anomalies), strings.Join(anomalies, ", "))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Row counts within p%g of the last %d runs", c.Percentile, len(c.History))
+	return result
+}
+
+// AdaptiveDurationChecker flags restores slower than the given percentile of
+// previous restore durations.
+type AdaptiveDurationChecker struct {
+	History    []*schema.Metrics
+	Percentile float64
+	MinRuns    int
+}
+
+func NewAdaptiveDurationChecker(history []*schema.Metrics, percentile float64, minRuns int) *AdaptiveDurationChecker {
+	return &AdaptiveDurationChecker{History: history, Percentile: percentile, MinRuns: minRuns}
+}
+
+func (c *AdaptiveDurationChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "restore_duration",
+		Level: LevelInfo,
+	}
+
+	if metrics == nil {
+		result.Passed = true
+		result.Message = "No metrics available"
+		return result
+	}
+
+	durationSecs := int(metrics.RestoreDuration.Seconds())
+	if len(c.History) < c.MinRuns || len(c.History) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Restore completed in %d seconds (collecting history: %d/%d runs)", durationSecs, len(c.History), c.MinRuns)
+		return result
+	}
+
+	durations := make([]float64, len(c.History))
+	for i, m := range c.History {
+		durations[i] = m.RestoreDuration.Seconds()
+	}
+	threshold := time.Duration(Percentile(durations, c.Percentile) * float64(time.Second))
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Restore completed in %d seconds (p%g: %d seconds)", durationSecs, c.Percentile, int(threshold.Seconds()))
+	if metrics.RestoreDuration > threshold {
+		result.Level = LevelWarning
+		result.Passed = false
+		result.Message = fmt.Sprintf("Restore took %d seconds, beyond p%g of the last %d runs (%d seconds)",
+			durationSecs, c.Percentile, len(c.History), int(threshold.Seconds()))
+	}
+	return result
+}
+
+// rowCounts indexes the row counts of a run by schema.table.
+func rowCounts(m *schema.Metrics) map[string]int64 {
+	counts := make(map[string]int64, len(m.TableMetrics))
+	for _, tm := range m.TableMetrics {
+		counts[fmt.Sprintf("%s.%s", tm.Schema, tm.Name)] = tm.RowCount
+	}
+	return counts
+}
+
+// dropPercent returns how far current fell below previous, or 0 if it did not.
+func dropPercent(previous, current int64) float64 {
+	if previous <= 0 || current >= previous {
+		return 0
+	}
+	return float64(previous-current) / float64(previous) * 100
+}
@@ -0,0 +1,105 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of planned table changes.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+)
+
+// Annotation declares a planned table change, so a verification that runs while a
+// migration is rolling out does not fail on drift that is expected.
+type Annotation struct {
+	// Table is a table name, optionally schema-qualified.
+	Table  string `yaml:"table"`
+	Change string `yaml:"change"`
+	// Release is the release that ships the change, shown in check messages.
+	Release string `yaml:"release,omitempty"`
+	// Until ends the annotation, so a forgotten one does not hide drift forever.
+	Until *time.Time `yaml:"until,omitempty"`
+	Note  string     `yaml:"note,omitempty"`
+}
+
+// describe returns the table with the release that changes it.
+func (a *Annotation) describe(table string) string {
+	if a.Release == "" {
+		return table
+	}
+	return fmt.Sprintf("%s (release %s)", table, a.Release)
+}
+
+// Annotations is the set of planned table changes read from an annotations file.
+// A nil *Annotations expects no changes.
+type Annotations struct {
+	entries []Annotation
+}
+
+// annotationsFile matches the structure of an annotations file.
+type annotationsFile struct {
+	Annotations []Annotation `yaml:"annotations"`
+}
+
+// LoadAnnotations reads the annotations file at path. Annotations past their Until
+// date are dropped.
+func LoadAnnotations(path string, now time.Time) (*Annotations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations file: %w", err)
+	}
+	var file annotationsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse annotations file %s: %w", path, err)
+	}
+
+	a := &Annotations{}
+	for i, e := range file.Annotations {
+		if e.Table == "" {
+			return nil, fmt.Errorf("annotation %d in %s has no table", i+1, path)
+		}
+		if e.Change != ChangeAdded && e.Change != ChangeRemoved {
+			return nil, fmt.Errorf("annotation for %s in %s: unsupported change %q (use %s or %s)",
+				e.Table, path, e.Change, ChangeAdded, ChangeRemoved)
+		}
+		if e.Until != nil && now.After(*e.Until) {
+			continue
+		}
+		a.entries = append(a.entries, e)
+	}
+	return a, nil
+}
+
+// Expected returns the annotation declaring change for the schema-qualified table,
+// or nil if the change is not planned.
+func (a *Annotations) Expected(table, change string) *Annotation {
+	if a == nil {
+		return nil
+	}
+	_, name, _ := strings.Cut(table, ".")
+	for i := range a.entries {
+		e := &a.entries[i]
+		if e.Change == change && (e.Table == table || e.Table == name) {
+			return e
+		}
+	}
+	return nil
+}
+
+// plannedNote summarizes tables with planned changes for a check message.
+func plannedNote(change string, planned []string) string {
+	if len(planned) == 0 {
+		return ""
+	}
+	verb := "removal"
+	if change == ChangeAdded {
+		verb = "addition"
+	}
+	return fmt.Sprintf(" (%d planned %s(s): %s)", len(planned), verb, strings.Join(planned, ", "))
+}
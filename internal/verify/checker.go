@@ -2,6 +2,8 @@ package verify
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"restorable.io/restorable-cli/internal/schema"
 )
@@ -21,6 +23,12 @@ type CheckResult struct {
 	Level   Level  `json:"level"`
 	Passed  bool   `json:"passed"`
 	Message string `json:"message"`
+	// Skipped is set when the check did not run because a dependency failed.
+	Skipped bool `json:"skipped,omitempty"`
+	// TableDiffs details the column changes found by column_drift.
+	TableDiffs []TableDiff `json:"table_diffs,omitempty"`
+	// Duration is how long the check took, shown at verbosity -vv.
+	Duration time.Duration `json:"-"`
 }
 
 // Checker defines the interface for verification checks.
@@ -29,15 +37,72 @@ type Checker interface {
 	Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult
 }
 
+// DependentChecker is a checker that only runs when the named checks passed.
+type DependentChecker struct {
+	Checker
+	// Name is the result name of the wrapped check, used when it is skipped.
+	Name string
+	// DependsOn lists the names of checks that must have passed first.
+	DependsOn []string
+}
+
+// Requires wraps a checker so it is skipped when any of the named checks failed or
+// was skipped, keeping the root cause from being buried under cascading failures.
+// Dependencies must appear earlier in the checker list.
+func Requires(c Checker, name string, dependsOn ...string) *DependentChecker {
+	return &DependentChecker{Checker: c, Name: name, DependsOn: dependsOn}
+}
+
 // RunChecks executes a list of checkers and returns all results.
 func RunChecks(ctx context.Context, checkers []Checker, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) []CheckResult {
 	results := make([]CheckResult, 0, len(checkers))
+	byName := make(map[string]CheckResult, len(checkers))
 	for _, c := range checkers {
-		results = append(results, c.Check(ctx, current, baseline, metrics))
+		var result CheckResult
+		if dep, ok := c.(*DependentChecker); ok {
+			if failed := failedDependency(dep.DependsOn, byName); failed != "" {
+				result = CheckResult{
+					Name:    dep.Name,
+					Level:   LevelInfo,
+					Passed:  true,
+					Skipped: true,
+					Message: fmt.Sprintf("Skipped because %s did not pass", failed),
+				}
+			}
+		}
+		if !result.Skipped {
+			start := time.Now()
+			result = c.Check(ctx, current, baseline, metrics)
+			result.Duration = time.Since(start)
+		}
+		results = append(results, result)
+		byName[result.Name] = result
 	}
 	return results
 }
 
+// failedDependency returns the first dependency that failed or was skipped.
+func failedDependency(dependsOn []string, results map[string]CheckResult) string {
+	for _, name := range dependsOn {
+		if r, ok := results[name]; ok && (!r.Passed || r.Skipped) {
+			return name
+		}
+	}
+	return ""
+}
+
+// StatusSymbol returns the symbol used to display a result.
+func (r CheckResult) StatusSymbol() string {
+	switch {
+	case r.Skipped:
+		return "-"
+	case r.Passed:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
+
 // HasCriticalFailure returns true if any critical check failed.
 func HasCriticalFailure(results []CheckResult) bool {
 	for _, r := range results {
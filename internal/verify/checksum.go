@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// TableChecksumChecker reports tables whose content hash differs from the most
+// recent earlier run that hashed them.
+type TableChecksumChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History []*schema.Metrics
+}
+
+func NewTableChecksumChecker(history []*schema.Metrics) *TableChecksumChecker {
+	return &TableChecksumChecker{History: history}
+}
+
+func (c *TableChecksumChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "table_checksums",
+		Level: LevelWarning,
+	}
+
+	var hashed, compared int
+	var changed []string
+	for _, t := range current.Tables {
+		if t.Checksum == "" {
+			continue
+		}
+		hashed++
+		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
+		expected, ok := c.previous(t.Schema, t.Name)
+		if !ok {
+			continue
+		}
+		compared++
+		if t.Checksum != expected {
+			changed = append(changed, key)
+		}
+	}
+
+	if compared == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Hashed %d tables; no previous checksums to compare", hashed)
+		return result
+	}
+
+	if len(changed) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Content changed since the previous run in %d/%d tables: %s", len(changed), compared, strings.Join(changed, ", "))
+	} else {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Content of all %d compared tables matches the previous run", compared)
+	}
+
+	return result
+}
+
+// previous returns the latest earlier checksum of the table.
+func (c *TableChecksumChecker) previous(schemaName, name string) (string, bool) {
+	for i := len(c.History) - 1; i >= 0; i-- {
+		for _, tm := range c.History[i].TableMetrics {
+			if tm.Schema == schemaName && tm.Name == name && tm.Checksum != "" {
+				return tm.Checksum, true
+			}
+		}
+	}
+	return "", false
+}
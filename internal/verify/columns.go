@@ -0,0 +1,90 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ColumnProfileChecker compares column profiles with the most recent earlier run
+// that profiled the same columns. A shrinking average size or distinct count, or a
+// rising null rate, points at systemic truncation that row counts do not show.
+type ColumnProfileChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History []*schema.Metrics
+	// WarnThresholdPercent is the relative size or distinct count decrease, and the
+	// null rate increase in percentage points, that triggers a warning.
+	WarnThresholdPercent int
+}
+
+func NewColumnProfileChecker(history []*schema.Metrics, warnThreshold int) *ColumnProfileChecker {
+	return &ColumnProfileChecker{History: history, WarnThresholdPercent: warnThreshold}
+}
+
+func (c *ColumnProfileChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "column_profiles",
+		Level: LevelWarning,
+	}
+
+	if metrics == nil || len(metrics.ColumnProfiles) == 0 {
+		result.Passed = true
+		result.Message = "No column profiles collected"
+		return result
+	}
+
+	threshold := float64(c.WarnThresholdPercent)
+	var compared int
+	var anomalies []string
+	for _, p := range metrics.ColumnProfiles {
+		prev, ok := c.previous(p.QualifiedName())
+		if !ok {
+			continue
+		}
+		compared++
+
+		var changes []string
+		if prev.AvgSizeBytes > 0 && (prev.AvgSizeBytes-p.AvgSizeBytes)/prev.AvgSizeBytes*100 > threshold {
+			changes = append(changes, fmt.Sprintf("avg size %.0f → %.0f bytes", prev.AvgSizeBytes, p.AvgSizeBytes))
+		}
+		if drop := dropPercent(prev.DistinctEstimate, p.DistinctEstimate); drop > threshold {
+			changes = append(changes, fmt.Sprintf("distinct %d → %d", prev.DistinctEstimate, p.DistinctEstimate))
+		}
+		if rise := (p.NullRate - prev.NullRate) * 100; rise > threshold {
+			changes = append(changes, fmt.Sprintf("null rate %.1f%% → %.1f%%", prev.NullRate*100, p.NullRate*100))
+		}
+		if len(changes) > 0 {
+			anomalies = append(anomalies, fmt.Sprintf("%s (%s)", p.QualifiedName(), strings.Join(changes, ", ")))
+		}
+	}
+
+	if compared == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Profiled %d columns; no previous profiles to compare", len(metrics.ColumnProfiles))
+		return result
+	}
+
+	if len(anomalies) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d columns changed beyond %d%%: %s", len(anomalies), c.WarnThresholdPercent, strings.Join(anomalies, "; "))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Profiles of %d columns consistent with the previous run", compared)
+	return result
+}
+
+// previous returns the latest earlier profile of the named column.
+func (c *ColumnProfileChecker) previous(name string) (schema.ColumnProfile, bool) {
+	for i := len(c.History) - 1; i >= 0; i-- {
+		for _, p := range c.History[i].ColumnProfiles {
+			if p.QualifiedName() == name {
+				return p, true
+			}
+		}
+	}
+	return schema.ColumnProfile{}, false
+}
@@ -0,0 +1,83 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ConstraintsChecker compares the primary key, unique and check constraints of
+// each restored table against the baseline. A constraint whose data violates it
+// fails to restore while the rows load anyway; a missing primary key is critical
+// because replication, upserts and ORMs depend on it, other drift is a warning.
+type ConstraintsChecker struct{}
+
+func NewConstraintsChecker() *ConstraintsChecker {
+	return &ConstraintsChecker{}
+}
+
+func (c *ConstraintsChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "constraints",
+		Level: LevelWarning,
+	}
+
+	var expected int
+	if baseline != nil {
+		for _, t := range baseline.Tables {
+			expected += len(t.Constraints)
+		}
+	}
+	if expected == 0 {
+		result.Passed = true
+		result.Message = "No constraints in baseline"
+		return result
+	}
+
+	currentTables := make(map[string]schema.Table, len(current.Tables))
+	for _, t := range current.Tables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t
+	}
+
+	var missingKeys, problems []string
+	for _, want := range baseline.Tables {
+		key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+		got, ok := currentTables[key]
+		if !ok {
+			// Missing tables are reported by tables_exist
+			continue
+		}
+		gotConstraints := make(map[string]schema.Constraint, len(got.Constraints))
+		hasPrimaryKey := false
+		for _, con := range got.Constraints {
+			gotConstraints[con.Name] = con
+			hasPrimaryKey = hasPrimaryKey || con.Type == schema.ConstraintPrimaryKey
+		}
+		for _, con := range want.Constraints {
+			have, ok := gotConstraints[con.Name]
+			switch {
+			case con.Type == schema.ConstraintPrimaryKey && !hasPrimaryKey:
+				missingKeys = append(missingKeys, fmt.Sprintf("%s has no primary key (baseline: %s)", key, con.Definition))
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s.%s is missing", key, con.Name))
+			case have.Definition != con.Definition:
+				problems = append(problems, fmt.Sprintf("%s.%s changed from %q to %q", key, con.Name, con.Definition, have.Definition))
+			}
+		}
+	}
+
+	if len(missingKeys) > 0 {
+		result.Level = LevelCritical
+		problems = append(missingKeys, problems...)
+	}
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d constraint problem(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("All %d baseline constraints present with matching definitions", expected)
+	return result
+}
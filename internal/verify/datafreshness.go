@@ -0,0 +1,72 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ColumnFreshness is the most recent value of a configured timestamp column.
+type ColumnFreshness struct {
+	Column string
+	// Latest is the largest value in the column, or nil if the table is empty.
+	Latest *time.Time
+	MaxAge time.Duration
+	// Err is set when the column could not be read, e.g. because its table did
+	// not restore.
+	Err error
+}
+
+// DataFreshnessChecker fails when the newest row of a configured timestamp column
+// is older than its maximum age. It catches a backup job that keeps producing
+// fresh files of an old snapshot, e.g. dumping a replica that stopped replicating.
+type DataFreshnessChecker struct {
+	Columns []ColumnFreshness
+	// Now is the reference time; the zero value means time.Now.
+	Now time.Time
+}
+
+func NewDataFreshnessChecker(columns []ColumnFreshness) *DataFreshnessChecker {
+	return &DataFreshnessChecker{Columns: columns}
+}
+
+func (c *DataFreshnessChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "data_freshness",
+		Level: LevelCritical,
+	}
+
+	now := c.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var problems []string
+	for _, col := range c.Columns {
+		if col.Err != nil {
+			problems = append(problems, col.Err.Error())
+			continue
+		}
+		if col.Latest == nil {
+			problems = append(problems, fmt.Sprintf("%s has no rows", col.Column))
+			continue
+		}
+		age := now.Sub(*col.Latest).Round(time.Minute)
+		if age > col.MaxAge {
+			problems = append(problems, fmt.Sprintf("latest %s is %s, %s old (maximum %s)",
+				col.Column, col.Latest.UTC().Format(time.RFC3339), age, col.MaxAge))
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d of %d columns stale: %s", len(problems), len(c.Columns), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Latest values of all %d columns are within their maximum age", len(c.Columns))
+	return result
+}
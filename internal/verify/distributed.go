@@ -0,0 +1,70 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// DistributedTablesChecker verifies hypertable chunk and distributed table shard counts
+// against the baseline. Plain table checks cannot see chunks or shards that went missing.
+type DistributedTablesChecker struct{}
+
+func NewDistributedTablesChecker() *DistributedTablesChecker {
+	return &DistributedTablesChecker{}
+}
+
+func (c *DistributedTablesChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "distributed_tables",
+		Level: LevelWarning,
+	}
+
+	if baseline == nil {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Found %d distributed tables (no baseline for comparison)", len(current.DistributedTables))
+		return result
+	}
+
+	if len(baseline.DistributedTables) == 0 {
+		result.Passed = true
+		result.Message = "No distributed tables in baseline"
+		return result
+	}
+
+	currentTables := make(map[string]schema.DistributedTable)
+	for _, t := range current.DistributedTables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t
+	}
+
+	var problems []string
+	for _, t := range baseline.DistributedTables {
+		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
+		cur, ok := currentTables[key]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is no longer a %s", key, t.Kind))
+		case cur.PartCount < t.PartCount:
+			problems = append(problems, fmt.Sprintf("%s has %d %s (baseline: %d)", key, cur.PartCount, partNoun(t.Kind), t.PartCount))
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d distributed tables degraded: %s", len(problems), strings.Join(problems, "; "))
+	} else {
+		result.Passed = true
+		result.Message = fmt.Sprintf("All %d distributed tables present with expected chunks/shards", len(baseline.DistributedTables))
+	}
+
+	return result
+}
+
+func partNoun(kind string) string {
+	if kind == schema.KindHypertable {
+		return "chunks"
+	}
+	return "shards"
+}
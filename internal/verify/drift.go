@@ -0,0 +1,140 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// TableDiff lists how the columns of one table differ from the baseline.
+type TableDiff struct {
+	Table   string         `json:"table"`
+	Added   []string       `json:"added,omitempty"`
+	Removed []string       `json:"removed,omitempty"`
+	Changed []ColumnChange `json:"changed,omitempty"`
+}
+
+// ColumnChange is a column whose type or nullability differs from the baseline.
+type ColumnChange struct {
+	Column string `json:"column"`
+	// Field is data_type or nullable.
+	Field    string `json:"field"`
+	Baseline string `json:"baseline"`
+	Current  string `json:"current"`
+}
+
+// String summarizes the diff, e.g. public.orders: added note; id type integer -> bigint.
+func (d TableDiff) String() string {
+	var parts []string
+	if len(d.Added) > 0 {
+		parts = append(parts, "added "+strings.Join(d.Added, ", "))
+	}
+	if len(d.Removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(d.Removed, ", "))
+	}
+	for _, c := range d.Changed {
+		field := "type"
+		if c.Field == "nullable" {
+			field = "nullable"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s -> %s", c.Column, field, c.Baseline, c.Current))
+	}
+	return fmt.Sprintf("%s: %s", d.Table, strings.Join(parts, "; "))
+}
+
+// ColumnDriftChecker diffs the columns of each table against the baseline:
+// columns added or removed, and changes of type or nullability. The per-table
+// diff is attached to the result.
+type ColumnDriftChecker struct{}
+
+func NewColumnDriftChecker() *ColumnDriftChecker {
+	return &ColumnDriftChecker{}
+}
+
+func (c *ColumnDriftChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "column_drift",
+		Level: LevelWarning,
+	}
+
+	if baseline == nil {
+		result.Passed = true
+		result.Message = "No baseline schema available"
+		return result
+	}
+
+	currentTables := make(map[string]schema.Table, len(current.Tables))
+	for _, t := range current.Tables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t
+	}
+
+	var compared int
+	for _, want := range baseline.Tables {
+		key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+		got, ok := currentTables[key]
+		// Missing tables are reported by tables_exist; baselines of document
+		// stores record no columns
+		if !ok || len(want.Columns) == 0 {
+			continue
+		}
+		compared++
+		if diff := diffColumns(key, want.Columns, got.Columns); diff != nil {
+			result.TableDiffs = append(result.TableDiffs, *diff)
+		}
+	}
+
+	if len(result.TableDiffs) > 0 {
+		problems := make([]string, len(result.TableDiffs))
+		for i, d := range result.TableDiffs {
+			problems[i] = d.String()
+		}
+		result.Passed = false
+		result.Message = fmt.Sprintf("Columns changed in %d/%d tables: %s", len(result.TableDiffs), compared, summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Columns of all %d compared tables match baseline", compared)
+	return result
+}
+
+// diffColumns returns the differences between the baseline and current columns
+// of table, or nil if there are none.
+func diffColumns(table string, baseline, current []schema.Column) *TableDiff {
+	currentColumns := make(map[string]schema.Column, len(current))
+	for _, col := range current {
+		currentColumns[col.Name] = col
+	}
+
+	diff := TableDiff{Table: table}
+	seen := make(map[string]bool, len(baseline))
+	for _, want := range baseline {
+		seen[want.Name] = true
+		got, ok := currentColumns[want.Name]
+		if !ok {
+			diff.Removed = append(diff.Removed, want.Name)
+			continue
+		}
+		if got.DataType != want.DataType {
+			diff.Changed = append(diff.Changed, ColumnChange{Column: want.Name, Field: "data_type", Baseline: want.DataType, Current: got.DataType})
+		}
+		if got.Nullable != want.Nullable {
+			diff.Changed = append(diff.Changed, ColumnChange{Column: want.Name, Field: "nullable", Baseline: fmt.Sprint(want.Nullable), Current: fmt.Sprint(got.Nullable)})
+		}
+	}
+	for _, col := range current {
+		if !seen[col.Name] {
+			diff.Added = append(diff.Added, col.Name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return nil
+	}
+	return &diff
+}
@@ -0,0 +1,96 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// DuplicateCounter looks for duplicate keys in the restored database.
+type DuplicateCounter interface {
+	// CountDuplicateKeys returns how many distinct values of columns occur in more
+	// than one row of the table.
+	CountDuplicateKeys(ctx context.Context, schemaName, table string, columns []string) (int64, error)
+}
+
+// DuplicateKeyChecker looks for duplicate values in the primary key and unique
+// columns of the baseline that were restored without their constraint. A
+// constraint fails to restore exactly when the data violates it, and the
+// duplicated rows then load anyway.
+type DuplicateKeyChecker struct {
+	Counter DuplicateCounter
+}
+
+func NewDuplicateKeyChecker(counter DuplicateCounter) *DuplicateKeyChecker {
+	return &DuplicateKeyChecker{Counter: counter}
+}
+
+func (c *DuplicateKeyChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "duplicate_keys",
+		Level: LevelCritical,
+	}
+
+	if baseline == nil {
+		result.Passed = true
+		result.Message = "No baseline schema available"
+		return result
+	}
+
+	currentTables := make(map[string]schema.Table, len(current.Tables))
+	for _, t := range current.Tables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t
+	}
+
+	var scanned int
+	var problems []string
+	for _, want := range baseline.Tables {
+		key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+		got, ok := currentTables[key]
+		if !ok {
+			// Missing tables are reported by tables_exist
+			continue
+		}
+		for _, con := range want.Constraints {
+			// The database enforces keys whose constraint was restored
+			if len(con.Columns) == 0 || hasKey(got, con.Columns) {
+				continue
+			}
+			scanned++
+			count, err := c.Counter.CountDuplicateKeys(ctx, want.Schema, want.Name, con.Columns)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s) could not be checked: %v", key, strings.Join(con.Columns, ", "), err))
+				continue
+			}
+			if count > 0 {
+				problems = append(problems, fmt.Sprintf("%s has %d duplicated %s value(s) of (%s)", key, count, con.Type, strings.Join(con.Columns, ", ")))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d key(s) with duplicates: %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	if scanned == 0 {
+		result.Message = "All baseline keys are enforced by restored constraints"
+		return result
+	}
+	result.Message = fmt.Sprintf("No duplicates in the %d baseline keys restored without their constraint", scanned)
+	return result
+}
+
+// hasKey reports whether t has a primary key or unique constraint on exactly columns.
+func hasKey(t schema.Table, columns []string) bool {
+	for _, con := range t.Constraints {
+		if con.Type != schema.ConstraintCheck && strings.Join(con.Columns, "\x00") == strings.Join(columns, "\x00") {
+			return true
+		}
+	}
+	return false
+}
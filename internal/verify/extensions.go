@@ -0,0 +1,72 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ExtensionChecker verifies that required extensions, and every extension in the
+// baseline, are installed in the restored database. A missing extension usually
+// means the restore image lacks it and the objects depending on it did not restore.
+type ExtensionChecker struct {
+	Required []string
+}
+
+func NewExtensionChecker(required []string) *ExtensionChecker {
+	return &ExtensionChecker{Required: required}
+}
+
+func (c *ExtensionChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "extensions",
+		Level: LevelCritical,
+	}
+
+	installed := make(map[string]bool, len(current.Extensions))
+	for _, e := range current.Extensions {
+		installed[e.Name] = true
+	}
+
+	expected := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if !expected[name] {
+			expected[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, name := range c.Required {
+		add(name)
+	}
+	if baseline != nil {
+		for _, e := range baseline.Extensions {
+			add(e.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("%d extensions installed (none required)", len(current.Extensions))
+		return result
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !installed[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d extension(s) missing: %s", len(missing), strings.Join(missing, ", "))
+	} else {
+		result.Passed = true
+		result.Message = fmt.Sprintf("All %d expected extensions installed", len(names))
+	}
+
+	return result
+}
@@ -0,0 +1,60 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// DefaultFreshnessMaxAge is the oldest acceptable backup when none is configured.
+const DefaultFreshnessMaxAge = 26 * time.Hour
+
+// BackupFreshnessChecker fails when the backup is older than a maximum age. A
+// backup that restores perfectly but is a week old is as bad as a broken one.
+type BackupFreshnessChecker struct {
+	// ArtifactTime is when the backup was written, or nil if the source does not know.
+	ArtifactTime *time.Time
+	MaxAge       time.Duration
+	// Now is the reference time; the zero value means time.Now.
+	Now time.Time
+}
+
+func NewBackupFreshnessChecker(artifactTime *time.Time, maxAgeHours int) *BackupFreshnessChecker {
+	maxAge := DefaultFreshnessMaxAge
+	if maxAgeHours > 0 {
+		maxAge = time.Duration(maxAgeHours) * time.Hour
+	}
+	return &BackupFreshnessChecker{ArtifactTime: artifactTime, MaxAge: maxAge}
+}
+
+func (c *BackupFreshnessChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "backup_freshness",
+		Level: LevelCritical,
+	}
+
+	if c.ArtifactTime == nil {
+		result.Level = LevelWarning
+		result.Passed = false
+		result.Message = "The backup source reports no modification time and the producer metadata has no created_at, so the backup age is unknown"
+		return result
+	}
+
+	now := c.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	age := now.Sub(*c.ArtifactTime).Round(time.Minute)
+	written := c.ArtifactTime.UTC().Format(time.RFC3339)
+	if age > c.MaxAge {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Backup written at %s is %s old, older than the maximum of %s", written, age, c.MaxAge)
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Backup written at %s is %s old (maximum %s)", written, age, c.MaxAge)
+	return result
+}
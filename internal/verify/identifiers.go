@@ -0,0 +1,162 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// maxIdentifierExamples bounds how many identifiers a result message lists.
+const maxIdentifierExamples = 5
+
+// reservedWords are SQL keywords that are common table or column names and must be
+// quoted wherever they are used.
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "both": true, "case": true, "cast": true, "check": true,
+	"column": true, "constraint": true, "create": true, "current_date": true,
+	"current_time": true, "current_user": true, "default": true, "desc": true,
+	"distinct": true, "do": true, "else": true, "end": true, "except": true, "false": true,
+	"fetch": true, "for": true, "foreign": true, "from": true, "grant": true, "group": true,
+	"having": true, "in": true, "index": true, "key": true, "limit": true, "not": true,
+	"null": true, "offset": true, "on": true, "only": true, "or": true, "order": true,
+	"primary": true, "references": true, "select": true, "table": true, "then": true,
+	"to": true, "true": true, "union": true, "unique": true, "user": true, "using": true,
+	"when": true, "where": true, "window": true, "with": true,
+}
+
+// IdentifierChecker flags table and column names that recovery tooling tends to
+// mishandle: scripts that skip quoting, tools that split schema.table on dots, and
+// restores into a database that folds case differently. Quotes, control characters,
+// whitespace and dots are warnings; names that merely require quoting are
+// informational.
+type IdentifierChecker struct {
+	DatabaseType string
+}
+
+func NewIdentifierChecker(databaseType string) *IdentifierChecker {
+	return &IdentifierChecker{DatabaseType: databaseType}
+}
+
+func (c *IdentifierChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "identifier_hazards",
+		Level: LevelInfo,
+	}
+
+	switch c.DatabaseType {
+	case "mongodb", "elasticsearch", "opensearch":
+		result.Passed = true
+		result.Message = fmt.Sprintf("Not applicable to %s", c.DatabaseType)
+		return result
+	}
+
+	var hazardous, severe int
+	var examples []string
+	seen := make(map[string]bool)
+	inspect := func(kind, display, name string) {
+		if seen[kind+"\x00"+display] {
+			return
+		}
+		seen[kind+"\x00"+display] = true
+		problems, isSevere := identifierProblems(name)
+		if len(problems) == 0 {
+			return
+		}
+		hazardous++
+		if isSevere {
+			severe++
+		}
+		if len(examples) < maxIdentifierExamples {
+			examples = append(examples, fmt.Sprintf("%s %q: %s", kind, display, strings.Join(problems, ", ")))
+		}
+	}
+	for _, t := range current.Tables {
+		table := t.Name
+		if t.Schema != "" {
+			inspect("schema", t.Schema, t.Schema)
+			table = t.Schema + "." + t.Name
+		}
+		inspect("table", table, t.Name)
+		for _, col := range t.Columns {
+			inspect("column", table+"."+col.Name, col.Name)
+		}
+	}
+
+	if hazardous == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("No hazardous names among %d table(s)", len(current.Tables))
+		return result
+	}
+
+	result.Passed = false
+	if severe > 0 {
+		result.Level = LevelWarning
+	}
+	more := ""
+	if hazardous > len(examples) {
+		more = fmt.Sprintf("; and %d more", hazardous-len(examples))
+	}
+	result.Message = fmt.Sprintf("%d name(s) may break recovery tooling: %s%s", hazardous, strings.Join(examples, "; "), more)
+	return result
+}
+
+// identifierProblems describes what makes name hazardous, and whether any of it
+// is severe rather than a matter of quoting.
+func identifierProblems(name string) (problems []string, severe bool) {
+	addSevere := func(problem string) {
+		problems = append(problems, problem)
+		severe = true
+	}
+
+	if strings.ContainsAny(name, "\"'`") {
+		addSevere("embedded quote")
+	}
+	if strings.IndexFunc(name, isHiddenRune) >= 0 {
+		addSevere("control character")
+	}
+	if strings.TrimSpace(name) != name {
+		addSevere("leading or trailing whitespace")
+	} else if strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		addSevere("whitespace")
+	}
+	if strings.Contains(name, ".") {
+		addSevere("dot, read as a schema separator")
+	}
+
+	if strings.ToLower(name) != name {
+		problems = append(problems, "upper case, folded or matched differently across databases")
+	}
+	if strings.IndexFunc(name, func(r rune) bool { return r > unicode.MaxASCII }) >= 0 {
+		problems = append(problems, "non-ASCII characters")
+	}
+	if reservedWords[strings.ToLower(name)] {
+		problems = append(problems, "reserved word")
+	}
+	if len(problems) == 0 && !plainIdentifier(name) {
+		problems = append(problems, "requires quoting")
+	}
+	return problems, severe
+}
+
+// plainIdentifier reports whether name can be used unquoted: a lower-case letter
+// or underscore followed by lower-case letters, digits, underscores or dollars.
+func plainIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '$'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isHiddenRune reports whether r is a control or invisible formatting character.
+func isHiddenRune(r rune) bool {
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
@@ -0,0 +1,81 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// IndexChecker compares the indexes of each restored table against the baseline.
+// pg_restore carries on past a failed CREATE INDEX in some modes, so a restore
+// can succeed with indexes missing, and queries that relied on them slow to a
+// crawl after a real recovery.
+type IndexChecker struct{}
+
+func NewIndexChecker() *IndexChecker {
+	return &IndexChecker{}
+}
+
+func (c *IndexChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "indexes",
+		Level: LevelWarning,
+	}
+
+	var problems []string
+	var restored int
+	currentTables := make(map[string]schema.Table, len(current.Tables))
+	for _, t := range current.Tables {
+		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
+		currentTables[key] = t
+		restored += len(t.Indexes)
+		for _, idx := range t.Indexes {
+			if idx.Invalid {
+				problems = append(problems, fmt.Sprintf("%s.%s is invalid", key, idx.Name))
+			}
+		}
+	}
+
+	var expected int
+	if baseline != nil {
+		for _, want := range baseline.Tables {
+			expected += len(want.Indexes)
+			key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+			got, ok := currentTables[key]
+			if !ok {
+				// Missing tables are reported by tables_exist
+				continue
+			}
+			gotIndexes := make(map[string]schema.Index, len(got.Indexes))
+			for _, idx := range got.Indexes {
+				gotIndexes[idx.Name] = idx
+			}
+			for _, idx := range want.Indexes {
+				have, ok := gotIndexes[idx.Name]
+				switch {
+				case !ok:
+					problems = append(problems, fmt.Sprintf("%s.%s is missing", key, idx.Name))
+				case have.Definition != idx.Definition:
+					problems = append(problems, fmt.Sprintf("%s.%s changed from %q to %q", key, idx.Name, idx.Definition, have.Definition))
+				case have.Unique != idx.Unique:
+					problems = append(problems, fmt.Sprintf("%s.%s changed uniqueness (baseline unique: %t)", key, idx.Name, idx.Unique))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d index problem(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	if expected == 0 {
+		result.Message = fmt.Sprintf("Recorded %d indexes (no indexes in baseline for comparison)", restored)
+		return result
+	}
+	result.Message = fmt.Sprintf("All %d baseline indexes restored with matching definitions", expected)
+	return result
+}
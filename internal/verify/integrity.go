@@ -0,0 +1,102 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// maxListedProblems caps how many problems are quoted in a check message.
+const maxListedProblems = 5
+
+// IntegrityChecker reports the result of the database's own corruption check.
+type IntegrityChecker struct {
+	Problems []string
+}
+
+func NewIntegrityChecker(problems []string) *IntegrityChecker {
+	return &IntegrityChecker{Problems: problems}
+}
+
+func (c *IntegrityChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "integrity_check",
+		Level: LevelCritical,
+	}
+
+	if len(c.Problems) == 0 {
+		result.Passed = true
+		result.Message = "Database integrity check passed"
+		return result
+	}
+
+	result.Passed = false
+	result.Message = fmt.Sprintf("Database integrity check found %d problem(s): %s",
+		len(c.Problems), summarizeProblems(c.Problems))
+	return result
+}
+
+// AmcheckChecker reports B-tree indexes that amcheck found corrupted. Queries
+// using a corrupted index return wrong results without any error.
+type AmcheckChecker struct {
+	Problems []string
+}
+
+func NewAmcheckChecker(problems []string) *AmcheckChecker {
+	return &AmcheckChecker{Problems: problems}
+}
+
+func (c *AmcheckChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "amcheck",
+		Level: LevelCritical,
+	}
+
+	if len(c.Problems) == 0 {
+		result.Passed = true
+		result.Message = "amcheck found no corrupted B-tree indexes"
+		return result
+	}
+
+	result.Passed = false
+	result.Message = fmt.Sprintf("amcheck found %d corrupted index(es): %s",
+		len(c.Problems), summarizeProblems(c.Problems))
+	return result
+}
+
+// ForeignKeyChecker reports rows that reference missing parent rows.
+type ForeignKeyChecker struct {
+	Violations []string
+}
+
+func NewForeignKeyChecker(violations []string) *ForeignKeyChecker {
+	return &ForeignKeyChecker{Violations: violations}
+}
+
+func (c *ForeignKeyChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "foreign_key_check",
+		Level: LevelWarning,
+	}
+
+	if len(c.Violations) == 0 {
+		result.Passed = true
+		result.Message = "No foreign key violations"
+		return result
+	}
+
+	result.Passed = false
+	result.Message = fmt.Sprintf("%d foreign key violation(s): %s",
+		len(c.Violations), summarizeProblems(c.Violations))
+	return result
+}
+
+// summarizeProblems joins the first few problems for display.
+func summarizeProblems(problems []string) string {
+	if len(problems) <= maxListedProblems {
+		return strings.Join(problems, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(problems[:maxListedProblems], "; "), len(problems)-maxListedProblems)
+}
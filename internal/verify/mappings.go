@@ -0,0 +1,67 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// IndexMappingChecker reports search indices whose field mapping differs from the
+// baseline. Only tables with a mapping hash, i.e. search indices, are compared.
+type IndexMappingChecker struct{}
+
+func NewIndexMappingChecker() *IndexMappingChecker {
+	return &IndexMappingChecker{}
+}
+
+func (c *IndexMappingChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "index_mappings",
+		Level: LevelWarning,
+	}
+
+	if baseline == nil {
+		result.Passed = true
+		result.Message = "No baseline schema available"
+		return result
+	}
+
+	baselineHashes := make(map[string]string)
+	for _, t := range baseline.Tables {
+		if t.MappingHash != "" {
+			baselineHashes[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t.MappingHash
+		}
+	}
+
+	if len(baselineHashes) == 0 {
+		result.Passed = true
+		result.Message = "No index mappings in baseline"
+		return result
+	}
+
+	var compared int
+	var changed []string
+	for _, t := range current.Tables {
+		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
+		expected, ok := baselineHashes[key]
+		if !ok || t.MappingHash == "" {
+			continue
+		}
+		compared++
+		if t.MappingHash != expected {
+			changed = append(changed, key)
+		}
+	}
+
+	if len(changed) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Mapping changed in %d/%d indices: %s", len(changed), compared, strings.Join(changed, ", "))
+	} else {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Mappings of all %d compared indices match baseline", compared)
+	}
+
+	return result
+}
@@ -0,0 +1,94 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// NullRatioChecker compares the NULL ratio of critical columns with the most
+// recent earlier run that counted them. A column suddenly full of NULLs is a
+// cheap tell of COPY data that was truncated or shifted between columns.
+type NullRatioChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History []*schema.Metrics
+	// MaxIncreasePercent is the tolerated rise in percentage points.
+	MaxIncreasePercent float64
+	Level              Level
+	// Errors are the columns whose NULLs could not be counted.
+	Errors []error
+}
+
+func NewNullRatioChecker(history []*schema.Metrics, maxIncreasePercent float64, level Level) *NullRatioChecker {
+	return &NullRatioChecker{History: history, MaxIncreasePercent: maxIncreasePercent, Level: level}
+}
+
+func (c *NullRatioChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "null_ratios",
+		Level: c.Level,
+	}
+
+	var columns []schema.ColumnNulls
+	if metrics != nil {
+		columns = metrics.ColumnNulls
+	}
+	if len(columns) == 0 && len(c.Errors) == 0 {
+		result.Passed = true
+		result.Message = "No column NULLs counted"
+		return result
+	}
+
+	var compared int
+	var anomalies []string
+	for _, n := range columns {
+		prev, ok := c.previous(n.QualifiedName())
+		if !ok {
+			continue
+		}
+		compared++
+		if rise := (n.Ratio() - prev.Ratio()) * 100; rise > c.MaxIncreasePercent {
+			anomalies = append(anomalies, fmt.Sprintf("%s NULL ratio %.1f%% → %.1f%%", n.QualifiedName(), prev.Ratio()*100, n.Ratio()*100))
+		}
+	}
+
+	if len(c.Errors) > 0 {
+		problems := make([]string, 0, len(c.Errors)+len(anomalies))
+		for _, err := range c.Errors {
+			problems = append(problems, err.Error())
+		}
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d columns could not be counted, %d rose beyond %g points of NULLs: %s",
+			len(c.Errors), len(anomalies), c.MaxIncreasePercent, summarizeProblems(append(problems, anomalies...)))
+		return result
+	}
+
+	if compared == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Counted NULLs of %d columns; no previous counts to compare", len(columns))
+		return result
+	}
+
+	if len(anomalies) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d columns rose beyond %g points of NULLs: %s", len(anomalies), c.MaxIncreasePercent, summarizeProblems(anomalies))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("NULL ratios of %d columns consistent with the previous run", compared)
+	return result
+}
+
+// previous returns the latest earlier NULL count of the named column.
+func (c *NullRatioChecker) previous(name string) (schema.ColumnNulls, bool) {
+	for i := len(c.History) - 1; i >= 0; i-- {
+		for _, n := range c.History[i].ColumnNulls {
+			if n.QualifiedName() == name {
+				return n, true
+			}
+		}
+	}
+	return schema.ColumnNulls{}, false
+}
@@ -0,0 +1,63 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// OrphanCounter checks declared relationships in the restored database.
+type OrphanCounter interface {
+	// CountOrphans counts the rows whose non-NULL child column value is missing
+	// from the parent column.
+	CountOrphans(ctx context.Context, child, parent string) (int64, error)
+}
+
+// Relationship is a declared parent/child relation between two columns.
+type Relationship struct {
+	Child  string
+	Parent string
+}
+
+// OrphanedRowsChecker looks for child rows that reference missing parent rows in
+// relationships declared in the configuration. Schemas that keep referential
+// integrity in the application have no foreign keys for the database to enforce,
+// so a dump taken without a consistent snapshot restores orphans silently.
+type OrphanedRowsChecker struct {
+	Counter       OrphanCounter
+	Relationships []Relationship
+}
+
+func NewOrphanedRowsChecker(counter OrphanCounter, relationships []Relationship) *OrphanedRowsChecker {
+	return &OrphanedRowsChecker{Counter: counter, Relationships: relationships}
+}
+
+func (c *OrphanedRowsChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "orphaned_rows",
+		Level: LevelCritical,
+	}
+
+	var problems []string
+	for _, rel := range c.Relationships {
+		count, err := c.Counter.CountOrphans(ctx, rel.Child, rel.Parent)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s → %s could not be checked: %v", rel.Child, rel.Parent, err))
+			continue
+		}
+		if count > 0 {
+			problems = append(problems, fmt.Sprintf("%s has %d row(s) with no matching %s", rel.Child, count, rel.Parent))
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d of %d relationships with orphaned rows: %s", len(problems), len(c.Relationships), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("No orphaned rows in %d declared relationships", len(c.Relationships))
+	return result
+}
@@ -0,0 +1,80 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// PrivilegesChecker compares table and sequence ownership and grants against the
+// baseline. Objects restored under the wrong owner or without their grants break
+// application access after a real recovery even though the data is intact.
+type PrivilegesChecker struct{}
+
+func NewPrivilegesChecker() *PrivilegesChecker {
+	return &PrivilegesChecker{}
+}
+
+func (c *PrivilegesChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "privileges",
+		Level: LevelWarning,
+	}
+
+	if baseline == nil || len(baseline.Privileges) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Recorded ownership of %d objects (no baseline for comparison)", len(current.Privileges))
+		return result
+	}
+
+	currentObjects := make(map[string]schema.ObjectPrivileges, len(current.Privileges))
+	for _, p := range current.Privileges {
+		currentObjects[fmt.Sprintf("%s.%s", p.Schema, p.Name)] = p
+	}
+
+	var problems []string
+	for _, want := range baseline.Privileges {
+		key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+		got, ok := currentObjects[key]
+		if !ok {
+			// Missing objects are reported by tables_exist
+			continue
+		}
+		if got.Owner != want.Owner {
+			problems = append(problems, fmt.Sprintf("%s owned by %s (baseline: %s)", key, got.Owner, want.Owner))
+		}
+		if lost := missingFrom(want.Grants, got.Grants); len(lost) > 0 {
+			problems = append(problems, fmt.Sprintf("%s lost %s", key, strings.Join(lost, ", ")))
+		}
+		if gained := missingFrom(got.Grants, want.Grants); len(gained) > 0 {
+			problems = append(problems, fmt.Sprintf("%s gained %s", key, strings.Join(gained, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d ownership/privilege drift(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Ownership and grants of %d objects match baseline", len(baseline.Privileges))
+	return result
+}
+
+// missingFrom returns the entries of want that are not in got.
+func missingFrom(want, got []string) []string {
+	have := make(map[string]bool, len(got))
+	for _, g := range got {
+		have[g] = true
+	}
+	var missing []string
+	for _, w := range want {
+		if !have[w] {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/backup"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ProducerMetadataChecker validates the metadata attached to the artifact by the backup job.
+type ProducerMetadataChecker struct {
+	Metadata *backup.ProducerMetadata
+	// ExpectedMajorVersion is the configured database major version.
+	ExpectedMajorVersion int
+}
+
+func NewProducerMetadataChecker(metadata *backup.ProducerMetadata, expectedMajorVersion int) *ProducerMetadataChecker {
+	return &ProducerMetadataChecker{Metadata: metadata, ExpectedMajorVersion: expectedMajorVersion}
+}
+
+func (c *ProducerMetadataChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "producer_metadata",
+		Level: LevelInfo,
+	}
+
+	if c.Metadata == nil {
+		result.Passed = true
+		result.Message = "No producer metadata attached to backup artifact"
+		return result
+	}
+
+	if c.Metadata.SourceDBVersion != "" {
+		major, err := c.Metadata.SourceMajorVersion()
+		if err != nil {
+			result.Level = LevelWarning
+			result.Passed = false
+			result.Message = err.Error()
+			return result
+		}
+		if c.ExpectedMajorVersion > 0 && major != c.ExpectedMajorVersion {
+			result.Level = LevelWarning
+			result.Passed = false
+			result.Message = fmt.Sprintf("Backup was produced from database version %s but restore targets major version %d",
+				c.Metadata.SourceDBVersion, c.ExpectedMajorVersion)
+			return result
+		}
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Backup produced on %s (database version %s, LSN %s)",
+		valueOrUnknown(c.Metadata.ProducerHost), valueOrUnknown(c.Metadata.SourceDBVersion), valueOrUnknown(c.Metadata.LSN))
+	return result
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
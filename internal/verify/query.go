@@ -0,0 +1,86 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// QueryRunner runs SQL against the restored database.
+type QueryRunner interface {
+	// RunQuery returns how many rows query returned and the first column of the
+	// first row as text, nil when there are no rows or the value is NULL.
+	RunQuery(ctx context.Context, query string) (rows int, first *string, err error)
+}
+
+// CustomQuery is a user-defined SQL check and the result it must produce.
+type CustomQuery struct {
+	Name     string
+	SQL      string
+	MinRows  *int
+	MaxRows  *int
+	Equals   *string
+	NonEmpty bool
+	Level    Level
+}
+
+// QueryChecker runs a custom query against the live restored database, so
+// business invariants can be checked alongside the schema.
+type QueryChecker struct {
+	Runner QueryRunner
+	Query  CustomQuery
+}
+
+func NewQueryChecker(runner QueryRunner, query CustomQuery) *QueryChecker {
+	return &QueryChecker{Runner: runner, Query: query}
+}
+
+func (c *QueryChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	q := c.Query
+	result := CheckResult{
+		Name:  "custom_query:" + q.Name,
+		Level: q.Level,
+	}
+
+	rows, first, err := c.Runner.RunQuery(ctx, q.SQL)
+	if err != nil {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Query failed: %v", err)
+		return result
+	}
+
+	var problems []string
+	if q.NonEmpty && rows == 0 {
+		problems = append(problems, "returned no rows")
+	}
+	if q.MinRows != nil && rows < *q.MinRows {
+		problems = append(problems, fmt.Sprintf("returned %d row(s), expected at least %d", rows, *q.MinRows))
+	}
+	if q.MaxRows != nil && rows > *q.MaxRows {
+		problems = append(problems, fmt.Sprintf("returned %d row(s), expected at most %d", rows, *q.MaxRows))
+	}
+	if q.Equals != nil {
+		switch {
+		case rows == 0:
+			problems = append(problems, fmt.Sprintf("returned no value, expected %q", *q.Equals))
+		case first == nil:
+			problems = append(problems, fmt.Sprintf("returned NULL, expected %q", *q.Equals))
+		case *first != *q.Equals:
+			problems = append(problems, fmt.Sprintf("returned %q, expected %q", *first, *q.Equals))
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = "Query " + strings.Join(problems, "; ")
+		return result
+	}
+	result.Passed = true
+	result.Message = fmt.Sprintf("Query returned %d row(s)", rows)
+	if q.Equals != nil {
+		result.Message += fmt.Sprintf(" with the expected value %q", *q.Equals)
+	}
+	return result
+}
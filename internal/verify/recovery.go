@@ -0,0 +1,56 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"restorable.io/restorable-cli/internal/backup"
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// RecoveryPointChecker validates that a point-in-time recovery replayed WAL or binlogs.
+type RecoveryPointChecker struct {
+	RecoveryPoint *backup.RecoveryPoint
+}
+
+func NewRecoveryPointChecker(rp *backup.RecoveryPoint) *RecoveryPointChecker {
+	return &RecoveryPointChecker{RecoveryPoint: rp}
+}
+
+func (c *RecoveryPointChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "point_in_time_recovery",
+		Level: LevelWarning,
+	}
+
+	rp := c.RecoveryPoint
+	if rp.AchievedTime == nil {
+		result.Passed = false
+		after := "base backup " + rp.BackupName
+		if rp.Binlogs {
+			after = "snapshot position " + rp.StartSegment
+		}
+		result.Message = fmt.Sprintf("No transactions were replayed from %d %s after %s", rp.WALSegments, rp.LogUnit(), after)
+		return result
+	}
+
+	achieved := rp.AchievedTime.UTC().Format(time.RFC3339)
+	if rp.TargetTime == nil {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Replayed %d %s to %s", rp.WALSegments, rp.LogUnit(), achieved)
+		return result
+	}
+
+	target := rp.TargetTime.UTC().Format(time.RFC3339)
+	if rp.AchievedTime.After(*rp.TargetTime) {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Recovered to %s, past the target %s", achieved, target)
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Replayed %d %s to %s (target %s, %s before it)",
+		rp.WALSegments, rp.LogUnit(), achieved, target, rp.TargetTime.Sub(*rp.AchievedTime).Round(time.Second))
+	return result
+}
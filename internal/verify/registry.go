@@ -0,0 +1,226 @@
+package verify
+
+import "sort"
+
+// Option describes a configuration key of a check.
+type Option struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+// Definition describes an available check. Checks are constructed in the verify
+// command from runtime state; the registry only documents them.
+type Definition struct {
+	// ID is the check name used in results and reports.
+	ID           string `json:"id"`
+	Description  string `json:"description"`
+	DefaultLevel Level  `json:"default_level"`
+	// EnabledBy is the config key that enables the check, or empty if it always runs.
+	EnabledBy string `json:"enabled_by,omitempty"`
+	// Requires lists checks that must pass before this one runs.
+	Requires []string `json:"requires,omitempty"`
+	// Databases lists the database types the check applies to; empty means all.
+	Databases []string `json:"databases,omitempty"`
+	Options   []Option `json:"options,omitempty"`
+}
+
+// AlwaysOn reports whether the check runs without configuration.
+func (d Definition) AlwaysOn() bool {
+	return d.EnabledBy == ""
+}
+
+var registry = []Definition{
+	{
+		ID:           "tables_exist",
+		Description:  "All tables in the baseline exist in the restored database, except planned removals",
+		DefaultLevel: LevelCritical,
+		Options: []Option{
+			{Key: "verification.schema.annotations_path", Type: "string", Description: "File declaring planned table additions and removals"},
+		},
+	},
+	{
+		ID:           "table_count",
+		Description:  "The number of tables matches the baseline, allowing for planned removals",
+		DefaultLevel: LevelWarning,
+		Options: []Option{
+			{Key: "verification.schema.annotations_path", Type: "string", Description: "File declaring planned table additions and removals"},
+		},
+	},
+	{
+		ID:           "new_tables",
+		Description:  "Lists tables present in the restore but not in the baseline",
+		DefaultLevel: LevelInfo,
+	},
+	{
+		ID:           "distributed_tables",
+		Description:  "Hypertable chunk and distributed table shard counts match the baseline",
+		DefaultLevel: LevelWarning,
+		Databases:    []string{"postgres"},
+	},
+	{
+		ID:           "index_mappings",
+		Description:  "Search index mappings match the baseline",
+		DefaultLevel: LevelWarning,
+		Databases:    []string{"elasticsearch", "opensearch"},
+	},
+	{
+		ID:           "extensions",
+		Description:  "Required extensions and every extension in the baseline are installed",
+		DefaultLevel: LevelCritical,
+		Databases:    []string{"postgres"},
+		Options: []Option{
+			{Key: "verification.extensions.required", Type: "list", Description: "Extensions the application depends on, e.g. postgis"},
+		},
+	},
+	{
+		ID:           "spatial_columns",
+		Description:  "PostGIS geometry and geography columns kept their type and SRID, and sampled values are valid",
+		DefaultLevel: LevelWarning,
+		Databases:    []string{"postgres"},
+	},
+	{
+		ID:           "verification_run",
+		Description:  "Recorded only when a run ends early, e.g. the backup cannot be acquired or Docker is down",
+		DefaultLevel: LevelCritical,
+	},
+	{
+		ID:           "app_rehearsal",
+		Description:  "The application's smoke test passes against the restored database",
+		DefaultLevel: LevelCritical,
+		EnabledBy:    "verification.rehearsal",
+		Databases:    []string{"postgres", "mariadb", "mysql", "mongodb", "cockroachdb"},
+		Options: []Option{
+			{Key: "verification.rehearsal.image", Type: "string", Description: "Application image started next to the restored database"},
+			{Key: "verification.rehearsal.command", Type: "list", Description: "Smoke test command; the image's default command runs when empty"},
+			{Key: "verification.rehearsal.env", Type: "map", Description: "Extra environment; values may reference DATABASE_URL and the DB_* variables"},
+			{Key: "verification.rehearsal.timeout_seconds", Type: "int", Default: "300", Description: "Time the smoke test may take before it fails"},
+		},
+	},
+	{
+		ID:           "point_in_time_recovery",
+		Description:  "WAL or binlogs after the snapshot replayed to the recovery target",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "backup.walg or database.restore.binlogs",
+		Databases:    []string{"postgres", "mysql", "mariadb"},
+		Options: []Option{
+			{Key: "backup.walg.target_time", Type: "string", Description: "RFC 3339 time to recover to; all archived WAL is replayed when empty"},
+			{Key: "database.restore.binlogs.path", Type: "string", Description: "Directory holding the source's binlog files"},
+			{Key: "database.restore.binlogs.target_time", Type: "string", Description: "RFC 3339 time to recover to; all binlogs are replayed when empty"},
+		},
+	},
+	{
+		ID:           "privileges",
+		Description:  "Table and sequence owners and grants match the baseline",
+		DefaultLevel: LevelWarning,
+		Databases:    []string{"postgres"},
+		Options: []Option{
+			{Key: "database.restore.roles", Type: "list", Description: "Roles created before the restore so ownership and grants restore"},
+			{Key: "database.restore.preserve_ownership", Type: "bool", Default: "false", Description: "Restore object owners instead of assigning everything to the restore user"},
+		},
+	},
+	{
+		ID:           "identifier_hazards",
+		Description:  "Flags schema, table and column names with quotes, control characters, whitespace or dots (warning), or that need quoting (info)",
+		DefaultLevel: LevelInfo,
+		Databases:    []string{"postgres", "cockroachdb", "mariadb", "mysql", "sqlite"},
+	},
+	{
+		ID:           "row_counts",
+		Description:  "Per-table row counts stay within a threshold of the baseline, or of previous runs when adaptive thresholds are enabled",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "verification.row_counts.enabled",
+		Requires:     []string{"tables_exist"},
+		Options: []Option{
+			{Key: "verification.row_counts.warn_threshold_percent", Type: "int", Description: "Change in row count that triggers a warning"},
+			{Key: "verification.adaptive.enabled", Type: "bool", Default: "false", Description: "Derive thresholds from previous runs"},
+			{Key: "verification.adaptive.percentile", Type: "float", Default: "95", Description: "Percentile of previous runs beyond which a run is flagged"},
+			{Key: "verification.adaptive.min_runs", Type: "int", Default: "5", Description: "Previous runs needed before adaptive thresholds apply"},
+			{Key: "verification.adaptive.history_runs", Type: "int", Default: "30", Description: "Previous runs considered"},
+		},
+	},
+	{
+		ID:           "non_empty_tables",
+		Description:  "At least one table contains rows",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "verification.row_counts.enabled",
+		Requires:     []string{"tables_exist"},
+	},
+	{
+		ID:           "total_row_count",
+		Description:  "The restored database contains at least one row in total",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "verification.row_counts.enabled",
+		Requires:     []string{"tables_exist"},
+	},
+	{
+		ID:           "table_checksums",
+		Description:  "Table content checksums match the baseline",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "verification.checksums.enabled",
+		Requires:     []string{"tables_exist"},
+		Databases:    []string{"postgres", "mariadb", "mysql"},
+	},
+	{
+		ID:           "column_profiles",
+		Description:  "Distinct estimates, null rates and average sizes of selected columns match the previous run",
+		DefaultLevel: LevelWarning,
+		EnabledBy:    "verification.column_profiles.enabled",
+		Requires:     []string{"tables_exist"},
+		Databases:    []string{"postgres"},
+		Options: []Option{
+			{Key: "verification.column_profiles.columns", Type: "list", Description: "Columns to profile as schema.table.column or table.column"},
+			{Key: "verification.column_profiles.warn_threshold_percent", Type: "int", Default: "20", Description: "Change that triggers a warning"},
+		},
+	},
+	{
+		ID:           "restore_duration",
+		Description:  "Records the restore duration, flagging outliers against previous runs when adaptive thresholds are enabled",
+		DefaultLevel: LevelInfo,
+	},
+	{
+		ID:           "producer_metadata",
+		Description:  "Reports metadata published by the backup producer and its database version",
+		DefaultLevel: LevelInfo,
+	},
+	{
+		ID:           "backup_freshness",
+		Description:  "The backup was written within the maximum age, going by the source's modification time",
+		DefaultLevel: LevelCritical,
+		EnabledBy:    "verification.freshness.enabled",
+		Options: []Option{
+			{Key: "verification.freshness.max_age_hours", Type: "int", Default: "26", Description: "Oldest acceptable backup"},
+		},
+	},
+	{
+		ID:           "integrity_check",
+		Description:  "The database's own corruption check found no problems",
+		DefaultLevel: LevelCritical,
+		Databases:    []string{"sqlite"},
+	},
+	{
+		ID:           "foreign_key_check",
+		Description:  "No rows violate foreign key constraints",
+		DefaultLevel: LevelWarning,
+		Databases:    []string{"sqlite"},
+	},
+}
+
+// Definitions returns all registered checks sorted by ID.
+func Definitions() []Definition {
+	defs := make([]Definition, len(registry))
+	copy(defs, registry)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].ID < defs[j].ID })
+	return defs
+}
+
+// Lookup returns the definition of the check with the given ID.
+func Lookup(id string) (Definition, bool) {
+	for _, d := range registry {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
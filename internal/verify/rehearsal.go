@@ -0,0 +1,57 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// RehearsalChecker reports whether the application's smoke test passed against the
+// restored database.
+type RehearsalChecker struct {
+	Image    string
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+	// Output is the tail of the application's logs.
+	Output string
+}
+
+func NewRehearsalChecker(image string, exitCode int, timedOut bool, duration time.Duration, output string) *RehearsalChecker {
+	return &RehearsalChecker{Image: image, ExitCode: exitCode, TimedOut: timedOut, Duration: duration, Output: output}
+}
+
+func (c *RehearsalChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "app_rehearsal",
+		Level: LevelCritical,
+	}
+
+	duration := c.Duration.Round(time.Second)
+	switch {
+	case c.TimedOut:
+		result.Passed = false
+		result.Message = fmt.Sprintf("%s did not finish within %s", c.Image, duration)
+	case c.ExitCode != 0:
+		result.Passed = false
+		result.Message = fmt.Sprintf("%s exited with code %d after %s", c.Image, c.ExitCode, duration)
+	default:
+		result.Passed = true
+		result.Message = fmt.Sprintf("%s passed against the restored database in %s", c.Image, duration)
+		return result
+	}
+
+	if last := lastLine(c.Output); last != "" {
+		result.Message += ": " + last
+	}
+	return result
+}
+
+// lastLine returns the last non-blank line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
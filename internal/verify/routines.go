@@ -0,0 +1,52 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// RoutinesChecker verifies that every function and procedure in the baseline was
+// restored. Routines missing after a restore usually mean the dump was taken
+// without them, e.g. mysqldump without --routines.
+type RoutinesChecker struct{}
+
+func NewRoutinesChecker() *RoutinesChecker {
+	return &RoutinesChecker{}
+}
+
+func (c *RoutinesChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "routines",
+		Level: LevelCritical,
+	}
+
+	if baseline == nil || len(baseline.Routines) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Recorded %d routines (no baseline for comparison)", len(current.Routines))
+		return result
+	}
+
+	restored := make(map[string]bool, len(current.Routines))
+	for _, r := range current.Routines {
+		restored[r.Signature()] = true
+	}
+
+	var missing []string
+	for _, want := range baseline.Routines {
+		if !restored[want.Signature()] {
+			missing = append(missing, fmt.Sprintf("%s %s", want.Type, want.Signature()))
+		}
+	}
+
+	if len(missing) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Missing %d/%d routines: %s", len(missing), len(baseline.Routines), summarizeProblems(missing))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("All %d baseline routines present", len(baseline.Routines))
+	return result
+}
@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// SampleChecksumChecker compares the hashes of row samples with the most recent
+// earlier run that hashed the same sample. Samples of rows that no longer change
+// catch silent corruption that row counts and full-table checksums of growing
+// tables cannot.
+type SampleChecksumChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History []*schema.Metrics
+	// Errors are the samples that could not be hashed.
+	Errors []error
+}
+
+func NewSampleChecksumChecker(history []*schema.Metrics) *SampleChecksumChecker {
+	return &SampleChecksumChecker{History: history}
+}
+
+func (c *SampleChecksumChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "sample_checksums",
+		Level: LevelWarning,
+	}
+
+	var samples []schema.SampleChecksum
+	if metrics != nil {
+		samples = metrics.SampleChecksums
+	}
+	if len(samples) == 0 && len(c.Errors) == 0 {
+		result.Passed = true
+		result.Message = "No row samples hashed"
+		return result
+	}
+
+	var compared int
+	var changed []string
+	for _, s := range samples {
+		prev, ok := c.previous(s)
+		if !ok {
+			continue
+		}
+		compared++
+		switch {
+		case s.Rows != prev.Rows:
+			changed = append(changed, fmt.Sprintf("%s (%s): %d rows, previously %d", s.Table, s.Sample, s.Rows, prev.Rows))
+		case s.Checksum != prev.Checksum:
+			changed = append(changed, fmt.Sprintf("%s (%s): content changed", s.Table, s.Sample))
+		}
+	}
+
+	if len(c.Errors) > 0 {
+		problems := make([]string, 0, len(c.Errors)+len(changed))
+		for _, err := range c.Errors {
+			problems = append(problems, err.Error())
+		}
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d row samples could not be hashed, %d differ from the previous run: %s",
+			len(c.Errors), len(changed), summarizeProblems(append(problems, changed...)))
+		return result
+	}
+
+	if compared == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Hashed %d row samples; no previous hashes to compare", len(samples))
+		return result
+	}
+
+	if len(changed) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d/%d row samples differ from the previous run: %s", len(changed), compared, summarizeProblems(changed))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("All %d compared row samples match the previous run", compared)
+	return result
+}
+
+// previous returns the latest earlier hash of the same sample.
+func (c *SampleChecksumChecker) previous(sample schema.SampleChecksum) (schema.SampleChecksum, bool) {
+	for i := len(c.History) - 1; i >= 0; i-- {
+		for _, s := range c.History[i].SampleChecksums {
+			if s.Table == sample.Table && s.Sample == sample.Sample {
+				return s, true
+			}
+		}
+	}
+	return schema.SampleChecksum{}, false
+}
@@ -0,0 +1,119 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// TableSizeChecker flags tables whose row count or on-disk size moved away from
+// the trend of previous runs: it expects each table to keep its average change
+// per run, and reports deviations beyond a drop or growth threshold. Unlike the
+// baseline comparison, a table that grows steadily does not alert, while one
+// that suddenly stops growing or doubles does.
+type TableSizeChecker struct {
+	// History holds the metrics of previous runs, oldest first.
+	History []*schema.Metrics
+	// MaxDropPercent and MaxGrowthPercent bound the deviation from the trend.
+	MaxDropPercent   float64
+	MaxGrowthPercent float64
+	// MinRuns is the number of previous runs needed before the check applies.
+	MinRuns int
+}
+
+func NewTableSizeChecker(history []*schema.Metrics, maxDropPercent, maxGrowthPercent float64, minRuns int) *TableSizeChecker {
+	return &TableSizeChecker{History: history, MaxDropPercent: maxDropPercent, MaxGrowthPercent: maxGrowthPercent, MinRuns: minRuns}
+}
+
+func (c *TableSizeChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "table_sizes",
+		Level: LevelWarning,
+	}
+
+	if metrics == nil {
+		result.Passed = true
+		result.Message = "No metrics available"
+		return result
+	}
+	if len(c.History) < c.MinRuns || len(c.History) == 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Collecting history for table size trends (%d/%d runs)", len(c.History), c.MinRuns)
+		return result
+	}
+
+	runs := make([]map[string]schema.TableMetrics, len(c.History))
+	for i, m := range c.History {
+		runs[i] = make(map[string]schema.TableMetrics, len(m.TableMetrics))
+		for _, tm := range m.TableMetrics {
+			runs[i][fmt.Sprintf("%s.%s", tm.Schema, tm.Name)] = tm
+		}
+	}
+
+	var anomalies []string
+	for _, tm := range metrics.TableMetrics {
+		name := fmt.Sprintf("%s.%s", tm.Schema, tm.Name)
+		rows := series(runs, name, func(m schema.TableMetrics) int64 { return m.RowCount })
+		if msg := c.deviation(rows, tm.RowCount, "rows"); msg != "" {
+			anomalies = append(anomalies, fmt.Sprintf("%s %s", name, msg))
+		}
+		if tm.SizeBytes == 0 {
+			continue
+		}
+		sizes := series(runs, name, func(m schema.TableMetrics) int64 { return m.SizeBytes })
+		if msg := c.deviation(sizes, tm.SizeBytes, "bytes"); msg != "" {
+			anomalies = append(anomalies, fmt.Sprintf("%s %s", name, msg))
+		}
+	}
+
+	if len(anomalies) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d table size(s) off their trend: %s", len(anomalies), summarizeProblems(anomalies))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Table sizes follow the trend of the last %d runs", len(c.History))
+	return result
+}
+
+// series returns a value of the table in each previous run that recorded it,
+// oldest first, skipping runs where it was zero.
+func series(runs []map[string]schema.TableMetrics, name string, value func(schema.TableMetrics) int64) []int64 {
+	var values []int64
+	for _, run := range runs {
+		if tm, ok := run[name]; ok {
+			if v := value(tm); v > 0 {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// deviation describes how far current is from the value the series' average
+// change per run predicts, or returns "" when it is within the thresholds.
+func (c *TableSizeChecker) deviation(series []int64, current int64, unit string) string {
+	if len(series) < c.MinRuns || len(series) == 0 {
+		return ""
+	}
+	last := float64(series[len(series)-1])
+	expected := last
+	if len(series) > 1 {
+		expected += (last - float64(series[0])) / float64(len(series)-1)
+	}
+	if expected <= 0 {
+		return ""
+	}
+
+	change := (float64(current) - expected) / expected * 100
+	switch {
+	case change < 0 && -change > c.MaxDropPercent:
+		return fmt.Sprintf("has %d %s, %.1f%% below the expected %d", current, unit, -change, int64(math.Round(expected)))
+	case change > c.MaxGrowthPercent:
+		return fmt.Sprintf("has %d %s, %.1f%% above the expected %d", current, unit, change, int64(math.Round(expected)))
+	}
+	return ""
+}
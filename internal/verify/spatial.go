@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// SpatialColumnsChecker verifies that PostGIS geometry and geography columns kept
+// their type and SRID and that sampled values are valid geometries.
+type SpatialColumnsChecker struct{}
+
+func NewSpatialColumnsChecker() *SpatialColumnsChecker {
+	return &SpatialColumnsChecker{}
+}
+
+func (c *SpatialColumnsChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "spatial_columns",
+		Level: LevelWarning,
+	}
+
+	currentColumns := make(map[string]schema.SpatialColumn, len(current.SpatialColumns))
+	var problems []string
+	for _, col := range current.SpatialColumns {
+		currentColumns[col.QualifiedName()] = col
+		if col.InvalidSample > 0 {
+			problems = append(problems, fmt.Sprintf("%s has %d invalid sampled value(s)", col.QualifiedName(), col.InvalidSample))
+		}
+	}
+
+	if baseline != nil {
+		for _, want := range baseline.SpatialColumns {
+			key := want.QualifiedName()
+			got, ok := currentColumns[key]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s is missing", key))
+			case got.Type != want.Type || got.SRID != want.SRID:
+				problems = append(problems, fmt.Sprintf("%s is %s SRID %d (baseline: %s SRID %d)", key, got.Type, got.SRID, want.Type, want.SRID))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d spatial column problem(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	switch {
+	case len(current.SpatialColumns) == 0:
+		result.Message = "No spatial columns"
+	case baseline == nil:
+		result.Message = fmt.Sprintf("Found %d valid spatial columns (no baseline for comparison)", len(current.SpatialColumns))
+	default:
+		result.Message = fmt.Sprintf("All %d spatial columns present with expected type and SRID", len(current.SpatialColumns))
+	}
+	return result
+}
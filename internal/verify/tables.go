@@ -9,10 +9,13 @@ import (
 )
 
 // TablesExistChecker verifies that expected tables exist in the restored database.
-type TablesExistChecker struct{}
+// Tables with a planned removal are not expected.
+type TablesExistChecker struct {
+	annotations *Annotations
+}
 
-func NewTablesExistChecker() *TablesExistChecker {
-	return &TablesExistChecker{}
+func NewTablesExistChecker(annotations *Annotations) *TablesExistChecker {
+	return &TablesExistChecker{annotations: annotations}
 }
 
 func (c *TablesExistChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
@@ -36,30 +39,39 @@ func (c *TablesExistChecker) Check(ctx context.Context, current *schema.Schema,
 	}
 
 	// Check which baseline tables are missing
-	var missingTables []string
+	var missingTables, plannedRemovals []string
 	for _, t := range baseline.Tables {
 		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
-		if !currentTables[key] {
+		if currentTables[key] {
+			continue
+		}
+		if a := c.annotations.Expected(key, ChangeRemoved); a != nil {
+			plannedRemovals = append(plannedRemovals, a.describe(key))
+		} else {
 			missingTables = append(missingTables, key)
 		}
 	}
 
+	note := plannedNote(ChangeRemoved, plannedRemovals)
 	if len(missingTables) > 0 {
 		result.Passed = false
-		result.Message = fmt.Sprintf("Missing %d tables: %s", len(missingTables), strings.Join(missingTables, ", "))
+		result.Message = fmt.Sprintf("Missing %d tables: %s%s", len(missingTables), strings.Join(missingTables, ", "), note)
 	} else {
 		result.Passed = true
-		result.Message = fmt.Sprintf("All %d expected tables present", len(baseline.Tables))
+		result.Message = fmt.Sprintf("All %d expected tables present%s", len(baseline.Tables)-len(plannedRemovals), note)
 	}
 
 	return result
 }
 
-// TableCountChecker verifies that the number of tables matches the baseline.
-type TableCountChecker struct{}
+// TableCountChecker verifies that the number of tables matches the baseline. Tables
+// with a planned removal do not count as a decrease.
+type TableCountChecker struct {
+	annotations *Annotations
+}
 
-func NewTableCountChecker() *TableCountChecker {
-	return &TableCountChecker{}
+func NewTableCountChecker(annotations *Annotations) *TableCountChecker {
+	return &TableCountChecker{annotations: annotations}
 }
 
 func (c *TableCountChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
@@ -81,6 +93,9 @@ func (c *TableCountChecker) Check(ctx context.Context, current *schema.Schema, b
 	} else if diff > 0 {
 		result.Passed = true // New tables are typically not a failure
 		result.Message = fmt.Sprintf("Table count increased: %d tables (+%d from baseline)", len(current.Tables), diff)
+	} else if planned := c.plannedRemovals(current, baseline); diff+planned >= 0 {
+		result.Passed = true
+		result.Message = fmt.Sprintf("Table count decreased as planned: %d tables (%d from baseline, %d planned removal(s))", len(current.Tables), diff, planned)
 	} else {
 		result.Passed = false
 		result.Message = fmt.Sprintf("Table count decreased: %d tables (%d from baseline)", len(current.Tables), diff)
@@ -89,11 +104,30 @@ func (c *TableCountChecker) Check(ctx context.Context, current *schema.Schema, b
 	return result
 }
 
-// NewTablesChecker reports new tables that weren't in the baseline.
-type NewTablesChecker struct{}
+// plannedRemovals counts the baseline tables missing from current whose removal is planned.
+func (c *TableCountChecker) plannedRemovals(current, baseline *schema.Schema) int {
+	currentTables := make(map[string]bool)
+	for _, t := range current.Tables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = true
+	}
+	var planned int
+	for _, t := range baseline.Tables {
+		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
+		if !currentTables[key] && c.annotations.Expected(key, ChangeRemoved) != nil {
+			planned++
+		}
+	}
+	return planned
+}
 
-func NewNewTablesChecker() *NewTablesChecker {
-	return &NewTablesChecker{}
+// NewTablesChecker reports new tables that weren't in the baseline, noting the
+// ones whose addition is planned.
+type NewTablesChecker struct {
+	annotations *Annotations
+}
+
+func NewNewTablesChecker(annotations *Annotations) *NewTablesChecker {
+	return &NewTablesChecker{annotations: annotations}
 }
 
 func (c *NewTablesChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
@@ -116,19 +150,25 @@ func (c *NewTablesChecker) Check(ctx context.Context, current *schema.Schema, ba
 	}
 
 	// Find new tables
-	var newTables []string
+	var newTables, plannedAdditions []string
 	for _, t := range current.Tables {
 		key := fmt.Sprintf("%s.%s", t.Schema, t.Name)
-		if !baselineTables[key] {
+		if baselineTables[key] {
+			continue
+		}
+		if a := c.annotations.Expected(key, ChangeAdded); a != nil {
+			plannedAdditions = append(plannedAdditions, a.describe(key))
+		} else {
 			newTables = append(newTables, key)
 		}
 	}
 
 	result.Passed = true // New tables are informational, not a failure
+	note := plannedNote(ChangeAdded, plannedAdditions)
 	if len(newTables) > 0 {
-		result.Message = fmt.Sprintf("Found %d new tables: %s", len(newTables), strings.Join(newTables, ", "))
+		result.Message = fmt.Sprintf("Found %d new tables: %s%s", len(newTables), strings.Join(newTables, ", "), note)
 	} else {
-		result.Message = "No new tables detected"
+		result.Message = "No new tables detected" + note
 	}
 
 	return result
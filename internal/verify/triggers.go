@@ -0,0 +1,77 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// TriggersChecker verifies that the triggers of each baseline table were restored
+// and fire. A data-only restore into a schema created without them, or a
+// --disable-triggers restore that stopped early, leaves audit and business
+// logic silently switched off.
+type TriggersChecker struct{}
+
+func NewTriggersChecker() *TriggersChecker {
+	return &TriggersChecker{}
+}
+
+func (c *TriggersChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "triggers",
+		Level: LevelCritical,
+	}
+
+	var expected int
+	if baseline != nil {
+		for _, t := range baseline.Tables {
+			expected += len(t.Triggers)
+		}
+	}
+	if expected == 0 {
+		result.Passed = true
+		result.Message = "No triggers in baseline"
+		return result
+	}
+
+	currentTables := make(map[string]schema.Table, len(current.Tables))
+	for _, t := range current.Tables {
+		currentTables[fmt.Sprintf("%s.%s", t.Schema, t.Name)] = t
+	}
+
+	var problems []string
+	for _, want := range baseline.Tables {
+		key := fmt.Sprintf("%s.%s", want.Schema, want.Name)
+		got, ok := currentTables[key]
+		if !ok {
+			// Missing tables are reported by tables_exist
+			continue
+		}
+		gotTriggers := make(map[string]schema.Trigger, len(got.Triggers))
+		for _, t := range got.Triggers {
+			gotTriggers[t.Name] = t
+		}
+		for _, t := range want.Triggers {
+			have, ok := gotTriggers[t.Name]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s.%s is missing", key, t.Name))
+			case have.Disabled && !t.Disabled:
+				problems = append(problems, fmt.Sprintf("%s.%s is disabled", key, t.Name))
+			case have.Definition != t.Definition:
+				problems = append(problems, fmt.Sprintf("%s.%s changed from %q to %q", key, t.Name, t.Definition, have.Definition))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d trigger problem(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("All %d baseline triggers present and enabled", expected)
+	return result
+}
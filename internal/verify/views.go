@@ -0,0 +1,67 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"restorable.io/restorable-cli/internal/schema"
+)
+
+// ViewsChecker verifies that the views and materialized views of the baseline
+// were restored and can be queried. A view whose tables or functions failed to
+// restore is skipped by pg_restore, and MariaDB and SQLite keep it but fail
+// every query against it.
+type ViewsChecker struct{}
+
+func NewViewsChecker() *ViewsChecker {
+	return &ViewsChecker{}
+}
+
+func (c *ViewsChecker) Check(ctx context.Context, current *schema.Schema, baseline *schema.Schema, metrics *schema.Metrics) CheckResult {
+	result := CheckResult{
+		Name:  "views",
+		Level: LevelCritical,
+	}
+
+	var problems []string
+	currentViews := make(map[string]schema.View, len(current.Views))
+	for _, v := range current.Views {
+		currentViews[v.QualifiedName()] = v
+		if v.Error != "" {
+			problems = append(problems, fmt.Sprintf("%s fails: %s", v.QualifiedName(), v.Error))
+		}
+	}
+
+	var expected int
+	if baseline != nil {
+		expected = len(baseline.Views)
+		for _, want := range baseline.Views {
+			key := want.QualifiedName()
+			got, ok := currentViews[key]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("%s is missing", key))
+			case got.Error != "":
+				// Already reported
+			case want.Populated && !got.Populated:
+				problems = append(problems, fmt.Sprintf("%s is not populated", key))
+			case want.RowCount != nil && *want.RowCount > 0 && got.RowCount != nil && *got.RowCount == 0:
+				problems = append(problems, fmt.Sprintf("%s is empty after refresh (baseline: %d rows)", key, *want.RowCount))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Passed = false
+		result.Message = fmt.Sprintf("%d view problem(s): %s", len(problems), summarizeProblems(problems))
+		return result
+	}
+
+	result.Passed = true
+	if expected == 0 {
+		result.Message = fmt.Sprintf("All %d views can be queried (no views in baseline for comparison)", len(current.Views))
+		return result
+	}
+	result.Message = fmt.Sprintf("All %d baseline views present and queryable", expected)
+	return result
+}
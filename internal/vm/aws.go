@@ -0,0 +1,141 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+// AWSProvider launches EC2 instances with the aws CLI, so its credential chain,
+// profiles and SSO sessions all work.
+type AWSProvider struct {
+	image            string
+	typ              string
+	region           string
+	keyName          string
+	subnetID         string
+	securityGroupIDs []string
+	diskGB           int
+}
+
+// NewAWSProvider creates an EC2 provider. The aws CLI must be installed.
+func NewAWSProvider(cfg *config.VM) (*AWSProvider, error) {
+	if cfg.Image == "" {
+		return nil, fmt.Errorf("execution.vm.image (an AMI ID) is required for aws")
+	}
+	if _, err := exec.LookPath("aws"); err != nil {
+		return nil, fmt.Errorf("execution.vm with provider aws requires the aws CLI to be installed: %w", err)
+	}
+	return &AWSProvider{
+		image:            cfg.Image,
+		typ:              cfg.Type,
+		region:           cfg.Region,
+		keyName:          cfg.KeyName,
+		subnetID:         cfg.SubnetID,
+		securityGroupIDs: cfg.SecurityGroupIDs,
+		diskGB:           cfg.DiskGB,
+	}, nil
+}
+
+func (p *AWSProvider) Name() string {
+	return "aws"
+}
+
+// Create launches an instance and waits until it is running. Powering the instance
+// off from inside terminates it, which backs up max_lifetime_minutes.
+func (p *AWSProvider) Create(ctx context.Context, name string, labels map[string]string, userData string) (*Machine, error) {
+	tags := []string{fmt.Sprintf("{Key=Name,Value=%s}", name)}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tags = append(tags, fmt.Sprintf("{Key=%s,Value=%s}", k, labels[k]))
+	}
+
+	args := []string{"ec2", "run-instances",
+		"--image-id", p.image,
+		"--instance-type", p.typ,
+		"--count", "1",
+		"--instance-initiated-shutdown-behavior", "terminate",
+		"--tag-specifications", "ResourceType=instance,Tags=[" + strings.Join(tags, ",") + "]",
+		"--query", "Instances[0].InstanceId",
+		"--output", "text",
+	}
+	if p.keyName != "" {
+		args = append(args, "--key-name", p.keyName)
+	}
+	if p.subnetID != "" {
+		args = append(args, "--subnet-id", p.subnetID)
+	}
+	if len(p.securityGroupIDs) > 0 {
+		args = append(append(args, "--security-group-ids"), p.securityGroupIDs...)
+	}
+	if userData != "" {
+		args = append(args, "--user-data", userData)
+	}
+	if p.diskGB > 0 {
+		device, err := p.aws(ctx, "ec2", "describe-images", "--image-ids", p.image,
+			"--query", "Images[0].RootDeviceName", "--output", "text")
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the root device of %s: %w", p.image, err)
+		}
+		args = append(args, "--block-device-mappings",
+			fmt.Sprintf("DeviceName=%s,Ebs={VolumeSize=%d,VolumeType=gp3,DeleteOnTermination=true}", device, p.diskGB))
+	}
+
+	id, err := p.aws(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch EC2 instance: %w", err)
+	}
+	m := &Machine{ID: id, Name: name}
+
+	if _, err := p.aws(ctx, "ec2", "wait", "instance-running", "--instance-ids", id); err != nil {
+		return m, fmt.Errorf("EC2 instance %s did not start: %w", id, err)
+	}
+	// Instances in private subnets are reached on their private address
+	address, err := p.aws(ctx, "ec2", "describe-instances", "--instance-ids", id,
+		"--query", "Reservations[0].Instances[0].[PublicIpAddress,PrivateIpAddress]", "--output", "text")
+	if err != nil {
+		return m, fmt.Errorf("failed to get the address of EC2 instance %s: %w", id, err)
+	}
+	for _, a := range strings.Fields(address) {
+		if a != "None" {
+			m.Address = a
+			break
+		}
+	}
+	if m.Address == "" {
+		return m, fmt.Errorf("EC2 instance %s has no IP address", id)
+	}
+	return m, nil
+}
+
+// Delete terminates the instance; its root volume is deleted with it.
+func (p *AWSProvider) Delete(ctx context.Context, m *Machine) error {
+	if _, err := p.aws(ctx, "ec2", "terminate-instances", "--instance-ids", m.ID); err != nil {
+		return fmt.Errorf("failed to terminate EC2 instance %s: %w", m.ID, err)
+	}
+	return nil
+}
+
+// aws runs the aws CLI in the configured region and returns its trimmed output.
+func (p *AWSProvider) aws(ctx context.Context, args ...string) (string, error) {
+	if p.region != "" {
+		args = append(args, "--region", p.region)
+	}
+	cmd := exec.CommandContext(ctx, "aws", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w\nstderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
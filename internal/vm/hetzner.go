@@ -0,0 +1,172 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	hetznerAPI          = "https://api.hetzner.cloud/v1"
+	hetznerDefaultImage = "docker-ce"
+	hetznerPollInterval = 3 * time.Second
+)
+
+// HetznerProvider creates servers with the Hetzner Cloud API.
+type HetznerProvider struct {
+	token    string
+	image    string
+	typ      string
+	location string
+	sshKey   string
+	client   *http.Client
+}
+
+// NewHetznerProvider creates a Hetzner provider. The API token is read from
+// token_env, HCLOUD_TOKEN by default.
+func NewHetznerProvider(cfg *config.VM) (*HetznerProvider, error) {
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = "HCLOUD_TOKEN"
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("Hetzner API token environment variable %s is not set", tokenEnv)
+	}
+	image := cfg.Image
+	if image == "" {
+		image = hetznerDefaultImage
+	}
+	return &HetznerProvider{
+		token:    token,
+		image:    image,
+		typ:      cfg.Type,
+		location: cfg.Region,
+		sshKey:   cfg.KeyName,
+		client:   &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+func (p *HetznerProvider) Name() string {
+	return "hetzner"
+}
+
+// hetznerServer is the part of a server object that is used.
+type hetznerServer struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	PublicNet struct {
+		IPv4 struct {
+			IP string `json:"ip"`
+		} `json:"ipv4"`
+	} `json:"public_net"`
+}
+
+// Create creates a server and waits until it is running.
+func (p *HetznerProvider) Create(ctx context.Context, name string, labels map[string]string, userData string) (*Machine, error) {
+	request := map[string]any{
+		"name":        name,
+		"server_type": p.typ,
+		"image":       p.image,
+		"labels":      labels,
+	}
+	if p.location != "" {
+		request["location"] = p.location
+	}
+	if p.sshKey != "" {
+		request["ssh_keys"] = []string{p.sshKey}
+	}
+	if userData != "" {
+		request["user_data"] = userData
+	}
+
+	var created struct {
+		Server hetznerServer `json:"server"`
+	}
+	if err := p.call(ctx, http.MethodPost, "/servers", request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create Hetzner server: %w", err)
+	}
+	m := &Machine{ID: strconv.FormatInt(created.Server.ID, 10), Name: name}
+
+	server := created.Server
+	for server.Status != "running" {
+		select {
+		case <-ctx.Done():
+			return m, ctx.Err()
+		case <-time.After(hetznerPollInterval):
+		}
+		var current struct {
+			Server hetznerServer `json:"server"`
+		}
+		if err := p.call(ctx, http.MethodGet, "/servers/"+m.ID, nil, &current); err != nil {
+			return m, fmt.Errorf("failed to get Hetzner server %s: %w", m.ID, err)
+		}
+		server = current.Server
+	}
+	m.Address = server.PublicNet.IPv4.IP
+	if m.Address == "" {
+		return m, fmt.Errorf("Hetzner server %s has no public IPv4 address", m.ID)
+	}
+	return m, nil
+}
+
+// Delete deletes the server and its disk.
+func (p *HetznerProvider) Delete(ctx context.Context, m *Machine) error {
+	if err := p.call(ctx, http.MethodDelete, "/servers/"+m.ID, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete Hetzner server %s: %w", m.ID, err)
+	}
+	return nil
+}
+
+// call sends a JSON request to the API and decodes the response into out.
+func (p *HetznerProvider) call(ctx context.Context, method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, hetznerAPI+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s (%s)", apiErr.Error.Message, apiErr.Error.Code)
+		}
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
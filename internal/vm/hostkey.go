@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKey is an SSH host key generated for one VM. It is installed with
+// cloud-init at first boot, so the connection that carries keys and secrets to
+// the VM is authenticated without trusting the network on first use.
+type HostKey struct {
+	public  ssh.PublicKey
+	private []byte
+}
+
+// NewHostKey generates an ed25519 host key.
+func NewHostKey() (*HostKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VM host key: %w", err)
+	}
+	public, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VM host key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "restorable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode VM host key: %w", err)
+	}
+	return &HostKey{public: public, private: pem.EncodeToMemory(block)}, nil
+}
+
+// PublicKey returns the public half the VM must present.
+func (k *HostKey) PublicKey() ssh.PublicKey {
+	return k.public
+}
+
+// UserData returns cloud-init user data installing the host key, followed by
+// userData, the configured user data, if any. The parts are combined as a MIME
+// multipart message, which cloud-init splits, detecting the type of userData
+// from its first line as it would on its own.
+func (k *HostKey) UserData(userData string) (string, error) {
+	var cloudConfig strings.Builder
+	cloudConfig.WriteString("#cloud-config\nssh_deletekeys: true\nssh_keys:\n  ed25519_private: |\n")
+	for _, line := range strings.Split(strings.TrimSpace(string(k.private)), "\n") {
+		cloudConfig.WriteString("    " + line + "\n")
+	}
+	cloudConfig.WriteString("  ed25519_public: " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(k.public))) + "\n")
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	addPart := func(contentType, content string) error {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", contentType+`; charset="utf-8"`)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write([]byte(content))
+		return err
+	}
+	if err := addPart("text/cloud-config", cloudConfig.String()); err != nil {
+		return "", fmt.Errorf("failed to build VM user data: %w", err)
+	}
+	if userData != "" {
+		if err := addPart("text/plain", userData); err != nil {
+			return "", fmt.Errorf("failed to build VM user data: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to build VM user data: %w", err)
+	}
+	return fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\nMIME-Version: 1.0\n\n%s", w.Boundary(), body.String()), nil
+}
@@ -0,0 +1,231 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"restorable.io/restorable-cli/internal/config"
+)
+
+const (
+	sshPort           = 22
+	sshConnectTimeout = 30 * time.Second
+	// sshRetryInterval spaces connection attempts while the VM boots
+	sshRetryInterval = 5 * time.Second
+)
+
+// Machine is a provisioned VM.
+type Machine struct {
+	// ID is the EC2 instance ID or Hetzner server ID.
+	ID   string
+	Name string
+	// Address is the IP address SSH connects to.
+	Address string
+}
+
+// Provider creates and deletes VMs with a cloud API.
+type Provider interface {
+	// Create provisions a VM with cloud-init userData and waits until it is
+	// running with an address.
+	Create(ctx context.Context, name string, labels map[string]string, userData string) (*Machine, error)
+	// Delete removes the VM and its disks.
+	Delete(ctx context.Context, m *Machine) error
+	// Name identifies the provider in output, e.g. "hetzner".
+	Name() string
+}
+
+// NewProvider returns the provider configured in cfg.
+func NewProvider(cfg *config.VM) (Provider, error) {
+	if cfg.Type == "" {
+		return nil, fmt.Errorf("execution.vm.type is required")
+	}
+	switch cfg.Provider {
+	case "aws":
+		return NewAWSProvider(cfg)
+	case "hetzner":
+		return NewHetznerProvider(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported VM provider %q (use aws or hetzner)", cfg.Provider)
+	}
+}
+
+// DefaultUser returns the SSH login user for cfg.
+func DefaultUser(cfg *config.VM) string {
+	switch {
+	case cfg.User != "":
+		return cfg.User
+	case cfg.Provider == "hetzner":
+		return "root"
+	default:
+		return "ec2-user"
+	}
+}
+
+// Session is an SSH connection to a VM.
+type Session struct {
+	client *ssh.Client
+	sftp   *sftp.Client
+}
+
+// Connect logs in to m, retrying until SSH is up or timeout elapses. The VM must
+// present hostKey, installed at first boot with its user data.
+func Connect(ctx context.Context, m *Machine, user, keyPath string, hostKey *HostKey, timeout time.Duration) (*Session, error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key %s: %w", keyPath, err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s (encrypted keys are not supported): %w", keyPath, err)
+	}
+	clientConfig := &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey.PublicKey()),
+		Timeout:         sshConnectTimeout,
+	}
+
+	addr := net.JoinHostPort(m.Address, fmt.Sprint(sshPort))
+	deadline := time.Now().Add(timeout)
+	for {
+		client, err := ssh.Dial("tcp", addr, clientConfig)
+		if err == nil {
+			sftpClient, err := sftp.NewClient(client)
+			if err != nil {
+				client.Close()
+				return nil, fmt.Errorf("failed to start SFTP on %s: %w", m.Name, err)
+			}
+			return &Session{client: client, sftp: sftpClient}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("SSH to %s (%s) did not come up within %s: %w", m.Name, addr, timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sshRetryInterval):
+		}
+	}
+}
+
+// Run runs command with the login shell, streaming its output, and returns its
+// exit code. An error means the command could not be run at all.
+func (s *Session) Run(command string, stdout, stderr io.Writer) (int, error) {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer session.Close()
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	err = session.Run(command)
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return exitErr.ExitStatus(), nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to run %q: %w", command, err)
+	}
+	return 0, nil
+}
+
+// Output runs command and returns its combined output, failing on a non-zero exit.
+func (s *Session) Output(command string) (string, error) {
+	var out strings.Builder
+	code, err := s.Run(command, &out, &out)
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", fmt.Errorf("%q failed (exit %d): %s", command, code, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
+
+// Upload copies the local file src to dst, relative to the login user's home
+// directory, creating parent directories.
+func (s *Session) Upload(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+	return s.Write(dst, in, mode)
+}
+
+// Write copies r to dst, relative to the login user's home directory, creating
+// parent directories.
+func (s *Session) Write(dst string, r io.Reader, mode os.FileMode) error {
+	if err := s.sftp.MkdirAll(path.Dir(dst)); err != nil {
+		return fmt.Errorf("failed to create %s on VM: %w", path.Dir(dst), err)
+	}
+	out, err := s.sftp.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create %s on VM: %w", dst, err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("failed to write %s on VM: %w", dst, err)
+	}
+	if err := s.sftp.Chmod(dst, mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s on VM: %w", dst, err)
+	}
+	return nil
+}
+
+// Download copies the remote file src to the local file dst.
+func (s *Session) Download(src, dst string) error {
+	in, err := s.sftp.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s on VM: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to download %s: %w", src, err)
+	}
+	return out.Close()
+}
+
+// ReadFile returns the contents of the remote file name.
+func (s *Session) ReadFile(name string) ([]byte, error) {
+	f, err := s.sftp.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s on VM: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// ReadDir lists the file names in the remote directory dir.
+func (s *Session) ReadDir(dir string) ([]string, error) {
+	entries, err := s.sftp.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Mode().IsRegular() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Close ends the SSH connection.
+func (s *Session) Close() error {
+	s.sftp.Close()
+	return s.client.Close()
+}